@@ -11,17 +11,34 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/user/coc/internal/accountdeletion"
 	"github.com/user/coc/internal/app/address"
 	"github.com/user/coc/internal/app/admin"
 	"github.com/user/coc/internal/app/admin_auth"
 	"github.com/user/coc/internal/app/admin_menu"
+	"github.com/user/coc/internal/app/audit_log"
+	"github.com/user/coc/internal/app/data_export"
 	"github.com/user/coc/internal/app/frontend_auth"
+	"github.com/user/coc/internal/app/role"
+	"github.com/user/coc/internal/app/security_event"
 	"github.com/user/coc/internal/app/user"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/config"
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/lockout"
+	"github.com/user/coc/internal/mailer"
 	"github.com/user/coc/internal/middleware"
+	"github.com/user/coc/internal/oidc"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/partition"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/passhistory"
+	"github.com/user/coc/internal/refreshtoken"
+	"github.com/user/coc/internal/revocation"
 	"github.com/user/coc/internal/router"
+	"github.com/user/coc/internal/signing"
+	"github.com/user/coc/internal/totp"
+	"github.com/user/coc/internal/usersession"
 	"github.com/user/coc/internal/validation"
 )
 
@@ -77,6 +94,10 @@ func main() {
 
 	slog.Info("starting application", "port", cfg.Port)
 
+	if cfg.FrontendJWTPrivateKeyFile == "" && cfg.AdminJWTPrivateKeyFile == "" && cfg.FrontendJWTSecret == cfg.AdminJWTSecret {
+		slog.Warn("frontend and admin tokens share a signing secret; set FRONTEND_JWT_SECRET and ADMIN_JWT_SECRET")
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -92,6 +113,19 @@ func main() {
 	// Initialize queries
 	queries := db.New(pool)
 
+	// Keep audit/error log partitions rolling forward
+	partitionInterval, err := time.ParseDuration(cfg.PartitionMaintenanceInterval)
+	if err != nil {
+		slog.Error("invalid PARTITION_MAINTENANCE_INTERVAL format", "error", err)
+		os.Exit(1)
+	}
+	partitionManager := partition.NewManager(queries, partition.Options{
+		MonthsAhead:     cfg.PartitionMonthsAhead,
+		RetentionMonths: cfg.PartitionRetentionMonths,
+		DetachOnly:      cfg.PartitionDetachOnly,
+	})
+	partitionManager.Start(ctx, partitionInterval)
+
 	// Initialize validator with i18n support (en, zh)
 	validator := validation.New()
 
@@ -102,16 +136,231 @@ func main() {
 		os.Exit(1)
 	}
 
+	refreshTokenDuration, err := time.ParseDuration(cfg.RefreshTokenDuration)
+	if err != nil {
+		slog.Error("invalid REFRESH_TOKEN_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	adminTokenDuration, err := time.ParseDuration(cfg.AdminTokenDuration)
+	if err != nil {
+		slog.Error("invalid ADMIN_TOKEN_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	adminRefreshTokenDuration, err := time.ParseDuration(cfg.AdminRefreshTokenDuration)
+	if err != nil {
+		slog.Error("invalid ADMIN_REFRESH_TOKEN_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	adminMFAChallengeDuration, err := time.ParseDuration(cfg.AdminMFAChallengeDuration)
+	if err != nil {
+		slog.Error("invalid ADMIN_MFA_CHALLENGE_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	passwordResetTokenDuration, err := time.ParseDuration(cfg.PasswordResetTokenDuration)
+	if err != nil {
+		slog.Error("invalid PASSWORD_RESET_TOKEN_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	emailVerificationPolicy, err := frontend_auth.ParseVerificationPolicy(cfg.EmailVerificationPolicy)
+	if err != nil {
+		slog.Error("invalid EMAIL_VERIFICATION_POLICY", "error", err)
+		os.Exit(1)
+	}
+
+	emailVerificationTokenDuration, err := time.ParseDuration(cfg.EmailVerificationTokenDuration)
+	if err != nil {
+		slog.Error("invalid EMAIL_VERIFICATION_TOKEN_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	impersonationTokenDuration, err := time.ParseDuration(cfg.ImpersonationTokenDuration)
+	if err != nil {
+		slog.Error("invalid IMPERSONATION_TOKEN_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	magicLinkTokenDuration, err := time.ParseDuration(cfg.MagicLinkTokenDuration)
+	if err != nil {
+		slog.Error("invalid MAGIC_LINK_TOKEN_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	accountDeletionGracePeriod, err := time.ParseDuration(cfg.AccountDeletionGracePeriod)
+	if err != nil || accountDeletionGracePeriod < 0 {
+		slog.Error("invalid ACCOUNT_DELETION_GRACE_PERIOD format", "error", err)
+		os.Exit(1)
+	}
+
+	dataExportLinkDuration, err := time.ParseDuration(cfg.DataExportLinkDuration)
+	if err != nil || dataExportLinkDuration <= 0 {
+		slog.Error("invalid DATA_EXPORT_LINK_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	dataExportRetention, err := time.ParseDuration(cfg.DataExportRetention)
+	if err != nil || dataExportRetention <= 0 {
+		slog.Error("invalid DATA_EXPORT_RETENTION format", "error", err)
+		os.Exit(1)
+	}
+
+	permissionCacheTTL, err := time.ParseDuration(cfg.PermissionCacheTTL)
+	if err != nil || permissionCacheTTL < 0 {
+		slog.Error("invalid PERMISSION_CACHE_TTL format", "error", err)
+		os.Exit(1)
+	}
+
+	securityEventRetention, err := time.ParseDuration(cfg.SecurityEventRetention)
+	if err != nil || securityEventRetention < 0 {
+		slog.Error("invalid SECURITY_EVENT_RETENTION format", "error", err)
+		os.Exit(1)
+	}
+
+	loginDelayBase, err := time.ParseDuration(cfg.LoginDelayBase)
+	if err != nil {
+		slog.Error("invalid LOGIN_DELAY_BASE format", "error", err)
+		os.Exit(1)
+	}
+
+	loginDelayMax, err := time.ParseDuration(cfg.LoginDelayMax)
+	if err != nil {
+		slog.Error("invalid LOGIN_DELAY_MAX format", "error", err)
+		os.Exit(1)
+	}
+
+	loginAttemptWindow, err := time.ParseDuration(cfg.LoginAttemptWindow)
+	if err != nil {
+		slog.Error("invalid LOGIN_ATTEMPT_WINDOW format", "error", err)
+		os.Exit(1)
+	}
+
+	loginLockoutDuration, err := time.ParseDuration(cfg.LoginLockoutDuration)
+	if err != nil {
+		slog.Error("invalid LOGIN_LOCKOUT_DURATION format", "error", err)
+		os.Exit(1)
+	}
+
+	// Client IP addresses drive per-IP login throttling, so only believe known proxies
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		slog.Error("invalid TRUSTED_PROXIES", "error", err)
+		os.Exit(1)
+	}
+
+	// Password hashing for logins, registration and password changes
+	passwordParams := passhash.DefaultParams()
+	passwordParams.Algorithm = passhash.Algorithm(cfg.PasswordHashAlgorithm)
+	passwordParams.Memory = uint32(cfg.PasswordArgon2Memory)
+	passwordParams.Iterations = uint32(cfg.PasswordArgon2Iterations)
+	passwordParams.Parallelism = uint8(cfg.PasswordArgon2Parallelism)
+	passwordParams.BcryptCost = cfg.PasswordBcryptCost
+	passwordHasher, err := passhash.NewHasher(passwordParams)
+	if err != nil {
+		slog.Error("invalid password hashing configuration", "error", err)
+		os.Exit(1)
+	}
+	passhash.SetDefault(passwordHasher)
+
+	// Outgoing email (password reset and email verification links)
+	mail, err := mailer.New(mailer.Config{
+		Driver:       cfg.MailDriver,
+		From:         cfg.MailFrom,
+		SMTPHost:     cfg.SMTPHost,
+		SMTPPort:     cfg.SMTPPort,
+		SMTPUsername: cfg.SMTPUsername,
+		SMTPPassword: cfg.SMTPPassword,
+		Dir:          cfg.MailDir,
+	})
+	if err != nil {
+		slog.Error("failed to initialize mailer", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize services
 	auditService := audit.NewService(queries)
 
+	// Refresh token store (shared by frontend and admin auth)
+	refreshTokenStore := refreshtoken.NewStore(pool)
+	denylist := revocation.NewDenylist(queries)
+	oneTimeTokens := onetimetoken.NewStore(queries)
+
+	// Failed login tracking (shared by frontend and admin auth)
+	loginGuard := lockout.NewGuard(queries, auditService, lockout.Policy{
+		MaxAccountAttempts: cfg.LoginMaxFailedAttempts,
+		MaxIPAttempts:      cfg.LoginMaxFailedAttemptsPerIP,
+		DelayAfter:         cfg.LoginDelayAfterAttempts,
+		IPDelayAfter:       cfg.LoginIPDelayAfterAttempts,
+		BaseDelay:          loginDelayBase,
+		MaxDelay:           loginDelayMax,
+		Window:             loginAttemptWindow,
+		LockoutDuration:    loginLockoutDuration,
+	})
+
+	// Previous passwords, refused when a password is changed or reset
+	passwordHistory := passhistory.NewStore(queries, cfg.PasswordHistorySize)
+
+	// Where frontend users are signed in
+	userSessions := usersession.NewStore(queries, auditService)
+
+	// Accounts users asked to delete, anonymized after the grace period
+	accountDeletions := accountdeletion.NewService(queries, auditService, accountDeletionGracePeriod)
+	accountDeletions.Start(ctx, time.Hour)
+
+	// Personal data exports, built in the background
+	dataExports := data_export.NewService(queries, auditService, cfg.DataExportSigningKey, dataExportLinkDuration, dataExportRetention)
+	dataExports.StartWorker(ctx, time.Minute)
+	dataExports.StartCleanup(ctx, time.Hour)
+
+	// Purge expired refresh tokens and the sessions left without any, denylisted access
+	// tokens, one-time tokens and stale login throttles
+	revocation.StartCleanup(ctx, time.Hour, refreshTokenStore, userSessions, denylist, oneTimeTokens, loginGuard)
+
+	// JWT signing keys, separate per API surface
+	frontendKeys, err := buildKeySet(cfg.FrontendJWTSecret, cfg.FrontendJWTPrivateKeyFile, cfg.FrontendJWTVerifyKeyFiles)
+	if err != nil {
+		slog.Error("failed to load frontend JWT keys", "error", err)
+		os.Exit(1)
+	}
+	adminKeys, err := buildKeySet(cfg.AdminJWTSecret, cfg.AdminJWTPrivateKeyFile, cfg.AdminJWTVerifyKeyFiles)
+	if err != nil {
+		slog.Error("failed to load admin JWT keys", "error", err)
+		os.Exit(1)
+	}
+
+	// Encryption of admin TOTP secrets at rest
+	mfaBox, err := totp.NewSecretBox(cfg.MFAEncryptionKey)
+	if err != nil {
+		slog.Error("failed to initialize MFA encryption", "error", err)
+		os.Exit(1)
+	}
+
 	// User auth service (for frontend API)
-	authService := frontend_auth.NewService(queries, auditService, cfg.JWTSecret, bearerTokenDuration)
+	authService := frontend_auth.NewService(pool, auditService, refreshTokenStore, userSessions, denylist, oneTimeTokens, loginGuard, passwordHistory, accountDeletions, mail, frontendKeys, frontend_auth.Config{
+		AppURL:                     cfg.FrontendURL,
+		VerificationPolicy:         emailVerificationPolicy,
+		BearerTokenDuration:        bearerTokenDuration,
+		RefreshTokenDuration:       refreshTokenDuration,
+		ResetTokenDuration:         passwordResetTokenDuration,
+		VerificationTokenDuration:  emailVerificationTokenDuration,
+		ImpersonationTokenDuration: impersonationTokenDuration,
+		MagicLinkDuration:          magicLinkTokenDuration,
+	})
+	authService.StartMagicLinkCleanup(ctx, time.Hour)
 	authHandler := frontend_auth.NewHandler(authService, validator)
 
 	// User services (for frontend and admin)
-	userAdminService := user.NewAdminService(queries, auditService)
-	userFrontendService := user.NewFrontendService(queries, auditService)
+	userAdminService := user.NewAdminService(queries, auditService, user.AdminServiceDeps{
+		Verifier:     authService,
+		Logins:       loginGuard,
+		Impersonator: authService,
+		Sessions:     userSessions,
+		Deletions:    accountDeletions,
+	})
+	userFrontendService := user.NewFrontendService(queries, auditService, authService)
 	userAdminHandler := user.NewAdminHandler(userAdminService, validator)
 	userFrontendHandler := user.NewFrontendHandler(userFrontendService, validator)
 
@@ -122,26 +371,91 @@ func main() {
 	addressFrontendHandler := address.NewFrontendHandler(addressFrontendService, validator)
 
 	// Admin authentication service and handler (for admin login)
-	adminAuthService := admin_auth.NewAuthService(queries, cfg.JWTSecret)
+	adminAuthService := admin_auth.NewAuthService(pool, auditService, refreshTokenStore, denylist, oneTimeTokens, loginGuard, passwordHistory, mail, adminKeys, mfaBox, cfg.AdminURL, adminTokenDuration, adminRefreshTokenDuration, adminMFAChallengeDuration, passwordResetTokenDuration)
+	adminAuthService.StartOIDCCleanup(ctx, time.Hour)
 	adminAuthHandler := admin_auth.NewAuthHandler(adminAuthService, validator)
 
+	// Admin single sign-on (OpenID Connect), next to the password login
+	if cfg.OIDCIssuerURL != "" {
+		// Mapped roles must exist in the roles table when the server starts
+		roles, err := queries.ListRoles(ctx)
+		if err != nil {
+			slog.Error("failed to load roles", "error", err)
+			os.Exit(1)
+		}
+		roleNames := make([]string, len(roles))
+		for i, role := range roles {
+			roleNames[i] = role.Name
+		}
+
+		oidcRoles, err := oidc.ParseRoleMapping(cfg.OIDCRoleClaim, cfg.OIDCRoleMapping, cfg.OIDCDefaultRole, roleNames)
+		if err != nil {
+			slog.Error("invalid OIDC_ROLE_MAPPING", "error", err)
+			os.Exit(1)
+		}
+
+		oidcLoginStateDuration, err := time.ParseDuration(cfg.OIDCLoginStateDuration)
+		if err != nil {
+			slog.Error("invalid OIDC_LOGIN_STATE_DURATION format", "error", err)
+			os.Exit(1)
+		}
+
+		oidcProvider := oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		}, nil)
+		adminAuthService.EnableOIDC(oidcProvider, oidcRoles, oidcLoginStateDuration)
+		slog.Info("admin single sign-on enabled", "issuer", cfg.OIDCIssuerURL)
+	}
+
 	// Admin CRUD service and handler (for managing admins)
-	adminService := admin.NewService(queries, auditService)
+	adminService := admin.NewService(queries, auditService, loginGuard)
 	adminHandler := admin.NewHandler(adminService, validator)
 
+	// Permission middleware (for granular access control), with role permissions
+	// cached in memory and flushed whenever any instance changes them
+	var permissionCache *middleware.PermissionCache
+	if permissionCacheTTL > 0 {
+		permissionCache = middleware.NewPermissionCache(permissionCacheTTL)
+		permissionCache.Listen(ctx, pool)
+	}
+	permissionMiddleware := middleware.NewPermissionMiddleware(queries, permissionCache)
+
+	// Role management service and handler (which permissions each role grants,
+	// and the permission cache counters of this instance)
+	roleService := role.NewService(queries, auditService)
+	roleHandler := role.NewHandler(roleService, validator, permissionMiddleware)
+
 	// Menu handler (for serving admin menu)
 	menuHandler := admin_menu.NewHandler(queries)
 
+	// Audit log browsing service and handler (read-only)
+	auditLogService := audit_log.NewService(queries)
+	auditLogHandler := audit_log.NewHandler(auditLogService, validator)
+
+	// Security event browsing service and handlers (read-only), purging events past their retention
+	securityEventService := security_event.NewService(queries)
+	securityEventService.StartRetention(ctx, time.Hour, securityEventRetention)
+	securityEventAdminHandler := security_event.NewAdminHandler(securityEventService, validator)
+	securityEventFrontendHandler := security_event.NewFrontendHandler(securityEventService)
+
+	// Data export handlers (for frontend and admin)
+	dataExportAdminHandler := data_export.NewAdminHandler(dataExports)
+	dataExportFrontendHandler := data_export.NewFrontendHandler(dataExports)
+
 	// Initialize middleware
 	// User auth middleware (for frontend API)
-	userAuthMiddleware := middleware.Middleware(authService, queries)
+	userAuthMiddleware := middleware.Middleware(authService)
+
+	// Guards frontend routes that unverified users may not reach
+	verifiedEmailMiddleware := middleware.RequireVerifiedEmail(emailVerificationPolicy)
 
 	// Admin auth middleware (for admin API)
 	adminAuthMiddleware := middleware.AdminAuthMiddleware(adminAuthService)
 
-	// Permission middleware (for granular access control)
-	permissionMiddleware := middleware.NewPermissionMiddleware(queries)
-
 	// Setup router with separate admin and frontend handlers
 	r := router.New(
 		userAdminHandler,
@@ -151,8 +465,17 @@ func main() {
 		authHandler,
 		adminAuthHandler,
 		adminHandler,
+		roleHandler,
 		menuHandler,
+		auditLogHandler,
+		securityEventAdminHandler,
+		securityEventFrontendHandler,
+		dataExportAdminHandler,
+		dataExportFrontendHandler,
+		signing.JWKSHandler(frontendKeys, adminKeys),
+		middleware.AuditContext(trustedProxies),
 		userAuthMiddleware,
+		verifiedEmailMiddleware,
 		adminAuthMiddleware,
 		permissionMiddleware,
 	)
@@ -193,3 +516,18 @@ func main() {
 
 	slog.Info("server stopped gracefully")
 }
+
+// buildKeySet signs with the private key file when one is configured and falls back to the HMAC secret
+func buildKeySet(secret, privateKeyFile string, verifyKeyFiles []string) (*signing.KeySet, error) {
+	if privateKeyFile == "" {
+		return signing.NewHMACKeySet(secret), nil
+	}
+
+	keys, err := signing.LoadKeySet(privateKeyFile, verifyKeyFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("loaded JWT signing key", "kid", keys.ActiveKeyID(), "file", privateKeyFile)
+	return keys, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/user/coc/internal/oidc/oidctest"
+)
+
+// Runs the mock OpenID Connect provider for trying admin single sign-on locally.
+// Every login signs in the user given by the flags, without a login page.
+func main() {
+	addr := flag.String("addr", "localhost:9400", "Address to listen on")
+	clientID := flag.String("client-id", "coc-admin", "Client ID the API is registered with (OIDC_CLIENT_ID)")
+	clientSecret := flag.String("client-secret", "local-secret", "Client secret (OIDC_CLIENT_SECRET); empty for a public client")
+	subject := flag.String("sub", "mock-user-1", "Subject of the signed-in user")
+	email := flag.String("email", "sso.admin@example.com", "Email of the signed-in user")
+	name := flag.String("name", "SSO Admin", "Given and family name of the signed-in user")
+	groups := flag.String("groups", "coc-admins", "Comma-separated groups of the signed-in user")
+	flag.Parse()
+
+	issuer := "http://" + *addr
+	provider, err := oidctest.New(issuer, *clientID, *clientSecret)
+	if err != nil {
+		log.Fatalf("Failed to create mock provider: %v", err)
+	}
+
+	givenName, familyName, _ := strings.Cut(*name, " ")
+	provider.SetUser(oidctest.Claims{
+		"sub":                *subject,
+		"email":              *email,
+		"email_verified":     true,
+		"preferred_username": strings.Split(*email, "@")[0],
+		"given_name":         givenName,
+		"family_name":        familyName,
+		"groups":             strings.Split(*groups, ","),
+	})
+
+	fmt.Println("🔑 Mock OIDC provider running")
+	fmt.Printf("  OIDC_ISSUER_URL=%s\n", issuer)
+	fmt.Printf("  OIDC_CLIENT_ID=%s\n", *clientID)
+	fmt.Printf("  OIDC_CLIENT_SECRET=%s\n", *clientSecret)
+	fmt.Printf("  signing in %s (groups: %s)\n", *email, *groups)
+
+	log.Fatal(http.ListenAndServe(*addr, provider))
+}
@@ -11,9 +11,9 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
-	"golang.org/x/crypto/bcrypt"
 
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/passhash"
 )
 
 const (
@@ -113,7 +113,7 @@ func clearDatabase(ctx context.Context, pool *pgxpool.Pool) error {
 
 func seedAdmins(ctx context.Context, queries *db.Queries, count int) (int, error) {
 	// Hash a default password for all test admins
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
+	passwordHash, err := passhash.Hash("admin123")
 	if err != nil {
 		return 0, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -140,7 +140,7 @@ func seedAdmins(ctx context.Context, queries *db.Queries, count int) (int, error
 		_, err := queries.CreateAdmin(ctx, db.CreateAdminParams{
 			Email:        email,
 			Username:     username,
-			PasswordHash: string(passwordHash),
+			PasswordHash: passwordHash,
 			FirstName:    firstName,
 			LastName:     lastName,
 			Role:         role,
@@ -169,7 +169,7 @@ func seedUsers(ctx context.Context, queries *db.Queries, count int) ([]pgtype.UU
 	userIDs := make([]pgtype.UUID, 0, count)
 
 	// Hash a default password for all test users
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	passwordHash, err := passhash.Hash("password123")
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -187,7 +187,7 @@ func seedUsers(ctx context.Context, queries *db.Queries, count int) ([]pgtype.UU
 		user, err := queries.CreateUser(ctx, db.CreateUserParams{
 			Email:        email,
 			Username:     username,
-			PasswordHash: string(passwordHash),
+			PasswordHash: passwordHash,
 			FirstName:    firstName,
 			LastName:     lastName,
 		})
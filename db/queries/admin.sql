@@ -25,6 +25,7 @@ ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
 -- name: UpdateAdmin :one
+-- Changing the password, role or active status bumps token_version, which signs the admin out everywhere
 UPDATE admins
 SET 
     email = COALESCE($2, email),
@@ -34,15 +35,42 @@ SET
     last_name = $6,
     role = COALESCE($7, role),
     is_active = COALESCE($8, is_active),
+    token_version = CASE
+        WHEN password_hash IS DISTINCT FROM COALESCE($4, password_hash)
+            OR role IS DISTINCT FROM COALESCE($7, role)
+            OR is_active IS DISTINCT FROM COALESCE($8, is_active)
+        THEN token_version + 1
+        ELSE token_version
+    END,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING *;
 
 -- name: DeleteAdmin :exec
 UPDATE admins
-SET is_active = false, updated_at = CURRENT_TIMESTAMP
+SET is_active = false, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1;
 
+-- name: IncrementAdminTokenVersion :one
+UPDATE admins
+SET token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING token_version;
+
 -- name: HardDeleteAdmin :exec
 DELETE FROM admins
 WHERE id = $1;
+
+-- name: RehashAdminPassword :execrows
+-- Upgrades the hash of an unchanged password, so sessions stay valid. Matches no
+-- row when the password was changed since old_hash was read.
+UPDATE admins
+SET password_hash = sqlc.arg('new_hash')
+WHERE id = sqlc.arg('id') AND password_hash = sqlc.arg('old_hash');
+
+-- name: UpdateAdminPassword :one
+-- Bumps token_version so existing sessions are signed out
+UPDATE admins
+SET password_hash = $2, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND is_active = true
+RETURNING *;
@@ -0,0 +1,26 @@
+-- name: CreateAdminAPIKey :one
+INSERT INTO admin_api_keys (admin_id, name, key_prefix, key_hash, permissions, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetAdminAPIKeyByHash :one
+SELECT * FROM admin_api_keys
+WHERE key_hash = $1 LIMIT 1;
+
+-- name: ListAdminAPIKeys :many
+SELECT * FROM admin_api_keys
+WHERE admin_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeAdminAPIKey :one
+-- Returns no rows if the key does not belong to the admin or is already revoked
+UPDATE admin_api_keys
+SET revoked_at = NOW()
+WHERE id = $1 AND admin_id = $2 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchAdminAPIKey :exec
+-- Only writes when last_used_at is older than the given time, to spare a write per request
+UPDATE admin_api_keys
+SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2);
@@ -0,0 +1,27 @@
+-- name: CreateAdminIdentity :one
+INSERT INTO admin_identities (admin_id, issuer, subject, email, last_login_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING *;
+
+-- name: GetAdminIdentity :one
+SELECT * FROM admin_identities
+WHERE issuer = $1 AND subject = $2 LIMIT 1;
+
+-- name: TouchAdminIdentity :exec
+UPDATE admin_identities
+SET email = $2, last_login_at = NOW()
+WHERE id = $1;
+
+-- name: CreateOIDCLoginState :exec
+INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: ConsumeOIDCLoginState :one
+-- Deletes the state so it cannot be used twice; returns no rows if it is unknown or expired
+DELETE FROM oidc_login_states
+WHERE state_hash = $1 AND expires_at > NOW()
+RETURNING *;
+
+-- name: DeleteExpiredOIDCLoginStates :execrows
+DELETE FROM oidc_login_states
+WHERE expires_at < $1;
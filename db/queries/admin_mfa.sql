@@ -0,0 +1,77 @@
+-- name: GetAdminMFA :one
+SELECT * FROM admin_mfa
+WHERE admin_id = $1;
+
+-- name: UpsertAdminMFASecret :one
+-- Starts (or restarts) enrollment; MFA stays disabled until EnableAdminMFA
+INSERT INTO admin_mfa (admin_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (admin_id) DO UPDATE
+SET secret = EXCLUDED.secret,
+    enabled_at = NULL,
+    last_used_step = NULL,
+    failed_attempts = 0
+RETURNING *;
+
+-- name: EnableAdminMFA :execrows
+UPDATE admin_mfa
+SET enabled_at = NOW(),
+    last_used_step = $2,
+    failed_attempts = 0
+WHERE admin_id = $1 AND enabled_at IS NULL;
+
+-- name: UseAdminMFAStep :execrows
+-- Records an accepted TOTP step; affects no rows if the step was already used
+UPDATE admin_mfa
+SET last_used_step = $2,
+    failed_attempts = 0
+WHERE admin_id = $1 AND (last_used_step IS NULL OR last_used_step < $2);
+
+-- name: RecordAdminMFAFailure :one
+UPDATE admin_mfa
+SET failed_attempts = failed_attempts + 1
+WHERE admin_id = $1
+RETURNING failed_attempts;
+
+-- name: ResetAdminMFAFailures :exec
+UPDATE admin_mfa
+SET failed_attempts = 0
+WHERE admin_id = $1;
+
+-- name: DeleteAdminMFA :exec
+DELETE FROM admin_mfa
+WHERE admin_id = $1;
+
+-- name: CreateAdminMFARecoveryCode :exec
+INSERT INTO admin_mfa_recovery_codes (admin_id, code_hash)
+VALUES ($1, $2);
+
+-- name: DeleteAdminMFARecoveryCodes :exec
+DELETE FROM admin_mfa_recovery_codes
+WHERE admin_id = $1;
+
+-- name: UseAdminMFARecoveryCode :execrows
+UPDATE admin_mfa_recovery_codes
+SET used_at = NOW()
+WHERE admin_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedAdminMFARecoveryCodes :one
+SELECT COUNT(*) FROM admin_mfa_recovery_codes
+WHERE admin_id = $1 AND used_at IS NULL;
+
+-- name: IsMFARequiredForRole :one
+SELECT EXISTS (
+    SELECT 1 FROM role_mfa_policies
+    WHERE role = $1 AND mfa_required = TRUE
+);
+
+-- name: ListRoleMFAPolicies :many
+SELECT * FROM role_mfa_policies
+ORDER BY role;
+
+-- name: UpsertRoleMFAPolicy :one
+INSERT INTO role_mfa_policies (role, mfa_required)
+VALUES ($1, $2)
+ON CONFLICT (role) DO UPDATE
+SET mfa_required = EXCLUDED.mfa_required
+RETURNING *;
@@ -0,0 +1,37 @@
+-- name: DeleteAdminPermissionOverride :one
+-- Returns no rows if the admin has no override for the permission
+DELETE FROM admin_permission_overrides
+WHERE admin_id = $1 AND permission_id = $2
+RETURNING *;
+
+-- name: GetActiveAdminPermissionOverrides :many
+-- Unexpired overrides of active permissions, as permission checks apply them
+SELECT p.code, o.effect, o.expires_at
+FROM admin_permission_overrides o
+INNER JOIN permissions p ON p.id = o.permission_id
+WHERE o.admin_id = $1
+  AND p.is_active = true
+  AND (o.expires_at IS NULL OR o.expires_at > NOW());
+
+-- name: GetAdminPermissionOverride :one
+SELECT * FROM admin_permission_overrides
+WHERE admin_id = $1 AND permission_id = $2;
+
+-- name: ListAdminPermissionOverrides :many
+-- Includes expired overrides
+SELECT o.*, p.code AS permission_code
+FROM admin_permission_overrides o
+INNER JOIN permissions p ON p.id = o.permission_id
+WHERE o.admin_id = $1
+ORDER BY p.code;
+
+-- name: UpsertAdminPermissionOverride :one
+-- Replaces the admin's existing override for the permission, if any
+INSERT INTO admin_permission_overrides (admin_id, permission_id, effect, reason, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (admin_id, permission_id) DO UPDATE
+SET effect = EXCLUDED.effect,
+    reason = EXCLUDED.reason,
+    expires_at = EXCLUDED.expires_at,
+    created_by = EXCLUDED.created_by
+RETURNING *;
@@ -1,6 +1,7 @@
 -- name: CreateAuditLog :one
 INSERT INTO audit_logs (
-    user_id,
+    actor_type,
+    actor_id,
     action,
     entity_type,
     entity_id,
@@ -12,7 +13,7 @@ INSERT INTO audit_logs (
     metadata,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 ) RETURNING *;
 
 -- name: GetAuditLogByID :one
@@ -25,11 +26,20 @@ WHERE entity_type = $1 AND entity_id = $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4;
 
--- name: ListAuditLogsByUser :many
+-- name: ListAuditLogsByActor :many
 SELECT * FROM audit_logs
-WHERE user_id = $1
+WHERE actor_type = $1 AND actor_id = $2
 ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+LIMIT $3 OFFSET $4;
+
+-- name: ListAuditLogsForUser :many
+-- Entries about the user or their addresses, and changes the user made
+SELECT * FROM audit_logs
+WHERE (actor_type = 'user' AND actor_id = sqlc.arg('user_id'))
+   OR (entity_type = 'users' AND entity_id = sqlc.arg('user_id'))
+   OR (entity_type = 'addresses' AND entity_id IN (SELECT id FROM addresses WHERE user_id = sqlc.arg('user_id')))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
 
 -- name: ListAuditLogsByEntityType :many
 SELECT * FROM audit_logs
@@ -61,6 +71,31 @@ LIMIT $5 OFFSET $6;
 SELECT COUNT(*) FROM audit_logs
 WHERE entity_type = $1 AND entity_id = $2;
 
--- name: CountAuditLogsByUser :one
+-- name: CountAuditLogsByActor :one
+SELECT COUNT(*) FROM audit_logs
+WHERE actor_type = $1 AND actor_id = $2;
+
+
+-- name: SearchAuditLogs :many
+SELECT * FROM audit_logs
+WHERE created_at >= sqlc.arg('created_from')
+  AND created_at < sqlc.arg('created_to')
+  AND (sqlc.narg('entity_type')::varchar IS NULL OR entity_type = sqlc.narg('entity_type'))
+  AND (sqlc.narg('entity_id')::uuid IS NULL OR entity_id = sqlc.narg('entity_id'))
+  AND (sqlc.narg('actor_type')::audit_actor_type IS NULL OR actor_type = sqlc.narg('actor_type'))
+  AND (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('action')::audit_action IS NULL OR action = sqlc.narg('action'))
+  AND (sqlc.narg('request_id')::varchar IS NULL OR request_id = sqlc.narg('request_id'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSearchAuditLogs :one
 SELECT COUNT(*) FROM audit_logs
-WHERE user_id = $1;
+WHERE created_at >= sqlc.arg('created_from')
+  AND created_at < sqlc.arg('created_to')
+  AND (sqlc.narg('entity_type')::varchar IS NULL OR entity_type = sqlc.narg('entity_type'))
+  AND (sqlc.narg('entity_id')::uuid IS NULL OR entity_id = sqlc.narg('entity_id'))
+  AND (sqlc.narg('actor_type')::audit_actor_type IS NULL OR actor_type = sqlc.narg('actor_type'))
+  AND (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('action')::audit_action IS NULL OR action = sqlc.narg('action'))
+  AND (sqlc.narg('request_id')::varchar IS NULL OR request_id = sqlc.narg('request_id'));
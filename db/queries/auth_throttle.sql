@@ -0,0 +1,31 @@
+-- name: GetAuthThrottle :one
+SELECT * FROM auth_throttles
+WHERE subject_type = $1 AND key_type = $2 AND throttle_key = $3;
+
+-- name: RecordAuthFailure :one
+-- Counting starts over when the previous failure is older than window_start
+INSERT INTO auth_throttles (subject_type, key_type, throttle_key, failed_attempts, last_failed_at)
+VALUES (sqlc.arg('subject_type'), sqlc.arg('key_type'), sqlc.arg('throttle_key'), 1, sqlc.arg('failed_at'))
+ON CONFLICT (subject_type, key_type, throttle_key) DO UPDATE
+SET failed_attempts = CASE
+        WHEN auth_throttles.last_failed_at < sqlc.arg('window_start') THEN 1
+        ELSE auth_throttles.failed_attempts + 1
+    END,
+    last_failed_at = EXCLUDED.last_failed_at
+RETURNING *;
+
+-- name: LockAuthThrottle :one
+UPDATE auth_throttles
+SET locked_until = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteAuthThrottle :one
+DELETE FROM auth_throttles
+WHERE subject_type = $1 AND key_type = $2 AND throttle_key = $3
+RETURNING *;
+
+-- name: DeleteStaleAuthThrottles :execrows
+-- Removes counters whose last failure is older than $1 and that are not locked at $2
+DELETE FROM auth_throttles
+WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < $2);
@@ -0,0 +1,60 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (user_id, requested_by_type, requested_by_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetDataExportForUser :one
+-- Exports of anonymized users are not returned
+SELECT * FROM data_exports
+WHERE id = $1 AND user_id = $2
+    AND EXISTS (SELECT 1 FROM users WHERE users.id = data_exports.user_id AND users.anonymized_at IS NULL)
+LIMIT 1;
+
+-- name: GetUnfinishedDataExportForUser :one
+SELECT * FROM data_exports
+WHERE user_id = $1 AND status IN ('pending', 'processing')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ClaimDataExport :one
+-- Takes the oldest pending export, or one whose worker stopped before finishing,
+-- and marks it processing. Concurrent workers skip exports another one claimed.
+UPDATE data_exports
+SET status = 'processing', started_at = NOW()
+WHERE id = (
+    SELECT id FROM data_exports
+    WHERE status = 'pending'
+        OR (status = 'processing' AND started_at < sqlc.arg('stale_before'))
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteDataExport :one
+UPDATE data_exports
+SET status = 'completed', error = NULL, completed_at = NOW(), expires_at = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', error = $2, completed_at = NOW(), expires_at = $3
+WHERE id = $1;
+
+-- name: CreateDataExportArchive :exec
+INSERT INTO data_export_archives (export_id, content)
+VALUES ($1, $2)
+ON CONFLICT (export_id) DO UPDATE SET content = EXCLUDED.content, created_at = NOW();
+
+-- name: GetDataExportArchive :one
+-- Archives of anonymized users are not returned
+SELECT a.content FROM data_export_archives a
+INNER JOIN data_exports e ON e.id = a.export_id
+INNER JOIN users u ON u.id = e.user_id
+WHERE a.export_id = $1 AND u.anonymized_at IS NULL
+LIMIT 1;
+
+-- name: DeleteExpiredDataExports :execrows
+DELETE FROM data_exports
+WHERE expires_at < $1;
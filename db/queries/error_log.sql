@@ -1,6 +1,7 @@
 -- name: CreateErrorLog :one
 INSERT INTO error_logs (
-    user_id,
+    actor_type,
+    actor_id,
     request_id,
     error_type,
     error_message,
@@ -12,7 +13,7 @@ INSERT INTO error_logs (
     metadata,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 ) RETURNING *;
 
 -- name: GetErrorLogByID :one
@@ -24,11 +25,11 @@ SELECT * FROM error_logs
 WHERE request_id = $1
 ORDER BY created_at ASC;
 
--- name: ListErrorLogsByUser :many
+-- name: ListErrorLogsByActor :many
 SELECT * FROM error_logs
-WHERE user_id = $1
+WHERE actor_type = $1 AND actor_id = $2
 ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+LIMIT $3 OFFSET $4;
 
 -- name: ListErrorLogsByType :many
 SELECT * FROM error_logs
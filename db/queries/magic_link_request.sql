@@ -0,0 +1,15 @@
+-- name: CreateMagicLinkRequest :exec
+INSERT INTO magic_link_requests (email, ip_address)
+VALUES ($1, $2);
+
+-- name: CountMagicLinkRequestsByEmailSince :one
+SELECT COUNT(*) FROM magic_link_requests
+WHERE email = $1 AND created_at > $2;
+
+-- name: CountMagicLinkRequestsByIPSince :one
+SELECT COUNT(*) FROM magic_link_requests
+WHERE ip_address = $1 AND created_at > $2;
+
+-- name: DeleteMagicLinkRequestsBefore :execrows
+DELETE FROM magic_link_requests
+WHERE created_at < $1;
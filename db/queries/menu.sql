@@ -1,4 +1,5 @@
 -- name: GetMenuItemsByRole :many
+-- Items the admin may see through their role and their own permission overrides
 WITH RECURSIVE menu_tree AS (
     -- Get root menu items (no parent)
     SELECT 
@@ -16,11 +17,7 @@ WITH RECURSIVE menu_tree AS (
       AND mi.is_active = true
       AND (
           mi.permission_id IS NULL 
-          OR mi.permission_id IN (
-              SELECT rp.permission_id 
-              FROM role_permissions rp 
-              WHERE rp.role = $1
-          )
+          OR mi.permission_id IN (SELECT admin_permission_ids(sqlc.arg('admin_id'), sqlc.arg('role')))
       )
     
     UNION ALL
@@ -41,11 +38,7 @@ WITH RECURSIVE menu_tree AS (
     WHERE mi.is_active = true
       AND (
           mi.permission_id IS NULL 
-          OR mi.permission_id IN (
-              SELECT rp.permission_id 
-              FROM role_permissions rp 
-              WHERE rp.role = $1
-          )
+          OR mi.permission_id IN (SELECT admin_permission_ids(sqlc.arg('admin_id'), sqlc.arg('role')))
       )
 )
 SELECT * FROM menu_tree
@@ -0,0 +1,26 @@
+-- name: CreateOneTimeToken :one
+INSERT INTO one_time_tokens (purpose, subject_type, subject_id, token_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ConsumeOneTimeToken :one
+-- Marks the token used; returns no rows if it is unknown, expired or already used
+UPDATE one_time_tokens
+SET used_at = NOW()
+WHERE token_hash = $1 AND purpose = $2 AND subject_type = $3
+    AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
+
+-- name: CountOneTimeTokensSince :one
+-- Used to throttle how often a token can be re-sent
+SELECT COUNT(*) FROM one_time_tokens
+WHERE purpose = $1 AND subject_type = $2 AND subject_id = $3 AND created_at > $4;
+
+-- name: InvalidateOneTimeTokens :execrows
+UPDATE one_time_tokens
+SET used_at = NOW()
+WHERE purpose = $1 AND subject_type = $2 AND subject_id = $3 AND used_at IS NULL;
+
+-- name: DeleteExpiredOneTimeTokens :execrows
+DELETE FROM one_time_tokens
+WHERE expires_at < $1;
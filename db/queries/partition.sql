@@ -0,0 +1,8 @@
+-- name: EnsureMonthlyPartition :one
+SELECT ensure_monthly_partition(sqlc.arg('parent_table')::text, sqlc.arg('month_start')::date);
+
+-- name: DrainDefaultPartition :one
+SELECT drain_default_partition(sqlc.arg('parent_table')::text);
+
+-- name: DropExpiredPartitions :one
+SELECT drop_expired_partitions(sqlc.arg('parent_table')::text, sqlc.arg('cutoff')::date, sqlc.arg('detach_only')::boolean);
@@ -0,0 +1,20 @@
+-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (subject_type, subject_id, password_hash)
+VALUES ($1, $2, $3);
+
+-- name: ListRecentPasswordHashes :many
+SELECT password_hash FROM password_history
+WHERE subject_type = $1 AND subject_id = $2
+ORDER BY created_at DESC
+LIMIT $3;
+
+-- name: PrunePasswordHistory :execrows
+-- Keeps the most recent entries of the account and deletes the rest
+DELETE FROM password_history
+WHERE subject_type = $1 AND subject_id = $2
+    AND id NOT IN (
+        SELECT id FROM password_history
+        WHERE subject_type = $1 AND subject_id = $2
+        ORDER BY created_at DESC
+        LIMIT $3
+    );
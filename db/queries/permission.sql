@@ -1,8 +1,13 @@
 -- name: GetPermissionsByRole :many
+-- Includes the permissions the role inherits
 SELECT p.*
 FROM permissions p
-INNER JOIN role_permissions rp ON rp.permission_id = p.id
-WHERE rp.role = $1 AND p.is_active = true;
+WHERE p.is_active = true
+  AND p.id IN (
+      SELECT rp.permission_id
+      FROM role_permissions rp
+      WHERE rp.role IN (SELECT role_lineage(sqlc.arg('role')))
+  );
 
 -- name: GetAllPermissions :many
 SELECT * FROM permissions
@@ -40,7 +45,24 @@ DELETE FROM role_permissions
 WHERE role = $1 AND permission_id = $2;
 
 -- name: GetRolePermissionCodes :many
+-- Includes the permissions the role inherits
+SELECT p.code
+FROM permissions p
+WHERE p.is_active = true
+  AND p.id IN (
+      SELECT rp.permission_id
+      FROM role_permissions rp
+      WHERE rp.role IN (SELECT role_lineage(sqlc.arg('role')))
+  );
+
+-- name: GetDirectRolePermissionCodes :many
+-- Only the permissions granted to the role itself, not the inherited ones
 SELECT p.code
 FROM permissions p
 INNER JOIN role_permissions rp ON rp.permission_id = p.id
-WHERE rp.role = $1 AND p.is_active = true;
+WHERE rp.role = $1 AND p.is_active = true
+ORDER BY p.code;
+
+-- name: GetRolePermission :one
+SELECT * FROM role_permissions
+WHERE role = $1 AND permission_id = $2;
@@ -0,0 +1,36 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (subject_type, subject_id, family_id, token_hash, expires_at, token_version)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetRefreshTokenByHash :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: RotateRefreshToken :execrows
+-- Only succeeds for a token that has not been rotated or revoked yet
+UPDATE refresh_tokens
+SET revoked_at = NOW(), replaced_by = $2
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokensBySubject :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW()
+WHERE subject_type = $1 AND subject_id = $2 AND revoked_at IS NULL;
+
+-- name: DeleteExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < $1;
+
+-- name: HasActiveRefreshToken :one
+-- Reports whether a token family (a session) can still be refreshed
+SELECT EXISTS (
+    SELECT 1 FROM refresh_tokens
+    WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+);
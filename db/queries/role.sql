@@ -0,0 +1,28 @@
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY name;
+
+-- name: GetRoleByName :one
+SELECT * FROM roles
+WHERE name = $1;
+
+-- name: GetRoleLineage :many
+-- The role and every role it inherits from, transitively
+SELECT role_lineage(sqlc.arg('role'))::VARCHAR AS name;
+
+-- name: CreateRole :one
+INSERT INTO roles (name, description, inherits_from)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateRole :one
+UPDATE roles
+SET description = $2,
+    inherits_from = $3
+WHERE name = $1
+RETURNING *;
+
+-- name: DeleteRole :execrows
+-- System roles are never deleted
+DELETE FROM roles
+WHERE name = $1 AND is_system = false;
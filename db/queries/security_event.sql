@@ -0,0 +1,53 @@
+-- name: CreateSecurityEvent :exec
+INSERT INTO security_events (
+    event_type,
+    outcome,
+    actor_type,
+    actor_id,
+    identifier,
+    reason,
+    request_id,
+    ip_address,
+    user_agent,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+);
+
+-- name: SearchSecurityEvents :many
+SELECT * FROM security_events
+WHERE created_at >= sqlc.arg('created_from')
+  AND created_at < sqlc.arg('created_to')
+  AND (sqlc.narg('event_type')::varchar IS NULL OR event_type = sqlc.narg('event_type'))
+  AND (sqlc.narg('outcome')::varchar IS NULL OR outcome = sqlc.narg('outcome'))
+  AND (sqlc.narg('actor_type')::audit_actor_type IS NULL OR actor_type = sqlc.narg('actor_type'))
+  AND (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('identifier')::varchar IS NULL OR identifier = sqlc.narg('identifier'))
+  AND (sqlc.narg('ip_address')::varchar IS NULL OR ip_address = sqlc.narg('ip_address'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSearchSecurityEvents :one
+SELECT COUNT(*) FROM security_events
+WHERE created_at >= sqlc.arg('created_from')
+  AND created_at < sqlc.arg('created_to')
+  AND (sqlc.narg('event_type')::varchar IS NULL OR event_type = sqlc.narg('event_type'))
+  AND (sqlc.narg('outcome')::varchar IS NULL OR outcome = sqlc.narg('outcome'))
+  AND (sqlc.narg('actor_type')::audit_actor_type IS NULL OR actor_type = sqlc.narg('actor_type'))
+  AND (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('identifier')::varchar IS NULL OR identifier = sqlc.narg('identifier'))
+  AND (sqlc.narg('ip_address')::varchar IS NULL OR ip_address = sqlc.narg('ip_address'));
+
+-- name: ListRecentSecurityEventsByActor :many
+-- The recent activity of one account, newest first
+SELECT * FROM security_events
+WHERE actor_type = sqlc.arg('actor_type')
+  AND actor_id = sqlc.arg('actor_id')
+  AND event_type = ANY(sqlc.arg('event_types')::varchar[])
+  AND created_at >= sqlc.arg('since')
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteSecurityEventsBefore :execrows
+DELETE FROM security_events
+WHERE created_at < $1;
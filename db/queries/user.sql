@@ -17,6 +17,14 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM users
 WHERE email = $1 LIMIT 1;
 
+-- name: GetUserByNormalizedEmail :one
+-- Matches email case-insensitively; $1 must already be lowercased. Should two
+-- accounts differ only in case, the oldest wins.
+SELECT * FROM users
+WHERE lower(email) = $1
+ORDER BY created_at
+LIMIT 1;
+
 -- name: GetUserByUsername :one
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
@@ -27,8 +35,13 @@ ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
 -- name: UpdateUser :one
+-- Changing the email clears email_verified_at until the new address is verified
 UPDATE users
 SET
+    email_verified_at = CASE
+        WHEN COALESCE(sqlc.narg('email'), email) IS DISTINCT FROM email THEN NULL
+        ELSE email_verified_at
+    END,
     email = COALESCE(sqlc.narg('email'), email),
     username = COALESCE(sqlc.narg('username'), username),
     first_name = COALESCE(sqlc.narg('first_name'), first_name),
@@ -38,6 +51,55 @@ SET
 WHERE id = sqlc.arg('id')
 RETURNING *;
 
+-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: IncrementUserTokenVersion :one
+UPDATE users
+SET token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING token_version;
+
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
+
+-- name: RehashUserPassword :execrows
+-- Upgrades the hash of an unchanged password, so sessions stay valid. Matches no
+-- row when the password was changed since old_hash was read.
+UPDATE users
+SET password_hash = sqlc.arg('new_hash')
+WHERE id = sqlc.arg('id') AND password_hash = sqlc.arg('old_hash');
+
+-- name: UpdateUserPassword :one
+-- Bumps token_version so existing sessions are signed out
+UPDATE users
+SET password_hash = $2, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND anonymized_at IS NULL
+RETURNING *;
+
+-- name: CancelUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND anonymized_at IS NULL
+RETURNING *;
+
+-- name: ListUsersDueForDeletion :many
+-- Accounts whose deletion grace period has ended and that are not anonymized yet
+SELECT id FROM users
+WHERE deletion_scheduled_at <= $1 AND anonymized_at IS NULL
+ORDER BY deletion_scheduled_at
+LIMIT $2;
+
+-- name: AnonymizeUser :one
+-- Returns false if the user does not exist or was already anonymized
+SELECT anonymize_user(sqlc.arg('id')::uuid);
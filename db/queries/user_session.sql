@@ -0,0 +1,38 @@
+-- name: CreateUserSession :exec
+INSERT INTO user_sessions (id, user_id, user_agent, ip_address)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListActiveUserSessions :many
+SELECT * FROM user_sessions
+WHERE user_id = $1 AND EXISTS (
+    SELECT 1 FROM refresh_tokens
+    WHERE family_id = user_sessions.id AND revoked_at IS NULL AND expires_at > NOW()
+)
+ORDER BY last_seen_at DESC;
+
+-- name: GetActiveUserSession :one
+SELECT * FROM user_sessions
+WHERE id = $1 AND user_id = $2 AND EXISTS (
+    SELECT 1 FROM refresh_tokens
+    WHERE family_id = user_sessions.id AND revoked_at IS NULL AND expires_at > NOW()
+)
+LIMIT 1;
+
+-- name: TouchUserSession :exec
+-- Only writes when last_seen_at is older than seen_before, to limit writes per request
+UPDATE user_sessions
+SET last_seen_at = NOW(), ip_address = $2, user_agent = $3
+WHERE id = $1 AND last_seen_at < sqlc.arg(seen_before);
+
+-- name: DeleteEndedUserSessions :execrows
+-- Removes sessions whose refresh tokens have all been purged
+DELETE FROM user_sessions
+WHERE NOT EXISTS (
+    SELECT 1 FROM refresh_tokens WHERE family_id = user_sessions.id
+);
+
+-- name: ListUserSessionsByUserID :many
+-- Every session of the user still on record, including ended ones
+SELECT * FROM user_sessions
+WHERE user_id = $1
+ORDER BY created_at DESC;
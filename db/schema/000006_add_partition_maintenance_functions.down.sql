@@ -0,0 +1,5 @@
+-- Remove partition maintenance functions
+-- Partitions created by them are left in place
+DROP FUNCTION IF EXISTS drop_expired_partitions(TEXT, DATE, BOOLEAN);
+DROP FUNCTION IF EXISTS drain_default_partition(TEXT);
+DROP FUNCTION IF EXISTS ensure_monthly_partition(TEXT, DATE);
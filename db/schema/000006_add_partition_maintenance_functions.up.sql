@@ -0,0 +1,132 @@
+-- ==============================================
+-- PARTITION MAINTENANCE FUNCTIONS
+-- ==============================================
+-- Used by the partition manager (internal/partition) to keep the monthly
+-- partitions of audit_logs and error_logs rolling forward. Partitions are
+-- named <parent>_YYYY_MM and cover [first day of month, first day of next
+-- month) in UTC, matching the partitions created in 001_initial_schema.
+
+-- Creates the monthly partition containing month_start if it does not exist.
+-- Rows for that month that already landed in <parent>_default are moved into
+-- the new partition before it is attached. Returns TRUE if a partition was created.
+CREATE OR REPLACE FUNCTION ensure_monthly_partition(parent_table TEXT, month_start DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    month_begin TIMESTAMP := date_trunc('month', month_start::timestamp);
+    range_start TIMESTAMPTZ := month_begin AT TIME ZONE 'UTC';
+    range_end TIMESTAMPTZ := (month_begin + INTERVAL '1 month') AT TIME ZONE 'UTC';
+    partition_name TEXT := parent_table || '_' || to_char(month_begin, 'YYYY_MM');
+    default_name TEXT := parent_table || '_default';
+    has_default BOOLEAN;
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_partitioned_table pt
+        JOIN pg_class c ON c.oid = pt.partrelid
+        WHERE c.relname = parent_table
+    ) THEN
+        RAISE EXCEPTION 'table % is not partitioned', parent_table;
+    END IF;
+
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN FALSE;
+    END IF;
+
+    has_default := to_regclass(default_name) IS NOT NULL;
+
+    -- Block writes routed to the default partition while rows are moved,
+    -- otherwise ATTACH PARTITION fails on rows inserted in between.
+    IF has_default THEN
+        EXECUTE format('LOCK TABLE %I IN ACCESS EXCLUSIVE MODE', default_name);
+
+        -- Another instance may have created the partition while we waited
+        IF to_regclass(partition_name) IS NOT NULL THEN
+            RETURN FALSE;
+        END IF;
+    END IF;
+
+    EXECUTE format(
+        'CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING CONSTRAINTS)',
+        partition_name, parent_table
+    );
+
+    IF has_default THEN
+        EXECUTE format(
+            'WITH moved AS (DELETE FROM %I WHERE created_at >= $1 AND created_at < $2 RETURNING *)
+             INSERT INTO %I SELECT * FROM moved',
+            default_name, partition_name
+        ) USING range_start, range_end;
+    END IF;
+
+    EXECUTE format(
+        'ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)',
+        parent_table, partition_name, range_start, range_end
+    );
+
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Moves every row in <parent>_default into its monthly partition, creating
+-- partitions as needed. Returns the number of rows moved.
+CREATE OR REPLACE FUNCTION drain_default_partition(parent_table TEXT)
+RETURNS BIGINT AS $$
+DECLARE
+    default_name TEXT := parent_table || '_default';
+    rows_before BIGINT;
+    rows_after BIGINT;
+    month_start DATE;
+BEGIN
+    IF to_regclass(default_name) IS NULL THEN
+        RETURN 0;
+    END IF;
+
+    EXECUTE format('SELECT COUNT(*) FROM %I', default_name) INTO rows_before;
+    IF rows_before = 0 THEN
+        RETURN 0;
+    END IF;
+
+    FOR month_start IN EXECUTE format(
+        'SELECT DISTINCT date_trunc(''month'', created_at AT TIME ZONE ''UTC'')::date FROM %I ORDER BY 1',
+        default_name
+    ) LOOP
+        PERFORM ensure_monthly_partition(parent_table, month_start);
+    END LOOP;
+
+    EXECUTE format('SELECT COUNT(*) FROM %I', default_name) INTO rows_after;
+    RETURN rows_before - rows_after;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Detaches every monthly partition of parent_table whose range ends on or
+-- before cutoff. Detached partitions are dropped unless detach_only is TRUE,
+-- in which case they remain as standalone tables for archiving.
+-- Returns the number of partitions removed from the parent.
+CREATE OR REPLACE FUNCTION drop_expired_partitions(parent_table TEXT, cutoff DATE, detach_only BOOLEAN)
+RETURNS INTEGER AS $$
+DECLARE
+    child_name TEXT;
+    month_start DATE;
+    removed INTEGER := 0;
+BEGIN
+    FOR child_name IN
+        SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        JOIN pg_class p ON p.oid = i.inhparent
+        WHERE p.relname = parent_table
+          AND c.relname ~ ('^' || parent_table || '_[0-9]{4}_[0-9]{2}$')
+        ORDER BY c.relname
+    LOOP
+        month_start := to_date(right(child_name, 7), 'YYYY_MM');
+        IF month_start + INTERVAL '1 month' <= cutoff THEN
+            EXECUTE format('ALTER TABLE %I DETACH PARTITION %I', parent_table, child_name);
+            IF NOT detach_only THEN
+                EXECUTE format('DROP TABLE %I', child_name);
+            END IF;
+            removed := removed + 1;
+        END IF;
+    END LOOP;
+
+    RETURN removed;
+END;
+$$ LANGUAGE plpgsql;
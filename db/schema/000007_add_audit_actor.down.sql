@@ -0,0 +1,27 @@
+-- Restore user_id columns (only user actors that still exist can be mapped back)
+
+-- error_logs
+ALTER TABLE error_logs ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE error_logs SET user_id = actor_id
+WHERE actor_type = 'user' AND actor_id IN (SELECT id FROM users);
+
+DROP INDEX IF EXISTS idx_error_logs_actor;
+ALTER TABLE error_logs DROP COLUMN actor_id;
+ALTER TABLE error_logs DROP COLUMN actor_type;
+
+CREATE INDEX idx_error_logs_user_id ON error_logs(user_id);
+
+-- audit_logs
+ALTER TABLE audit_logs ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE audit_logs SET user_id = actor_id
+WHERE actor_type = 'user' AND actor_id IN (SELECT id FROM users);
+
+DROP INDEX IF EXISTS idx_audit_logs_actor;
+ALTER TABLE audit_logs DROP COLUMN actor_id;
+ALTER TABLE audit_logs DROP COLUMN actor_type;
+
+CREATE INDEX idx_audit_logs_user_id ON audit_logs(user_id);
+
+DROP TYPE IF EXISTS audit_actor_type;
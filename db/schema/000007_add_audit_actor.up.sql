@@ -0,0 +1,30 @@
+-- ==============================================
+-- AUDIT ACTOR MODEL
+-- ==============================================
+-- audit_logs.user_id and error_logs.user_id referenced users(id), so every
+-- entry written by an admin (or by the system) violated the foreign key and
+-- was dropped. Replace them with an actor type plus an actor id that is not
+-- tied to a single table.
+
+CREATE TYPE audit_actor_type AS ENUM ('admin', 'user', 'system');
+
+-- audit_logs
+ALTER TABLE audit_logs ADD COLUMN actor_type audit_actor_type NOT NULL DEFAULT 'system';
+ALTER TABLE audit_logs ADD COLUMN actor_id UUID;
+
+UPDATE audit_logs SET actor_type = 'user', actor_id = user_id WHERE user_id IS NOT NULL;
+
+-- Dropping the column also drops its foreign key and idx_audit_logs_user_id
+ALTER TABLE audit_logs DROP COLUMN user_id;
+
+CREATE INDEX idx_audit_logs_actor ON audit_logs(actor_type, actor_id, created_at DESC);
+
+-- error_logs
+ALTER TABLE error_logs ADD COLUMN actor_type audit_actor_type NOT NULL DEFAULT 'system';
+ALTER TABLE error_logs ADD COLUMN actor_id UUID;
+
+UPDATE error_logs SET actor_type = 'user', actor_id = user_id WHERE user_id IS NOT NULL;
+
+ALTER TABLE error_logs DROP COLUMN user_id;
+
+CREATE INDEX idx_error_logs_actor ON error_logs(actor_type, actor_id, created_at DESC);
@@ -0,0 +1,11 @@
+-- Remove audit log menu item
+DELETE FROM menu_items WHERE code = 'audit-logs';
+
+-- Remove audit log role permissions
+DELETE FROM role_permissions
+WHERE permission_id IN (
+    SELECT id FROM permissions WHERE category = 'audit'
+);
+
+-- Remove audit log permissions
+DELETE FROM permissions WHERE category = 'audit';
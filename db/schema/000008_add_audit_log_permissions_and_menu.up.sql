@@ -0,0 +1,19 @@
+-- ==============================================
+-- ADD AUDIT LOG PERMISSIONS
+-- ==============================================
+
+INSERT INTO permissions (code, name, description, category) VALUES
+    ('audit.read', 'Read Audit Logs', 'Ability to browse the audit trail of changes', 'audit');
+
+-- Super Admin can browse the audit trail
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'super_admin', id FROM permissions
+WHERE code = 'audit.read' AND is_active = true;
+
+-- ==============================================
+-- ADD AUDIT LOG MENU ITEM
+-- ==============================================
+
+INSERT INTO menu_items (code, label, icon, path, order_index, permission_id) VALUES
+    ('audit-logs', 'Audit Logs', 'clipboard-list', '/admin/audit-logs', 7,
+        (SELECT id FROM permissions WHERE code = 'audit.read'));
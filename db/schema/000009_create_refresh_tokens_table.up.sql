@@ -0,0 +1,32 @@
+-- ==============================================
+-- REFRESH TOKENS TABLE
+-- ==============================================
+-- Opaque refresh tokens for users and admins. Only the SHA-256 hash of a
+-- token is stored. Every refresh rotates the token: the old row is revoked
+-- and points at its replacement through replaced_by. All tokens descending
+-- from one login share a family_id, so presenting an already rotated token
+-- (a sign that it was stolen) revokes the whole family.
+
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('user', 'admin')),
+    subject_id UUID NOT NULL,
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    replaced_by UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX idx_refresh_tokens_subject ON refresh_tokens(subject_type, subject_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+-- Add trigger for auto-updating updated_at
+CREATE TRIGGER trigger_update_refresh_tokens_updated_at
+    BEFORE UPDATE ON refresh_tokens
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+-- Drop revoked tokens table and token versions
+DROP TABLE IF EXISTS revoked_tokens CASCADE;
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS token_version;
+ALTER TABLE admins DROP COLUMN IF EXISTS token_version;
+ALTER TABLE users DROP COLUMN IF EXISTS token_version;
@@ -0,0 +1,30 @@
+-- ==============================================
+-- SESSION REVOCATION
+-- ==============================================
+-- token_version is embedded in every access and refresh token. Bumping it
+-- (logout-all, password/role/status change) invalidates every token issued
+-- before, without having to track them individually.
+
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE admins ADD COLUMN token_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE refresh_tokens ADD COLUMN token_version INTEGER NOT NULL DEFAULT 1;
+
+-- Denylist of individual access tokens (by jti) revoked through logout.
+-- Rows are only needed until the token would have expired anyway.
+CREATE TABLE revoked_tokens (
+    jti UUID PRIMARY KEY,
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('user', 'admin')),
+    subject_id UUID NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- Add trigger for auto-updating updated_at
+CREATE TRIGGER trigger_update_revoked_tokens_updated_at
+    BEFORE UPDATE ON revoked_tokens
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Drop admin MFA tables
+DROP TABLE IF EXISTS role_mfa_policies CASCADE;
+DROP TABLE IF EXISTS admin_mfa_recovery_codes CASCADE;
+DROP TABLE IF EXISTS admin_mfa CASCADE;
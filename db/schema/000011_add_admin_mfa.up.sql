@@ -0,0 +1,63 @@
+-- ==============================================
+-- ADMIN MULTI-FACTOR AUTHENTICATION
+-- ==============================================
+-- TOTP (RFC 6238) second factor for admins. The shared secret is stored
+-- encrypted; enabled_at stays NULL until the admin confirms enrollment with
+-- a valid code. last_used_step records the time step of the last accepted
+-- code so the same code cannot be replayed within its validity window.
+-- failed_attempts counts wrong codes since the last success; reaching the
+-- limit invalidates the pending login challenge.
+
+CREATE TABLE admin_mfa (
+    admin_id UUID PRIMARY KEY REFERENCES admins(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- One-time recovery codes, stored as HMAC-SHA256 hashes keyed from the MFA key
+CREATE TABLE admin_mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Roles whose admins must complete MFA before receiving a token
+CREATE TABLE role_mfa_policies (
+    role VARCHAR(50) PRIMARY KEY,
+    mfa_required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX idx_admin_mfa_recovery_codes_admin_code ON admin_mfa_recovery_codes(admin_id, code_hash);
+
+-- Add triggers for auto-updating updated_at
+CREATE TRIGGER trigger_update_admin_mfa_updated_at
+    BEFORE UPDATE ON admin_mfa
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER trigger_update_admin_mfa_recovery_codes_updated_at
+    BEFORE UPDATE ON admin_mfa_recovery_codes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER trigger_update_role_mfa_policies_updated_at
+    BEFORE UPDATE ON role_mfa_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Super admins (including the seeded superadmin) must enroll on next login
+INSERT INTO role_mfa_policies (role, mfa_required) VALUES
+    ('super_admin', TRUE),
+    ('admin', FALSE),
+    ('moderator', FALSE)
+ON CONFLICT (role) DO NOTHING;
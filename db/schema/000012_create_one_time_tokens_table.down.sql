@@ -0,0 +1,2 @@
+-- Drop one-time tokens table
+DROP TABLE IF EXISTS one_time_tokens CASCADE;
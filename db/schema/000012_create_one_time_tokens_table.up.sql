@@ -0,0 +1,29 @@
+-- ==============================================
+-- ONE-TIME TOKENS TABLE
+-- ==============================================
+-- Short-lived, single-use tokens sent to users and admins by email (password
+-- reset links). Only the SHA-256 hash of a token is stored. A token is
+-- consumed by setting used_at, and issuing a new token for the same purpose
+-- invalidates the ones sent before it.
+
+CREATE TABLE one_time_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    purpose VARCHAR(32) NOT NULL,
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('user', 'admin')),
+    subject_id UUID NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_one_time_tokens_subject ON one_time_tokens(purpose, subject_type, subject_id);
+CREATE INDEX idx_one_time_tokens_expires_at ON one_time_tokens(expires_at);
+
+-- Add trigger for auto-updating updated_at
+CREATE TRIGGER trigger_update_one_time_tokens_updated_at
+    BEFORE UPDATE ON one_time_tokens
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- ==============================================
+-- EMAIL VERIFICATION
+-- ==============================================
+-- email_verified_at is set once the user opens the link sent to their email
+-- address, and cleared again when the email address changes.
+
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Existing accounts predate verification; treat them as verified
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,2 @@
+-- Drop auth throttles table
+DROP TABLE IF EXISTS auth_throttles CASCADE;
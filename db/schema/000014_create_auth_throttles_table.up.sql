@@ -0,0 +1,30 @@
+-- ==============================================
+-- AUTH THROTTLES TABLE
+-- ==============================================
+-- Failed login attempts per account (lowercased email or admin username) and
+-- per client IP address, separately for users and admins. Logins are delayed
+-- progressively as failures add up and locked until locked_until once the
+-- configured limit is reached. A successful login deletes the account's row.
+-- Kept in Postgres so every replica sees the same counters.
+
+CREATE TABLE auth_throttles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('user', 'admin')),
+    key_type VARCHAR(20) NOT NULL CHECK (key_type IN ('account', 'ip')),
+    throttle_key VARCHAR(255) NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (subject_type, key_type, throttle_key)
+);
+
+-- Create indexes
+CREATE INDEX idx_auth_throttles_last_failed_at ON auth_throttles(last_failed_at);
+
+-- Add trigger for auto-updating updated_at
+CREATE TRIGGER trigger_update_auth_throttles_updated_at
+    BEFORE UPDATE ON auth_throttles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
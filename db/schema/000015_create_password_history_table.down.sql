@@ -0,0 +1,2 @@
+-- Drop password history table
+DROP TABLE IF EXISTS password_history CASCADE;
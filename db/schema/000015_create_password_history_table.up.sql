@@ -0,0 +1,17 @@
+-- ==============================================
+-- PASSWORD HISTORY TABLE
+-- ==============================================
+-- Previous password hashes of users and admins, so a password change can
+-- reject recently used passwords. Only the configured number of most recent
+-- hashes is kept per account; older rows are pruned when a new one is added.
+
+CREATE TABLE password_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject_type VARCHAR(20) NOT NULL CHECK (subject_type IN ('user', 'admin')),
+    subject_id UUID NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_password_history_subject ON password_history(subject_type, subject_id, created_at DESC);
@@ -0,0 +1,2 @@
+-- Drop admin API keys table
+DROP TABLE IF EXISTS admin_api_keys CASCADE;
@@ -0,0 +1,31 @@
+-- ==============================================
+-- ADMIN API KEYS TABLE
+-- ==============================================
+-- Named keys that let scripts and batch jobs call the admin API on behalf of
+-- the admin who created them. A key carries a subset of its owner's
+-- permission codes and always expires. Only the SHA-256 hash of a key is
+-- stored; key_prefix keeps its first characters so admins can tell keys apart.
+-- Revoked keys are kept so audit entries can still be traced to them.
+
+CREATE TABLE admin_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_admin_api_keys_admin_id ON admin_api_keys(admin_id, created_at DESC);
+
+-- Add trigger for auto-updating updated_at
+CREATE TRIGGER trigger_update_admin_api_keys_updated_at
+    BEFORE UPDATE ON admin_api_keys
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
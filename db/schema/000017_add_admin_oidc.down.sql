@@ -0,0 +1,3 @@
+-- Drop admin single sign-on tables
+DROP TABLE IF EXISTS oidc_login_states CASCADE;
+DROP TABLE IF EXISTS admin_identities CASCADE;
@@ -0,0 +1,42 @@
+-- ==============================================
+-- ADMIN SINGLE SIGN-ON (OPENID CONNECT)
+-- ==============================================
+-- admin_identities links an admin to an account at the identity provider.
+-- The subject is only unique per issuer, so both identify the account.
+
+CREATE TABLE admin_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (issuer, subject)
+);
+
+-- Create indexes
+CREATE INDEX idx_admin_identities_admin_id ON admin_identities(admin_id);
+
+-- Add trigger for auto-updating updated_at
+CREATE TRIGGER trigger_update_admin_identities_updated_at
+    BEFORE UPDATE ON admin_identities
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- oidc_login_states holds a started login until the provider redirects back.
+-- The state parameter is stored hashed; the nonce and PKCE code verifier are
+-- never sent to the browser. Rows are deleted when used or expired.
+
+CREATE TABLE oidc_login_states (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    state_hash VARCHAR(64) UNIQUE NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
@@ -0,0 +1,8 @@
+-- Remove user impersonation role permissions
+DELETE FROM role_permissions
+WHERE permission_id IN (
+    SELECT id FROM permissions WHERE code = 'users.impersonate'
+);
+
+-- Remove user impersonation permission
+DELETE FROM permissions WHERE code = 'users.impersonate';
@@ -0,0 +1,11 @@
+-- ==============================================
+-- ADD USER IMPERSONATION PERMISSION
+-- ==============================================
+
+INSERT INTO permissions (code, name, description, category) VALUES
+    ('users.impersonate', 'Impersonate Users', 'Ability to act as a frontend user with a short-lived token', 'users');
+
+-- Only Super Admin can impersonate by default
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'super_admin', id FROM permissions
+WHERE code = 'users.impersonate' AND is_active = true;
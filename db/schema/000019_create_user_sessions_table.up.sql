@@ -0,0 +1,20 @@
+-- ==============================================
+-- USER SESSIONS TABLE
+-- ==============================================
+-- One row per frontend login, so users can see where they are signed in.
+-- The id is the refresh token family of the login and is carried as sid in
+-- access tokens. A session is active while its family has a refresh token
+-- that is neither revoked nor expired; rows are purged once the family's
+-- tokens are gone.
+
+CREATE TABLE user_sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
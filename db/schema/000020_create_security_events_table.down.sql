@@ -0,0 +1,14 @@
+-- Remove security events menu item
+DELETE FROM menu_items WHERE code = 'security-events';
+
+-- Remove security event role permissions
+DELETE FROM role_permissions
+WHERE permission_id IN (
+    SELECT id FROM permissions WHERE category = 'security'
+);
+
+-- Remove security event permissions
+DELETE FROM permissions WHERE category = 'security';
+
+-- Drop security events table
+DROP TABLE IF EXISTS security_events CASCADE;
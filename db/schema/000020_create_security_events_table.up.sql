@@ -0,0 +1,48 @@
+-- ==============================================
+-- SECURITY EVENTS TABLE
+-- ==============================================
+-- Authentication activity of users and admins: logins, registrations,
+-- token refreshes, password changes and lockouts, successful or not.
+-- actor_type says which kind of account the event concerns; actor_id is
+-- empty when the account is unknown, e.g. a login with an unregistered email.
+-- identifier is the email or username that was tried.
+
+CREATE TABLE security_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(50) NOT NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('success', 'failure')),
+    actor_type audit_actor_type NOT NULL,
+    actor_id UUID,
+    identifier VARCHAR(255),
+    reason VARCHAR(100),
+    request_id VARCHAR(100),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_security_events_created_at ON security_events(created_at DESC);
+CREATE INDEX idx_security_events_actor ON security_events(actor_type, actor_id, created_at DESC);
+CREATE INDEX idx_security_events_ip_address ON security_events(ip_address, created_at DESC);
+
+-- ==============================================
+-- ADD SECURITY EVENT PERMISSIONS
+-- ==============================================
+
+INSERT INTO permissions (code, name, description, category) VALUES
+    ('security.read', 'Read Security Events', 'Ability to browse authentication activity of users and admins', 'security');
+
+-- Super Admin can browse security events
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'super_admin', id FROM permissions
+WHERE code = 'security.read' AND is_active = true;
+
+-- ==============================================
+-- ADD SECURITY EVENTS MENU ITEM
+-- ==============================================
+
+INSERT INTO menu_items (code, label, icon, path, order_index, permission_id) VALUES
+    ('security-events', 'Security Events', 'lock', '/admin/security-events', 8,
+        (SELECT id FROM permissions WHERE code = 'security.read'));
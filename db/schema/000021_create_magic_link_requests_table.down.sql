@@ -0,0 +1,5 @@
+-- Drop normalized email lookup index
+DROP INDEX IF EXISTS idx_users_email_lower;
+
+-- Drop magic link requests table
+DROP TABLE IF EXISTS magic_link_requests CASCADE;
@@ -0,0 +1,22 @@
+-- ==============================================
+-- MAGIC LINK REQUESTS TABLE
+-- ==============================================
+-- One row per passwordless login link requested on the frontend, whether or
+-- not the email belongs to an account, so requests can be throttled per
+-- email and per client IP address without revealing which emails are
+-- registered. The links themselves live in one_time_tokens. Rows are purged
+-- once they are older than the throttling window.
+
+CREATE TABLE magic_link_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_magic_link_requests_email ON magic_link_requests(email, created_at);
+CREATE INDEX idx_magic_link_requests_ip_address ON magic_link_requests(ip_address, created_at);
+
+-- Login links are requested by normalized (lowercased) email
+CREATE INDEX idx_users_email_lower ON users(lower(email));
@@ -0,0 +1,8 @@
+-- Drop account deletion functions
+DROP FUNCTION IF EXISTS anonymize_user(UUID);
+DROP FUNCTION IF EXISTS redact_jsonb_keys(JSONB, TEXT[]);
+
+-- Remove account deletion columns from users table
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
@@ -0,0 +1,97 @@
+-- ==============================================
+-- ACCOUNT DELETION
+-- ==============================================
+-- Users schedule the deletion of their own account. deletion_scheduled_at is
+-- when the grace period ends; logging in before then clears it. Once it has
+-- passed the account is anonymized instead of deleted: the row and its
+-- addresses stay, so foreign keys and reports keep working, but personal data
+-- is overwritten and anonymized_at is set.
+
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE;
+
+-- Finds the accounts the cleanup job has to anonymize
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL AND anonymized_at IS NULL;
+
+-- Sets the given top-level keys of a JSON object to null, keeping the others.
+-- Keys that are not present are not added.
+CREATE OR REPLACE FUNCTION redact_jsonb_keys(data JSONB, keys TEXT[])
+RETURNS JSONB AS $$
+    SELECT CASE
+        WHEN data IS NULL OR jsonb_typeof(data) <> 'object' THEN data
+        ELSE data || COALESCE(
+            (SELECT jsonb_object_agg(key, 'null'::jsonb) FROM jsonb_object_keys(data) AS key WHERE key = ANY(keys)),
+            '{}'::jsonb
+        )
+    END;
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Removes the personal data of a user: profile fields, address details, the
+-- same fields in the audit log snapshots of the user and their addresses
+-- (including addresses deleted earlier), the IP addresses and user agents of
+-- their sessions and of the audit entries they made, and the email, IP
+-- addresses and user agents of their security events. Password history, login
+-- throttles and magic link requests of the account are deleted, and
+-- token_version is bumped so remaining access tokens stop working. Returns
+-- FALSE if the user does not exist or was already anonymized.
+CREATE OR REPLACE FUNCTION anonymize_user(target_id UUID)
+RETURNS BOOLEAN AS $$
+DECLARE
+    old_email TEXT;
+BEGIN
+    SELECT email INTO old_email FROM users
+    WHERE id = target_id AND anonymized_at IS NULL
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RETURN FALSE;
+    END IF;
+
+    UPDATE audit_logs
+    SET old_data = redact_jsonb_keys(old_data, ARRAY['email', 'username', 'first_name', 'last_name']),
+        new_data = redact_jsonb_keys(new_data, ARRAY['email', 'username', 'first_name', 'last_name'])
+    WHERE entity_type = 'users' AND entity_id = target_id;
+
+    UPDATE audit_logs
+    SET old_data = redact_jsonb_keys(old_data, ARRAY['address', 'floor', 'unit_no', 'block_tower', 'company_name']),
+        new_data = redact_jsonb_keys(new_data, ARRAY['address', 'floor', 'unit_no', 'block_tower', 'company_name'])
+    WHERE entity_type = 'addresses'
+        AND (old_data->>'user_id' = target_id::text OR new_data->>'user_id' = target_id::text);
+
+    UPDATE audit_logs
+    SET ip_address = NULL, user_agent = NULL
+    WHERE actor_type = 'user' AND actor_id = target_id;
+
+    UPDATE addresses
+    SET address = '', floor = '', unit_no = '', block_tower = NULL, company_name = NULL
+    WHERE user_id = target_id;
+
+    UPDATE security_events
+    SET identifier = NULL, ip_address = NULL, user_agent = NULL
+    WHERE actor_type = 'user' AND (actor_id = target_id OR lower(identifier) = lower(old_email));
+
+    UPDATE user_sessions
+    SET ip_address = NULL, user_agent = NULL
+    WHERE user_id = target_id;
+
+    DELETE FROM password_history WHERE subject_type = 'user' AND subject_id = target_id;
+    DELETE FROM auth_throttles
+    WHERE subject_type = 'user' AND key_type = 'account' AND throttle_key = lower(old_email);
+    DELETE FROM magic_link_requests WHERE lower(email) = lower(old_email);
+
+    UPDATE users
+    SET email = 'deleted-' || id || '@deleted.invalid',
+        username = 'deleted-' || id,
+        password_hash = '',
+        first_name = NULL,
+        last_name = NULL,
+        email_verified_at = NULL,
+        token_version = token_version + 1,
+        anonymized_at = NOW(),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = target_id;
+
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
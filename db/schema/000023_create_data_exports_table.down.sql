@@ -0,0 +1,74 @@
+-- Remove data export role permissions
+DELETE FROM role_permissions
+WHERE permission_id IN (
+    SELECT id FROM permissions WHERE code = 'users.export'
+);
+
+-- Remove data export permission
+DELETE FROM permissions WHERE code = 'users.export';
+
+-- Restore anonymize_user without the data export cleanup
+CREATE OR REPLACE FUNCTION anonymize_user(target_id UUID)
+RETURNS BOOLEAN AS $$
+DECLARE
+    old_email TEXT;
+BEGIN
+    SELECT email INTO old_email FROM users
+    WHERE id = target_id AND anonymized_at IS NULL
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RETURN FALSE;
+    END IF;
+
+    UPDATE audit_logs
+    SET old_data = redact_jsonb_keys(old_data, ARRAY['email', 'username', 'first_name', 'last_name']),
+        new_data = redact_jsonb_keys(new_data, ARRAY['email', 'username', 'first_name', 'last_name'])
+    WHERE entity_type = 'users' AND entity_id = target_id;
+
+    UPDATE audit_logs
+    SET old_data = redact_jsonb_keys(old_data, ARRAY['address', 'floor', 'unit_no', 'block_tower', 'company_name']),
+        new_data = redact_jsonb_keys(new_data, ARRAY['address', 'floor', 'unit_no', 'block_tower', 'company_name'])
+    WHERE entity_type = 'addresses'
+        AND (old_data->>'user_id' = target_id::text OR new_data->>'user_id' = target_id::text);
+
+    UPDATE audit_logs
+    SET ip_address = NULL, user_agent = NULL
+    WHERE actor_type = 'user' AND actor_id = target_id;
+
+    UPDATE addresses
+    SET address = '', floor = '', unit_no = '', block_tower = NULL, company_name = NULL
+    WHERE user_id = target_id;
+
+    UPDATE security_events
+    SET identifier = NULL, ip_address = NULL, user_agent = NULL
+    WHERE actor_type = 'user' AND (actor_id = target_id OR lower(identifier) = lower(old_email));
+
+    UPDATE user_sessions
+    SET ip_address = NULL, user_agent = NULL
+    WHERE user_id = target_id;
+
+    DELETE FROM password_history WHERE subject_type = 'user' AND subject_id = target_id;
+    DELETE FROM auth_throttles
+    WHERE subject_type = 'user' AND key_type = 'account' AND throttle_key = lower(old_email);
+    DELETE FROM magic_link_requests WHERE lower(email) = lower(old_email);
+
+    UPDATE users
+    SET email = 'deleted-' || id || '@deleted.invalid',
+        username = 'deleted-' || id,
+        password_hash = '',
+        first_name = NULL,
+        last_name = NULL,
+        email_verified_at = NULL,
+        token_version = token_version + 1,
+        anonymized_at = NOW(),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = target_id;
+
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Drop data export tables
+DROP TABLE IF EXISTS data_export_archives CASCADE;
+DROP TABLE IF EXISTS data_exports CASCADE;
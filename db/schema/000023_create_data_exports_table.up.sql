@@ -0,0 +1,115 @@
+-- ==============================================
+-- DATA EXPORTS TABLE
+-- ==============================================
+-- Personal data exports of frontend users, requested by the user or by an
+-- admin on their behalf. A background worker picks up pending exports, builds
+-- the archive and marks them completed (or failed). requested_by_type and
+-- requested_by_id record who asked for it. Exports and their archives are
+-- purged once expires_at has passed.
+
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_by_type audit_actor_type NOT NULL,
+    requested_by_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_data_exports_user_id ON data_exports(user_id, created_at DESC);
+CREATE INDEX idx_data_exports_pending ON data_exports(created_at)
+    WHERE status IN ('pending', 'processing');
+CREATE INDEX idx_data_exports_expires_at ON data_exports(expires_at);
+
+-- The archive is kept apart, so reading the status of an export does not load it
+CREATE TABLE data_export_archives (
+    export_id UUID PRIMARY KEY REFERENCES data_exports(id) ON DELETE CASCADE,
+    content BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- ==============================================
+-- DELETE DATA EXPORTS ON ANONYMIZATION
+-- ==============================================
+-- Archives hold the complete personal data of the user, so anonymize_user
+-- (see 000022) now deletes the user's exports as well.
+
+CREATE OR REPLACE FUNCTION anonymize_user(target_id UUID)
+RETURNS BOOLEAN AS $$
+DECLARE
+    old_email TEXT;
+BEGIN
+    SELECT email INTO old_email FROM users
+    WHERE id = target_id AND anonymized_at IS NULL
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RETURN FALSE;
+    END IF;
+
+    UPDATE audit_logs
+    SET old_data = redact_jsonb_keys(old_data, ARRAY['email', 'username', 'first_name', 'last_name']),
+        new_data = redact_jsonb_keys(new_data, ARRAY['email', 'username', 'first_name', 'last_name'])
+    WHERE entity_type = 'users' AND entity_id = target_id;
+
+    UPDATE audit_logs
+    SET old_data = redact_jsonb_keys(old_data, ARRAY['address', 'floor', 'unit_no', 'block_tower', 'company_name']),
+        new_data = redact_jsonb_keys(new_data, ARRAY['address', 'floor', 'unit_no', 'block_tower', 'company_name'])
+    WHERE entity_type = 'addresses'
+        AND (old_data->>'user_id' = target_id::text OR new_data->>'user_id' = target_id::text);
+
+    UPDATE audit_logs
+    SET ip_address = NULL, user_agent = NULL
+    WHERE actor_type = 'user' AND actor_id = target_id;
+
+    UPDATE addresses
+    SET address = '', floor = '', unit_no = '', block_tower = NULL, company_name = NULL
+    WHERE user_id = target_id;
+
+    UPDATE security_events
+    SET identifier = NULL, ip_address = NULL, user_agent = NULL
+    WHERE actor_type = 'user' AND (actor_id = target_id OR lower(identifier) = lower(old_email));
+
+    UPDATE user_sessions
+    SET ip_address = NULL, user_agent = NULL
+    WHERE user_id = target_id;
+
+    DELETE FROM password_history WHERE subject_type = 'user' AND subject_id = target_id;
+    DELETE FROM auth_throttles
+    WHERE subject_type = 'user' AND key_type = 'account' AND throttle_key = lower(old_email);
+    DELETE FROM magic_link_requests WHERE lower(email) = lower(old_email);
+    -- Archives cascade with their exports
+    DELETE FROM data_exports WHERE user_id = target_id;
+
+    UPDATE users
+    SET email = 'deleted-' || id || '@deleted.invalid',
+        username = 'deleted-' || id,
+        password_hash = '',
+        first_name = NULL,
+        last_name = NULL,
+        email_verified_at = NULL,
+        token_version = token_version + 1,
+        anonymized_at = NOW(),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE id = target_id;
+
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ==============================================
+-- ADD DATA EXPORT PERMISSION
+-- ==============================================
+
+INSERT INTO permissions (code, name, description, category) VALUES
+    ('users.export', 'Export User Data', 'Ability to export the personal data of a user', 'users');
+
+-- Only Super Admin can export user data by default
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'super_admin', id FROM permissions
+WHERE code = 'users.export' AND is_active = true;
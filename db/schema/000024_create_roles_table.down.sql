@@ -0,0 +1,10 @@
+-- Drop foreign keys to roles
+ALTER TABLE role_mfa_policies DROP CONSTRAINT IF EXISTS fk_role_mfa_policies_role;
+ALTER TABLE role_permissions DROP CONSTRAINT IF EXISTS fk_role_permissions_role;
+ALTER TABLE admins DROP CONSTRAINT IF EXISTS fk_admins_role;
+
+-- Drop role inheritance lookup
+DROP FUNCTION IF EXISTS role_lineage(VARCHAR);
+
+-- Drop roles table
+DROP TABLE IF EXISTS roles CASCADE;
@@ -0,0 +1,83 @@
+-- ==============================================
+-- ROLES
+-- ==============================================
+-- Admin roles used to exist only as strings in admins.role and
+-- role_permissions.role. They now live in their own table, which admins,
+-- role_permissions and role_mfa_policies reference by name.
+--
+-- A role may inherit from another role: it grants its own permissions plus
+-- every permission of the role it inherits from, transitively.
+
+CREATE TABLE roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    -- System roles are referenced by the application and cannot be deleted
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    inherits_from VARCHAR(50) REFERENCES roles(name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT roles_no_self_inheritance CHECK (inherits_from <> name)
+);
+
+CREATE INDEX idx_roles_inherits_from ON roles(inherits_from);
+
+CREATE TRIGGER trigger_update_roles_updated_at
+    BEFORE UPDATE ON roles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Returns the role and every role it inherits from, transitively. UNION
+-- rather than UNION ALL stops the recursion should a cycle ever be created.
+CREATE OR REPLACE FUNCTION role_lineage(role_name VARCHAR)
+RETURNS SETOF VARCHAR AS $$
+    WITH RECURSIVE lineage(name, inherits_from) AS (
+        SELECT r.name, r.inherits_from FROM roles r WHERE r.name = role_name
+        UNION
+        SELECT r.name, r.inherits_from
+        FROM roles r
+        INNER JOIN lineage l ON r.name = l.inherits_from
+    )
+    SELECT name FROM lineage;
+$$ LANGUAGE SQL STABLE;
+
+-- ==============================================
+-- SEED DATA: ROLES
+-- ==============================================
+
+-- Each seeded role already grants everything the role below it grants, so the
+-- inheritance chain moderator < admin < super_admin changes no permissions
+INSERT INTO roles (name, description, is_system) VALUES
+    ('moderator', 'Read-only access', TRUE),
+    ('admin', 'User and address management', TRUE),
+    ('super_admin', 'Full access, including admin and role management', TRUE);
+
+UPDATE roles SET inherits_from = 'moderator' WHERE name = 'admin';
+UPDATE roles SET inherits_from = 'admin' WHERE name = 'super_admin';
+
+-- Keep any other role already in use, so the foreign keys below can be added
+INSERT INTO roles (name)
+SELECT role FROM admins
+UNION
+SELECT role FROM role_permissions
+UNION
+SELECT role FROM role_mfa_policies
+ON CONFLICT (name) DO NOTHING;
+
+-- ==============================================
+-- FOREIGN KEYS TO ROLES
+-- ==============================================
+
+-- A role cannot be deleted while admins have it
+ALTER TABLE admins
+    ADD CONSTRAINT fk_admins_role FOREIGN KEY (role)
+    REFERENCES roles(name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+-- Grants and MFA policies go with their role
+ALTER TABLE role_permissions
+    ADD CONSTRAINT fk_role_permissions_role FOREIGN KEY (role)
+    REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE role_mfa_policies
+    ADD CONSTRAINT fk_role_mfa_policies_role FOREIGN KEY (role)
+    REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE;
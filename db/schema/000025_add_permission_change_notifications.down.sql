@@ -0,0 +1,6 @@
+-- Drop permission change notifications
+DROP TRIGGER IF EXISTS trigger_notify_permissions_changed ON permissions;
+DROP TRIGGER IF EXISTS trigger_notify_role_permissions_changed ON role_permissions;
+DROP TRIGGER IF EXISTS trigger_notify_roles_changed ON roles;
+
+DROP FUNCTION IF EXISTS notify_permissions_changed();
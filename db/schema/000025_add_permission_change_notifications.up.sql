@@ -0,0 +1,24 @@
+-- Notify API instances when roles or their permissions change so they can
+-- drop cached permission sets. Notifications are delivered on commit.
+CREATE OR REPLACE FUNCTION notify_permissions_changed()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('permissions_changed', TG_TABLE_NAME);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_notify_roles_changed
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON roles
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_permissions_changed();
+
+CREATE TRIGGER trigger_notify_role_permissions_changed
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON role_permissions
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_permissions_changed();
+
+CREATE TRIGGER trigger_notify_permissions_changed
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON permissions
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_permissions_changed();
@@ -0,0 +1,5 @@
+-- Drop effective permission lookup
+DROP FUNCTION IF EXISTS admin_permission_ids(UUID, VARCHAR);
+
+-- Drop admin permission overrides table
+DROP TABLE IF EXISTS admin_permission_overrides CASCADE;
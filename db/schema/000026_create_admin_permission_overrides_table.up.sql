@@ -0,0 +1,55 @@
+-- ==============================================
+-- ADMIN PERMISSION OVERRIDES TABLE
+-- ==============================================
+-- Grants or denies a single permission to one admin on top of their role,
+-- optionally until expires_at. An admin's effective permissions are the
+-- role's (inherited included) plus their allows minus their denies; a deny
+-- wins over any grant. Expired overrides are ignored and kept for reference.
+
+CREATE TABLE admin_permission_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    effect VARCHAR(10) NOT NULL CHECK (effect IN ('allow', 'deny')),
+    reason TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES admins(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE(admin_id, permission_id)
+);
+
+CREATE TRIGGER trigger_update_admin_permission_overrides_updated_at
+    BEFORE UPDATE ON admin_permission_overrides
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Cached permission sets are flushed when overrides change, like role grants
+CREATE TRIGGER trigger_notify_admin_permission_overrides_changed
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON admin_permission_overrides
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION notify_permissions_changed();
+
+-- Returns the IDs of the permissions the admin holds: those of the role and
+-- the roles it inherits from, plus the admin's unexpired allows, minus the
+-- admin's unexpired denies.
+CREATE OR REPLACE FUNCTION admin_permission_ids(p_admin_id UUID, p_role VARCHAR)
+RETURNS SETOF UUID AS $$
+    (
+        SELECT rp.permission_id
+        FROM role_permissions rp
+        WHERE rp.role IN (SELECT role_lineage(p_role))
+        UNION
+        SELECT o.permission_id
+        FROM admin_permission_overrides o
+        WHERE o.admin_id = p_admin_id
+          AND o.effect = 'allow'
+          AND (o.expires_at IS NULL OR o.expires_at > NOW())
+    )
+    EXCEPT
+    SELECT o.permission_id
+    FROM admin_permission_overrides o
+    WHERE o.admin_id = p_admin_id
+      AND o.effect = 'deny'
+      AND (o.expires_at IS NULL OR o.expires_at > NOW());
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,154 @@
+// Package accountdeletion schedules user accounts for deletion and anonymizes
+// them once their grace period has ended. Anonymized accounts keep their row,
+// so addresses and audit history still reference a user, but lose their
+// personal data.
+package accountdeletion
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// batchSize caps how many accounts one AnonymizeDue run anonymizes, so a
+// backlog is worked off over several runs instead of in one long one
+const batchSize = 100
+
+// Service schedules, cancels and carries out account deletions
+type Service struct {
+	queries      *db.Queries
+	auditService *audit.Service
+	gracePeriod  time.Duration
+	now          func() time.Time
+}
+
+// NewService creates an account deletion service. Scheduled accounts are
+// anonymized once gracePeriod has passed.
+func NewService(queries *db.Queries, auditService *audit.Service, gracePeriod time.Duration) *Service {
+	return &Service{
+		queries:      queries,
+		auditService: auditService,
+		gracePeriod:  max(gracePeriod, 0),
+		now:          time.Now,
+	}
+}
+
+// Schedule marks the user for deletion at the end of the grace period and
+// returns the updated user. Scheduling again moves the deletion date.
+func (s *Service) Schedule(ctx context.Context, user *db.User) (*db.User, error) {
+	scheduled, err := s.queries.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+		ID:                  user.ID,
+		DeletionScheduledAt: pgtype.Timestamptz{Time: s.now().Add(s.gracePeriod), Valid: true},
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("user not found")
+		}
+		return nil, errors.Internal("failed to schedule account deletion", err)
+	}
+
+	s.auditService.LogUpdate(ctx, "users", uuid.UUID(user.ID.Bytes), user, scheduled)
+
+	return &scheduled, nil
+}
+
+// Cancel lifts a scheduled deletion of the user and updates user in place.
+// It does nothing when no deletion is scheduled.
+func (s *Service) Cancel(ctx context.Context, user *db.User) error {
+	if !user.DeletionScheduledAt.Valid {
+		return nil
+	}
+
+	cancelled, err := s.queries.CancelUserDeletion(ctx, user.ID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("user not found")
+		}
+		return errors.Internal("failed to cancel account deletion", err)
+	}
+
+	s.auditService.LogUpdate(ctx, "users", uuid.UUID(user.ID.Bytes), user, cancelled)
+	*user = cancelled
+
+	return nil
+}
+
+// Anonymize removes the personal data of the user right away, including from
+// earlier audit log entries, and signs it out everywhere. It returns a not
+// found error when the user does not exist or is already anonymized.
+func (s *Service) Anonymize(ctx context.Context, userID uuid.UUID) error {
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+
+	anonymized, err := s.queries.AnonymizeUser(ctx, pgUserID)
+	if err != nil {
+		slog.Error("failed to anonymize user", "user_id", userID.String(), "error", err)
+		return errors.Internal("failed to delete user", err)
+	}
+	if !anonymized {
+		return errors.NotFound("user not found")
+	}
+
+	user, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		slog.Error("failed to get anonymized user", "user_id", userID.String(), "error", err)
+		return nil
+	}
+
+	// Only the anonymized row is logged, so the deletion does not write the personal data back
+	s.auditService.LogDelete(ctx, "users", userID, user)
+
+	return nil
+}
+
+// Start anonymizes due accounts immediately and then on every interval until ctx is cancelled.
+// Failures are logged and retried on the next tick.
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			anonymized, err := s.AnonymizeDue(ctx)
+			if err != nil {
+				slog.Error("failed to anonymize accounts due for deletion", "error", err)
+			} else if anonymized > 0 {
+				slog.Info("anonymized accounts due for deletion", "anonymized", anonymized)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// AnonymizeDue anonymizes accounts whose grace period has ended and returns how many it did
+func (s *Service) AnonymizeDue(ctx context.Context) (int64, error) {
+	ids, err := s.queries.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{
+		DeletionScheduledAt: pgtype.Timestamptz{Time: s.now(), Valid: true},
+		Limit:               batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, id := range ids {
+		if err := s.Anonymize(ctx, uuid.UUID(id.Bytes)); err != nil {
+			// Another instance may have got to it first; the rest of the batch still runs
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
@@ -3,12 +3,14 @@ package address
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/user/coc/internal/accountdeletion"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
 )
@@ -466,6 +468,68 @@ func TestIntegration_FrontendService_SetDefaultAddress(t *testing.T) {
 	_ = address1 // Silence unused warning
 }
 
+// TestIntegration_AccountDeletion_RedactsDeletedAddresses tests that anonymizing
+// a user also redacts the audit snapshots of addresses they deleted earlier
+func TestIntegration_AccountDeletion_RedactsDeletedAddresses(t *testing.T) {
+	pool, queries, _ := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	auditService := audit.NewService(qtx)
+	service := NewFrontendService(qtx, auditService)
+
+	userIDBytes := createTestUser(t, qtx, ctx)
+	userID := uuid.UUID(userIDBytes)
+
+	created, err := service.CreateAddress(ctx, userID, UserCreateAddressRequest{
+		Address:     "221B Baker Street",
+		Floor:       "2",
+		UnitNo:      "B",
+		CompanyName: stringPtr("Consulting Detectives"),
+	})
+	if err != nil {
+		t.Fatalf("CreateAddress failed: %v", err)
+	}
+
+	// Delete the address, then the account
+	if err := service.DeleteAddress(ctx, userID, created.ID); err != nil {
+		t.Fatalf("DeleteAddress failed: %v", err)
+	}
+	if err := accountdeletion.NewService(qtx, auditService, 0).Anonymize(ctx, userID); err != nil {
+		t.Fatalf("Anonymize failed: %v", err)
+	}
+
+	addressUUID, _ := uuid.Parse(created.ID)
+	auditLogs, err := qtx.ListAuditLogsByEntity(ctx, db.ListAuditLogsByEntityParams{
+		EntityType: "addresses",
+		EntityID:   pgtype.UUID{Bytes: addressUUID, Valid: true},
+		Limit:      10,
+		Offset:     0,
+	})
+	if err != nil {
+		t.Fatalf("failed to get audit logs: %v", err)
+	}
+	if len(auditLogs) < 2 {
+		t.Fatalf("expected CREATE and DELETE audit log entries, got %d", len(auditLogs))
+	}
+
+	for _, log := range auditLogs {
+		for _, data := range [][]byte{log.OldData, log.NewData} {
+			if strings.Contains(string(data), "Baker Street") || strings.Contains(string(data), "Consulting Detectives") {
+				t.Errorf("expected %s audit snapshot to be redacted, got %s", log.Action, data)
+			}
+		}
+	}
+}
+
 // Helper functions
 func stringPtr(s string) *string {
 	return &s
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/user/coc/internal/ctxkeys"
 	"github.com/user/coc/internal/validation"
@@ -203,3 +204,60 @@ func TestHandler_UpdateAdmin_ValidationError(t *testing.T) {
 		t.Errorf("expected 400, got %d", rec.Code)
 	}
 }
+
+// TestHandler_UnlockAdmin_MissingAdminRole tests UnlockAdmin without admin role
+func TestHandler_UnlockAdmin_MissingAdminRole(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+	req, rec := newRequestWithoutAdminRole("POST", "/admins/"+uuid.New().String()+"/unlock", nil)
+
+	handler.UnlockAdmin(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestHandler_SetPermissionOverride_MissingAdminRole tests SetPermissionOverride without admin role
+func TestHandler_SetPermissionOverride_MissingAdminRole(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+	req, rec := newRequestWithoutAdminRole("PUT", "/admins/"+uuid.New().String()+"/permission-overrides/users.update", map[string]interface{}{
+		"effect": "allow",
+	})
+
+	handler.SetPermissionOverride(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestHandler_SetPermissionOverride_InvalidEffect tests that the effect must be allow or deny
+func TestHandler_SetPermissionOverride_InvalidEffect(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+	id := uuid.New().String()
+	req, rec := newAdminRequest("PUT", "/admins/"+id+"/permission-overrides/users.update", map[string]interface{}{
+		"effect": "grant",
+	})
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	rctx.URLParams.Add("code", "users.update")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.SetPermissionOverride(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestHandler_RemovePermissionOverride_MissingCode tests that the permission code is required
+func TestHandler_RemovePermissionOverride_MissingCode(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+	req, rec := newAdminRequest("DELETE", "/admins/"+uuid.New().String()+"/permission-overrides/", nil)
+
+	handler.RemovePermissionOverride(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
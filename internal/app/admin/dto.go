@@ -1,5 +1,7 @@
 package admin
 
+import "time"
+
 // CreateAdminRequest represents the request to create a new admin
 type CreateAdminRequest struct {
 	Email     string `json:"email" validate:"required,email" example:"newadmin@example.com"`
@@ -8,7 +10,8 @@ type CreateAdminRequest struct {
 	FirstName string `json:"first_name" validate:"required,max=100" example:"John"`
 	LastName  string `json:"last_name" validate:"required,max=100" example:"Doe"`
 	// Role is optional on request; if omitted the service will default to "moderator".
-	Role string `json:"role,omitempty" validate:"omitempty,oneof=admin super_admin moderator" example:"moderator"`
+	// It must be one of the roles in the roles table.
+	Role string `json:"role,omitempty" validate:"omitempty,max=50" example:"moderator"`
 }
 
 // UpdateAdminRequest represents the request to update an admin
@@ -18,6 +21,28 @@ type UpdateAdminRequest struct {
 	Password  string `json:"password,omitempty" validate:"omitempty,min=8" example:"NewSecurePass123"`
 	FirstName string `json:"first_name,omitempty" validate:"omitempty,max=100" example:"Jane"`
 	LastName  string `json:"last_name,omitempty" validate:"omitempty,max=100" example:"Smith"`
-	Role      string `json:"role,omitempty" validate:"omitempty,oneof=admin super_admin moderator" example:"admin"`
+	Role      string `json:"role,omitempty" validate:"omitempty,max=50" example:"admin"`
 	IsActive  *bool  `json:"is_active,omitempty" example:"true"`
 }
+
+// SetPermissionOverrideRequest grants (allow) or denies (deny) one permission to
+// an admin regardless of their role. Without expires_at the override is permanent.
+type SetPermissionOverrideRequest struct {
+	Effect    string     `json:"effect" validate:"required,oneof=allow deny" example:"allow"`
+	Reason    string     `json:"reason,omitempty" validate:"omitempty,max=255" example:"Covering the support queue this week"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-10-23T00:00:00Z"`
+}
+
+// PermissionOverrideResponse represents a permission granted or denied to one admin
+type PermissionOverrideResponse struct {
+	ID             string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	PermissionCode string     `json:"permission_code" example:"users.update"`
+	Effect         string     `json:"effect" example:"allow"`
+	Reason         string     `json:"reason,omitempty" example:"Covering the support queue this week"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" example:"2026-10-23T00:00:00Z"`
+	// Expired overrides no longer apply and are kept for reference
+	Expired   bool      `json:"expired" example:"false"`
+	CreatedBy string    `json:"created_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt time.Time `json:"created_at" example:"2026-10-16T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2026-10-16T12:00:00Z"`
+}
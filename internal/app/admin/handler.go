@@ -222,3 +222,169 @@ func (h *Handler) DeleteAdmin(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, http.StatusOK, "admin deleted successfully", nil)
 }
+
+// UnlockAdmin handles POST /api/admin/v1/admins/{id}/unlock
+// @Summary      Unlock admin login
+// @Description  Lift a lockout after too many failed logins and clear the admin's failed attempts
+// @Tags         Admin Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Admin ID"
+// @Success      200 {object} response.JSONResponse "Admin unlocked successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      404 {object} response.JSONResponse "Admin not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/admins/{id}/unlock [post]
+func (h *Handler) UnlockAdmin(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "admin ID is required")
+		return
+	}
+
+	if err := h.service.UnlockAdmin(r.Context(), id); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "admin unlocked successfully", nil)
+}
+
+// ListPermissionOverrides handles GET /api/admin/v1/admins/{id}/permission-overrides
+// @Summary      List admin permission overrides
+// @Description  List the permissions granted or denied to an admin on top of their role, expired overrides included
+// @Tags         Admin Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Admin ID"
+// @Success      200 {object} response.JSONResponse{data=[]PermissionOverrideResponse} "Permission overrides retrieved successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Admin not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/admins/{id}/permission-overrides [get]
+func (h *Handler) ListPermissionOverrides(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "admin ID is required")
+		return
+	}
+
+	overrides, err := h.service.ListPermissionOverrides(r.Context(), id)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "permission overrides retrieved successfully", overrides)
+}
+
+// SetPermissionOverride handles PUT /api/admin/v1/admins/{id}/permission-overrides/{code}
+// @Summary      Grant or deny a permission to an admin
+// @Description  Grant (allow) or deny one permission to an admin regardless of their role, optionally until expires_at. A deny wins over the role and any allow. Replaces the admin's existing override for the permission and takes effect on the next request. An admin cannot deny themselves admins.manage.
+// @Tags         Admin Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Admin ID"
+// @Param        code path string true "Permission code"
+// @Param        request body SetPermissionOverrideRequest true "Override data"
+// @Success      200 {object} response.JSONResponse{data=PermissionOverrideResponse} "Permission override saved"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Admin or permission not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/admins/{id}/permission-overrides/{code} [put]
+func (h *Handler) SetPermissionOverride(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	actorID, _ := ctxkeys.GetAdminID(r)
+
+	id := chi.URLParam(r, "id")
+	code := chi.URLParam(r, "code")
+	if id == "" || code == "" {
+		response.Error(w, http.StatusBadRequest, "admin ID and permission code are required")
+		return
+	}
+
+	var req SetPermissionOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	override, err := h.service.SetPermissionOverride(r.Context(), actorID, id, code, req)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "permission override saved", override)
+}
+
+// RemovePermissionOverride handles DELETE /api/admin/v1/admins/{id}/permission-overrides/{code}
+// @Summary      Remove an admin permission override
+// @Description  Delete an admin's override for a permission, so they hold it again only if their role grants it. Takes effect on the next request. An admin cannot remove their own admins.manage allow.
+// @Tags         Admin Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Admin ID"
+// @Param        code path string true "Permission code"
+// @Success      200 {object} response.JSONResponse "Permission override removed"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Admin, permission or override not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/admins/{id}/permission-overrides/{code} [delete]
+func (h *Handler) RemovePermissionOverride(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	actorID, _ := ctxkeys.GetAdminID(r)
+
+	id := chi.URLParam(r, "id")
+	code := chi.URLParam(r, "code")
+	if id == "" || code == "" {
+		response.Error(w, http.StatusBadRequest, "admin ID and permission code are required")
+		return
+	}
+
+	if err := h.service.RemovePermissionOverride(r.Context(), actorID, id, code); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "permission override removed", nil)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/user/coc/internal/app/admin_menu"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/lockout"
 )
 
 func setupTestDB(t *testing.T) (*pgxpool.Pool, *db.Queries, *audit.Service) {
@@ -70,7 +73,7 @@ func TestIntegration_AdminService_CreateAdmin(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewService(qtx, auditService)
+	service := NewService(qtx, auditService, lockout.NewGuard(qtx, auditService, lockout.Policy{}))
 
 	// Create test data
 	req := CreateAdminRequest{
@@ -128,6 +131,12 @@ func TestIntegration_AdminService_CreateAdmin(t *testing.T) {
 		t.Errorf("expected audit action CREATE, got %s", auditLogs[0].Action)
 	}
 
+	// Roles come from the roles table
+	req.Email, req.Username, req.Role = "otheradmin@example.com", "otheradmin", "owner"
+	if _, err := service.CreateAdmin(ctx, req); err == nil {
+		t.Error("expected CreateAdmin with unknown role to fail")
+	}
+
 	// Transaction rolls back automatically - no cleanup needed!
 }
 
@@ -145,7 +154,7 @@ func TestIntegration_AdminService_GetAdmin(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewService(qtx, auditService)
+	service := NewService(qtx, auditService, lockout.NewGuard(qtx, auditService, lockout.Policy{}))
 
 	// First create an admin
 	req := CreateAdminRequest{
@@ -195,7 +204,7 @@ func TestIntegration_AdminService_ListAdmins(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewService(qtx, auditService)
+	service := NewService(qtx, auditService, lockout.NewGuard(qtx, auditService, lockout.Policy{}))
 
 	// Create multiple admins
 	admins := []CreateAdminRequest{
@@ -261,7 +270,7 @@ func TestIntegration_AdminService_UpdateAdmin(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewService(qtx, auditService)
+	service := NewService(qtx, auditService, lockout.NewGuard(qtx, auditService, lockout.Policy{}))
 
 	// Create an admin
 	req := CreateAdminRequest{
@@ -338,7 +347,7 @@ func TestIntegration_AdminService_DeleteAdmin(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewService(qtx, auditService)
+	service := NewService(qtx, auditService, lockout.NewGuard(qtx, auditService, lockout.Policy{}))
 
 	// Create an admin
 	req := CreateAdminRequest{
@@ -390,6 +399,101 @@ func TestIntegration_AdminService_DeleteAdmin(t *testing.T) {
 	}
 }
 
+func TestIntegration_AdminService_PermissionOverrides(t *testing.T) {
+	pool, queries, auditService := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+	service := NewService(qtx, auditService, lockout.NewGuard(qtx, auditService, lockout.Policy{}))
+
+	// Moderators can read users but not create them
+	created, err := service.CreateAdmin(ctx, CreateAdminRequest{
+		Email:     "overridetest@example.com",
+		Username:  "overridetest",
+		Password:  "password123",
+		FirstName: "Override",
+		LastName:  "Test",
+		Role:      "moderator",
+	})
+	if err != nil {
+		t.Fatalf("failed to create admin for override test: %v", err)
+	}
+	adminID := uuid.MustParse(created.ID)
+	actorID := uuid.NewString()
+
+	expiresAt := time.Now().Add(time.Hour)
+	if _, err := service.SetPermissionOverride(ctx, actorID, created.ID, "users.create", SetPermissionOverrideRequest{Effect: EffectAllow, ExpiresAt: &expiresAt}); err != nil {
+		t.Fatalf("SetPermissionOverride(allow) failed: %v", err)
+	}
+	if _, err := service.SetPermissionOverride(ctx, actorID, created.ID, "users.read", SetPermissionOverrideRequest{Effect: EffectDeny, Reason: "Pending review"}); err != nil {
+		t.Fatalf("SetPermissionOverride(deny) failed: %v", err)
+	}
+
+	permissions, err := admin_menu.GetAdminPermissions(ctx, qtx, adminID, "moderator")
+	if err != nil {
+		t.Fatalf("GetAdminPermissions failed: %v", err)
+	}
+	if !permissions["users.create"] {
+		t.Error("expected the allow override to grant users.create")
+	}
+	if permissions["users.read"] {
+		t.Error("expected the deny override to win over the role's users.read")
+	}
+
+	// The menu follows the same rules
+	menu, err := admin_menu.GetMenuForAdmin(ctx, qtx, adminID, "moderator")
+	if err != nil {
+		t.Fatalf("GetMenuForAdmin failed: %v", err)
+	}
+	paths := map[string]bool{}
+	for _, item := range menu {
+		for _, child := range item.Children {
+			paths[child.Path] = true
+		}
+	}
+	if !paths["/admin/users/create"] || paths["/admin/users"] {
+		t.Errorf("expected Create User but not User List in the menu, got %v", paths)
+	}
+
+	overrides, err := service.ListPermissionOverrides(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("ListPermissionOverrides failed: %v", err)
+	}
+	if len(overrides) != 2 {
+		t.Errorf("expected 2 overrides, got %d", len(overrides))
+	}
+
+	// Removing the deny restores the role's grant
+	if err := service.RemovePermissionOverride(ctx, actorID, created.ID, "users.read"); err != nil {
+		t.Fatalf("RemovePermissionOverride failed: %v", err)
+	}
+	permissions, err = admin_menu.GetAdminPermissions(ctx, qtx, adminID, "moderator")
+	if err != nil {
+		t.Fatalf("GetAdminPermissions failed: %v", err)
+	}
+	if !permissions["users.read"] {
+		t.Error("expected users.read to be granted again after removing the deny")
+	}
+
+	// Overrides must expire in the future, and admins cannot lock themselves out
+	past := time.Now().Add(-time.Hour)
+	if _, err := service.SetPermissionOverride(ctx, actorID, created.ID, "users.update", SetPermissionOverrideRequest{Effect: EffectAllow, ExpiresAt: &past}); err == nil {
+		t.Error("expected an override expiring in the past to be rejected")
+	}
+	if _, err := service.SetPermissionOverride(ctx, created.ID, created.ID, "admins.manage", SetPermissionOverrideRequest{Effect: EffectDeny}); err == nil {
+		t.Error("expected denying yourself admins.manage to be rejected")
+	}
+}
+
 // Helper function
 // func stringPtr(s string) *string {
 // 	return &s
@@ -0,0 +1,227 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/app/role"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// Permission override effects
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// override is the audit log snapshot of an admin_permission_overrides row. It
+// carries the permission code, which the row itself only references by ID.
+type override struct {
+	ID             pgtype.UUID        `json:"id"`
+	AdminID        pgtype.UUID        `json:"admin_id"`
+	PermissionID   pgtype.UUID        `json:"permission_id"`
+	PermissionCode string             `json:"permission_code"`
+	Effect         string             `json:"effect"`
+	Reason         pgtype.Text        `json:"reason"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	CreatedBy      pgtype.UUID        `json:"created_by"`
+}
+
+// ListPermissionOverrides returns the permission overrides of an admin, expired ones included
+func (s *Service) ListPermissionOverrides(ctx context.Context, id string) ([]*PermissionOverrideResponse, error) {
+	admin, err := s.activeAdmin(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListAdminPermissionOverrides(ctx, admin.ID)
+	if err != nil {
+		return nil, errors.Internal("failed to list permission overrides", err)
+	}
+
+	now := time.Now()
+	responses := make([]*PermissionOverrideResponse, len(rows))
+	for i, row := range rows {
+		responses[i] = toPermissionOverrideResponse(&db.AdminPermissionOverride{
+			ID:           row.ID,
+			AdminID:      row.AdminID,
+			PermissionID: row.PermissionID,
+			Effect:       row.Effect,
+			Reason:       row.Reason,
+			ExpiresAt:    row.ExpiresAt,
+			CreatedBy:    row.CreatedBy,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+		}, row.PermissionCode, now)
+	}
+
+	return responses, nil
+}
+
+// SetPermissionOverride grants or denies the permission to the admin regardless
+// of their role, replacing any override they already have for it. actorID is
+// the admin making the change; they cannot deny themselves role.ManagePermission.
+func (s *Service) SetPermissionOverride(ctx context.Context, actorID, id, code string, req SetPermissionOverrideRequest) (*PermissionOverrideResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.Validation("expires_at must be in the future")
+	}
+
+	admin, permission, err := s.adminPermission(ctx, id, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Effect == EffectDeny && code == role.ManagePermission && actorID == uuid.UUID(admin.ID.Bytes).String() {
+		return nil, errors.Forbidden("cannot deny yourself " + role.ManagePermission)
+	}
+
+	old, err := s.queries.GetAdminPermissionOverride(ctx, db.GetAdminPermissionOverrideParams{
+		AdminID:      admin.ID,
+		PermissionID: permission.ID,
+	})
+	exists := err == nil
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, errors.Internal("failed to check permission override", err)
+	}
+
+	params := db.UpsertAdminPermissionOverrideParams{
+		AdminID:      admin.ID,
+		PermissionID: permission.ID,
+		Effect:       req.Effect,
+	}
+	if req.Reason != "" {
+		params.Reason = pgtype.Text{String: req.Reason, Valid: true}
+	}
+	if req.ExpiresAt != nil {
+		params.ExpiresAt = pgtype.Timestamptz{Time: *req.ExpiresAt, Valid: true}
+	}
+	if actorUUID, err := uuid.Parse(actorID); err == nil {
+		params.CreatedBy = pgtype.UUID{Bytes: actorUUID, Valid: true}
+	}
+
+	saved, err := s.queries.UpsertAdminPermissionOverride(ctx, params)
+	if err != nil {
+		return nil, errors.Internal("failed to save permission override", err)
+	}
+
+	// Audit log
+	overrideID := uuid.UUID(saved.ID.Bytes)
+	if exists {
+		s.auditService.LogUpdate(ctx, "admin_permission_overrides", overrideID, toOverride(&old, code), toOverride(&saved, code))
+	} else {
+		s.auditService.LogCreate(ctx, "admin_permission_overrides", overrideID, toOverride(&saved, code))
+	}
+
+	return toPermissionOverrideResponse(&saved, code, time.Now()), nil
+}
+
+// RemovePermissionOverride deletes the admin's override for the permission, so
+// they hold it again only if their role grants it. actorID is the admin making
+// the change; they cannot remove their own allow of role.ManagePermission.
+func (s *Service) RemovePermissionOverride(ctx context.Context, actorID, id, code string) error {
+	admin, permission, err := s.adminPermission(ctx, id, code)
+	if err != nil {
+		return err
+	}
+
+	params := db.GetAdminPermissionOverrideParams{
+		AdminID:      admin.ID,
+		PermissionID: permission.ID,
+	}
+	existing, err := s.queries.GetAdminPermissionOverride(ctx, params)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("permission override not found")
+		}
+		return errors.Internal("failed to get permission override", err)
+	}
+
+	if existing.Effect == EffectAllow && code == role.ManagePermission && actorID == uuid.UUID(admin.ID.Bytes).String() {
+		return errors.Forbidden("cannot remove your own " + role.ManagePermission + " override")
+	}
+
+	deleted, err := s.queries.DeleteAdminPermissionOverride(ctx, db.DeleteAdminPermissionOverrideParams(params))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("permission override not found")
+		}
+		return errors.Internal("failed to delete permission override", err)
+	}
+
+	// Audit log
+	s.auditService.LogDelete(ctx, "admin_permission_overrides", uuid.UUID(deleted.ID.Bytes), toOverride(&deleted, code))
+
+	return nil
+}
+
+// activeAdmin looks up an active admin by ID
+func (s *Service) activeAdmin(ctx context.Context, id string) (*db.Admin, error) {
+	adminUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.Validation("invalid admin ID format")
+	}
+
+	admin, err := s.queries.GetAdminByID(ctx, pgtype.UUID{Bytes: adminUUID, Valid: true})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("admin not found")
+		}
+		return nil, errors.Internal("failed to get admin", err)
+	}
+
+	return &admin, nil
+}
+
+// adminPermission looks up an active admin and an active permission
+func (s *Service) adminPermission(ctx context.Context, id, code string) (*db.Admin, *db.Permission, error) {
+	admin, err := s.activeAdmin(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permission, err := s.queries.GetPermissionByCode(ctx, code)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil, errors.NotFound("permission not found")
+		}
+		return nil, nil, errors.Internal("failed to get permission", err)
+	}
+
+	return admin, &permission, nil
+}
+
+func toOverride(o *db.AdminPermissionOverride, code string) override {
+	return override{
+		ID:             o.ID,
+		AdminID:        o.AdminID,
+		PermissionID:   o.PermissionID,
+		PermissionCode: code,
+		Effect:         o.Effect,
+		Reason:         o.Reason,
+		ExpiresAt:      o.ExpiresAt,
+		CreatedBy:      o.CreatedBy,
+	}
+}
+
+func toPermissionOverrideResponse(o *db.AdminPermissionOverride, code string, now time.Time) *PermissionOverrideResponse {
+	resp := &PermissionOverrideResponse{
+		ID:             uuid.UUID(o.ID.Bytes).String(),
+		PermissionCode: code,
+		Effect:         o.Effect,
+		Reason:         o.Reason.String,
+		CreatedAt:      o.CreatedAt.Time,
+		UpdatedAt:      o.UpdatedAt.Time,
+	}
+	if o.ExpiresAt.Valid {
+		resp.ExpiresAt = &o.ExpiresAt.Time
+		resp.Expired = !now.Before(o.ExpiresAt.Time)
+	}
+	if o.CreatedBy.Valid {
+		resp.CreatedBy = uuid.UUID(o.CreatedBy.Bytes).String()
+	}
+	return resp
+}
@@ -5,24 +5,29 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/user/coc/internal/app/admin_auth"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/errors"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/user/coc/internal/lockout"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
 )
 
 // Service handles admin management operations (CRUD)
 type Service struct {
 	queries      *db.Queries
 	auditService *audit.Service
+	logins       *lockout.Guard
 }
 
-func NewService(queries *db.Queries, auditService *audit.Service) *Service {
+func NewService(queries *db.Queries, auditService *audit.Service, logins *lockout.Guard) *Service {
 	return &Service{
 		queries:      queries,
 		auditService: auditService,
+		logins:       logins,
 	}
 }
 
@@ -46,9 +51,12 @@ func (s *Service) CreateAdmin(ctx context.Context, req CreateAdminRequest) (*adm
 	if req.Role == "" {
 		req.Role = "moderator"
 	}
+	if err := s.checkRole(ctx, req.Role); err != nil {
+		return nil, err
+	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := passhash.Hash(req.Password)
 	if err != nil {
 		return nil, errors.Internal("failed to hash password", err)
 	}
@@ -57,7 +65,7 @@ func (s *Service) CreateAdmin(ctx context.Context, req CreateAdminRequest) (*adm
 	params := db.CreateAdminParams{
 		Email:        req.Email,
 		Username:     req.Username,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Role:         req.Role,
 		IsActive:     true,
 	}
@@ -162,11 +170,11 @@ func (s *Service) UpdateAdmin(ctx context.Context, id string, req UpdateAdminReq
 
 	// Update password if provided
 	if req.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hashedPassword, err := passhash.Hash(req.Password)
 		if err != nil {
 			return nil, errors.Internal("failed to hash password", err)
 		}
-		params.PasswordHash = string(hashedPassword)
+		params.PasswordHash = hashedPassword
 	}
 
 	// Update first name
@@ -181,6 +189,9 @@ func (s *Service) UpdateAdmin(ctx context.Context, id string, req UpdateAdminReq
 
 	// Update role if provided
 	if req.Role != "" {
+		if err := s.checkRole(ctx, req.Role); err != nil {
+			return nil, err
+		}
 		params.Role = req.Role
 	}
 
@@ -229,6 +240,33 @@ func (s *Service) DeleteAdmin(ctx context.Context, id string) error {
 	return nil
 }
 
+// UnlockAdmin lifts a login lockout of an admin and clears its failed login attempts
+func (s *Service) UnlockAdmin(ctx context.Context, id string) error {
+	adminUUID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.Validation("invalid admin ID format")
+	}
+
+	admin, err := s.queries.GetAdminByID(ctx, pgtype.UUID{Bytes: adminUUID, Valid: true})
+	if err != nil {
+		return errors.NotFound("admin not found")
+	}
+
+	// Admins log in with their username, which is what failed attempts are counted for
+	return s.logins.Unlock(ctx, refreshtoken.SubjectAdmin, admin.Username)
+}
+
+// checkRole ensures the role exists in the roles table
+func (s *Service) checkRole(ctx context.Context, role string) error {
+	if _, err := s.queries.GetRoleByName(ctx, role); err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.Validation("role does not exist")
+		}
+		return errors.Internal("failed to check role", err)
+	}
+	return nil
+}
+
 // Helper function to convert db.Admin to AdminResponse
 func toAdminResponse(admin *db.Admin) *admin_auth.AdminResponse {
 	adminID, _ := uuid.FromBytes(admin.ID.Bytes[:])
@@ -2,11 +2,15 @@ package admin_auth
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/ctxkeys"
 	"github.com/user/coc/internal/validation"
 )
 
@@ -63,3 +67,229 @@ func TestAuthHandler_Login_ValidRequest(t *testing.T) {
 	// If we get here, validation passed and service was called
 	t.Log("Validation passed, service call attempted")
 }
+
+// TestAuthHandler_Refresh_InvalidJSON tests Refresh with invalid JSON
+func TestAuthHandler_Refresh_InvalidJSON(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/refresh", bytes.NewBufferString("invalid json"))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.Refresh(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_Refresh_MissingToken tests Refresh without a refresh token
+func TestAuthHandler_Refresh_MissingToken(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/refresh", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.Refresh(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_Logout_NotAuthenticated tests Logout without admin claims in context
+func TestAuthHandler_Logout_NotAuthenticated(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/logout", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Logout(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_LogoutAll_NotAuthenticated tests LogoutAll without admin ID in context
+func TestAuthHandler_LogoutAll_NotAuthenticated(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/logout-all", nil)
+	rec := httptest.NewRecorder()
+
+	handler.LogoutAll(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_VerifyMFA_MissingCode tests VerifyMFA without a code or recovery code
+func TestAuthHandler_VerifyMFA_MissingCode(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/mfa/verify", bytes.NewBufferString(`{"mfa_token": "token"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.VerifyMFA(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_VerifyMFA_InvalidCode tests VerifyMFA with a malformed TOTP code
+func TestAuthHandler_VerifyMFA_InvalidCode(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/mfa/verify", bytes.NewBufferString(`{"mfa_token": "token", "code": "12ab"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.VerifyMFA(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_ActivateMFA_NotAuthenticated tests ActivateMFA without admin ID in context
+func TestAuthHandler_ActivateMFA_NotAuthenticated(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/me/mfa/activate", bytes.NewBufferString(`{"code": "123456"}`))
+	rec := httptest.NewRecorder()
+
+	handler.ActivateMFA(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_UpdateMFAPolicy_InvalidRole tests UpdateMFAPolicy with a role name too long to exist
+func TestAuthHandler_UpdateMFAPolicy_InvalidRole(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	role := strings.Repeat("r", 51)
+	req := httptest.NewRequest("PUT", "/mfa-policies/"+role, bytes.NewBufferString(`{"mfa_required": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("role", role)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	handler.UpdateMFAPolicy(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_ForgotPassword_InvalidJSON tests ForgotPassword with invalid JSON
+func TestAuthHandler_ForgotPassword_InvalidJSON(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/password/forgot", bytes.NewBufferString("invalid json"))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ForgotPassword(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_ResetPassword_MissingToken tests ResetPassword without a reset token
+func TestAuthHandler_ResetPassword_MissingToken(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/password/reset", bytes.NewBufferString(`{"password": "NewSecurePass123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ResetPassword(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_ChangePassword_NotAuthenticated tests ChangePassword without admin ID in context
+func TestAuthHandler_ChangePassword_NotAuthenticated(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("PUT", "/me/password", bytes.NewBufferString(`{"current_password": "SecurePass123", "new_password": "NewSecurePass123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ChangePassword(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_ChangePassword_WeakPassword tests ChangePassword with a password the strongpassword rule rejects
+func TestAuthHandler_ChangePassword_WeakPassword(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("PUT", "/me/password", bytes.NewBufferString(`{"current_password": "SecurePass123", "new_password": "short1A"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.AdminIDContextKey, "550e8400-e29b-41d4-a716-446655440000"))
+	rec := httptest.NewRecorder()
+
+	handler.ChangePassword(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_CreateAPIKey_NotAuthenticated tests CreateAPIKey without admin ID in context
+func TestAuthHandler_CreateAPIKey_NotAuthenticated(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/me/api-keys", bytes.NewBufferString(`{"name": "sync", "permissions": ["users.read"], "expires_at": "2099-01-01T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.CreateAPIKey(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_CreateAPIKey_MissingPermissions tests CreateAPIKey without any permission codes
+func TestAuthHandler_CreateAPIKey_MissingPermissions(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/me/api-keys", bytes.NewBufferString(`{"name": "sync", "permissions": [], "expires_at": "2099-01-01T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.AdminIDContextKey, "550e8400-e29b-41d4-a716-446655440000"))
+	rec := httptest.NewRecorder()
+
+	handler.CreateAPIKey(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_StartOIDCLogin_Disabled tests StartOIDCLogin when single sign-on is not configured
+func TestAuthHandler_StartOIDCLogin_Disabled(t *testing.T) {
+	handler := NewAuthHandler(&AuthService{}, validation.New())
+	req := httptest.NewRequest("GET", "/auth/oidc/login", nil)
+	rec := httptest.NewRecorder()
+
+	handler.StartOIDCLogin(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+// TestAuthHandler_CompleteOIDCLogin_MissingState tests CompleteOIDCLogin without the state from the redirect
+func TestAuthHandler_CompleteOIDCLogin_MissingState(t *testing.T) {
+	handler := NewAuthHandler(nil, validation.New())
+	req := httptest.NewRequest("POST", "/auth/oidc/callback", bytes.NewBufferString(`{"code": "SplxlOBeZQQYbYS6WxSbIA"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.CompleteOIDCLogin(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
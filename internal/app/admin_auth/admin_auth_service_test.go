@@ -10,12 +10,16 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/oidc"
+	"github.com/user/coc/internal/signing"
+	"github.com/user/coc/internal/totp"
 )
 
 // TestAuthService_GenerateToken tests JWT token generation
 func TestAuthService_GenerateToken(t *testing.T) {
 	service := &AuthService{
-		jwtSecret: "test-secret-key",
+		keys:          signing.NewHMACKeySet("test-secret-key"),
+		tokenDuration: 15 * time.Minute,
 	}
 
 	// Create test admin
@@ -32,7 +36,7 @@ func TestAuthService_GenerateToken(t *testing.T) {
 		IsActive: true,
 	}
 
-	token, err := service.GenerateToken(admin)
+	token, err := service.GenerateToken(admin, uuid.New())
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -42,9 +46,7 @@ func TestAuthService_GenerateToken(t *testing.T) {
 	}
 
 	// Verify token can be parsed
-	parsedToken, err := jwt.ParseWithClaims(token, &AdminClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(service.jwtSecret), nil
-	})
+	parsedToken, err := jwt.ParseWithClaims(token, &AdminClaims{}, service.keys.Keyfunc)
 
 	if err != nil {
 		t.Fatalf("failed to parse generated token: %v", err)
@@ -75,8 +77,8 @@ func TestAuthService_GenerateToken(t *testing.T) {
 		t.Errorf("expected subject 'admin', got %s", claims.Subject)
 	}
 
-	// Check expiration (should be ~24 hours from now)
-	expectedExp := time.Now().Add(24 * time.Hour)
+	// Check expiration (should be ~15 minutes from now)
+	expectedExp := time.Now().Add(15 * time.Minute)
 	if claims.ExpiresAt.Time.After(expectedExp.Add(time.Minute)) ||
 		claims.ExpiresAt.Time.Before(expectedExp.Add(-time.Minute)) {
 		t.Errorf("unexpected expiration time: %v", claims.ExpiresAt.Time)
@@ -86,7 +88,8 @@ func TestAuthService_GenerateToken(t *testing.T) {
 // TestAuthService_ValidateToken tests JWT token validation
 func TestAuthService_ValidateToken(t *testing.T) {
 	service := &AuthService{
-		jwtSecret: "test-secret-key",
+		keys:          signing.NewHMACKeySet("test-secret-key"),
+		tokenDuration: 15 * time.Minute,
 	}
 
 	// Create test admin
@@ -104,7 +107,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	}
 
 	// Generate token
-	token, err := service.GenerateToken(admin)
+	token, err := service.GenerateToken(admin, uuid.New())
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -127,7 +130,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 // TestAuthService_ValidateToken_InvalidToken tests validation of invalid tokens
 func TestAuthService_ValidateToken_InvalidToken(t *testing.T) {
 	service := &AuthService{
-		jwtSecret: "test-secret-key",
+		keys: signing.NewHMACKeySet("test-secret-key"),
 	}
 
 	tests := []struct {
@@ -152,7 +155,7 @@ func TestAuthService_ValidateToken_InvalidToken(t *testing.T) {
 // TestAuthService_ValidateToken_WrongSubject tests validation of non-admin tokens
 func TestAuthService_ValidateToken_WrongSubject(t *testing.T) {
 	service := &AuthService{
-		jwtSecret: "test-secret-key",
+		keys: signing.NewHMACKeySet("test-secret-key"),
 	}
 
 	// Create a token with wrong subject
@@ -162,12 +165,14 @@ func TestAuthService_ValidateToken_WrongSubject(t *testing.T) {
 		Username: "testuser",
 		Role:     "admin",
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject: "user", // Wrong subject
+			Issuer:    TokenIssuer,
+			Audience:  jwt.ClaimStrings{TokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Subject:   "user", // Wrong subject
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, _ := token.SignedString([]byte(service.jwtSecret))
+	tokenString, _ := service.keys.Sign(claims)
 
 	_, err := service.ValidateToken(tokenString)
 	if err == nil {
@@ -179,6 +184,31 @@ func TestAuthService_ValidateToken_WrongSubject(t *testing.T) {
 	}
 }
 
+// TestAuthService_ValidateToken_FrontendAudience tests that tokens issued for the frontend API are rejected
+func TestAuthService_ValidateToken_FrontendAudience(t *testing.T) {
+	service := &AuthService{
+		keys: signing.NewHMACKeySet("shared-secret"),
+	}
+
+	// Same secret and subject, but issued for the frontend API
+	claims := &AdminClaims{
+		AdminID: uuid.New().String(),
+		Email:   "test@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "coc-api",
+			Audience:  jwt.ClaimStrings{"coc-frontend"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Subject:   "admin",
+		},
+	}
+
+	tokenString, _ := service.keys.Sign(claims)
+
+	if _, err := service.ValidateToken(tokenString); err == nil {
+		t.Error("expected error for token with frontend audience")
+	}
+}
+
 // TestAuthService_GetAdminByID_InvalidUUID tests GetAdminByID with invalid UUID
 func TestAuthService_GetAdminByID_InvalidUUID(t *testing.T) {
 	service := &AuthService{
@@ -210,3 +240,96 @@ func TestAuthService_Login_InvalidInputs(t *testing.T) {
 	// Login calls database methods first, tested in integration tests
 	t.Skip("Login calls database methods first, tested in integration tests")
 }
+
+// TestAuthService_MFAChallenge_NotAnAccessToken tests that MFA challenges and access tokens are not interchangeable
+func TestAuthService_MFAChallenge_NotAnAccessToken(t *testing.T) {
+	service := &AuthService{
+		keys:                 signing.NewHMACKeySet("test-secret-key"),
+		tokenDuration:        15 * time.Minute,
+		mfaChallengeDuration: 5 * time.Minute,
+	}
+
+	admin := &db.Admin{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username: "testuser",
+		Role:     "super_admin",
+		IsActive: true,
+	}
+
+	challenge, err := service.issueChallenge(admin, MFAPurposeVerify)
+	if err != nil {
+		t.Fatalf("issueChallenge failed: %v", err)
+	}
+	if _, err := service.ValidateToken(challenge.Token); err == nil {
+		t.Error("expected MFA challenge to be rejected as an access token")
+	}
+
+	accessToken, err := service.GenerateToken(admin, uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if _, _, err := service.parseChallenge(context.Background(), accessToken); err == nil {
+		t.Error("expected access token to be rejected as an MFA challenge")
+	}
+}
+
+// TestGenerateRecoveryCode tests recovery code format and hashing
+func TestGenerateRecoveryCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatalf("generateRecoveryCode failed: %v", err)
+		}
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("unexpected recovery code format: %s", code)
+		}
+		for _, c := range strings.ReplaceAll(code, "-", "") {
+			if !strings.ContainsRune(recoveryAlphabet, c) {
+				t.Errorf("unexpected character %q in %s", c, code)
+			}
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %s", code)
+		}
+		seen[code] = true
+	}
+
+	box, err := totp.NewSecretBox("test-mfa-key")
+	if err != nil {
+		t.Fatalf("NewSecretBox failed: %v", err)
+	}
+	service := &AuthService{mfaBox: box}
+	if service.hashRecoveryCode("abcde-fghjk") != service.hashRecoveryCode(" ABCDE-FGHJK ") {
+		t.Error("expected recovery code hashing to ignore case and surrounding spaces")
+	}
+}
+
+// TestIsAPIKey tests that API keys are told apart from JWTs
+func TestIsAPIKey(t *testing.T) {
+	if !IsAPIKey(APIKeyPrefix + "q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc") {
+		t.Error("expected prefixed credential to be an API key")
+	}
+	if IsAPIKey("eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.sig") {
+		t.Error("expected JWT not to be an API key")
+	}
+}
+
+// TestOIDCUsername tests usernames derived for admins provisioned by single sign-on
+func TestOIDCUsername(t *testing.T) {
+	tests := []struct {
+		token *oidc.IDToken
+		want  string
+	}{
+		{&oidc.IDToken{PreferredUsername: "Jane.Doe", Email: "jane@example.com"}, "jane.doe"},
+		{&oidc.IDToken{PreferredUsername: "jane@example.com", Email: "jane@example.com"}, "jane"},
+		{&oidc.IDToken{Email: "J Smith+ops@example.com"}, "j-smith-ops"},
+		{&oidc.IDToken{Email: "js@example.com"}, "admin-js"},
+	}
+
+	for _, tt := range tests {
+		if got := oidcUsername(tt.token); got != tt.want {
+			t.Errorf("oidcUsername(%+v) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
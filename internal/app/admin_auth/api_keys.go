@@ -0,0 +1,178 @@
+package admin_auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/app/admin_menu"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+const (
+	// APIKeyPrefix starts every API key, so the auth middleware can tell keys from JWTs
+	APIKeyPrefix = "coc_ak_"
+
+	// MaxAPIKeyLifetime caps how far in the future an API key may expire
+	MaxAPIKeyLifetime = 365 * 24 * time.Hour
+
+	// apiKeyDisplayLength is how much of a key is kept in key_prefix to identify it
+	apiKeyDisplayLength = len(APIKeyPrefix) + 6
+
+	// apiKeyTouchInterval limits how often last_used_at is written for a busy key
+	apiKeyTouchInterval = time.Minute
+)
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// CreatedAPIKey is a new API key. Key is only available here; the database keeps its hash.
+type CreatedAPIKey struct {
+	Key    string
+	APIKey *db.AdminApiKey
+}
+
+// CreateAPIKey creates a named API key for the admin. The key may only carry
+// permission codes the admin holds through their role and permission overrides,
+// and must expire within MaxAPIKeyLifetime.
+func (s *AuthService) CreateAPIKey(ctx context.Context, adminID, name string, permissions []string, expiresAt time.Time) (*CreatedAPIKey, error) {
+	admin, err := s.getActiveAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil, errors.Validation("expires_at must be in the future")
+	}
+	if expiresAt.After(now.Add(MaxAPIKeyLifetime)) {
+		return nil, errors.Validation(fmt.Sprintf("expires_at must be within %d days", int(MaxAPIKeyLifetime.Hours()/24)))
+	}
+
+	granted, err := admin_menu.GetAdminPermissions(ctx, s.queries, uuid.UUID(admin.ID.Bytes), admin.Role)
+	if err != nil {
+		return nil, errors.Internal("failed to load admin permissions", err)
+	}
+
+	codes := slices.Clone(permissions)
+	slices.Sort(codes)
+	codes = slices.Compact(codes)
+	for _, code := range codes {
+		if !granted[code] {
+			return nil, errors.Validation(fmt.Sprintf("permission %q is not granted to you", code))
+		}
+	}
+
+	secret, err := refreshtoken.GenerateToken()
+	if err != nil {
+		return nil, errors.Internal("failed to generate API key", err)
+	}
+	key := APIKeyPrefix + secret
+
+	apiKey, err := s.queries.CreateAdminAPIKey(ctx, db.CreateAdminAPIKeyParams{
+		AdminID:     admin.ID,
+		Name:        strings.TrimSpace(name),
+		KeyPrefix:   key[:apiKeyDisplayLength],
+		KeyHash:     refreshtoken.HashToken(key),
+		Permissions: codes,
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return nil, errors.Internal("failed to create API key", err)
+	}
+
+	// Audit log the creation (the key hash is filtered out)
+	s.auditService.LogCreate(ctx, "admin_api_keys", uuid.UUID(apiKey.ID.Bytes), apiKey)
+
+	return &CreatedAPIKey{Key: key, APIKey: &apiKey}, nil
+}
+
+// ListAPIKeys lists the admin's API keys, including expired and revoked ones
+func (s *AuthService) ListAPIKeys(ctx context.Context, adminID string) ([]db.AdminApiKey, error) {
+	admin, err := s.getActiveAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.queries.ListAdminAPIKeys(ctx, admin.ID)
+	if err != nil {
+		return nil, errors.Internal("failed to list API keys", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes one of the admin's API keys. It stops working immediately.
+func (s *AuthService) RevokeAPIKey(ctx context.Context, adminID, keyID string) error {
+	admin, err := s.getActiveAdmin(ctx, adminID)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return errors.Validation("invalid API key ID format")
+	}
+
+	apiKey, err := s.queries.RevokeAdminAPIKey(ctx, db.RevokeAdminAPIKeyParams{
+		ID:      pgtype.UUID{Bytes: id, Valid: true},
+		AdminID: admin.ID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("API key not found or already revoked")
+		}
+		return errors.Internal("failed to revoke API key", err)
+	}
+
+	// Audit log the revocation
+	s.auditService.LogUpdate(ctx, "admin_api_keys", id, nil, apiKey)
+
+	return nil
+}
+
+// AuthenticateAPIKey validates an API key and returns it with its owner.
+// Used by the auth middleware instead of Authenticate for API key credentials.
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key string) (*db.Admin, *db.AdminApiKey, error) {
+	apiKey, err := s.queries.GetAdminAPIKeyByHash(ctx, refreshtoken.HashToken(key))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil, errors.Unauthorized("invalid API key")
+		}
+		return nil, nil, errors.Internal("failed to check API key", err)
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt.Valid {
+		return nil, nil, errors.Unauthorized("API key has been revoked")
+	}
+	if !apiKey.ExpiresAt.Time.After(now) {
+		return nil, nil, errors.Unauthorized("API key has expired")
+	}
+
+	// GetAdminByID only returns active admins, so deactivating an admin disables their keys
+	admin, err := s.queries.GetAdminByID(ctx, apiKey.AdminID)
+	if err != nil {
+		return nil, nil, errors.Unauthorized("admin not found or inactive")
+	}
+
+	err = s.queries.TouchAdminAPIKey(ctx, db.TouchAdminAPIKeyParams{
+		ID:         apiKey.ID,
+		LastUsedAt: pgtype.Timestamptz{Time: now.Add(-apiKeyTouchInterval), Valid: true},
+	})
+	if err != nil {
+		slog.Error("failed to record API key use", "api_key_id", uuid.UUID(apiKey.ID.Bytes).String(), "error", err)
+	}
+
+	return &admin, &apiKey, nil
+}
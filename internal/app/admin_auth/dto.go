@@ -1,5 +1,7 @@
 package admin_auth
 
+import "time"
+
 // LoginRequest represents admin login request
 type LoginRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50" example:"admin"`
@@ -8,8 +10,72 @@ type LoginRequest struct {
 
 // LoginResponse represents admin login response
 type LoginResponse struct {
-	Token string         `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	Admin *AdminResponse `json:"admin"`
+	Token        string         `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string         `json:"refresh_token" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+	ExpiresIn    int64          `json:"expires_in" example:"900"`
+	Admin        *AdminResponse `json:"admin"`
+	// RecoveryCodes is only returned when MFA was activated during this login; they are shown once
+	RecoveryCodes []string `json:"recovery_codes,omitempty" example:"k7m2p-x9d4f"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is required
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required" example:"true"`
+	MFAToken    string `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Purpose     string `json:"purpose" example:"verify" enums:"verify,enroll"`
+	ExpiresIn   int64  `json:"expires_in" example:"300"`
+}
+
+// MFAVerifyRequest completes a login with a TOTP code or a recovery code
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric" example:"123456"`
+	RecoveryCode string `json:"recovery_code" validate:"omitempty,max=20" example:"k7m2p-x9d4f"`
+}
+
+// MFAEnrollRequest starts enrollment during a login whose role requires MFA
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// MFAEnrollmentResponse contains the TOTP secret to add to an authenticator app
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/COC%20Admin:admin@example.com?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=COC+Admin"`
+}
+
+// MFACodeRequest confirms an MFA change with a current TOTP code
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// RecoveryCodesResponse lists newly generated one-time recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7m2p-x9d4f"`
+}
+
+// MFAPolicyResponse shows whether a role must use MFA
+type MFAPolicyResponse struct {
+	Role        string `json:"role" example:"super_admin"`
+	MFARequired bool   `json:"mfa_required" example:"true"`
+}
+
+// UpdateMFAPolicyRequest requires or stops requiring MFA for a role
+type UpdateMFAPolicyRequest struct {
+	Role        string `json:"-" validate:"required,max=50"`
+	MFARequired *bool  `json:"mfa_required" validate:"required" example:"true"`
+}
+
+// RefreshRequest represents admin token refresh request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+}
+
+// TokenResponse represents a refreshed admin token pair
+type TokenResponse struct {
+	Token        string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
 }
 
 // AdminResponse represents admin data in response
@@ -22,3 +88,56 @@ type AdminResponse struct {
 	Role      string `json:"role" example:"super_admin"`
 	IsActive  bool   `json:"is_active" example:"true"`
 }
+
+// ForgotPasswordRequest represents the admin forgot password request payload
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"admin@example.com"`
+}
+
+// ResetPasswordRequest represents the admin password reset request payload
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+	Password string `json:"password" validate:"required,min=6" example:"NewSecurePass123"`
+}
+
+// ChangePasswordRequest represents the admin password change request payload
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"SecurePass123"`
+	NewPassword     string `json:"new_password" validate:"required,strongpassword" example:"NewSecurePass123"`
+}
+
+// OIDCLoginResponse contains the identity provider URL that starts a single sign-on login
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://idp.example.com/authorize?client_id=coc-admin&response_type=code&state=..."`
+}
+
+// OIDCCallbackRequest carries the query parameters the identity provider redirected back with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required" example:"SplxlOBeZQQYbYS6WxSbIA"`
+	State string `json:"state" validate:"required" example:"af0ifjsldkj"`
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name        string    `json:"name" validate:"required,max=100" example:"nightly-user-sync"`
+	Permissions []string  `json:"permissions" validate:"required,min=1,dive,required" example:"users.read,addresses.read"`
+	ExpiresAt   time.Time `json:"expires_at" validate:"required" example:"2025-12-31T00:00:00Z"`
+}
+
+// APIKeyResponse describes an API key without the key itself
+type APIKeyResponse struct {
+	ID          string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string     `json:"name" example:"nightly-user-sync"`
+	KeyPrefix   string     `json:"key_prefix" example:"coc_ak_q0n8Yc"`
+	Permissions []string   `json:"permissions" example:"users.read,addresses.read"`
+	ExpiresAt   time.Time  `json:"expires_at" example:"2025-12-31T00:00:00Z"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" example:"2025-06-01T02:00:00Z"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" example:"2025-06-02T09:00:00Z"`
+	CreatedAt   time.Time  `json:"created_at" example:"2025-01-01T12:00:00Z"`
+}
+
+// CreatedAPIKeyResponse is returned once when a key is created; the key cannot be shown again
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"coc_ak_q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+}
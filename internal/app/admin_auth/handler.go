@@ -3,8 +3,12 @@ package admin_auth
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/response"
 	"github.com/user/coc/internal/validation"
 )
@@ -23,14 +27,16 @@ func NewAuthHandler(authService *AuthService, validator *validation.Validator) *
 
 // Login handles POST /api/admin/v1/auth/login
 // @Summary      Admin login
-// @Description  Authenticate admin user and receive JWT token
+// @Description  Authenticate admin user and receive JWT token. If the admin has MFA enabled, or their role requires it, an MFA challenge is returned instead and the login is completed with /auth/mfa/verify.
 // @Tags         Admin Authentication
 // @Accept       json
 // @Produce      json
 // @Param        request body LoginRequest true "Login credentials"
 // @Success      200 {object} response.JSONResponse{data=LoginResponse} "Login successful"
+// @Success      202 {object} response.JSONResponse{data=MFAChallengeResponse} "MFA verification required"
 // @Failure      400 {object} response.JSONResponse "Invalid request or credentials"
 // @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      429 {object} response.JSONResponse "Too many failed login attempts"
 // @Router       /api/admin/v1/auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
@@ -45,12 +51,593 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, admin, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	result, err := h.authService.Login(r.Context(), req.Username, req.Password)
 	if err != nil {
 		response.HandleServiceError(w, err)
 		return
 	}
 
+	if result.MFA != nil {
+		response.JSON(w, http.StatusAccepted, "MFA verification required", &MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    result.MFA.Token,
+			Purpose:     result.MFA.Purpose,
+			ExpiresIn:   result.MFA.ExpiresIn,
+		})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "admin login successful", toLoginResponse(result))
+}
+
+// StartOIDCLogin handles GET /api/admin/v1/auth/oidc/login
+// @Summary      Start single sign-on
+// @Description  Start an OpenID Connect login with the company identity provider. Redirect the browser to the returned URL; the provider sends it back to the admin panel with a code and state for /auth/oidc/callback.
+// @Tags         Admin Authentication
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=OIDCLoginResponse} "Authorization URL"
+// @Failure      404 {object} response.JSONResponse "Single sign-on is not enabled"
+// @Failure      500 {object} response.JSONResponse "Identity provider unavailable"
+// @Router       /api/admin/v1/auth/oidc/login [get]
+func (h *AuthHandler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.authService.StartOIDCLogin(r.Context())
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "redirect to the identity provider", &OIDCLoginResponse{AuthorizationURL: authURL})
+}
+
+// CompleteOIDCLogin handles POST /api/admin/v1/auth/oidc/callback
+// @Summary      Complete single sign-on
+// @Description  Exchange the code and state from the identity provider's redirect for admin tokens. The admin is created on first login and gets the role mapped from the provider's claims. As with the password login, an MFA challenge may be returned instead.
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body OIDCCallbackRequest true "Code and state from the provider redirect"
+// @Success      200 {object} response.JSONResponse{data=LoginResponse} "Login successful"
+// @Success      202 {object} response.JSONResponse{data=MFAChallengeResponse} "MFA verification required"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Invalid or expired login"
+// @Failure      403 {object} response.JSONResponse "No admin role for this account"
+// @Router       /api/admin/v1/auth/oidc/callback [post]
+func (h *AuthHandler) CompleteOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	var req OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	result, err := h.authService.CompleteOIDCLogin(r.Context(), req.Code, req.State)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	if result.MFA != nil {
+		response.JSON(w, http.StatusAccepted, "MFA verification required", &MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    result.MFA.Token,
+			Purpose:     result.MFA.Purpose,
+			ExpiresIn:   result.MFA.ExpiresIn,
+		})
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "admin login successful", toLoginResponse(result))
+}
+
+// VerifyMFA handles POST /api/admin/v1/auth/mfa/verify
+// @Summary      Complete admin login with MFA
+// @Description  Exchange the MFA challenge from login and a TOTP code (or a one-time recovery code) for admin tokens. For enroll challenges the code activates MFA and recovery codes are returned once.
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body MFAVerifyRequest true "MFA challenge and code"
+// @Success      200 {object} response.JSONResponse{data=LoginResponse} "Login successful"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Invalid code or expired challenge"
+// @Router       /api/admin/v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	result, err := h.authService.VerifyMFA(r.Context(), req.MFAToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "admin login successful", toLoginResponse(result))
+}
+
+// EnrollMFAWithChallenge handles POST /api/admin/v1/auth/mfa/enroll
+// @Summary      Enroll MFA during login
+// @Description  For admins whose role requires MFA but who have not enrolled yet: returns a TOTP secret and provisioning URI for the enroll challenge from login. Confirm with /auth/mfa/verify.
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body MFAEnrollRequest true "MFA challenge"
+// @Success      200 {object} response.JSONResponse{data=MFAEnrollmentResponse} "Enrollment started"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Invalid or expired challenge"
+// @Router       /api/admin/v1/auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFAWithChallenge(w http.ResponseWriter, r *http.Request) {
+	var req MFAEnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	enrollment, err := h.authService.EnrollWithChallenge(r.Context(), req.MFAToken)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "MFA enrollment started", &MFAEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// StartMFAEnrollment handles POST /api/admin/v1/me/mfa/enroll
+// @Summary      Start MFA enrollment
+// @Description  Generate a TOTP secret and provisioning URI for the current admin. MFA is enforced once activated with a valid code.
+// @Tags         Admin MFA
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=MFAEnrollmentResponse} "Enrollment started"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      409 {object} response.JSONResponse "MFA already enabled"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/mfa/enroll [post]
+func (h *AuthHandler) StartMFAEnrollment(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	enrollment, err := h.authService.StartMFAEnrollment(r.Context(), adminID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "MFA enrollment started", &MFAEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+// ActivateMFA handles POST /api/admin/v1/me/mfa/activate
+// @Summary      Activate MFA
+// @Description  Confirm a pending enrollment with a code from the authenticator app. Returns one-time recovery codes, shown only once.
+// @Tags         Admin MFA
+// @Accept       json
+// @Produce      json
+// @Param        request body MFACodeRequest true "TOTP code"
+// @Success      200 {object} response.JSONResponse{data=RecoveryCodesResponse} "MFA enabled"
+// @Failure      400 {object} response.JSONResponse "Invalid code"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/mfa/activate [post]
+func (h *AuthHandler) ActivateMFA(w http.ResponseWriter, r *http.Request) {
+	adminID, req, ok := h.mfaCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.authService.ActivateMFA(r.Context(), adminID, req.Code)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "MFA enabled", &RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA handles POST /api/admin/v1/me/mfa/disable
+// @Summary      Disable MFA
+// @Description  Remove the current admin's second factor. Not allowed when the admin's role requires MFA.
+// @Tags         Admin MFA
+// @Accept       json
+// @Produce      json
+// @Param        request body MFACodeRequest true "TOTP code"
+// @Success      200 {object} response.JSONResponse "MFA disabled"
+// @Failure      400 {object} response.JSONResponse "Invalid code or MFA required by role"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	adminID, req, ok := h.mfaCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.DisableMFA(r.Context(), adminID, req.Code); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "MFA disabled", nil)
+}
+
+// RegenerateRecoveryCodes handles POST /api/admin/v1/me/mfa/recovery-codes
+// @Summary      Regenerate MFA recovery codes
+// @Description  Replace all recovery codes of the current admin. Previous codes stop working.
+// @Tags         Admin MFA
+// @Accept       json
+// @Produce      json
+// @Param        request body MFACodeRequest true "TOTP code"
+// @Success      200 {object} response.JSONResponse{data=RecoveryCodesResponse} "Recovery codes regenerated"
+// @Failure      400 {object} response.JSONResponse "Invalid code or MFA not enabled"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	adminID, req, ok := h.mfaCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(r.Context(), adminID, req.Code)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "recovery codes regenerated", &RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ListMFAPolicies handles GET /api/admin/v1/mfa-policies
+// @Summary      List MFA policies
+// @Description  Show which admin roles must use MFA (super admin only)
+// @Tags         Admin MFA
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=[]MFAPolicyResponse} "MFA policies"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/mfa-policies [get]
+func (h *AuthHandler) ListMFAPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.authService.ListMFAPolicies(r.Context())
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	resp := make([]MFAPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		resp = append(resp, MFAPolicyResponse{Role: policy.Role, MFARequired: policy.MfaRequired})
+	}
+
+	response.JSON(w, http.StatusOK, "MFA policies retrieved", resp)
+}
+
+// UpdateMFAPolicy handles PUT /api/admin/v1/mfa-policies/{role}
+// @Summary      Update MFA policy
+// @Description  Require (or stop requiring) MFA for every admin with the role (super admin only). Admins without MFA must enroll on their next login.
+// @Tags         Admin MFA
+// @Accept       json
+// @Produce      json
+// @Param        role path string true "Admin role"
+// @Param        request body UpdateMFAPolicyRequest true "MFA policy"
+// @Success      200 {object} response.JSONResponse{data=MFAPolicyResponse} "MFA policy updated"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Role not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/mfa-policies/{role} [put]
+func (h *AuthHandler) UpdateMFAPolicy(w http.ResponseWriter, r *http.Request) {
+	var req UpdateMFAPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Role = chi.URLParam(r, "role")
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	policy, err := h.authService.SetMFAPolicy(r.Context(), req.Role, *req.MFARequired)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "MFA policy updated", &MFAPolicyResponse{
+		Role:        policy.Role,
+		MFARequired: policy.MfaRequired,
+	})
+}
+
+// ForgotPassword handles POST /api/admin/v1/auth/password/forgot
+// @Summary      Admin forgot password
+// @Description  Email a single-use password reset link to an active admin. The response is the same whether or not the email belongs to an admin.
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ForgotPasswordRequest true "Admin email"
+// @Success      200 {object} response.JSONResponse "Reset link sent if the account exists"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Router       /api/admin/v1/auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	if err := h.authService.ForgotPassword(r.Context(), strings.TrimSpace(req.Email)); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "if an admin account exists for this email, a password reset link has been sent", nil)
+}
+
+// ResetPassword handles POST /api/admin/v1/auth/password/reset
+// @Summary      Admin reset password
+// @Description  Set a new password with the token from the reset email. Signs the admin out of every session.
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ResetPasswordRequest true "Reset token and new password"
+// @Success      200 {object} response.JSONResponse "Password reset"
+// @Failure      400 {object} response.JSONResponse "Invalid request or invalid, expired or used token"
+// @Router       /api/admin/v1/auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), strings.TrimSpace(req.Token), req.Password); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "password has been reset", nil)
+}
+
+// ChangePassword handles PUT /api/admin/v1/me/password
+// @Summary      Change admin password
+// @Description  Change the current admin's password. Requires the current password; recently used passwords are refused. Signs out every other session and returns new tokens for this one.
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ChangePasswordRequest true "Current and new password"
+// @Success      200 {object} response.JSONResponse{data=TokenResponse} "Password changed"
+// @Failure      400 {object} response.JSONResponse "Invalid request, wrong current password or recently used password"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      429 {object} response.JSONResponse "Too many failed attempts"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/password [put]
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	tokens, err := h.authService.ChangePassword(r.Context(), adminID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "admin password changed", &TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+// CreateAPIKey handles POST /api/admin/v1/me/api-keys
+// @Summary      Create API key
+// @Description  Create a named API key for scripts and batch jobs. It carries a subset of the current admin's permissions and must expire within a year. The key is only shown in this response.
+// @Tags         Admin API Keys
+// @Accept       json
+// @Produce      json
+// @Param        request body CreateAPIKeyRequest true "API key name, permissions and expiry"
+// @Success      201 {object} response.JSONResponse{data=CreatedAPIKeyResponse} "API key created"
+// @Failure      400 {object} response.JSONResponse "Invalid request or permission not granted to the role"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Called with an API key"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/api-keys [post]
+func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	created, err := h.authService.CreateAPIKey(r.Context(), adminID, req.Name, req.Permissions, req.ExpiresAt)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, "API key created", &CreatedAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(created.APIKey),
+		Key:            created.Key,
+	})
+}
+
+// ListAPIKeys handles GET /api/admin/v1/me/api-keys
+// @Summary      List API keys
+// @Description  List the current admin's API keys, including expired and revoked ones
+// @Tags         Admin API Keys
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=[]APIKeyResponse} "API keys"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Called with an API key"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/api-keys [get]
+func (h *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	keys, err := h.authService.ListAPIKeys(r.Context(), adminID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	resp := make([]APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		resp = append(resp, toAPIKeyResponse(&keys[i]))
+	}
+
+	response.JSON(w, http.StatusOK, "API keys retrieved", resp)
+}
+
+// RevokeAPIKey handles DELETE /api/admin/v1/me/api-keys/{id}
+// @Summary      Revoke API key
+// @Description  Revoke one of the current admin's API keys. It stops working immediately.
+// @Tags         Admin API Keys
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "API key ID"
+// @Success      200 {object} response.JSONResponse "API key revoked"
+// @Failure      400 {object} response.JSONResponse "Invalid ID"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Called with an API key"
+// @Failure      404 {object} response.JSONResponse "API key not found or already revoked"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/me/api-keys/{id} [delete]
+func (h *AuthHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	if err := h.authService.RevokeAPIKey(r.Context(), adminID, chi.URLParam(r, "id")); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "API key revoked", nil)
+}
+
+// toAPIKeyResponse converts a db.AdminApiKey to APIKeyResponse (without the key hash)
+func toAPIKeyResponse(apiKey *db.AdminApiKey) APIKeyResponse {
+	resp := APIKeyResponse{
+		ID:          uuid.UUID(apiKey.ID.Bytes).String(),
+		Name:        apiKey.Name,
+		KeyPrefix:   apiKey.KeyPrefix,
+		Permissions: apiKey.Permissions,
+		ExpiresAt:   apiKey.ExpiresAt.Time,
+		CreatedAt:   apiKey.CreatedAt.Time,
+	}
+	if apiKey.LastUsedAt.Valid {
+		resp.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+	if apiKey.RevokedAt.Valid {
+		resp.RevokedAt = &apiKey.RevokedAt.Time
+	}
+	return resp
+}
+
+// mfaCodeRequest reads the authenticated admin ID and a TOTP code, writing an error response on failure
+func (h *AuthHandler) mfaCodeRequest(w http.ResponseWriter, r *http.Request) (string, *MFACodeRequest, bool) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return "", nil, false
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return "", nil, false
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return "", nil, false
+	}
+
+	return adminID, &req, true
+}
+
+// toLoginResponse converts a completed login into the response body
+func toLoginResponse(result *LoginResult) *LoginResponse {
+	admin := result.Admin
+
 	// Convert UUID to string
 	adminID, _ := uuid.FromBytes(admin.ID.Bytes[:])
 
@@ -69,10 +656,98 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		adminResp.LastName = admin.LastName.String
 	}
 
-	resp := &LoginResponse{
-		Token: token,
-		Admin: adminResp,
+	return &LoginResponse{
+		Token:         result.Tokens.AccessToken,
+		RefreshToken:  result.Tokens.RefreshToken,
+		ExpiresIn:     result.Tokens.ExpiresIn,
+		Admin:         adminResp,
+		RecoveryCodes: result.RecoveryCodes,
+	}
+}
+
+// Refresh handles POST /api/admin/v1/auth/refresh
+// @Summary      Refresh admin tokens
+// @Description  Exchange an admin refresh token for a new access token and a rotated refresh token. Reusing a rotated refresh token revokes the whole session.
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body RefreshRequest true "Refresh token"
+// @Success      200 {object} response.JSONResponse{data=TokenResponse} "Token refreshed"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Invalid, expired or revoked refresh token"
+// @Router       /api/admin/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	tokens, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "admin token refreshed", &TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+// Logout handles POST /api/admin/v1/auth/logout
+// @Summary      Admin logout
+// @Description  Revoke the current admin access token and its refresh token
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse "Logged out"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(ctxkeys.AdminClaimsContextKey).(*AdminClaims)
+	if !ok || claims == nil {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), claims); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "admin logged out", nil)
+}
+
+// LogoutAll handles POST /api/admin/v1/auth/logout-all
+// @Summary      Admin logout everywhere
+// @Description  Revoke every access and refresh token of the current admin on all devices
+// @Tags         Admin Authentication
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse "Logged out from all sessions"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	if err := h.authService.LogoutAll(r.Context(), adminID); err != nil {
+		response.HandleServiceError(w, err)
+		return
 	}
 
-	response.JSON(w, http.StatusOK, "admin login successful", resp)
+	response.JSON(w, http.StatusOK, "admin logged out from all sessions", nil)
 }
@@ -2,12 +2,27 @@ package admin_auth
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/lockout"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/oidc"
+	"github.com/user/coc/internal/oidc/oidctest"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/passhistory"
+	"github.com/user/coc/internal/refreshtoken"
+	"github.com/user/coc/internal/revocation"
+	"github.com/user/coc/internal/signing"
+	"github.com/user/coc/internal/totp"
 )
 
 func setupTestDB(t *testing.T) (*pgxpool.Pool, *db.Queries) {
@@ -53,6 +68,16 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, *db.Queries) {
 	return pool, queries
 }
 
+func testMFABox(t *testing.T) *totp.SecretBox {
+	t.Helper()
+
+	box, err := totp.NewSecretBox("test-mfa-key")
+	if err != nil {
+		t.Fatalf("failed to create MFA secret box: %v", err)
+	}
+	return box
+}
+
 func TestIntegration_AuthService_Login_Success(t *testing.T) {
 	pool, queries := setupTestDB(t)
 
@@ -67,7 +92,7 @@ func TestIntegration_AuthService_Login_Success(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewAuthService(qtx, "test-jwt-secret")
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
 
 	// Create a test admin
 	admin, err := service.CreateAdmin(ctx, "logintest@example.com", "logintest", "password123", "Login", "Test", "admin")
@@ -82,15 +107,23 @@ func TestIntegration_AuthService_Login_Success(t *testing.T) {
 	adminIDStr := adminUUID.String()
 
 	// Test login with correct credentials
-	token, returnedAdmin, err := service.Login(ctx, "logintest", "password123")
+	result, err := service.Login(ctx, "logintest", "password123")
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
+	if result.MFA != nil {
+		t.Fatal("expected no MFA challenge for an admin without MFA")
+	}
+	tokens, returnedAdmin := result.Tokens, result.Admin
 
-	if token == "" {
+	if tokens.AccessToken == "" {
 		t.Error("expected non-empty token")
 	}
 
+	if tokens.RefreshToken == "" {
+		t.Error("expected non-empty refresh token")
+	}
+
 	returnedAdminUUID, err := uuid.FromBytes(returnedAdmin.ID.Bytes[:])
 	if err != nil {
 		t.Fatalf("failed to convert returned admin ID to UUID: %v", err)
@@ -106,7 +139,7 @@ func TestIntegration_AuthService_Login_Success(t *testing.T) {
 	}
 
 	// Verify token is valid
-	claims, err := service.ValidateToken(token)
+	claims, err := service.ValidateToken(tokens.AccessToken)
 	if err != nil {
 		t.Fatalf("token validation failed: %v", err)
 	}
@@ -130,7 +163,7 @@ func TestIntegration_AuthService_Login_InvalidCredentials(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewAuthService(qtx, "test-jwt-secret")
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
 
 	// Create a test admin
 	_, err = service.CreateAdmin(ctx, "invalidtest@example.com", "invalidtest", "password123", "Invalid", "Test", "admin")
@@ -139,13 +172,13 @@ func TestIntegration_AuthService_Login_InvalidCredentials(t *testing.T) {
 	}
 
 	// Test login with wrong password
-	_, _, err = service.Login(ctx, "invalidtest", "wrongpassword")
+	_, err = service.Login(ctx, "invalidtest", "wrongpassword")
 	if err == nil {
 		t.Error("expected error for wrong password")
 	}
 
 	// Test login with non-existent username
-	_, _, err = service.Login(ctx, "nonexistent", "password123")
+	_, err = service.Login(ctx, "nonexistent", "password123")
 	if err == nil {
 		t.Error("expected error for non-existent username")
 	}
@@ -165,7 +198,7 @@ func TestIntegration_AuthService_Login_InactiveAdmin(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewAuthService(qtx, "test-jwt-secret")
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
 
 	// Create a test admin
 	admin, err := service.CreateAdmin(ctx, "inactivetest@example.com", "inactivetest", "password123", "Inactive", "Test", "admin")
@@ -194,7 +227,7 @@ func TestIntegration_AuthService_Login_InactiveAdmin(t *testing.T) {
 	}
 
 	// Test login with inactive admin
-	_, _, err = service.Login(ctx, "inactivetest", "password123")
+	_, err = service.Login(ctx, "inactivetest", "password123")
 	if err == nil {
 		t.Error("expected error for inactive admin")
 	}
@@ -218,7 +251,7 @@ func TestIntegration_AuthService_CreateAdmin_DuplicateEmail(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewAuthService(qtx, "test-jwt-secret")
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
 
 	// Create first admin
 	_, err = service.CreateAdmin(ctx, "duplicate@example.com", "admin1", "password123", "First", "Admin", "admin")
@@ -251,7 +284,7 @@ func TestIntegration_AuthService_CreateAdmin_DuplicateUsername(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewAuthService(qtx, "test-jwt-secret")
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
 
 	// Create first admin
 	_, err = service.CreateAdmin(ctx, "admin1@example.com", "duplicateuser", "password123", "First", "Admin", "admin")
@@ -284,7 +317,7 @@ func TestIntegration_AuthService_GetAdminByID(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewAuthService(qtx, "test-jwt-secret")
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
 
 	// Create a test admin
 	admin, err := service.CreateAdmin(ctx, "gettest@example.com", "gettest", "password123", "Get", "Test", "moderator")
@@ -319,3 +352,327 @@ func TestIntegration_AuthService_GetAdminByID(t *testing.T) {
 		t.Errorf("expected role %s, got %s", admin.Role, result.Role)
 	}
 }
+
+func TestIntegration_AuthService_MFA(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
+
+	admin, err := service.CreateAdmin(ctx, "mfatest@example.com", "mfatest", "password123", "MFA", "Test", "admin")
+	if err != nil {
+		t.Fatalf("failed to create test admin: %v", err)
+	}
+	adminID := uuid.UUID(admin.ID.Bytes).String()
+
+	// Enroll and activate
+	enrollment, err := service.StartMFAEnrollment(ctx, adminID)
+	if err != nil {
+		t.Fatalf("StartMFAEnrollment failed: %v", err)
+	}
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	recoveryCodes, err := service.ActivateMFA(ctx, adminID, code)
+	if err != nil {
+		t.Fatalf("ActivateMFA failed: %v", err)
+	}
+	if len(recoveryCodes) == 0 {
+		t.Fatal("expected recovery codes after activation")
+	}
+
+	// Password alone now yields a challenge
+	result, err := service.Login(ctx, "mfatest", "password123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if result.MFA == nil || result.Tokens != nil {
+		t.Fatal("expected an MFA challenge instead of tokens")
+	}
+	if result.MFA.Purpose != MFAPurposeVerify {
+		t.Errorf("expected purpose %s, got %s", MFAPurposeVerify, result.MFA.Purpose)
+	}
+
+	// The code used for activation cannot be replayed
+	if _, err := service.VerifyMFA(ctx, result.MFA.Token, code, ""); err == nil {
+		t.Error("expected replayed TOTP code to be rejected")
+	}
+
+	// A recovery code completes the login once
+	verified, err := service.VerifyMFA(ctx, result.MFA.Token, "", recoveryCodes[0])
+	if err != nil {
+		t.Fatalf("VerifyMFA with recovery code failed: %v", err)
+	}
+	if verified.Tokens == nil || verified.Tokens.AccessToken == "" {
+		t.Error("expected tokens after MFA verification")
+	}
+
+	// The challenge is single-use
+	if _, err := service.VerifyMFA(ctx, result.MFA.Token, "", recoveryCodes[1]); err == nil {
+		t.Error("expected used challenge to be rejected")
+	}
+
+	result, err = service.Login(ctx, "mfatest", "password123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := service.VerifyMFA(ctx, result.MFA.Token, "", recoveryCodes[0]); err == nil {
+		t.Error("expected used recovery code to be rejected")
+	}
+
+	// Policies can only be set for roles in the roles table
+	if _, err := service.SetMFAPolicy(ctx, "owner", true); err == nil {
+		t.Error("expected MFA policy for unknown role to be rejected")
+	}
+}
+
+func TestIntegration_AuthService_MFA_Lockout(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+	policy := lockout.Policy{MaxAccountAttempts: MaxMFAFailedAttempts + 2, Window: time.Hour, LockoutDuration: time.Hour}
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), policy), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
+
+	admin, err := service.CreateAdmin(ctx, "mfalockout@example.com", "mfalockout", "password123", "MFA", "Lockout", "admin")
+	if err != nil {
+		t.Fatalf("failed to create test admin: %v", err)
+	}
+	adminID := uuid.UUID(admin.ID.Bytes).String()
+
+	enrollment, err := service.StartMFAEnrollment(ctx, adminID)
+	if err != nil {
+		t.Fatalf("StartMFAEnrollment failed: %v", err)
+	}
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	recoveryCodes, err := service.ActivateMFA(ctx, adminID, code)
+	if err != nil {
+		t.Fatalf("ActivateMFA failed: %v", err)
+	}
+
+	// Use up one challenge
+	result, err := service.Login(ctx, "mfalockout", "password123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	for i := 0; i < MaxMFAFailedAttempts; i++ {
+		if _, err := service.VerifyMFA(ctx, result.MFA.Token, "", "wrong-code"); err == nil {
+			t.Fatal("expected wrong recovery code to be rejected")
+		}
+	}
+
+	// The password step does not reset the count, so the next challenge locks the account
+	result, err = service.Login(ctx, "mfalockout", "password123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := service.VerifyMFA(ctx, result.MFA.Token, "", "wrong-code"); err == nil {
+			t.Fatal("expected wrong recovery code to be rejected")
+		}
+	}
+
+	_, err = service.VerifyMFA(ctx, result.MFA.Token, "", recoveryCodes[0])
+	if appErr, ok := err.(*errors.DomainError); !ok || appErr.Code != errors.CodeRateLimited {
+		t.Errorf("expected a locked account to be rate limited, got %v", err)
+	}
+	_, err = service.Login(ctx, "mfalockout", "password123")
+	if appErr, ok := err.(*errors.DomainError); !ok || appErr.Code != errors.CodeRateLimited {
+		t.Errorf("expected login to a locked account to be rate limited, got %v", err)
+	}
+}
+
+func TestIntegration_AuthService_APIKeys(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
+
+	admin, err := service.CreateAdmin(ctx, "apikeytest@example.com", "apikeytest", "password123", "API", "Key", "admin")
+	if err != nil {
+		t.Fatalf("failed to create test admin: %v", err)
+	}
+	adminID := uuid.UUID(admin.ID.Bytes).String()
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+
+	// A key cannot carry permissions the role does not grant
+	if _, err := service.CreateAPIKey(ctx, adminID, "too-broad", []string{"admins.delete"}, expiresAt); err == nil {
+		t.Error("expected permission outside the role to be rejected")
+	}
+
+	// Nor can it live longer than the maximum lifetime
+	if _, err := service.CreateAPIKey(ctx, adminID, "too-long", []string{"users.read"}, time.Now().Add(MaxAPIKeyLifetime+time.Hour)); err == nil {
+		t.Error("expected expiry beyond the maximum lifetime to be rejected")
+	}
+
+	created, err := service.CreateAPIKey(ctx, adminID, "sync", []string{"users.read", "users.read"}, expiresAt)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if !IsAPIKey(created.Key) {
+		t.Errorf("expected key to start with %s, got %s", APIKeyPrefix, created.Key)
+	}
+	if len(created.APIKey.Permissions) != 1 {
+		t.Errorf("expected duplicate permissions to be collapsed, got %v", created.APIKey.Permissions)
+	}
+
+	owner, apiKey, err := service.AuthenticateAPIKey(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIKey failed: %v", err)
+	}
+	if owner.ID != admin.ID || apiKey.ID != created.APIKey.ID {
+		t.Error("expected key to authenticate as its owner")
+	}
+
+	if _, _, err := service.AuthenticateAPIKey(ctx, APIKeyPrefix+"not-a-key"); err == nil {
+		t.Error("expected unknown key to be rejected")
+	}
+
+	keyID := uuid.UUID(created.APIKey.ID.Bytes).String()
+	if err := service.RevokeAPIKey(ctx, adminID, keyID); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	if _, _, err := service.AuthenticateAPIKey(ctx, created.Key); err == nil {
+		t.Error("expected revoked key to be rejected")
+	}
+	if err := service.RevokeAPIKey(ctx, adminID, keyID); err == nil {
+		t.Error("expected revoking twice to fail")
+	}
+
+	keys, err := service.ListAPIKeys(ctx, adminID)
+	if err != nil {
+		t.Fatalf("ListAPIKeys failed: %v", err)
+	}
+	if len(keys) != 1 || !keys[0].RevokedAt.Valid {
+		t.Errorf("expected the revoked key to be listed, got %d keys", len(keys))
+	}
+}
+
+func TestIntegration_AuthService_OIDCLogin(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+	service := NewAuthService(tx, audit.NewService(qtx), refreshtoken.NewStore(tx), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, audit.NewService(qtx), lockout.Policy{}), passhistory.NewStore(qtx, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-jwt-secret"), testMFABox(t), "http://localhost:3001", 15*time.Minute, 24*time.Hour, 5*time.Minute, time.Hour)
+
+	// Local mock identity provider
+	mock, server, err := oidctest.Start("coc-admin", "test-secret")
+	if err != nil {
+		t.Fatalf("failed to start mock provider: %v", err)
+	}
+	defer server.Close()
+
+	roles, err := oidc.ParseRoleMapping("groups", "coc-admins=admin,coc-support=moderator", "", []string{"super_admin", "admin", "moderator"})
+	if err != nil {
+		t.Fatalf("ParseRoleMapping failed: %v", err)
+	}
+	service.EnableOIDC(oidc.NewProvider(oidc.Config{
+		IssuerURL:    mock.Issuer(),
+		ClientID:     "coc-admin",
+		ClientSecret: "test-secret",
+		RedirectURL:  "http://localhost:3001/auth/oidc/callback",
+	}, server.Client()), roles, 10*time.Minute)
+
+	// login runs the browser part of the flow and completes it
+	login := func(groups ...string) (*LoginResult, string, error) {
+		mock.SetUser(oidctest.Claims{
+			"sub":            "idp-user-42",
+			"email":          "sso.jane@example.com",
+			"email_verified": true,
+			"given_name":     "Jane",
+			"groups":         groups,
+		})
+
+		authURL, err := service.StartOIDCLogin(ctx)
+		if err != nil {
+			t.Fatalf("StartOIDCLogin failed: %v", err)
+		}
+
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		resp, err := client.Get(authURL)
+		if err != nil {
+			t.Fatalf("authorization request failed: %v", err)
+		}
+		resp.Body.Close()
+		callback, err := url.Parse(resp.Header.Get("Location"))
+		if err != nil {
+			t.Fatalf("invalid callback URL: %v", err)
+		}
+
+		state := callback.Query().Get("state")
+		result, err := service.CompleteOIDCLogin(ctx, callback.Query().Get("code"), state)
+		return result, state, err
+	}
+
+	// First login provisions the admin with the mapped role
+	result, state, err := login("staff", "coc-admins")
+	if err != nil {
+		t.Fatalf("CompleteOIDCLogin failed: %v", err)
+	}
+	if result.Tokens == nil || result.Tokens.AccessToken == "" {
+		t.Fatal("expected tokens after single sign-on")
+	}
+	if result.Admin.Role != "admin" || result.Admin.Email != "sso.jane@example.com" || result.Admin.Username != "sso.jane" {
+		t.Errorf("unexpected provisioned admin: %+v", result.Admin)
+	}
+	adminID := result.Admin.ID
+
+	// The state is single-use
+	if _, err := service.CompleteOIDCLogin(ctx, "any-code", state); err == nil {
+		t.Error("expected used state to be rejected")
+	}
+
+	// Later logins find the same admin and follow role changes at the provider
+	result, _, err = login("coc-support")
+	if err != nil {
+		t.Fatalf("second CompleteOIDCLogin failed: %v", err)
+	}
+	if result.Admin.ID != adminID {
+		t.Error("expected the linked admin to be reused")
+	}
+	if result.Admin.Role != "moderator" {
+		t.Errorf("expected role moderator, got %s", result.Admin.Role)
+	}
+
+	// Accounts without a mapped group are refused
+	_, _, err = login("staff")
+	if domainErr, ok := err.(*errors.DomainError); !ok || domainErr.Code != errors.CodeForbidden {
+		t.Errorf("expected forbidden error, got %v", err)
+	}
+}
@@ -0,0 +1,328 @@
+package admin_auth
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/oidc"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// usernameCleaner replaces characters that do not belong in a generated username
+var usernameCleaner = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// oidcLogin is the single sign-on setup; nil when SSO is disabled
+type oidcLogin struct {
+	provider      *oidc.Provider
+	roles         oidc.RoleMapping
+	stateDuration time.Duration
+}
+
+// EnableOIDC turns on single sign-on with an OpenID Connect provider next to the
+// password login. stateDuration is how long a started login may take to complete.
+func (s *AuthService) EnableOIDC(provider *oidc.Provider, roles oidc.RoleMapping, stateDuration time.Duration) {
+	s.oidc = &oidcLogin{
+		provider:      provider,
+		roles:         roles,
+		stateDuration: stateDuration,
+	}
+}
+
+// StartOIDCCleanup deletes abandoned single sign-on logins immediately and then on
+// every interval until ctx is cancelled. It runs whether or not SSO is enabled, so
+// logins left behind when it is switched off are removed too.
+func (s *AuthService) StartOIDCCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			deleted, err := s.queries.DeleteExpiredOIDCLoginStates(ctx, pgtype.Timestamptz{Time: time.Now(), Valid: true})
+			if err != nil {
+				slog.Error("failed to delete expired single sign-on logins", "error", err)
+			} else if deleted > 0 {
+				slog.Info("deleted expired single sign-on logins", "deleted", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StartOIDCLogin begins a single sign-on login and returns the provider URL to
+// send the browser to. The state, nonce and PKCE verifier stay in the database.
+func (s *AuthService) StartOIDCLogin(ctx context.Context) (string, error) {
+	if s.oidc == nil {
+		return "", errors.NotFound("single sign-on is not enabled")
+	}
+
+	secrets := make([]string, 3)
+	for i := range secrets {
+		secret, err := refreshtoken.GenerateToken()
+		if err != nil {
+			return "", errors.Internal("failed to start single sign-on", err)
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	authURL, err := s.oidc.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", errors.Internal("identity provider is unavailable", err)
+	}
+
+	err = s.queries.CreateOIDCLoginState(ctx, db.CreateOIDCLoginStateParams{
+		StateHash:    refreshtoken.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(s.oidc.stateDuration), Valid: true},
+	})
+	if err != nil {
+		return "", errors.Internal("failed to start single sign-on", err)
+	}
+
+	return authURL, nil
+}
+
+// CompleteOIDCLogin finishes a single sign-on login with the code and state the
+// provider redirected back with. The admin is created on first login and their
+// role follows the provider's claims on every login. Like the password login,
+// it returns an MFA challenge instead of tokens when MFA applies.
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, code, state string) (*LoginResult, error) {
+	if s.oidc == nil {
+		return nil, errors.NotFound("single sign-on is not enabled")
+	}
+
+	loginState, err := s.queries.ConsumeOIDCLoginState(ctx, refreshtoken.HashToken(state))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.logEvent(ctx, audit.EventSSOLogin, uuid.Nil, "", "invalid_state")
+			return nil, errors.Unauthorized("single sign-on session is invalid or expired, please start again")
+		}
+		return nil, errors.Internal("failed to complete single sign-on", err)
+	}
+
+	idToken, err := s.oidc.provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		slog.Warn("single sign-on rejected", "error", err)
+		s.logEvent(ctx, audit.EventSSOLogin, uuid.Nil, "", "provider_rejected")
+		return nil, errors.Unauthorized("single sign-on failed")
+	}
+
+	role, ok := s.oidc.roles.Role(idToken)
+	if !ok {
+		slog.Warn("single sign-on user has no admin role", "subject", idToken.Subject, "email", idToken.Email)
+		s.logEvent(ctx, audit.EventSSOLogin, uuid.Nil, idToken.Email, "no_admin_role")
+		return nil, errors.Forbidden("your account is not allowed to access the admin panel")
+	}
+
+	admin, err := s.oidcAdmin(ctx, idToken, role)
+	if err != nil {
+		s.logEvent(ctx, audit.EventSSOLogin, uuid.Nil, idToken.Email, "account_rejected")
+		return nil, err
+	}
+
+	// With MFA the second factor is logged as mfa_verify, as for password logins
+	s.logEvent(ctx, audit.EventSSOLogin, uuid.UUID(admin.ID.Bytes), idToken.Email, "")
+
+	challenge, err := s.mfaChallengeFor(ctx, admin)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &LoginResult{Admin: admin, MFA: challenge}, nil
+	}
+
+	tokens, err := s.IssueTokens(ctx, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{Tokens: tokens, Admin: admin}, nil
+}
+
+// oidcAdmin finds the admin linked to the provider account, links an admin with
+// the same verified email, or provisions a new admin, and applies the mapped role
+func (s *AuthService) oidcAdmin(ctx context.Context, idToken *oidc.IDToken, role string) (*db.Admin, error) {
+	issuer := s.oidc.provider.Issuer()
+	email := pgtype.Text{String: idToken.Email, Valid: idToken.Email != ""}
+
+	identity, err := s.queries.GetAdminIdentity(ctx, db.GetAdminIdentityParams{
+		Issuer:  issuer,
+		Subject: idToken.Subject,
+	})
+	if err == nil {
+		// GetAdminByID only returns active admins
+		admin, err := s.queries.GetAdminByID(ctx, identity.AdminID)
+		if err != nil {
+			return nil, errors.Unauthorized("admin account is disabled")
+		}
+
+		if err := s.queries.TouchAdminIdentity(ctx, db.TouchAdminIdentityParams{ID: identity.ID, Email: email}); err != nil {
+			slog.Error("failed to record single sign-on", "admin_id", uuid.UUID(admin.ID.Bytes).String(), "error", err)
+		}
+
+		return s.syncOIDCAdmin(ctx, &admin, idToken, role)
+	}
+	if err != pgx.ErrNoRows {
+		return nil, errors.Internal("failed to look up admin identity", err)
+	}
+
+	// Accounts are only matched or created by email when the provider vouches for it
+	if idToken.Email == "" || !idToken.EmailVerified {
+		return nil, errors.Forbidden("the identity provider did not share a verified email address")
+	}
+
+	var admin *db.Admin
+	existing, err := s.queries.GetAdminByEmail(ctx, idToken.Email)
+	switch {
+	case err == nil:
+		admin, err = s.syncOIDCAdmin(ctx, &existing, idToken, role)
+	case err == pgx.ErrNoRows:
+		admin, err = s.provisionOIDCAdmin(ctx, idToken, role)
+	default:
+		err = errors.Internal("failed to look up admin", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err = s.queries.CreateAdminIdentity(ctx, db.CreateAdminIdentityParams{
+		AdminID: admin.ID,
+		Issuer:  issuer,
+		Subject: idToken.Subject,
+		Email:   email,
+	})
+	if err != nil {
+		return nil, errors.Internal("failed to link admin identity", err)
+	}
+
+	adminID := uuid.UUID(admin.ID.Bytes)
+	s.auditService.LogCreate(audit.WithAdminID(ctx, adminID), "admin_identities", uuid.UUID(identity.ID.Bytes), identity)
+
+	return admin, nil
+}
+
+// provisionOIDCAdmin creates an admin for a first single sign-on login. The
+// admin gets an unguessable password, so they sign in through the provider
+// unless they set a password with the reset flow.
+func (s *AuthService) provisionOIDCAdmin(ctx context.Context, idToken *oidc.IDToken, role string) (*db.Admin, error) {
+	secret, err := refreshtoken.GenerateToken()
+	if err != nil {
+		return nil, errors.Internal("failed to create admin", err)
+	}
+	passwordHash, err := passhash.Hash(secret)
+	if err != nil {
+		return nil, errors.Internal("failed to create admin", err)
+	}
+
+	params := db.CreateAdminParams{
+		Email:        idToken.Email,
+		Username:     oidcUsername(idToken),
+		PasswordHash: passwordHash,
+		FirstName:    pgtype.Text{String: idToken.GivenName, Valid: idToken.GivenName != ""},
+		LastName:     pgtype.Text{String: idToken.FamilyName, Valid: idToken.FamilyName != ""},
+		Role:         role,
+		IsActive:     true,
+	}
+
+	admin, err := s.queries.CreateAdmin(ctx, params)
+	if isUniqueViolation(err, "admins_username_key") {
+		// The username is taken by another admin; keep it recognizable but unique
+		params.Username = fmt.Sprintf("%s-%s", params.Username, secret[:6])
+		admin, err = s.queries.CreateAdmin(ctx, params)
+	}
+	if isUniqueViolation(err, "admins_email_key") {
+		// GetAdminByEmail skips deactivated admins; they must not come back through SSO
+		return nil, errors.Unauthorized("admin account is disabled")
+	}
+	if err != nil {
+		return nil, errors.Internal("failed to create admin", err)
+	}
+
+	slog.Info("provisioned admin from single sign-on", "admin_id", uuid.UUID(admin.ID.Bytes).String(), "role", role)
+
+	adminID := uuid.UUID(admin.ID.Bytes)
+	s.auditService.LogCreate(audit.WithAdminID(ctx, adminID), "admins", adminID, admin)
+
+	return &admin, nil
+}
+
+// syncOIDCAdmin applies the mapped role and the provider's name claims.
+// A role change bumps token_version, signing the admin out of older sessions.
+func (s *AuthService) syncOIDCAdmin(ctx context.Context, admin *db.Admin, idToken *oidc.IDToken, role string) (*db.Admin, error) {
+	firstName, lastName := admin.FirstName, admin.LastName
+	if idToken.GivenName != "" {
+		firstName = pgtype.Text{String: idToken.GivenName, Valid: true}
+	}
+	if idToken.FamilyName != "" {
+		lastName = pgtype.Text{String: idToken.FamilyName, Valid: true}
+	}
+
+	if admin.Role == role && firstName == admin.FirstName && lastName == admin.LastName {
+		return admin, nil
+	}
+
+	updated, err := s.queries.UpdateAdmin(ctx, db.UpdateAdminParams{
+		ID:           admin.ID,
+		Email:        admin.Email,
+		Username:     admin.Username,
+		PasswordHash: admin.PasswordHash,
+		FirstName:    firstName,
+		LastName:     lastName,
+		Role:         role,
+		IsActive:     admin.IsActive,
+	})
+	if err != nil {
+		return nil, errors.Internal("failed to update admin", err)
+	}
+
+	if admin.Role != role {
+		slog.Info("admin role changed by single sign-on", "admin_id", uuid.UUID(admin.ID.Bytes).String(), "old_role", admin.Role, "new_role", role)
+	}
+
+	adminID := uuid.UUID(admin.ID.Bytes)
+	s.auditService.LogUpdate(audit.WithAdminID(ctx, adminID), "admins", adminID, admin, updated)
+
+	return &updated, nil
+}
+
+// oidcUsername derives a username from preferred_username or the email's local part
+func oidcUsername(idToken *oidc.IDToken) string {
+	name := idToken.PreferredUsername
+	if name == "" || strings.Contains(name, "@") {
+		name, _, _ = strings.Cut(idToken.Email, "@")
+	}
+
+	name = strings.Trim(usernameCleaner.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if len(name) < 3 {
+		name = "admin-" + name
+	}
+	if len(name) > 90 {
+		name = name[:90]
+	}
+	return name
+}
+
+// isUniqueViolation reports whether err is a unique violation of the named constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return stderrors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
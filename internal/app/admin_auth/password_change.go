@@ -0,0 +1,91 @@
+package admin_auth
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// ChangePassword replaces the password of the signed-in admin after checking the
+// current one. Every session is signed out and a new token pair is returned,
+// so only the caller stays signed in.
+func (s *AuthService) ChangePassword(ctx context.Context, adminID, currentPassword, newPassword string) (*TokenPair, error) {
+	oldAdmin, err := s.getActiveAdmin(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	id := uuid.UUID(oldAdmin.ID.Bytes)
+
+	// Wrong current passwords count as failed logins, so a stolen session cannot be
+	// used to guess the password
+	if err := s.logins.Check(ctx, refreshtoken.SubjectAdmin, oldAdmin.Username); err != nil {
+		s.logEvent(ctx, audit.EventPasswordChange, id, oldAdmin.Username, "throttled")
+		return nil, err
+	}
+
+	match, _, err := passhash.Verify(oldAdmin.PasswordHash, currentPassword)
+	if err != nil {
+		slog.Error("failed to verify password hash", "admin_id", id.String(), "error", err)
+	}
+	if !match {
+		s.logEvent(ctx, audit.EventPasswordChange, id, oldAdmin.Username, "invalid_password")
+		if err := s.logins.Fail(ctx, refreshtoken.SubjectAdmin, oldAdmin.Username); err != nil {
+			return nil, err
+		}
+		return nil, errors.Validation("current password is incorrect")
+	}
+
+	if err := s.history.Check(ctx, refreshtoken.SubjectAdmin, id, oldAdmin.PasswordHash, newPassword); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := passhash.Hash(newPassword)
+	if err != nil {
+		return nil, errors.Internal("failed to hash password", err)
+	}
+
+	// The new password, the history entry and the sign-out commit together, so a
+	// failure cannot leave the password changed with the old sessions still valid
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Internal("failed to change password", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Bumps token_version, which invalidates outstanding access tokens
+	admin, err := s.queries.WithTx(tx).UpdateAdminPassword(ctx, db.UpdateAdminPasswordParams{
+		ID:           oldAdmin.ID,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("admin not found")
+		}
+		return nil, errors.Internal("failed to change password", err)
+	}
+
+	if err := s.history.WithTx(tx).Record(ctx, refreshtoken.SubjectAdmin, id, oldAdmin.PasswordHash); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokens.WithTx(tx).RevokeSubject(ctx, refreshtoken.SubjectAdmin, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.Internal("failed to change password", err)
+	}
+
+	// Audit log the change (the audit service strips password hashes)
+	s.auditService.LogUpdate(ctx, "admins", id, oldAdmin, admin)
+	s.logEvent(ctx, audit.EventPasswordChange, id, admin.Username, "")
+
+	return s.IssueTokens(ctx, &admin)
+}
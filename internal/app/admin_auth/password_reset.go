@@ -0,0 +1,107 @@
+package admin_auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// ForgotPassword emails a password reset link when an active admin exists for email.
+// It succeeds either way so the response does not reveal which emails belong to admins.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	// GetAdminByEmail only returns active admins
+	admin, err := s.queries.GetAdminByEmail(ctx, email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.logEvent(ctx, audit.EventPasswordResetRequest, uuid.Nil, email, "unknown_account")
+			return nil
+		}
+		return errors.Internal("failed to request password reset", err)
+	}
+
+	token, err := s.oneTimeTokens.Issue(ctx, onetimetoken.PurposePasswordReset, refreshtoken.SubjectAdmin, uuid.UUID(admin.ID.Bytes), s.resetTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      admin.Email,
+		Subject: "Reset your admin password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset the password of your admin account. Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link expires in %s and can only be used once. If you did not request this, contact a super admin.\n",
+			admin.Username, link, s.resetTokenDuration),
+	}
+
+	// Delivery failures are logged rather than returned, for the same reason unknown emails succeed
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		slog.Error("failed to send password reset email", "admin_id", uuid.UUID(admin.ID.Bytes).String(), "error", err)
+	}
+
+	s.logEvent(ctx, audit.EventPasswordResetRequest, uuid.UUID(admin.ID.Bytes), email, "")
+
+	return nil
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword.
+// Every existing session of the admin is signed out; MFA is still required on the next login.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	adminID, err := s.oneTimeTokens.Consume(ctx, onetimetoken.PurposePasswordReset, refreshtoken.SubjectAdmin, token)
+	if err != nil {
+		s.logEvent(ctx, audit.EventPasswordReset, uuid.Nil, "", "invalid_token")
+		return err
+	}
+	pgAdminID := pgtype.UUID{Bytes: adminID, Valid: true}
+
+	oldAdmin, err := s.queries.GetAdminByID(ctx, pgAdminID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.Validation("invalid or expired token")
+		}
+		return errors.Internal("failed to reset password", err)
+	}
+
+	hashedPassword, err := passhash.Hash(newPassword)
+	if err != nil {
+		return errors.Internal("failed to hash password", err)
+	}
+
+	// Bumps token_version, which invalidates outstanding access tokens
+	admin, err := s.queries.UpdateAdminPassword(ctx, db.UpdateAdminPasswordParams{
+		ID:           pgAdminID,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.Validation("invalid or expired token")
+		}
+		return errors.Internal("failed to reset password", err)
+	}
+
+	// Remember the replaced password, so a later password change cannot go back to it
+	if err := s.history.Record(ctx, refreshtoken.SubjectAdmin, adminID, oldAdmin.PasswordHash); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokens.RevokeSubject(ctx, refreshtoken.SubjectAdmin, adminID); err != nil {
+		return err
+	}
+
+	// Audit log the reset (the admin proved control of the email, so is the actor)
+	s.auditService.LogUpdate(audit.WithAdminID(ctx, adminID), "admins", adminID, oldAdmin, admin)
+	s.logEvent(ctx, audit.EventPasswordReset, adminID, admin.Username, "")
+
+	return nil
+}
@@ -3,69 +3,364 @@ package admin_auth
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/errors"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/user/coc/internal/lockout"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/passhistory"
+	"github.com/user/coc/internal/refreshtoken"
+	"github.com/user/coc/internal/revocation"
+	"github.com/user/coc/internal/signing"
+	"github.com/user/coc/internal/totp"
+)
+
+// Issuer and audience of admin access tokens. Frontend tokens use different
+// values, so a token issued for one API is rejected by the other.
+const (
+	TokenIssuer   = "coc-admin-api"
+	TokenAudience = "coc-admin"
 )
 
 // AuthService handles admin authentication
 type AuthService struct {
-	queries   *db.Queries
-	jwtSecret string
+	conn                 refreshtoken.DB // for statements that must commit together
+	queries              *db.Queries
+	auditService         *audit.Service
+	refreshTokens        *refreshtoken.Store
+	denylist             *revocation.Denylist
+	oneTimeTokens        *onetimetoken.Store
+	logins               *lockout.Guard
+	history              *passhistory.Store
+	mailer               mailer.Mailer
+	keys                 *signing.KeySet
+	mfaBox               *totp.SecretBox
+	appURL               string // base URL of the admin panel, used for links in emails
+	tokenDuration        time.Duration
+	refreshTokenDuration time.Duration
+	mfaChallengeDuration time.Duration
+	resetTokenDuration   time.Duration
+	oidc                 *oidcLogin // single sign-on, set by EnableOIDC
 }
 
-func NewAuthService(queries *db.Queries, jwtSecret string) *AuthService {
+func NewAuthService(conn refreshtoken.DB, auditService *audit.Service, refreshTokens *refreshtoken.Store, denylist *revocation.Denylist, oneTimeTokens *onetimetoken.Store, logins *lockout.Guard, history *passhistory.Store, mail mailer.Mailer, keys *signing.KeySet, mfaBox *totp.SecretBox, appURL string, tokenDuration, refreshTokenDuration, mfaChallengeDuration, resetTokenDuration time.Duration) *AuthService {
 	return &AuthService{
-		queries:   queries,
-		jwtSecret: jwtSecret,
+		conn:                 conn,
+		queries:              db.New(conn),
+		auditService:         auditService,
+		refreshTokens:        refreshTokens,
+		denylist:             denylist,
+		oneTimeTokens:        oneTimeTokens,
+		logins:               logins,
+		history:              history,
+		mailer:               mail,
+		keys:                 keys,
+		mfaBox:               mfaBox,
+		appURL:               strings.TrimRight(appURL, "/"),
+		tokenDuration:        tokenDuration,
+		refreshTokenDuration: refreshTokenDuration,
+		mfaChallengeDuration: mfaChallengeDuration,
+		resetTokenDuration:   resetTokenDuration,
 	}
 }
 
+// TokenPair is a short-lived admin access token and the refresh token used to renew it
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // access token lifetime in seconds
+}
+
 // AdminClaims defines the JWT claims structure for admins
+// RegisteredClaims.ID carries the token's jti, used to revoke it on logout.
+// Role is informational only; the middleware reads the current role from the database.
 type AdminClaims struct {
-	AdminID  string `json:"admin_id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	AdminID      string `json:"admin_id"`
+	Email        string `json:"email"`
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	TokenVersion int32  `json:"tv"`
+	SessionID    string `json:"sid,omitempty"` // refresh token family the token was issued with
 	jwt.RegisteredClaims
 }
 
-// Login authenticates an admin and returns a JWT token
-func (s *AuthService) Login(ctx context.Context, username, password string) (string, *db.Admin, error) {
+// Login authenticates an admin with username and password.
+// Admins with MFA enabled, or whose role requires it, get an MFA challenge
+// instead of tokens and finish the login with VerifyMFA.
+func (s *AuthService) Login(ctx context.Context, username, password string) (*LoginResult, error) {
+	// Refuse locked or throttled attempts before spending a password hash on them
+	if err := s.logins.Check(ctx, refreshtoken.SubjectAdmin, username); err != nil {
+		s.logEvent(ctx, audit.EventLogin, uuid.Nil, username, "throttled")
+		return nil, err
+	}
+
 	// Get admin by username
 	admin, err := s.queries.GetAdminByUsername(ctx, username)
 	if err != nil {
-		return "", nil, errors.Unauthorized("invalid username or password")
+		// Spend as long as a wrong password would, so response times do not reveal which usernames exist
+		passhash.Verify(passhash.DummyHash, password)
+		return nil, s.loginFailed(ctx, uuid.Nil, username, "unknown_account")
 	}
+	adminID := uuid.UUID(admin.ID.Bytes)
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(password)); err != nil {
-		return "", nil, errors.Unauthorized("invalid username or password")
+	match, rehash, err := passhash.Verify(admin.PasswordHash, password)
+	if err != nil {
+		slog.Error("failed to verify password hash", "admin_id", adminID.String(), "error", err)
+	}
+	if !match {
+		return nil, s.loginFailed(ctx, adminID, username, "invalid_password")
+	}
+
+	if rehash {
+		s.rehashPassword(ctx, &admin, password)
 	}
 
 	// Check if admin is active
 	if !admin.IsActive {
-		return "", nil, errors.Unauthorized("admin account is disabled")
+		s.logEvent(ctx, audit.EventLogin, adminID, username, "account_disabled")
+		return nil, errors.Unauthorized("admin account is disabled")
+	}
+
+	// The password was right; with MFA the second factor is logged as mfa_verify
+	s.logEvent(ctx, audit.EventLogin, adminID, username, "")
+
+	challenge, err := s.mfaChallengeFor(ctx, &admin)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		// Failed attempts are only forgotten once the second factor is verified too
+		return &LoginResult{Admin: &admin, MFA: challenge}, nil
+	}
+
+	if err := s.logins.Succeed(ctx, refreshtoken.SubjectAdmin, username); err != nil {
+		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := s.GenerateToken(&admin)
+	tokens, err := s.IssueTokens(ctx, &admin)
 	if err != nil {
-		return "", nil, errors.Internal("failed to generate token", err)
+		return nil, err
 	}
 
-	return token, &admin, nil
+	return &LoginResult{Tokens: tokens, Admin: &admin}, nil
+}
+
+// loginFailed records and counts a failed login and returns the error for it
+func (s *AuthService) loginFailed(ctx context.Context, adminID uuid.UUID, username, reason string) error {
+	s.logEvent(ctx, audit.EventLogin, adminID, username, reason)
+
+	if err := s.logins.Fail(ctx, refreshtoken.SubjectAdmin, username); err != nil {
+		return err
+	}
+	return errors.Unauthorized("invalid username or password")
+}
+
+// logEvent records authentication activity of an admin in the security event log.
+// adminID is uuid.Nil when no account matched; an empty reason records a success.
+func (s *AuthService) logEvent(ctx context.Context, eventType audit.SecurityEventType, adminID uuid.UUID, identifier, reason string) {
+	outcome := audit.OutcomeSuccess
+	if reason != "" {
+		outcome = audit.OutcomeFailure
+	}
+
+	s.auditService.LogSecurityEvent(ctx, audit.SecurityEvent{
+		Type:       eventType,
+		Outcome:    outcome,
+		ActorType:  audit.ActorAdmin,
+		ActorID:    adminID,
+		Identifier: identifier,
+		Reason:     reason,
+	})
 }
 
-// GenerateToken creates a new JWT token for an admin
-func (s *AuthService) GenerateToken(admin *db.Admin) (string, error) {
-	// Token expires in 24 hours (typical for admin sessions)
-	expirationTime := time.Now().Add(24 * time.Hour)
+// rehashPassword replaces an outdated password hash after a successful login.
+// Failures are only logged, since the old hash keeps working.
+func (s *AuthService) rehashPassword(ctx context.Context, admin *db.Admin, password string) {
+	hash, err := passhash.Hash(password)
+	if err != nil {
+		slog.Error("failed to rehash password", "admin_id", uuid.UUID(admin.ID.Bytes).String(), "error", err)
+		return
+	}
+
+	_, err = s.queries.RehashAdminPassword(ctx, db.RehashAdminPasswordParams{
+		NewHash: hash,
+		ID:      admin.ID,
+		OldHash: admin.PasswordHash,
+	})
+	if err != nil {
+		slog.Error("failed to store rehashed password", "admin_id", uuid.UUID(admin.ID.Bytes).String(), "error", err)
+		return
+	}
+	admin.PasswordHash = hash
+}
+
+// IssueTokens starts a new admin session: an access token plus a refresh token in a new family
+func (s *AuthService) IssueTokens(ctx context.Context, admin *db.Admin) (*TokenPair, error) {
+	refresh, err := s.refreshTokens.Issue(ctx, refreshtoken.SubjectAdmin, uuid.UUID(admin.ID.Bytes), admin.TokenVersion, s.refreshTokenDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.GenerateToken(admin, refresh.FamilyID)
+	if err != nil {
+		return nil, errors.Internal("failed to generate token", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  token,
+		RefreshToken: refresh.Token,
+		ExpiresIn:    int64(s.tokenDuration.Seconds()),
+	}, nil
+}
+
+// Refresh rotates an admin refresh token and issues a new access token.
+// The admin is re-read so disabled admins cannot refresh.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	refresh, err := s.refreshTokens.Rotate(ctx, refreshtoken.SubjectAdmin, refreshToken, s.refreshTokenDuration)
+	if err != nil {
+		s.logEvent(ctx, audit.EventTokenRefresh, uuid.Nil, "", "invalid_token")
+		return nil, err
+	}
+
+	// GetAdminByID only returns active admins
+	admin, err := s.queries.GetAdminByID(ctx, pgtype.UUID{Bytes: refresh.SubjectID, Valid: true})
+	if err != nil {
+		s.logEvent(ctx, audit.EventTokenRefresh, refresh.SubjectID, "", "account_disabled")
+		return nil, errors.Unauthorized("admin account is disabled")
+	}
+
+	// Password, role or status changed (or logout-all) after this session started
+	if refresh.TokenVersion != admin.TokenVersion {
+		if err := s.refreshTokens.RevokeFamily(ctx, refresh.FamilyID); err != nil {
+			return nil, err
+		}
+		s.logEvent(ctx, audit.EventTokenRefresh, refresh.SubjectID, "", "session_revoked")
+		return nil, errors.Unauthorized("session has been revoked")
+	}
+
+	token, err := s.GenerateToken(&admin, refresh.FamilyID)
+	if err != nil {
+		return nil, errors.Internal("failed to generate token", err)
+	}
+
+	s.logEvent(ctx, audit.EventTokenRefresh, refresh.SubjectID, "", "")
+
+	return &TokenPair{
+		AccessToken:  token,
+		RefreshToken: refresh.Token,
+		ExpiresIn:    int64(s.tokenDuration.Seconds()),
+	}, nil
+}
+
+// Authenticate validates an admin access token and checks it has not been revoked.
+// The returned admin is freshly loaded, so its role reflects any change made after login.
+func (s *AuthService) Authenticate(ctx context.Context, tokenString string) (*db.Admin, *AdminClaims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, nil, errors.Unauthorized("invalid or expired admin token")
+	}
+
+	adminID, err := uuid.Parse(claims.AdminID)
+	if err != nil {
+		return nil, nil, errors.Unauthorized("invalid or expired admin token")
+	}
+
+	// GetAdminByID only returns active admins
+	admin, err := s.queries.GetAdminByID(ctx, pgtype.UUID{Bytes: adminID, Valid: true})
+	if err != nil {
+		return nil, nil, errors.Unauthorized("admin not found")
+	}
+
+	if !admin.IsActive {
+		return nil, nil, errors.Unauthorized("admin account is disabled")
+	}
+
+	if claims.TokenVersion != admin.TokenVersion {
+		return nil, nil, errors.Unauthorized("session has been revoked")
+	}
+
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, nil, errors.Unauthorized("invalid or expired admin token")
+	}
+
+	revoked, err := s.denylist.IsRevoked(ctx, jti)
+	if err != nil {
+		return nil, nil, errors.Internal("failed to check token revocation", err)
+	}
+	if revoked {
+		return nil, nil, errors.Unauthorized("session has been revoked")
+	}
+
+	return &admin, claims, nil
+}
+
+// Logout revokes the current admin access token and the refresh tokens of its session
+func (s *AuthService) Logout(ctx context.Context, claims *AdminClaims) error {
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return errors.Validation("invalid token ID")
+	}
+
+	adminID, err := uuid.Parse(claims.AdminID)
+	if err != nil {
+		return errors.Validation("invalid admin ID format")
+	}
+
+	if err := s.denylist.Revoke(ctx, jti, string(refreshtoken.SubjectAdmin), adminID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		if err := s.refreshTokens.RevokeFamily(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	s.logEvent(ctx, audit.EventLogout, adminID, "", "")
+
+	return nil
+}
+
+// LogoutAll invalidates every access and refresh token of the admin by bumping its token version
+func (s *AuthService) LogoutAll(ctx context.Context, adminID string) error {
+	id, err := uuid.Parse(adminID)
+	if err != nil {
+		return errors.Validation("invalid admin ID format")
+	}
+
+	if _, err := s.queries.IncrementAdminTokenVersion(ctx, pgtype.UUID{Bytes: id, Valid: true}); err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("admin not found")
+		}
+		return errors.Internal("failed to revoke sessions", err)
+	}
+
+	if err := s.refreshTokens.RevokeSubject(ctx, refreshtoken.SubjectAdmin, id); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, audit.EventLogoutAll, id, "", "")
+
+	return nil
+}
+
+// GenerateToken creates a new JWT token for an admin.
+// sessionID is the refresh token family the access token belongs to.
+func (s *AuthService) GenerateToken(admin *db.Admin, sessionID uuid.UUID) (string, error) {
+	// Token expires based on ADMIN_TOKEN_DURATION from config
+	expirationTime := time.Now().Add(s.tokenDuration)
 
 	// Convert UUID to string
 	adminID, err := uuid.FromBytes(admin.ID.Bytes[:])
@@ -75,11 +370,15 @@ func (s *AuthService) GenerateToken(admin *db.Admin) (string, error) {
 
 	// Create claims with admin role
 	claims := &AdminClaims{
-		AdminID:  adminID.String(),
-		Email:    admin.Email,
-		Username: admin.Username,
-		Role:     admin.Role,
+		AdminID:      adminID.String(),
+		Email:        admin.Email,
+		Username:     admin.Username,
+		Role:         admin.Role,
+		TokenVersion: admin.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    TokenIssuer,
+			Audience:  jwt.ClaimStrings{TokenAudience},
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -87,11 +386,12 @@ func (s *AuthService) GenerateToken(admin *db.Admin) (string, error) {
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if sessionID != uuid.Nil {
+		claims.SessionID = sessionID.String()
+	}
 
-	// Sign token with secret (can use different secret for admin if desired)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	// Sign token with the active key; the kid header tells verifiers which key to use
+	tokenString, err := s.keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -102,13 +402,12 @@ func (s *AuthService) GenerateToken(admin *db.Admin) (string, error) {
 // ValidateToken validates a JWT token and returns the admin claims
 func (s *AuthService) ValidateToken(tokenString string) (*AdminClaims, error) {
 	// Parse token
-	token, err := jwt.ParseWithClaims(tokenString, &AdminClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &AdminClaims{}, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.Algorithms()),
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithAudience(TokenAudience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -176,7 +475,7 @@ func (s *AuthService) CreateAdmin(ctx context.Context, email, username, password
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := passhash.Hash(password)
 	if err != nil {
 		return nil, errors.Internal("failed to hash password", err)
 	}
@@ -185,7 +484,7 @@ func (s *AuthService) CreateAdmin(ctx context.Context, email, username, password
 	params := db.CreateAdminParams{
 		Email:        email,
 		Username:     username,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Role:         role,
 		IsActive:     true,
 	}
@@ -1,7 +1,9 @@
 package admin_menu
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -269,3 +271,45 @@ func TestBuildMenuTree_InvalidUUID(t *testing.T) {
 		t.Error("expected non-empty ID string")
 	}
 }
+
+func TestApplyPermissionOverrides(t *testing.T) {
+	now := time.Now()
+	role := map[string]bool{"users.read": true, "users.delete": true}
+
+	tests := []struct {
+		name      string
+		overrides []PermissionOverride
+		expected  []string
+	}{
+		{"no_overrides", nil, []string{"users.delete", "users.read"}},
+		{"allow_adds", []PermissionOverride{{Code: "users.update", Allow: true}}, []string{"users.delete", "users.read", "users.update"}},
+		{"deny_removes_role_grant", []PermissionOverride{{Code: "users.delete"}}, []string{"users.read"}},
+		{"deny_wins_over_allow", []PermissionOverride{{Code: "users.update", Allow: true}, {Code: "users.update"}}, []string{"users.delete", "users.read"}},
+		{"expired_allow_ignored", []PermissionOverride{{Code: "users.update", Allow: true, ExpiresAt: now}}, []string{"users.delete", "users.read"}},
+		{"expired_deny_ignored", []PermissionOverride{{Code: "users.delete", ExpiresAt: now.Add(-time.Hour)}}, []string{"users.delete", "users.read"}},
+		{"unexpired_allow_applies", []PermissionOverride{{Code: "users.update", Allow: true, ExpiresAt: now.Add(time.Hour)}}, []string{"users.delete", "users.read", "users.update"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplyPermissionOverrides(role, tt.overrides, now)
+
+			codes := make([]string, 0, len(result))
+			for code, granted := range result {
+				if granted {
+					codes = append(codes, code)
+				}
+			}
+			slices.Sort(codes)
+
+			if !slices.Equal(codes, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, codes)
+			}
+		})
+	}
+
+	// The role's map is shared through the permission cache and must not change
+	if len(role) != 2 || !role["users.delete"] {
+		t.Errorf("role permissions were modified: %v", role)
+	}
+}
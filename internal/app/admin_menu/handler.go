@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/user/coc/internal/ctxkeys"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/response"
@@ -23,7 +24,7 @@ func NewHandler(queries *db.Queries) *Handler {
 
 // GetMenu returns the menu structure for the authenticated admin
 // @Summary      Get admin menu
-// @Description  Retrieve the menu structure for the authenticated admin based on their role
+// @Description  Retrieve the menu structure for the authenticated admin based on their role and permission overrides
 // @Tags         Admin Menu
 // @Accept       json
 // @Produce      json
@@ -40,8 +41,19 @@ func (h *Handler) GetMenu(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get menu from database for this role
-	menuItems, err := GetMenuForRole(r.Context(), h.queries, role)
+	adminIDStr, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminIDStr == "" {
+		response.Error(w, http.StatusUnauthorized, "admin ID not found")
+		return
+	}
+	adminID, err := uuid.Parse(adminIDStr)
+	if err != nil {
+		response.Error(w, http.StatusUnauthorized, "invalid admin ID")
+		return
+	}
+
+	// Get menu from database for this admin's role and permission overrides
+	menuItems, err := GetMenuForAdmin(r.Context(), h.queries, adminID, role)
 	if err != nil {
 		slog.Error("failed to get menu", "error", err, "role", role)
 		response.Error(w, http.StatusInternalServerError, "failed to retrieve menu")
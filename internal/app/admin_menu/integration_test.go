@@ -113,7 +113,7 @@ func assignPermissionToRole(t *testing.T, ctx context.Context, queries *db.Queri
 	}
 }
 
-func TestIntegration_GetMenuForRole_SuperAdmin(t *testing.T) {
+func TestIntegration_GetMenuForAdmin_SuperAdmin(t *testing.T) {
 	pool, queries := setupTestDB(t)
 
 	ctx := context.Background()
@@ -145,10 +145,10 @@ func TestIntegration_GetMenuForRole_SuperAdmin(t *testing.T) {
 	assignPermissionToRole(t, ctx, qtx, "super_admin", dashboardPerm.ID)
 	assignPermissionToRole(t, ctx, qtx, "super_admin", usersPerm.ID)
 
-	// Test GetMenuForRole for super_admin
-	menuItems, err := GetMenuForRole(ctx, qtx, "super_admin")
+	// Test GetMenuForAdmin for super_admin
+	menuItems, err := GetMenuForAdmin(ctx, qtx, uuid.Nil, "super_admin")
 	if err != nil {
-		t.Fatalf("GetMenuForRole failed: %v", err)
+		t.Fatalf("GetMenuForAdmin failed: %v", err)
 	}
 
 	// Find our test menu items (they should be present among all menu items)
@@ -182,7 +182,7 @@ func TestIntegration_GetMenuForRole_SuperAdmin(t *testing.T) {
 	}
 }
 
-func TestIntegration_GetMenuForRole_AdminLimitedPermissions(t *testing.T) {
+func TestIntegration_GetMenuForAdmin_AdminLimitedPermissions(t *testing.T) {
 	pool, queries := setupTestDB(t)
 
 	ctx := context.Background()
@@ -214,10 +214,10 @@ func TestIntegration_GetMenuForRole_AdminLimitedPermissions(t *testing.T) {
 	assignPermissionToRole(t, ctx, qtx, "admin", dashboardPerm.ID)
 	// Don't assign users permission
 
-	// Test GetMenuForRole for admin (should see dashboard and settings among all items)
-	menuItems, err := GetMenuForRole(ctx, qtx, "admin")
+	// Test GetMenuForAdmin for admin (should see dashboard and settings among all items)
+	menuItems, err := GetMenuForAdmin(ctx, qtx, uuid.Nil, "admin")
 	if err != nil {
-		t.Fatalf("GetMenuForRole failed: %v", err)
+		t.Fatalf("GetMenuForAdmin failed: %v", err)
 	}
 
 	// Should see both dashboard (has permission) and settings (no permission required) among the menu items
@@ -234,7 +234,7 @@ func TestIntegration_GetMenuForRole_AdminLimitedPermissions(t *testing.T) {
 	}
 }
 
-func TestIntegration_GetMenuForRole_ModeratorNoPermissions(t *testing.T) {
+func TestIntegration_GetMenuForAdmin_ModeratorNoPermissions(t *testing.T) {
 	pool, queries := setupTestDB(t)
 
 	ctx := context.Background()
@@ -264,10 +264,10 @@ func TestIntegration_GetMenuForRole_ModeratorNoPermissions(t *testing.T) {
 
 	// Don't assign any permissions to moderator role
 
-	// Test GetMenuForRole for moderator (should not see permission-required items)
-	menuItems, err := GetMenuForRole(ctx, qtx, "moderator")
+	// Test GetMenuForAdmin for moderator (should not see permission-required items)
+	menuItems, err := GetMenuForAdmin(ctx, qtx, uuid.Nil, "moderator")
 	if err != nil {
-		t.Fatalf("GetMenuForRole failed: %v", err)
+		t.Fatalf("GetMenuForAdmin failed: %v", err)
 	}
 
 	// Should not see dashboard or settings items since they require permissions that moderator doesn't have
@@ -304,6 +304,11 @@ func TestIntegration_GetRolePermissions(t *testing.T) {
 	perm2 := createTestPermission(t, ctx, qtx, "test.perm2", "Test Permission 2", "test")
 	_ = createTestPermission(t, ctx, qtx, "other.perm", "Other Permission", "other")
 
+	// Roles are referenced by role_permissions, so the test role must exist
+	if _, err := qtx.CreateRole(ctx, db.CreateRoleParams{Name: "test_role"}); err != nil {
+		t.Fatalf("failed to create test role: %v", err)
+	}
+
 	// Assign permissions to test role
 	assignPermissionToRole(t, ctx, qtx, "test_role", perm1.ID)
 	assignPermissionToRole(t, ctx, qtx, "test_role", perm2.ID)
@@ -2,8 +2,10 @@ package admin_menu
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/user/coc/internal/db"
 )
 
@@ -17,10 +19,14 @@ type MenuItem struct {
 	Order    int         `json:"order" example:"1"`
 }
 
-// GetMenuForRole returns the menu structure from database based on admin role
-func GetMenuForRole(ctx context.Context, queries *db.Queries, role string) ([]*MenuItem, error) {
-	// Fetch menu items from database for this role
-	dbMenuItems, err := queries.GetMenuItemsByRole(ctx, role)
+// GetMenuForAdmin returns the menu structure from database based on the admin's
+// role and their permission overrides
+func GetMenuForAdmin(ctx context.Context, queries *db.Queries, adminID uuid.UUID, role string) ([]*MenuItem, error) {
+	// Fetch menu items from database for this admin
+	dbMenuItems, err := queries.GetMenuItemsByRole(ctx, db.GetMenuItemsByRoleParams{
+		AdminID: pgtype.UUID{Bytes: adminID, Valid: true},
+		Role:    role,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -85,3 +91,87 @@ func GetRolePermissions(ctx context.Context, queries *db.Queries, role string) (
 
 	return permissions, nil
 }
+
+// PermissionOverride grants (Allow) or denies one permission to a single admin
+// on top of their role. A zero ExpiresAt never expires.
+type PermissionOverride struct {
+	Code      string
+	Allow     bool
+	ExpiresAt time.Time
+}
+
+// GetAdminPermissionOverrides returns the unexpired overrides of an admin from database
+func GetAdminPermissionOverrides(ctx context.Context, queries *db.Queries, adminID uuid.UUID) ([]PermissionOverride, error) {
+	rows, err := queries.GetActiveAdminPermissionOverrides(ctx, pgtype.UUID{Bytes: adminID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make([]PermissionOverride, len(rows))
+	for i, row := range rows {
+		overrides[i] = PermissionOverride{
+			Code:  row.Code,
+			Allow: row.Effect == "allow",
+		}
+		if row.ExpiresAt.Valid {
+			overrides[i].ExpiresAt = row.ExpiresAt.Time
+		}
+	}
+
+	return overrides, nil
+}
+
+// Active reports whether the override still applies at now
+func (o PermissionOverride) Active(now time.Time) bool {
+	return o.ExpiresAt.IsZero() || now.Before(o.ExpiresAt)
+}
+
+// ApplyPermissionOverrides returns the role's permissions plus the admin's
+// allows minus their denies, ignoring overrides expired at now. A deny wins
+// over any grant. The role's map is returned as is when no override applies.
+func ApplyPermissionOverrides(permissions map[string]bool, overrides []PermissionOverride, now time.Time) map[string]bool {
+	var active []PermissionOverride
+	for _, override := range overrides {
+		if override.Active(now) {
+			active = append(active, override)
+		}
+	}
+	if len(active) == 0 {
+		return permissions
+	}
+
+	effective := make(map[string]bool, len(permissions))
+	for code := range permissions {
+		effective[code] = true
+	}
+	for _, override := range active {
+		if override.Allow {
+			effective[override.Code] = true
+		}
+	}
+
+	// Denies are applied last so they win over role grants and allows alike
+	for _, override := range active {
+		if !override.Allow {
+			delete(effective, override.Code)
+		}
+	}
+
+	return effective
+}
+
+// GetAdminPermissions returns the effective permission codes of an admin: those
+// of their role with their permission overrides applied
+func GetAdminPermissions(ctx context.Context, queries *db.Queries, adminID uuid.UUID, role string) (map[string]bool, error) {
+	permissions, err := GetRolePermissions(ctx, queries, role)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := GetAdminPermissionOverrides(ctx, queries, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyPermissionOverrides(permissions, overrides, time.Now()), nil
+}
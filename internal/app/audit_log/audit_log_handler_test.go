@@ -0,0 +1,96 @@
+package audit_log
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/validation"
+)
+
+func newAuditLogRequest(method, url string, role string) (*http.Request, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, url, nil)
+	if role != "" {
+		req = req.WithContext(context.WithValue(req.Context(), ctxkeys.AdminRoleContextKey, role))
+	}
+	return req, httptest.NewRecorder()
+}
+
+// TestHandler_ListAuditLogs_MissingAdminRole tests that listing requires an admin role in context
+func TestHandler_ListAuditLogs_MissingAdminRole(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+	req, rec := newAuditLogRequest("GET", "/api/admin/v1/audit-logs", "")
+
+	handler.ListAuditLogs(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 Unauthorized, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if response["message"] != "admin role not found" {
+		t.Errorf("expected 'admin role not found' message, got %s", response["message"])
+	}
+}
+
+// TestHandler_ListAuditLogs_InvalidFilters tests that invalid query filters are rejected before hitting the database
+func TestHandler_ListAuditLogs_InvalidFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid entity_id", query: "entity_id=not-a-uuid"},
+		{name: "invalid actor_type", query: "actor_type=robot"},
+		{name: "invalid action", query: "action=READ"},
+		{name: "limit too large", query: "limit=500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(nil, validation.New())
+			req, rec := newAuditLogRequest("GET", "/api/admin/v1/audit-logs?"+tt.query, "super_admin")
+
+			handler.ListAuditLogs(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+// TestHandler_GetAuditLog_MissingAdminRole tests that fetching an entry requires an admin role in context
+func TestHandler_GetAuditLog_MissingAdminRole(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+	req, rec := newAuditLogRequest("GET", "/api/admin/v1/audit-logs/123", "")
+
+	handler.GetAuditLog(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 Unauthorized, got %d", rec.Code)
+	}
+}
+
+// TestHandler_GetAuditLog_InvalidID tests that a malformed ID returns 400
+func TestHandler_GetAuditLog_InvalidID(t *testing.T) {
+	handler := NewHandler(NewService(nil), validation.New())
+	req, rec := newAuditLogRequest("GET", "/api/admin/v1/audit-logs/not-a-uuid", "super_admin")
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "not-a-uuid")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	handler.GetAuditLog(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,124 @@
+package audit_log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/user/coc/internal/db"
+)
+
+// TestDiffData tests the field-level diff between old_data and new_data
+func TestDiffData(t *testing.T) {
+	oldData := []byte(`{"email":"old@example.com","first_name":"Jane","is_active":true}`)
+	newData := []byte(`{"email":"new@example.com","first_name":"Jane","phone":"123"}`)
+
+	changes := diffData(oldData, newData)
+
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+
+	expected := []string{"email", "is_active", "phone"}
+	for i, field := range expected {
+		if changes[i].Field != field {
+			t.Errorf("expected change %d to be %q, got %q", i, field, changes[i].Field)
+		}
+	}
+
+	if changes[0].Old != "old@example.com" || changes[0].New != "new@example.com" {
+		t.Errorf("unexpected email change: %+v", changes[0])
+	}
+	if changes[1].New != nil {
+		t.Errorf("expected removed field to have nil new value, got %v", changes[1].New)
+	}
+	if changes[2].Old != nil {
+		t.Errorf("expected added field to have nil old value, got %v", changes[2].Old)
+	}
+}
+
+// TestDiffData_CreateAndDelete tests diffs when one side is empty
+func TestDiffData_CreateAndDelete(t *testing.T) {
+	created := diffData(nil, []byte(`{"a":1,"b":2}`))
+	if len(created) != 2 {
+		t.Errorf("expected 2 changes for create, got %d", len(created))
+	}
+
+	deleted := diffData([]byte(`{"a":1}`), nil)
+	if len(deleted) != 1 || deleted[0].Old == nil || deleted[0].New != nil {
+		t.Errorf("unexpected delete diff: %+v", deleted)
+	}
+
+	if changes := diffData(nil, nil); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+// TestBuildFilter_DefaultRange tests that the date range defaults to the last 30 days
+func TestBuildFilter_DefaultRange(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	filter, err := buildFilter(ListAuditLogsRequest{}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !filter.CreatedTo.Time.Equal(now) {
+		t.Errorf("expected to=%v, got %v", now, filter.CreatedTo.Time)
+	}
+	if !filter.CreatedFrom.Time.Equal(now.Add(-defaultLookback)) {
+		t.Errorf("expected from=%v, got %v", now.Add(-defaultLookback), filter.CreatedFrom.Time)
+	}
+	if filter.EntityType.Valid || filter.EntityID.Valid || filter.ActorType.Valid || filter.Action.Valid {
+		t.Errorf("expected optional filters to be unset, got %+v", filter)
+	}
+}
+
+// TestBuildFilter_AllFilters tests that every filter is mapped onto the query params
+func TestBuildFilter_AllFilters(t *testing.T) {
+	req := ListAuditLogsRequest{
+		EntityType: "users",
+		EntityID:   "550e8400-e29b-41d4-a716-446655440000",
+		ActorType:  "admin",
+		ActorID:    "650e8400-e29b-41d4-a716-446655440001",
+		Action:     "UPDATE",
+		RequestID:  "req-1",
+		From:       "2026-01-01T00:00:00Z",
+		To:         "2026-02-01T00:00:00Z",
+	}
+
+	filter, err := buildFilter(req, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if filter.EntityType.String != "users" || !filter.EntityID.Valid || !filter.ActorID.Valid || filter.RequestID.String != "req-1" {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+	if filter.ActorType.AuditActorType != db.AuditActorTypeAdmin {
+		t.Errorf("expected actor type admin, got %v", filter.ActorType.AuditActorType)
+	}
+	if filter.Action.AuditAction != db.AuditActionUPDATE {
+		t.Errorf("expected action UPDATE, got %v", filter.Action.AuditAction)
+	}
+}
+
+// TestBuildFilter_InvalidRange tests that invalid or inverted date ranges are rejected
+func TestBuildFilter_InvalidRange(t *testing.T) {
+	tests := []struct {
+		name string
+		req  ListAuditLogsRequest
+	}{
+		{name: "bad from", req: ListAuditLogsRequest{From: "yesterday"}},
+		{name: "bad to", req: ListAuditLogsRequest{To: "2026-13-01"}},
+		{name: "inverted", req: ListAuditLogsRequest{From: "2026-02-01T00:00:00Z", To: "2026-01-01T00:00:00Z"}},
+		{name: "bad entity id", req: ListAuditLogsRequest{EntityID: "nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildFilter(tt.req, time.Now()); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
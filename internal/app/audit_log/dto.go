@@ -0,0 +1,54 @@
+package audit_log
+
+import "encoding/json"
+
+// ListAuditLogsRequest represents the filters for browsing audit logs
+// All filters are optional and combined with AND.
+type ListAuditLogsRequest struct {
+	EntityType string `json:"entity_type" validate:"omitempty,max=50" example:"users"`
+	EntityID   string `json:"entity_id" validate:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ActorType  string `json:"actor_type" validate:"omitempty,oneof=admin user system" example:"admin"`
+	ActorID    string `json:"actor_id" validate:"omitempty,uuid" example:"650e8400-e29b-41d4-a716-446655440001"`
+	Action     string `json:"action" validate:"omitempty,oneof=CREATE UPDATE DELETE" example:"UPDATE"`
+	RequestID  string `json:"request_id" validate:"omitempty,max=100" example:"b3c1f7a2-1d2e-4f5a-9b8c-7d6e5f4a3b2c"`
+	// From and To bound created_at (RFC3339). Defaults to the last 30 days.
+	From   string `json:"from" example:"2026-01-01T00:00:00Z"`
+	To     string `json:"to" example:"2026-02-01T00:00:00Z"`
+	Limit  int32  `json:"limit" validate:"omitempty,min=1,max=100" example:"10"`
+	Offset int32  `json:"offset" validate:"omitempty,min=0" example:"0"`
+}
+
+// FieldChange represents a single field that differs between old_data and new_data
+type FieldChange struct {
+	Field string      `json:"field" example:"email"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// AuditLogResponse represents an audit log entry
+type AuditLogResponse struct {
+	ID         string          `json:"id" example:"750e8400-e29b-41d4-a716-446655440002"`
+	ActorType  string          `json:"actor_type" example:"admin"`
+	ActorID    string          `json:"actor_id,omitempty" example:"650e8400-e29b-41d4-a716-446655440001"`
+	Action     string          `json:"action" example:"UPDATE"`
+	EntityType string          `json:"entity_type" example:"users"`
+	EntityID   string          `json:"entity_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OldData    json.RawMessage `json:"old_data,omitempty" swaggertype:"object"`
+	NewData    json.RawMessage `json:"new_data,omitempty" swaggertype:"object"`
+	Changes    []FieldChange   `json:"changes"`
+	RequestID  string          `json:"request_id,omitempty" example:"b3c1f7a2-1d2e-4f5a-9b8c-7d6e5f4a3b2c"`
+	IPAddress  string          `json:"ip_address,omitempty" example:"203.0.113.10"`
+	UserAgent  string          `json:"user_agent,omitempty" example:"Mozilla/5.0"`
+	Metadata   json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+	CreatedAt  string          `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// AuditLogListResponse represents a page of audit log entries
+type AuditLogListResponse struct {
+	Items  []*AuditLogResponse `json:"items"`
+	Total  int64               `json:"total" example:"42"`
+	Limit  int32               `json:"limit" example:"10"`
+	Offset int32               `json:"offset" example:"0"`
+	From   string              `json:"from" example:"2026-01-01T00:00:00Z"`
+	To     string              `json:"to" example:"2026-02-01T00:00:00Z"`
+}
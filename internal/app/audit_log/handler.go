@@ -0,0 +1,128 @@
+package audit_log
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/response"
+	"github.com/user/coc/internal/validation"
+)
+
+// Handler handles admin audit log browsing (read-only)
+type Handler struct {
+	service  *Service
+	validate *validation.Validator
+}
+
+func NewHandler(service *Service, validator *validation.Validator) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator,
+	}
+}
+
+// ListAuditLogs handles GET /api/admin/v1/audit-logs
+// Filters are combined; the date range defaults to the last 30 days
+// @Summary      List audit logs
+// @Description  Browse audit log entries with combined filters, total count and per-entry field diff
+// @Tags         Audit Logs
+// @Accept       json
+// @Produce      json
+// @Param        entity_type query string false "Entity type (e.g. users, addresses, admins)"
+// @Param        entity_id query string false "Entity ID"
+// @Param        actor_type query string false "Actor type" Enums(admin, user, system)
+// @Param        actor_id query string false "Actor ID"
+// @Param        action query string false "Action" Enums(CREATE, UPDATE, DELETE)
+// @Param        request_id query string false "Request ID"
+// @Param        from query string false "Start of created_at range (RFC3339, default 30 days before 'to')"
+// @Param        to query string false "End of created_at range, exclusive (RFC3339, default now)"
+// @Param        limit query int false "Number of entries to return (default 10, max 100)"
+// @Param        offset query int false "Number of entries to skip (default 0)"
+// @Success      200 {object} response.JSONResponse{data=AuditLogListResponse} "Audit logs retrieved successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/audit-logs [get]
+func (h *Handler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.ParseInt(query.Get("limit"), 10, 32)
+	offset, _ := strconv.ParseInt(query.Get("offset"), 10, 32)
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := ListAuditLogsRequest{
+		EntityType: query.Get("entity_type"),
+		EntityID:   query.Get("entity_id"),
+		ActorType:  query.Get("actor_type"),
+		ActorID:    query.Get("actor_id"),
+		Action:     query.Get("action"),
+		RequestID:  query.Get("request_id"),
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	logs, err := h.service.ListAuditLogs(r.Context(), req)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "audit logs retrieved successfully", logs)
+}
+
+// GetAuditLog handles GET /api/admin/v1/audit-logs/{id}
+// @Summary      Get audit log
+// @Description  Retrieve a single audit log entry with its field diff
+// @Tags         Audit Logs
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Audit log ID"
+// @Success      200 {object} response.JSONResponse{data=AuditLogResponse} "Audit log retrieved successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Audit log not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/audit-logs/{id} [get]
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "audit log ID is required")
+		return
+	}
+
+	log, err := h.service.GetAuditLog(r.Context(), id)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "audit log retrieved successfully", log)
+}
@@ -0,0 +1,231 @@
+package audit_log
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// defaultLookback is the date range searched when no from/to is given.
+// Searches are always bounded by created_at so they stay partition-pruned.
+const defaultLookback = 30 * 24 * time.Hour
+
+// Service contains business logic for browsing audit logs (read-only)
+type Service struct {
+	queries *db.Queries
+}
+
+func NewService(queries *db.Queries) *Service {
+	return &Service{
+		queries: queries,
+	}
+}
+
+// ListAuditLogs returns audit log entries matching the combined filters, newest first
+func (s *Service) ListAuditLogs(ctx context.Context, req ListAuditLogsRequest) (*AuditLogListResponse, error) {
+	filter, err := buildFilter(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	logs, err := s.queries.SearchAuditLogs(ctx, db.SearchAuditLogsParams{
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+		EntityType:  filter.EntityType,
+		EntityID:    filter.EntityID,
+		ActorType:   filter.ActorType,
+		ActorID:     filter.ActorID,
+		Action:      filter.Action,
+		RequestID:   filter.RequestID,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+	})
+	if err != nil {
+		slog.Error("failed to search audit logs", "error", err)
+		return nil, errors.Internal("failed to list audit logs", err)
+	}
+
+	total, err := s.queries.CountSearchAuditLogs(ctx, filter)
+	if err != nil {
+		slog.Error("failed to count audit logs", "error", err)
+		return nil, errors.Internal("failed to count audit logs", err)
+	}
+
+	items := make([]*AuditLogResponse, 0, len(logs))
+	for i := range logs {
+		items = append(items, toAuditLogResponse(&logs[i]))
+	}
+
+	return &AuditLogListResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+		From:   filter.CreatedFrom.Time.Format(time.RFC3339),
+		To:     filter.CreatedTo.Time.Format(time.RFC3339),
+	}, nil
+}
+
+// GetAuditLog returns a single audit log entry
+func (s *Service) GetAuditLog(ctx context.Context, id string) (*AuditLogResponse, error) {
+	logID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.Validation("invalid audit log ID format")
+	}
+
+	log, err := s.queries.GetAuditLogByID(ctx, pgtype.UUID{Bytes: logID, Valid: true})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("audit log not found")
+		}
+		slog.Error("failed to get audit log", "audit_log_id", id, "error", err)
+		return nil, errors.Internal("failed to get audit log", err)
+	}
+
+	return toAuditLogResponse(&log), nil
+}
+
+// buildFilter converts request filters into query parameters.
+// The date range defaults to the 30 days before now.
+func buildFilter(req ListAuditLogsRequest, now time.Time) (db.CountSearchAuditLogsParams, error) {
+	filter := db.CountSearchAuditLogsParams{
+		EntityType: pgtype.Text{String: req.EntityType, Valid: req.EntityType != ""},
+		RequestID:  pgtype.Text{String: req.RequestID, Valid: req.RequestID != ""},
+	}
+
+	if req.EntityID != "" {
+		entityID, err := uuid.Parse(req.EntityID)
+		if err != nil {
+			return filter, errors.Validation("invalid entity ID format")
+		}
+		filter.EntityID = pgtype.UUID{Bytes: entityID, Valid: true}
+	}
+
+	if req.ActorID != "" {
+		actorID, err := uuid.Parse(req.ActorID)
+		if err != nil {
+			return filter, errors.Validation("invalid actor ID format")
+		}
+		filter.ActorID = pgtype.UUID{Bytes: actorID, Valid: true}
+	}
+
+	if req.ActorType != "" {
+		filter.ActorType = db.NullAuditActorType{AuditActorType: db.AuditActorType(req.ActorType), Valid: true}
+	}
+
+	if req.Action != "" {
+		filter.Action = db.NullAuditAction{AuditAction: db.AuditAction(req.Action), Valid: true}
+	}
+
+	to := now
+	if req.To != "" {
+		parsed, err := time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return filter, errors.Validation("invalid 'to' date, expected RFC3339")
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultLookback)
+	if req.From != "" {
+		parsed, err := time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			return filter, errors.Validation("invalid 'from' date, expected RFC3339")
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return filter, errors.Validation("'from' must be before 'to'")
+	}
+
+	filter.CreatedFrom = pgtype.Timestamptz{Time: from, Valid: true}
+	filter.CreatedTo = pgtype.Timestamptz{Time: to, Valid: true}
+
+	return filter, nil
+}
+
+// diffData compares the top-level fields of old_data and new_data.
+// Fields only present on one side are reported with nil for the other side.
+func diffData(oldData, newData []byte) []FieldChange {
+	oldMap := map[string]interface{}{}
+	newMap := map[string]interface{}{}
+
+	if len(oldData) > 0 {
+		if err := json.Unmarshal(oldData, &oldMap); err != nil {
+			slog.Warn("failed to decode audit old_data", "error", err)
+		}
+	}
+	if len(newData) > 0 {
+		if err := json.Unmarshal(newData, &newMap); err != nil {
+			slog.Warn("failed to decode audit new_data", "error", err)
+		}
+	}
+
+	fields := make(map[string]struct{}, len(oldMap)+len(newMap))
+	for field := range oldMap {
+		fields[field] = struct{}{}
+	}
+	for field := range newMap {
+		fields[field] = struct{}{}
+	}
+
+	changes := []FieldChange{}
+	for field := range fields {
+		oldValue, inOld := oldMap[field]
+		newValue, inNew := newMap[field]
+		if inOld && inNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+func toAuditLogResponse(log *db.AuditLog) *AuditLogResponse {
+	resp := &AuditLogResponse{
+		ID:         uuid.UUID(log.ID.Bytes).String(),
+		ActorType:  string(log.ActorType),
+		Action:     string(log.Action),
+		EntityType: log.EntityType,
+		EntityID:   uuid.UUID(log.EntityID.Bytes).String(),
+		OldData:    log.OldData,
+		NewData:    log.NewData,
+		Changes:    diffData(log.OldData, log.NewData),
+		RequestID:  log.RequestID.String,
+		IPAddress:  log.IpAddress.String,
+		UserAgent:  log.UserAgent.String,
+		Metadata:   log.Metadata,
+		CreatedAt:  log.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	if log.ActorID.Valid {
+		resp.ActorID = uuid.UUID(log.ActorID.Bytes).String()
+	}
+
+	return resp
+}
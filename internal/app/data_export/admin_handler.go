@@ -0,0 +1,82 @@
+package data_export
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/response"
+)
+
+// AdminHandler lets admins export the personal data of a user, e.g. to answer
+// a data access request made outside the app
+type AdminHandler struct {
+	service *Service
+}
+
+func NewAdminHandler(service *Service) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+	}
+}
+
+// RequestExport handles POST /api/admin/v1/users/{id}/export
+// @Summary      Request personal data export (admin)
+// @Description  Start building an archive (JSON and CSV) of the user's profile, addresses, sessions and audit history. While an export of the user is still being built, that export is returned.
+// @Tags         Admin User Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      202 {object} response.JSONResponse{data=DataExportResponse} "Export requested"
+// @Failure      400 {object} response.JSONResponse "Invalid user ID"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "User not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/users/{id}/export [post]
+func (h *AdminHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		response.Error(w, http.StatusBadRequest, "user ID is required")
+		return
+	}
+
+	export, err := h.service.RequestExport(r.Context(), userID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, "export requested", export)
+}
+
+// GetExport handles GET /api/admin/v1/users/{id}/export/{export_id}
+// @Summary      Get personal data export (admin)
+// @Description  Status of an export of the user. Completed exports include a download link that expires after DATA_EXPORT_LINK_DURATION.
+// @Tags         Admin User Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        export_id path string true "Export ID"
+// @Success      200 {object} response.JSONResponse{data=DataExportResponse} "Export retrieved successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid ID"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Export not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/users/{id}/export/{export_id} [get]
+func (h *AdminHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	exportID := chi.URLParam(r, "export_id")
+	if userID == "" || exportID == "" {
+		response.Error(w, http.StatusBadRequest, "user ID and export ID are required")
+		return
+	}
+
+	export, err := h.service.GetExport(r.Context(), userID, exportID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "export retrieved successfully", export)
+}
@@ -0,0 +1,35 @@
+package data_export
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestAdminHandler_RequestExport_MissingUserID tests that the user ID is required
+func TestAdminHandler_RequestExport_MissingUserID(t *testing.T) {
+	handler := NewAdminHandler(nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/v1/users//export", nil)
+	rec := httptest.NewRecorder()
+
+	handler.RequestExport(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request, got %d", rec.Code)
+	}
+}
+
+// TestAdminHandler_GetExport_MissingIDs tests that the user and export IDs are required
+func TestAdminHandler_GetExport_MissingIDs(t *testing.T) {
+	handler := NewAdminHandler(nil)
+
+	req := httptest.NewRequest("GET", "/api/admin/v1/users//export/", nil)
+	rec := httptest.NewRecorder()
+
+	handler.GetExport(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request, got %d", rec.Code)
+	}
+}
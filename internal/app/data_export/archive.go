@@ -0,0 +1,220 @@
+package data_export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+)
+
+// exportData is everything an archive contains. It is written as export.json,
+// and each section again as a CSV file.
+type exportData struct {
+	ExportedAt string           `json:"exported_at"`
+	User       exportUser       `json:"user"`
+	Addresses  []exportAddress  `json:"addresses"`
+	Sessions   []exportSession  `json:"sessions"`
+	AuditLogs  []exportAuditLog `json:"audit_logs"`
+}
+
+type exportUser struct {
+	ID                  string `json:"id"`
+	Email               string `json:"email"`
+	Username            string `json:"username"`
+	FirstName           string `json:"first_name"`
+	LastName            string `json:"last_name"`
+	DefaultAddressID    string `json:"default_address_id"`
+	EmailVerifiedAt     string `json:"email_verified_at"`
+	DeletionScheduledAt string `json:"deletion_scheduled_at"`
+	CreatedAt           string `json:"created_at"`
+	UpdatedAt           string `json:"updated_at"`
+}
+
+type exportAddress struct {
+	ID          string `json:"id"`
+	Address     string `json:"address"`
+	Floor       string `json:"floor"`
+	UnitNo      string `json:"unit_no"`
+	BlockTower  string `json:"block_tower"`
+	CompanyName string `json:"company_name"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type exportSession struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+}
+
+type exportAuditLog struct {
+	ID         string          `json:"id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    string          `json:"actor_id"`
+	OldData    json.RawMessage `json:"old_data,omitempty"`
+	NewData    json.RawMessage `json:"new_data,omitempty"`
+	IPAddress  string          `json:"ip_address"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+func newExportData(user *db.User, addresses []db.Address, sessions []db.UserSession, auditLogs []db.AuditLog, now time.Time) *exportData {
+	data := &exportData{
+		ExportedAt: now.UTC().Format(time.RFC3339),
+		User: exportUser{
+			ID:                  formatUUID(user.ID),
+			Email:               user.Email,
+			Username:            user.Username,
+			FirstName:           user.FirstName.String,
+			LastName:            user.LastName.String,
+			DefaultAddressID:    formatUUID(user.DefaultAddressID),
+			EmailVerifiedAt:     formatTime(user.EmailVerifiedAt),
+			DeletionScheduledAt: formatTime(user.DeletionScheduledAt),
+			CreatedAt:           formatTime(user.CreatedAt),
+			UpdatedAt:           formatTime(user.UpdatedAt),
+		},
+		Addresses: make([]exportAddress, 0, len(addresses)),
+		Sessions:  make([]exportSession, 0, len(sessions)),
+		AuditLogs: make([]exportAuditLog, 0, len(auditLogs)),
+	}
+
+	for _, a := range addresses {
+		data.Addresses = append(data.Addresses, exportAddress{
+			ID:          formatUUID(a.ID),
+			Address:     a.Address,
+			Floor:       a.Floor,
+			UnitNo:      a.UnitNo,
+			BlockTower:  a.BlockTower.String,
+			CompanyName: a.CompanyName.String,
+			CreatedAt:   formatTime(a.CreatedAt),
+			UpdatedAt:   formatTime(a.UpdatedAt),
+		})
+	}
+
+	for _, s := range sessions {
+		data.Sessions = append(data.Sessions, exportSession{
+			ID:         formatUUID(s.ID),
+			UserAgent:  s.UserAgent.String,
+			IPAddress:  s.IpAddress.String,
+			CreatedAt:  formatTime(s.CreatedAt),
+			LastSeenAt: formatTime(s.LastSeenAt),
+		})
+	}
+
+	for _, l := range auditLogs {
+		data.AuditLogs = append(data.AuditLogs, exportAuditLog{
+			ID:         formatUUID(l.ID),
+			Action:     string(l.Action),
+			EntityType: l.EntityType,
+			EntityID:   formatUUID(l.EntityID),
+			ActorType:  string(l.ActorType),
+			ActorID:    formatUUID(l.ActorID),
+			OldData:    l.OldData,
+			NewData:    l.NewData,
+			IPAddress:  l.IpAddress.String,
+			UserAgent:  l.UserAgent.String,
+			CreatedAt:  formatTime(l.CreatedAt),
+		})
+	}
+
+	return data
+}
+
+// buildArchive writes data as a zip archive with export.json and one CSV file per section
+func buildArchive(data *exportData) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	f, err := zw.Create("export.json")
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+
+	u := data.User
+	err = writeCSV(zw, "user.csv",
+		[]string{"id", "email", "username", "first_name", "last_name", "default_address_id", "email_verified_at", "deletion_scheduled_at", "created_at", "updated_at"},
+		[][]string{{u.ID, u.Email, u.Username, u.FirstName, u.LastName, u.DefaultAddressID, u.EmailVerifiedAt, u.DeletionScheduledAt, u.CreatedAt, u.UpdatedAt}})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(data.Addresses))
+	for _, a := range data.Addresses {
+		rows = append(rows, []string{a.ID, a.Address, a.Floor, a.UnitNo, a.BlockTower, a.CompanyName, a.CreatedAt, a.UpdatedAt})
+	}
+	err = writeCSV(zw, "addresses.csv",
+		[]string{"id", "address", "floor", "unit_no", "block_tower", "company_name", "created_at", "updated_at"}, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	rows = make([][]string, 0, len(data.Sessions))
+	for _, s := range data.Sessions {
+		rows = append(rows, []string{s.ID, s.UserAgent, s.IPAddress, s.CreatedAt, s.LastSeenAt})
+	}
+	err = writeCSV(zw, "sessions.csv", []string{"id", "user_agent", "ip_address", "created_at", "last_seen_at"}, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// The JSON snapshots stay JSON inside their CSV cells
+	rows = make([][]string, 0, len(data.AuditLogs))
+	for _, l := range data.AuditLogs {
+		rows = append(rows, []string{l.ID, l.Action, l.EntityType, l.EntityID, l.ActorType, l.ActorID, string(l.OldData), string(l.NewData), l.IPAddress, l.UserAgent, l.CreatedAt})
+	}
+	err = writeCSV(zw, "audit_logs.csv",
+		[]string{"id", "action", "entity_type", "entity_id", "actor_type", "actor_id", "old_data", "new_data", "ip_address", "user_agent", "created_at"}, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+func formatUUID(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
+
+func formatTime(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
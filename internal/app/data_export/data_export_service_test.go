@@ -0,0 +1,229 @@
+package data_export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+func newTestService(now time.Time) *Service {
+	s := NewService(nil, nil, "test-signing-key", 15*time.Minute, 7*24*time.Hour)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func completedExport(now time.Time) *db.DataExport {
+	return &db.DataExport{
+		ID:              pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		RequestedByType: db.AuditActorTypeUser,
+		Status:          StatusCompleted,
+		CompletedAt:     pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true},
+		ExpiresAt:       pgtype.Timestamptz{Time: now.Add(24 * time.Hour), Valid: true},
+		CreatedAt:       pgtype.Timestamptz{Time: now.Add(-2 * time.Minute), Valid: true},
+	}
+}
+
+// TestToResponse_CompletedHasSignedLink tests that completed exports come with a download link the service accepts
+func TestToResponse_CompletedHasSignedLink(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	s := newTestService(now)
+	export := completedExport(now)
+
+	resp := s.toResponse(export)
+	if resp.DownloadURL == "" {
+		t.Fatal("expected a download URL")
+	}
+
+	link, err := url.Parse(resp.DownloadURL)
+	if err != nil {
+		t.Fatalf("invalid download URL: %v", err)
+	}
+	exportID := uuid.UUID(export.ID.Bytes)
+	if link.Path != "/api/v1/exports/"+exportID.String()+"/download" {
+		t.Errorf("unexpected download path %s", link.Path)
+	}
+
+	expires, err := strconv.ParseInt(link.Query().Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid expires: %v", err)
+	}
+	if expires != now.Add(15*time.Minute).Unix() {
+		t.Errorf("expected link to expire after the link duration, got %d", expires)
+	}
+	if !s.validSignature(exportID, expires, link.Query().Get("signature")) {
+		t.Error("expected signature to be valid")
+	}
+
+	// A link for one export does not open another
+	if s.validSignature(uuid.New(), expires, link.Query().Get("signature")) {
+		t.Error("expected signature to be bound to the export")
+	}
+	// Moving the expiry invalidates the signature
+	if s.validSignature(exportID, expires+3600, link.Query().Get("signature")) {
+		t.Error("expected signature to be bound to the expiry")
+	}
+}
+
+// TestToResponse_LinkNeverOutlivesArchive tests that links expire with the archive
+func TestToResponse_LinkNeverOutlivesArchive(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	s := newTestService(now)
+	export := completedExport(now)
+	export.ExpiresAt = pgtype.Timestamptz{Time: now.Add(5 * time.Minute), Valid: true}
+
+	resp := s.toResponse(export)
+
+	if resp.DownloadExpiresAt != now.Add(5*time.Minute).Format(time.RFC3339) {
+		t.Errorf("expected link to expire with the archive, got %s", resp.DownloadExpiresAt)
+	}
+}
+
+// TestToResponse_NoLinkUnlessCompleted tests that pending, failed and expired exports have no download link
+func TestToResponse_NoLinkUnlessCompleted(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	s := newTestService(now)
+
+	for _, status := range []string{StatusPending, StatusProcessing, StatusFailed} {
+		export := completedExport(now)
+		export.Status = status
+		if resp := s.toResponse(export); resp.DownloadURL != "" {
+			t.Errorf("expected no download URL for %s export", status)
+		}
+	}
+
+	expired := completedExport(now)
+	expired.ExpiresAt = pgtype.Timestamptz{Time: now.Add(-time.Second), Valid: true}
+	if resp := s.toResponse(expired); resp.DownloadURL != "" {
+		t.Error("expected no download URL for expired export")
+	}
+}
+
+// TestDownload_RejectsBadLinks tests that forged and expired links are refused before the archive is read
+func TestDownload_RejectsBadLinks(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	s := newTestService(now)
+	exportID := uuid.New()
+	ctx := context.Background()
+
+	past := now.Add(-time.Minute).Unix()
+	future := now.Add(time.Minute).Unix()
+
+	tests := []struct {
+		name      string
+		expires   string
+		signature string
+	}{
+		{"missing signature", strconv.FormatInt(future, 10), ""},
+		{"forged signature", strconv.FormatInt(future, 10), strings.Repeat("0", 64)},
+		{"invalid expiry", "soon", s.sign(exportID, future)},
+		{"expired link", strconv.FormatInt(past, 10), s.sign(exportID, past)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Download(ctx, exportID.String(), tt.expires, tt.signature)
+
+			domainErr, ok := err.(*errors.DomainError)
+			if !ok {
+				t.Fatalf("expected DomainError, got %T (%v)", err, err)
+			}
+			if domainErr.Code != errors.CodeForbidden {
+				t.Errorf("expected FORBIDDEN, got %s", domainErr.Code)
+			}
+		})
+	}
+}
+
+// TestBuildArchive tests that the archive holds export.json and a CSV file per section
+func TestBuildArchive(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	user := &db.User{
+		ID:        userID,
+		Email:     "jane@example.com",
+		Username:  "jane",
+		FirstName: pgtype.Text{String: "Jane", Valid: true},
+		CreatedAt: pgtype.Timestamptz{Time: now.Add(-48 * time.Hour), Valid: true},
+	}
+	addresses := []db.Address{{
+		ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:  userID,
+		Address: "1 Main Street, Springfield",
+		Floor:   "2",
+		UnitNo:  "2A",
+	}}
+	sessions := []db.UserSession{{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:    userID,
+		UserAgent: pgtype.Text{String: "Mozilla/5.0", Valid: true},
+		IpAddress: pgtype.Text{String: "203.0.113.7", Valid: true},
+	}}
+	auditLogs := []db.AuditLog{{
+		ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Action:     db.AuditActionUPDATE,
+		EntityType: "users",
+		EntityID:   userID,
+		ActorType:  db.AuditActorTypeUser,
+		ActorID:    userID,
+		OldData:    []byte(`{"first_name":"J"}`),
+		NewData:    []byte(`{"first_name":"Jane"}`),
+	}}
+
+	content, err := buildArchive(newExportData(user, addresses, sessions, auditLogs, now))
+	if err != nil {
+		t.Fatalf("buildArchive failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("invalid zip archive: %v", err)
+	}
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var data exportData
+	if err := json.Unmarshal(files["export.json"], &data); err != nil {
+		t.Fatalf("invalid export.json: %v", err)
+	}
+	if data.User.Email != "jane@example.com" || len(data.Addresses) != 1 || len(data.Sessions) != 1 || len(data.AuditLogs) != 1 {
+		t.Errorf("unexpected export.json contents: %+v", data)
+	}
+	var snapshot map[string]string
+	if err := json.Unmarshal(data.AuditLogs[0].NewData, &snapshot); err != nil || snapshot["first_name"] != "Jane" {
+		t.Errorf("expected audit snapshots to stay JSON, got %s", data.AuditLogs[0].NewData)
+	}
+
+	wantRows := map[string]int{"user.csv": 1, "addresses.csv": 1, "sessions.csv": 1, "audit_logs.csv": 1}
+	for name, want := range wantRows {
+		records, err := csv.NewReader(bytes.NewReader(files[name])).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid %s: %v", name, err)
+		}
+		if len(records) != want+1 {
+			t.Errorf("expected %s to have a header and %d rows, got %d records", name, want, len(records))
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package data_export
+
+// DataExportResponse represents a personal data export and, once it is
+// completed, a link to download its archive
+type DataExportResponse struct {
+	ID                string `json:"id" example:"850e8400-e29b-41d4-a716-446655440003"`
+	UserID            string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status            string `json:"status" enums:"pending,processing,completed,failed" example:"completed"`
+	RequestedByType   string `json:"requested_by_type" enums:"user,admin" example:"user"`
+	CreatedAt         string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	CompletedAt       string `json:"completed_at,omitempty" example:"2024-01-01T12:00:05Z"`
+	ExpiresAt         string `json:"expires_at,omitempty" example:"2024-01-08T12:00:05Z"`
+	DownloadURL       string `json:"download_url,omitempty" example:"/api/v1/exports/850e8400-e29b-41d4-a716-446655440003/download?expires=1704114000&signature=3f2a..."`
+	DownloadExpiresAt string `json:"download_expires_at,omitempty" example:"2024-01-01T12:15:00Z"`
+}
@@ -0,0 +1,115 @@
+package data_export
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/response"
+)
+
+// FrontendHandler lets users export their own personal data
+type FrontendHandler struct {
+	service *Service
+}
+
+func NewFrontendHandler(service *Service) *FrontendHandler {
+	return &FrontendHandler{
+		service: service,
+	}
+}
+
+// RequestExport handles POST /api/v1/user/export
+// @Summary      Request personal data export
+// @Description  Start building an archive (JSON and CSV) of the authenticated user's profile, addresses, sessions and audit history. Poll the export until it is completed, then follow its download link. While an export is still being built, that export is returned.
+// @Tags         User Profile
+// @Accept       json
+// @Produce      json
+// @Success      202 {object} response.JSONResponse{data=DataExportResponse} "Export requested"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Not allowed while impersonating"
+// @Security     BearerAuth
+// @Router       /api/v1/user/export [post]
+func (h *FrontendHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := ctxkeys.GetUserID(r)
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	export, err := h.service.RequestExport(r.Context(), userID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, "export requested", export)
+}
+
+// GetExport handles GET /api/v1/user/export/{id}
+// @Summary      Get personal data export
+// @Description  Status of an export of the authenticated user. Completed exports include a download link that expires after DATA_EXPORT_LINK_DURATION; fetch the export again for a new one.
+// @Tags         User Profile
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Export ID"
+// @Success      200 {object} response.JSONResponse{data=DataExportResponse} "Export retrieved successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid export ID"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      404 {object} response.JSONResponse "Export not found"
+// @Security     BearerAuth
+// @Router       /api/v1/user/export/{id} [get]
+func (h *FrontendHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := ctxkeys.GetUserID(r)
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	exportID := chi.URLParam(r, "id")
+	if exportID == "" {
+		response.Error(w, http.StatusBadRequest, "export ID is required")
+		return
+	}
+
+	export, err := h.service.GetExport(r.Context(), userID, exportID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "export retrieved successfully", export)
+}
+
+// Download handles GET /api/v1/exports/{id}/download
+// The signed link is the credential, so it works without an access token,
+// e.g. when opened in a browser
+// @Summary      Download personal data export
+// @Description  Download the zip archive of a completed export with a signed link from the export status
+// @Tags         User Profile
+// @Produce      application/zip
+// @Param        id path string true "Export ID"
+// @Param        expires query string true "Link expiry (Unix time)"
+// @Param        signature query string true "Link signature"
+// @Success      200 {file} file "Export archive"
+// @Failure      403 {object} response.JSONResponse "Invalid or expired link"
+// @Failure      404 {object} response.JSONResponse "Export not found"
+// @Router       /api/v1/exports/{id}/download [get]
+func (h *FrontendHandler) Download(w http.ResponseWriter, r *http.Request) {
+	exportID := chi.URLParam(r, "id")
+	query := r.URL.Query()
+
+	archive, err := h.service.Download(r.Context(), exportID, query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+archive.Filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive.Content)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(archive.Content)
+}
@@ -0,0 +1,60 @@
+package data_export
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/user/coc/internal/ctxkeys"
+)
+
+// TestFrontendHandler_RequestExport_NotAuthenticated tests that requesting an export requires a user
+func TestFrontendHandler_RequestExport_NotAuthenticated(t *testing.T) {
+	handler := NewFrontendHandler(nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/user/export", nil)
+	rec := httptest.NewRecorder()
+
+	handler.RequestExport(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 Unauthorized, got %d", rec.Code)
+	}
+}
+
+// TestFrontendHandler_GetExport_MissingID tests that the export ID is required
+func TestFrontendHandler_GetExport_MissingID(t *testing.T) {
+	handler := NewFrontendHandler(nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/user/export/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.UserIDContextKey, "550e8400-e29b-41d4-a716-446655440000"))
+	rec := httptest.NewRecorder()
+
+	handler.GetExport(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request, got %d", rec.Code)
+	}
+}
+
+// TestFrontendHandler_Download_InvalidSignature tests that a forged link is refused
+func TestFrontendHandler_Download_InvalidSignature(t *testing.T) {
+	handler := NewFrontendHandler(newTestService(time.Now()))
+	exportID := uuid.NewString()
+
+	req := httptest.NewRequest("GET", "/api/v1/exports/"+exportID+"/download?expires=9999999999&signature=forged", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", exportID)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rec := httptest.NewRecorder()
+
+	handler.Download(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 Forbidden, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,357 @@
+package data_export
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// Export statuses
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+)
+
+// staleAfter is how long an export may stay processing before another worker
+// takes it over, e.g. after the instance building it was stopped
+const staleAfter = 10 * time.Minute
+
+// auditLogPageSize is how many audit log entries are read per query while
+// building an archive
+const auditLogPageSize = 500
+
+// Archive is a downloadable export archive
+type Archive struct {
+	Filename string
+	Content  []byte
+}
+
+// Service contains business logic for personal data exports. Exports are
+// requested here and built in the background by StartWorker.
+type Service struct {
+	queries      *db.Queries
+	auditService *audit.Service
+	signingKey   []byte
+	linkDuration time.Duration
+	retention    time.Duration
+	wake         chan struct{}
+	now          func() time.Time
+}
+
+// NewService creates a data export service. Download links are signed with
+// signingKey and valid for linkDuration; archives are kept for retention
+// after they were built.
+func NewService(queries *db.Queries, auditService *audit.Service, signingKey string, linkDuration, retention time.Duration) *Service {
+	return &Service{
+		queries:      queries,
+		auditService: auditService,
+		signingKey:   []byte(signingKey),
+		linkDuration: linkDuration,
+		retention:    retention,
+		wake:         make(chan struct{}, 1),
+		now:          time.Now,
+	}
+}
+
+// RequestExport starts an export of the personal data of the user. The actor
+// in ctx is recorded as the requester. While an export of the user is still
+// pending or processing, that export is returned instead of starting another.
+func (s *Service) RequestExport(ctx context.Context, userID string) (*DataExportResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.Validation("invalid user ID format")
+	}
+	pgUserID := pgtype.UUID{Bytes: id, Valid: true}
+
+	user, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("user not found")
+		}
+		return nil, errors.Internal("failed to request data export", err)
+	}
+	if user.AnonymizedAt.Valid {
+		return nil, errors.NotFound("user not found")
+	}
+
+	unfinished, err := s.queries.GetUnfinishedDataExportForUser(ctx, pgUserID)
+	if err == nil {
+		return s.toResponse(&unfinished), nil
+	} else if err != pgx.ErrNoRows {
+		return nil, errors.Internal("failed to request data export", err)
+	}
+
+	auditCtx := audit.ExtractAuditContext(ctx)
+	export, err := s.queries.CreateDataExport(ctx, db.CreateDataExportParams{
+		UserID:          pgUserID,
+		RequestedByType: db.AuditActorType(auditCtx.ActorType),
+		RequestedByID:   pgtype.UUID{Bytes: auditCtx.ActorID, Valid: auditCtx.ActorID != uuid.Nil},
+	})
+	if err != nil {
+		slog.Error("failed to create data export", "user_id", userID, "error", err)
+		return nil, errors.Internal("failed to request data export", err)
+	}
+
+	// Audit log the request
+	s.auditService.LogCreate(ctx, "data_exports", uuid.UUID(export.ID.Bytes), export)
+
+	// Let the worker of this instance pick it up right away
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return s.toResponse(&export), nil
+}
+
+// GetExport returns an export of the user, with a fresh download link once it
+// is completed. Exports of anonymized users are not found.
+func (s *Service) GetExport(ctx context.Context, userID, exportID string) (*DataExportResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.Validation("invalid user ID format")
+	}
+	eid, err := uuid.Parse(exportID)
+	if err != nil {
+		return nil, errors.Validation("invalid export ID format")
+	}
+
+	export, err := s.queries.GetDataExportForUser(ctx, db.GetDataExportForUserParams{
+		ID:     pgtype.UUID{Bytes: eid, Valid: true},
+		UserID: pgtype.UUID{Bytes: uid, Valid: true},
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("export not found")
+		}
+		return nil, errors.Internal("failed to get data export", err)
+	}
+
+	return s.toResponse(&export), nil
+}
+
+// Download returns the archive of an export for a signed download link. Links
+// to exports of users anonymized since stop working.
+func (s *Service) Download(ctx context.Context, exportID, expires, signature string) (*Archive, error) {
+	id, err := uuid.Parse(exportID)
+	if err != nil {
+		return nil, errors.NotFound("export not found")
+	}
+
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !s.validSignature(id, expiresUnix, signature) {
+		return nil, errors.Forbidden("invalid download link")
+	}
+	if s.now().Unix() > expiresUnix {
+		return nil, errors.Forbidden("download link has expired")
+	}
+
+	content, err := s.queries.GetDataExportArchive(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// The export was purged after its retention
+			return nil, errors.NotFound("export not found")
+		}
+		return nil, errors.Internal("failed to download data export", err)
+	}
+
+	return &Archive{
+		Filename: "data-export-" + id.String() + ".zip",
+		Content:  content,
+	}, nil
+}
+
+// StartWorker builds pending exports in the background, checking for new ones on
+// every interval and whenever one is requested on this instance, until ctx is cancelled
+func (s *Service) StartWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for {
+				processed, err := s.processNext(ctx)
+				if err != nil {
+					slog.Error("failed to process data export", "error", err)
+				}
+				if !processed {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+		}
+	}()
+}
+
+// processNext builds the oldest pending export. It reports false when there was none.
+func (s *Service) processNext(ctx context.Context) (bool, error) {
+	export, err := s.queries.ClaimDataExport(ctx, pgtype.Timestamptz{Time: s.now().Add(-staleAfter), Valid: true})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	exportID := uuid.UUID(export.ID.Bytes)
+
+	expiresAt := pgtype.Timestamptz{Time: s.now().Add(s.retention), Valid: true}
+
+	content, err := s.buildExport(ctx, export.UserID)
+	if err == nil {
+		err = s.queries.CreateDataExportArchive(ctx, db.CreateDataExportArchiveParams{
+			ExportID: export.ID,
+			Content:  content,
+		})
+	}
+	if err != nil {
+		slog.Error("failed to build data export", "export_id", exportID.String(), "error", err)
+		failErr := s.queries.FailDataExport(ctx, db.FailDataExportParams{
+			ID:        export.ID,
+			Error:     pgtype.Text{String: err.Error(), Valid: true},
+			ExpiresAt: expiresAt,
+		})
+		return true, failErr
+	}
+
+	if _, err := s.queries.CompleteDataExport(ctx, db.CompleteDataExportParams{
+		ID:        export.ID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return true, err
+	}
+
+	slog.Info("built data export", "export_id", exportID.String(), "size", len(content))
+
+	return true, nil
+}
+
+// buildExport collects the personal data of the user into an archive
+func (s *Service) buildExport(ctx context.Context, userID pgtype.UUID) ([]byte, error) {
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := s.queries.GetAddressesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.queries.ListUserSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditLogs []db.AuditLog
+	for offset := int32(0); ; offset += auditLogPageSize {
+		page, err := s.queries.ListAuditLogsForUser(ctx, db.ListAuditLogsForUserParams{
+			UserID:    userID,
+			RowLimit:  auditLogPageSize,
+			RowOffset: offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		auditLogs = append(auditLogs, page...)
+		if len(page) < auditLogPageSize {
+			break
+		}
+	}
+
+	return buildArchive(newExportData(&user, addresses, sessions, auditLogs, s.now()))
+}
+
+// StartCleanup deletes expired exports immediately and then on every interval
+// until ctx is cancelled. Failures are logged and retried on the next tick.
+func (s *Service) StartCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			deleted, err := s.DeleteExpired(ctx)
+			if err != nil {
+				slog.Error("failed to delete expired data exports", "error", err)
+			} else if deleted > 0 {
+				slog.Info("deleted expired data exports", "deleted", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// DeleteExpired removes exports and their archives past their retention
+func (s *Service) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.queries.DeleteExpiredDataExports(ctx, pgtype.Timestamptz{Time: s.now(), Valid: true})
+}
+
+// downloadURL returns a signed link to the archive of the export and when it
+// expires. Links never outlive the archive.
+func (s *Service) downloadURL(export *db.DataExport) (string, time.Time) {
+	expiresAt := s.now().Add(s.linkDuration)
+	if export.ExpiresAt.Valid && export.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = export.ExpiresAt.Time
+	}
+
+	id := uuid.UUID(export.ID.Bytes)
+	expires := expiresAt.Unix()
+	url := "/api/v1/exports/" + id.String() + "/download?expires=" + strconv.FormatInt(expires, 10) +
+		"&signature=" + s.sign(id, expires)
+
+	return url, time.Unix(expires, 0)
+}
+
+func (s *Service) sign(exportID uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(exportID.String() + "." + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) validSignature(exportID uuid.UUID, expires int64, signature string) bool {
+	expected := s.sign(exportID, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func (s *Service) toResponse(export *db.DataExport) *DataExportResponse {
+	resp := &DataExportResponse{
+		ID:              uuid.UUID(export.ID.Bytes).String(),
+		UserID:          uuid.UUID(export.UserID.Bytes).String(),
+		Status:          export.Status,
+		RequestedByType: string(export.RequestedByType),
+		CreatedAt:       formatTime(export.CreatedAt),
+		CompletedAt:     formatTime(export.CompletedAt),
+		ExpiresAt:       formatTime(export.ExpiresAt),
+	}
+
+	if export.Status == StatusCompleted && export.ExpiresAt.Valid && s.now().Before(export.ExpiresAt.Time) {
+		url, expiresAt := s.downloadURL(export)
+		resp.DownloadURL = url
+		resp.DownloadExpiresAt = expiresAt.UTC().Format(time.RFC3339)
+	}
+
+	return resp
+}
@@ -0,0 +1,82 @@
+package frontend_auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// DeleteAccount schedules the account of a signed-in user for deletion and signs
+// it out everywhere. The account is anonymized once the grace period ends,
+// unless the user logs in again before then.
+func (s *Service) DeleteAccount(ctx context.Context, userID string) (*db.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.Validation("invalid user ID format")
+	}
+	pgUserID := pgtype.UUID{Bytes: id, Valid: true}
+
+	user, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("user not found")
+		}
+		return nil, errors.Internal("failed to delete account", err)
+	}
+
+	scheduled, err := s.deletions.Schedule(ctx, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logging back in is how the deletion is cancelled, so no session may survive it
+	if _, err := s.queries.IncrementUserTokenVersion(ctx, pgUserID); err != nil {
+		return nil, errors.Internal("failed to revoke sessions", err)
+	}
+	if err := s.refreshTokens.RevokeSubject(ctx, refreshtoken.SubjectUser, id); err != nil {
+		return nil, err
+	}
+
+	deleteAt := scheduled.DeletionScheduledAt.Time
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account is scheduled for deletion on %s.\n\n"+
+			"Log in again before then to keep your account. If you did not ask for this, log in and change your password.\n",
+			user.Username, deleteAt.UTC().Format("January 2, 2006 15:04 MST")),
+	}
+
+	// The deletion is scheduled either way; the notice is informational
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		slog.Error("failed to send account deletion email", "user_id", id.String(), "error", err)
+	}
+
+	s.logEvent(ctx, audit.EventAccountDeletion, id, user.Email, "")
+
+	return scheduled, nil
+}
+
+// restoreAccount cancels a scheduled deletion when the user logs in during the grace period
+func (s *Service) restoreAccount(ctx context.Context, user *db.User) error {
+	if !user.DeletionScheduledAt.Valid {
+		return nil
+	}
+
+	userID := uuid.UUID(user.ID.Bytes)
+	if err := s.deletions.Cancel(audit.WithUserID(ctx, userID), user); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, audit.EventAccountRestore, userID, user.Email, "")
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
 )
 
 // LoginRequest represents the login request payload
@@ -15,8 +16,22 @@ type LoginRequest struct {
 
 // LoginResponse represents the login response
 type LoginResponse struct {
-	Token string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	User  UserResponse `json:"user"`
+	Token        string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string       `json:"refresh_token" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+	ExpiresIn    int64        `json:"expires_in" example:"900"`
+	User         UserResponse `json:"user"`
+}
+
+// RefreshRequest represents the token refresh request payload
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+}
+
+// TokenResponse represents a refreshed token pair
+type TokenResponse struct {
+	Token        string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
 }
 
 // RegisterRequest represents the registration request payload
@@ -28,19 +43,62 @@ type RegisterRequest struct {
 	LastName  string `json:"last_name,omitempty" example:"Doe"`
 }
 
+// ForgotPasswordRequest represents the forgot password request payload
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john.doe@example.com"`
+}
+
+// ResetPasswordRequest represents the password reset request payload
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+	Password string `json:"password" validate:"required,min=6" example:"NewSecurePass123"`
+}
+
+// ChangePasswordRequest represents the password change request payload
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"SecurePass123"`
+	NewPassword     string `json:"new_password" validate:"required,strongpassword" example:"NewSecurePass123"`
+}
+
+// VerifyEmailRequest represents the email verification request payload
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+}
+
+// ResendVerificationRequest represents the request to re-send the verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john.doe@example.com"`
+}
+
+// MagicLinkRequest represents the request to email a passwordless login link
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john.doe@example.com"`
+}
+
+// VerifyMagicLinkRequest represents the request to log in with a login link token
+type VerifyMagicLinkRequest struct {
+	Token string `json:"token" validate:"required" example:"q0n8Yc1v9mJ2xk7P4aTtR3sWzE6hUoLfB5dNgXiKjQc"`
+}
+
+// AccountDeletionResponse tells when a scheduled account deletion takes effect
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" example:"2024-01-31T12:00:00Z"`
+}
+
 // UserResponse represents the user data in responses (excluding password)
 type UserResponse struct {
-	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Email     string    `json:"email" example:"john.doe@example.com"`
-	Username  string    `json:"username" example:"johndoe"`
-	FirstName *string   `json:"first_name,omitempty" example:"John"`
-	LastName  *string   `json:"last_name,omitempty" example:"Doe"`
-	CreatedAt time.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2024-01-02T15:30:00Z"`
+	ID              string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email           string     `json:"email" example:"john.doe@example.com"`
+	Username        string     `json:"username" example:"johndoe"`
+	FirstName       *string    `json:"first_name,omitempty" example:"John"`
+	LastName        *string    `json:"last_name,omitempty" example:"Doe"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" example:"2024-01-01T12:05:00Z"`
+	CreatedAt       time.Time  `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt       time.Time  `json:"updated_at" example:"2024-01-02T15:30:00Z"`
 }
 
 // ToUserResponse converts a db.User to UserResponse (excluding password_hash)
-func ToUserResponse(id pgtype.UUID, email, username string, firstName, lastName pgtype.Text, createdAt, updatedAt pgtype.Timestamptz) UserResponse {
+func ToUserResponse(id pgtype.UUID, email, username string, firstName, lastName pgtype.Text, emailVerifiedAt, createdAt, updatedAt pgtype.Timestamptz) UserResponse {
 	resp := UserResponse{
 		Email:     email,
 		Username:  username,
@@ -64,5 +122,33 @@ func ToUserResponse(id pgtype.UUID, email, username string, firstName, lastName
 		resp.LastName = &lastName.String
 	}
 
+	if emailVerifiedAt.Valid {
+		resp.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+
 	return resp
 }
+
+// SessionResponse represents a device the user is signed in on
+type SessionResponse struct {
+	ID         string    `json:"id" example:"0b9d3c6e-5f1a-4c2e-9a7b-8d4e2f6a1c3b"`
+	UserAgent  string    `json:"user_agent,omitempty" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) Safari/605.1.15"`
+	IPAddress  string    `json:"ip_address,omitempty" example:"203.0.113.7"`
+	Current    bool      `json:"current" example:"true"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	LastSeenAt time.Time `json:"last_seen_at" example:"2024-01-02T15:30:00Z"`
+}
+
+// ToSessionResponse converts a db.UserSession to SessionResponse. The session
+// is marked current when its ID is currentSessionID.
+func ToSessionResponse(session db.UserSession, currentSessionID string) SessionResponse {
+	id := uuid.UUID(session.ID.Bytes).String()
+	return SessionResponse{
+		ID:         id,
+		UserAgent:  session.UserAgent.String,
+		IPAddress:  session.IpAddress.String,
+		Current:    id == currentSessionID,
+		CreatedAt:  session.CreatedAt.Time,
+		LastSeenAt: session.LastSeenAt.Time,
+	}
+}
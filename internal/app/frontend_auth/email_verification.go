@@ -0,0 +1,141 @@
+package frontend_auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// VerificationPolicy decides what users who have not verified their email may do
+type VerificationPolicy string
+
+const (
+	// VerificationOptional gives unverified users full access
+	VerificationOptional VerificationPolicy = "optional"
+	// VerificationLimited lets unverified users log in, but only reach routes
+	// that are not wrapped by RequireVerifiedEmail (profile, auth)
+	VerificationLimited VerificationPolicy = "limited"
+	// VerificationRequired rejects logins until the email is verified
+	VerificationRequired VerificationPolicy = "required"
+)
+
+// ParseVerificationPolicy validates a policy name from configuration
+func ParseVerificationPolicy(s string) (VerificationPolicy, error) {
+	switch p := VerificationPolicy(s); p {
+	case VerificationOptional, VerificationLimited, VerificationRequired:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown email verification policy %q (expected optional, limited or required)", s)
+	}
+}
+
+// Verification emails can be re-sent once per cooldown and at most
+// MaxVerificationEmailsPerHour times per hour
+const (
+	VerificationResendCooldown   = time.Minute
+	MaxVerificationEmailsPerHour = 5
+)
+
+// VerificationPolicy returns the configured policy, used by the router to guard routes
+func (s *Service) VerificationPolicy() VerificationPolicy {
+	return s.verificationPolicy
+}
+
+// SendVerificationEmail emails a verification link for the user's current email address.
+// Links sent before are invalidated.
+func (s *Service) SendVerificationEmail(ctx context.Context, user *db.User) error {
+	token, err := s.oneTimeTokens.Issue(ctx, onetimetoken.PurposeEmailVerification, refreshtoken.SubjectUser, uuid.UUID(user.ID.Bytes), s.verificationTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that %s is your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in %s. If you did not create an account, you can ignore this email.\n",
+			user.Username, user.Email, link, s.verificationTokenDuration),
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return errors.Internal("failed to send verification email", err)
+	}
+
+	return nil
+}
+
+// VerifyEmail marks the user's email as verified using a token from SendVerificationEmail
+func (s *Service) VerifyEmail(ctx context.Context, token string) (*db.User, error) {
+	userID, err := s.oneTimeTokens.Consume(ctx, onetimetoken.PurposeEmailVerification, refreshtoken.SubjectUser, token)
+	if err != nil {
+		return nil, err
+	}
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+
+	oldUser, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.Validation("invalid or expired token")
+		}
+		return nil, errors.Internal("failed to verify email", err)
+	}
+
+	user, err := s.queries.MarkUserEmailVerified(ctx, pgUserID)
+	if err != nil {
+		return nil, errors.Internal("failed to verify email", err)
+	}
+
+	// Audit log the verification (the user proved control of the email, so is the actor)
+	s.auditService.LogUpdate(audit.WithUserID(ctx, userID), "users", userID, oldUser, user)
+
+	return &user, nil
+}
+
+// ResendVerificationEmail sends a new verification link to an unverified account.
+// Unknown and already verified emails succeed without sending anything.
+func (s *Service) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := s.queries.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return errors.Internal("failed to resend verification email", err)
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		return nil
+	}
+
+	userID := uuid.UUID(user.ID.Bytes)
+	now := time.Now()
+
+	recent, err := s.oneTimeTokens.CountIssuedSince(ctx, onetimetoken.PurposeEmailVerification, refreshtoken.SubjectUser, userID, now.Add(-VerificationResendCooldown))
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return errors.RateLimited("a verification email was sent recently, please wait before requesting another")
+	}
+
+	hourly, err := s.oneTimeTokens.CountIssuedSince(ctx, onetimetoken.PurposeEmailVerification, refreshtoken.SubjectUser, userID, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if hourly >= MaxVerificationEmailsPerHour {
+		return errors.RateLimited("too many verification emails requested, please try again later")
+	}
+
+	return s.SendVerificationEmail(ctx, &user)
+}
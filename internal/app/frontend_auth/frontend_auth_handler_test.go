@@ -2,11 +2,13 @@ package frontend_auth
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/user/coc/internal/ctxkeys"
 	"github.com/user/coc/internal/validation"
 )
 
@@ -132,3 +134,210 @@ func TestHandler_Register_ValidRequestStructure(t *testing.T) {
 	// If we get here without panic, something is wrong
 	t.Error("expected panic due to nil service")
 }
+
+func TestHandler_Refresh_InvalidJSON(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader("invalid json"))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.Refresh(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_Refresh_MissingToken(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/refresh", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.Refresh(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_Logout_NotAuthenticated(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/logout", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Logout(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestHandler_LogoutAll_NotAuthenticated(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/logout-all", nil)
+	rec := httptest.NewRecorder()
+
+	handler.LogoutAll(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ForgotPassword_InvalidEmail(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/password/forgot", bytes.NewBufferString(`{"email": "not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ForgotPassword(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ResetPassword_ValidationError(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/password/reset", bytes.NewBufferString(`{"token": "abc", "password": "123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ResetPassword(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_VerifyEmail_MissingToken(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/email/verify", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.VerifyEmail(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ResendVerificationEmail_InvalidEmail(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/email/resend", bytes.NewBufferString(`{"email": "not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ResendVerificationEmail(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_RequestMagicLink_InvalidEmail(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/magic-link", bytes.NewBufferString(`{"email": "not-an-email"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.RequestMagicLink(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_VerifyMagicLink_MissingToken(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("POST", "/auth/magic-link/verify", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.VerifyMagicLink(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ChangePassword_NotAuthenticated(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("PUT", "/user/password", bytes.NewBufferString(`{"current_password": "SecurePass123", "new_password": "NewSecurePass123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ChangePassword(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ChangePassword_WeakPassword(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("PUT", "/user/password", bytes.NewBufferString(`{"current_password": "SecurePass123", "new_password": "alllowercase"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.UserIDContextKey, "550e8400-e29b-41d4-a716-446655440000"))
+	rec := httptest.NewRecorder()
+
+	handler.ChangePassword(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandler_DeleteAccount_NotAuthenticated(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("DELETE", "/user", nil)
+	rec := httptest.NewRecorder()
+
+	handler.DeleteAccount(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ListSessions_NotAuthenticated(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("GET", "/user/sessions", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListSessions(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestHandler_RevokeSession_MissingSessionID(t *testing.T) {
+	handler := NewHandler(nil, validation.New())
+
+	req := httptest.NewRequest("DELETE", "/user/sessions/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.UserIDContextKey, "550e8400-e29b-41d4-a716-446655440000"))
+	rec := httptest.NewRecorder()
+
+	handler.RevokeSession(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+}
@@ -4,14 +4,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/signing"
 )
 
 func TestService_GenerateToken(t *testing.T) {
 	service := &Service{
-		jwtSecret:           "test-secret",
+		keys:                signing.NewHMACKeySet("test-secret"),
 		bearerTokenDuration: time.Hour,
 	}
 
@@ -22,7 +24,7 @@ func TestService_GenerateToken(t *testing.T) {
 		Username: "testuser",
 	}
 
-	token, err := service.GenerateToken(user)
+	token, err := service.GenerateToken(user, uuid.New())
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -48,11 +50,27 @@ func TestService_GenerateToken(t *testing.T) {
 	if claims.Username != "testuser" {
 		t.Errorf("expected username testuser, got %s", claims.Username)
 	}
+
+	if _, err := uuid.Parse(claims.ID); err != nil {
+		t.Errorf("expected jti to be a UUID, got %q", claims.ID)
+	}
+
+	if claims.SessionID == "" {
+		t.Error("expected session ID in token")
+	}
+
+	if claims.Impersonated() {
+		t.Error("expected regular token not to be marked as impersonated")
+	}
+
+	if claims.Subject != userID.String() {
+		t.Errorf("expected subject %s, got %s", userID.String(), claims.Subject)
+	}
 }
 
 func TestService_ValidateToken_InvalidToken(t *testing.T) {
 	service := &Service{
-		jwtSecret: "test-secret",
+		keys: signing.NewHMACKeySet("test-secret"),
 	}
 
 	_, err := service.ValidateToken("invalid.token.here")
@@ -63,12 +81,12 @@ func TestService_ValidateToken_InvalidToken(t *testing.T) {
 
 func TestService_ValidateToken_WrongSecret(t *testing.T) {
 	service1 := &Service{
-		jwtSecret:           "secret1",
+		keys:                signing.NewHMACKeySet("secret1"),
 		bearerTokenDuration: time.Hour,
 	}
 
 	service2 := &Service{
-		jwtSecret: "secret2", // Different secret
+		keys: signing.NewHMACKeySet("secret2"), // Different secret
 	}
 
 	user := &db.User{
@@ -77,7 +95,7 @@ func TestService_ValidateToken_WrongSecret(t *testing.T) {
 		Username: "testuser",
 	}
 
-	token, err := service1.GenerateToken(user)
+	token, err := service1.GenerateToken(user, uuid.New())
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -90,7 +108,7 @@ func TestService_ValidateToken_WrongSecret(t *testing.T) {
 
 func TestService_ValidateToken_ExpiredToken(t *testing.T) {
 	service := &Service{
-		jwtSecret:           "test-secret",
+		keys:                signing.NewHMACKeySet("test-secret"),
 		bearerTokenDuration: -time.Hour, // Already expired
 	}
 
@@ -100,7 +118,7 @@ func TestService_ValidateToken_ExpiredToken(t *testing.T) {
 		Username: "testuser",
 	}
 
-	token, err := service.GenerateToken(user)
+	token, err := service.GenerateToken(user, uuid.New())
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -111,6 +129,58 @@ func TestService_ValidateToken_ExpiredToken(t *testing.T) {
 	}
 }
 
+func TestService_ValidateToken_AdminAudience(t *testing.T) {
+	service := &Service{
+		keys: signing.NewHMACKeySet("shared-secret"),
+	}
+
+	userID := uuid.New().String()
+	claims := &CustomClaims{
+		UserID: userID,
+		Email:  "test@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "coc-admin-api",
+			Audience:  jwt.ClaimStrings{"coc-admin"},
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	token, err := service.keys.Sign(claims)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	if _, err := service.ValidateToken(token); err == nil {
+		t.Fatal("expected error for token issued for the admin API, got nil")
+	}
+}
+
+func TestService_ValidateToken_SubjectMismatch(t *testing.T) {
+	service := &Service{
+		keys: signing.NewHMACKeySet("test-secret"),
+	}
+
+	claims := &CustomClaims{
+		UserID: uuid.New().String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    TokenIssuer,
+			Audience:  jwt.ClaimStrings{TokenAudience},
+			Subject:   uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	token, err := service.keys.Sign(claims)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	if _, err := service.ValidateToken(token); err == nil {
+		t.Fatal("expected error for token whose subject does not match the user ID, got nil")
+	}
+}
+
 func TestToUserResponse(t *testing.T) {
 	userID := uuid.New()
 	now := time.Now()
@@ -123,6 +193,7 @@ func TestToUserResponse(t *testing.T) {
 		pgtype.Text{String: "Doe", Valid: true},
 		pgtype.Timestamptz{Time: now, Valid: true},
 		pgtype.Timestamptz{Time: now, Valid: true},
+		pgtype.Timestamptz{Time: now, Valid: true},
 	)
 
 	if userResp.ID != userID.String() {
@@ -144,6 +215,10 @@ func TestToUserResponse(t *testing.T) {
 	if userResp.LastName == nil || *userResp.LastName != "Doe" {
 		t.Errorf("expected last name Doe, got %v", userResp.LastName)
 	}
+
+	if userResp.EmailVerifiedAt == nil || !userResp.EmailVerifiedAt.Equal(now) {
+		t.Errorf("expected email verified at %v, got %v", now, userResp.EmailVerifiedAt)
+	}
 }
 
 func TestToUserResponse_InvalidUUID(t *testing.T) {
@@ -155,6 +230,7 @@ func TestToUserResponse_InvalidUUID(t *testing.T) {
 		pgtype.Text{Valid: false},
 		pgtype.Timestamptz{Valid: false},
 		pgtype.Timestamptz{Valid: false},
+		pgtype.Timestamptz{Valid: false},
 	)
 
 	if userResp.ID != "" {
@@ -168,4 +244,24 @@ func TestToUserResponse_InvalidUUID(t *testing.T) {
 	if userResp.LastName != nil {
 		t.Error("expected nil last name for invalid text")
 	}
+
+	if userResp.EmailVerifiedAt != nil {
+		t.Error("expected nil email verified at for unverified user")
+	}
+}
+
+func TestParseVerificationPolicy(t *testing.T) {
+	for _, name := range []string{"optional", "limited", "required"} {
+		policy, err := ParseVerificationPolicy(name)
+		if err != nil {
+			t.Errorf("expected %q to be valid, got %v", name, err)
+		}
+		if string(policy) != name {
+			t.Errorf("expected policy %q, got %q", name, policy)
+		}
+	}
+
+	if _, err := ParseVerificationPolicy("sometimes"); err == nil {
+		t.Error("expected unknown policy to be rejected")
+	}
 }
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/ctxkeys"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/response"
 	"github.com/user/coc/internal/validation"
@@ -32,6 +34,8 @@ func NewHandler(service *Service, validator *validation.Validator) *Handler {
 // @Success      200 {object} response.JSONResponse{data=LoginResponse} "Login successful"
 // @Failure      400 {object} response.JSONResponse "Invalid request"
 // @Failure      401 {object} response.JSONResponse "Invalid credentials"
+// @Failure      403 {object} response.JSONResponse "Email address not verified (when EMAIL_VERIFICATION_POLICY=required)"
+// @Failure      429 {object} response.JSONResponse "Too many failed login attempts"
 // @Router       /api/v1/auth/login [post]
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
@@ -46,7 +50,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, user, err := h.service.Login(r.Context(), strings.TrimSpace(req.Email), strings.TrimSpace(req.Password))
+	tokens, user, err := h.service.Login(r.Context(), strings.TrimSpace(req.Email), strings.TrimSpace(req.Password))
 	if err != nil {
 		response.HandleServiceError(w, err)
 		return
@@ -59,13 +63,16 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		user.Username,
 		user.FirstName,
 		user.LastName,
+		user.EmailVerifiedAt,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
 
 	loginResp := LoginResponse{
-		Token: token,
-		User:  userResp,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         userResp,
 	}
 
 	response.JSON(w, http.StatusOK, "login successful", loginResp)
@@ -109,10 +116,10 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate token for the new user
-	token, err := h.service.GenerateToken(user)
+	// Start a session for the new user
+	tokens, err := h.service.IssueTokens(r.Context(), user)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "failed to generate token")
+		response.HandleServiceError(w, err)
 		return
 	}
 
@@ -123,18 +130,461 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		user.Username,
 		user.FirstName,
 		user.LastName,
+		user.EmailVerifiedAt,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
 
 	registerResp := LoginResponse{
-		Token: token,
-		User:  userResp,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         userResp,
 	}
 
 	response.JSON(w, http.StatusCreated, "registration successful", registerResp)
 }
 
+// Refresh handles POST /auth/refresh
+// @Summary      Refresh tokens
+// @Description  Exchange a refresh token for a new access token and a rotated refresh token. Reusing a rotated refresh token revokes the whole session.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body RefreshRequest true "Refresh token"
+// @Success      200 {object} response.JSONResponse{data=TokenResponse} "Token refreshed"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Invalid, expired or revoked refresh token"
+// @Router       /api/v1/auth/refresh [post]
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), strings.TrimSpace(req.RefreshToken))
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "token refreshed", TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+// Logout handles POST /auth/logout
+// @Summary      Logout
+// @Description  Revoke the current access token and its refresh token
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse "Logged out"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/v1/auth/logout [post]
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(ctxkeys.UserClaimsContextKey).(*CustomClaims)
+	if !ok || claims == nil {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	if err := h.service.Logout(r.Context(), claims); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "logged out", nil)
+}
+
+// LogoutAll handles POST /auth/logout-all
+// @Summary      Logout everywhere
+// @Description  Revoke every access and refresh token of the current user on all devices
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse "Logged out from all sessions"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/v1/auth/logout-all [post]
+func (h *Handler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := ctxkeys.GetUserID(r)
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	if err := h.service.LogoutAll(r.Context(), userID); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "logged out from all sessions", nil)
+}
+
+// DeleteAccount handles DELETE /user
+// @Summary      Delete account
+// @Description  Schedule the current user's account for deletion and sign out every session. Logging in before the grace period ends cancels the deletion; afterwards the account is anonymized.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Success      202 {object} response.JSONResponse{data=AccountDeletionResponse} "Deletion scheduled"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Not allowed while impersonating"
+// @Security     BearerAuth
+// @Router       /api/v1/user [delete]
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := ctxkeys.GetUserID(r)
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	user, err := h.service.DeleteAccount(r.Context(), userID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, "account deletion scheduled", &AccountDeletionResponse{
+		DeletionScheduledAt: user.DeletionScheduledAt.Time,
+	})
+}
+
+// ForgotPassword handles POST /auth/password/forgot
+// @Summary      Forgot password
+// @Description  Email a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ForgotPasswordRequest true "Account email"
+// @Success      200 {object} response.JSONResponse "Reset link sent if the account exists"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Router       /api/v1/auth/password/forgot [post]
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	if err := h.service.ForgotPassword(r.Context(), strings.TrimSpace(req.Email)); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "if an account exists for this email, a password reset link has been sent", nil)
+}
+
+// ResetPassword handles POST /auth/password/reset
+// @Summary      Reset password
+// @Description  Set a new password with the token from the reset email. Signs the user out of every session.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ResetPasswordRequest true "Reset token and new password"
+// @Success      200 {object} response.JSONResponse "Password reset"
+// @Failure      400 {object} response.JSONResponse "Invalid request or invalid, expired or used token"
+// @Router       /api/v1/auth/password/reset [post]
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), strings.TrimSpace(req.Token), req.Password); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "password has been reset", nil)
+}
+
+// ChangePassword handles PUT /user/password
+// @Summary      Change password
+// @Description  Change the current user's password. Requires the current password; recently used passwords are refused. Signs out every other session and returns new tokens for this one.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ChangePasswordRequest true "Current and new password"
+// @Success      200 {object} response.JSONResponse{data=TokenResponse} "Password changed"
+// @Failure      400 {object} response.JSONResponse "Invalid request, wrong current password or recently used password"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      429 {object} response.JSONResponse "Too many failed attempts"
+// @Security     BearerAuth
+// @Router       /api/v1/user/password [put]
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := ctxkeys.GetUserID(r)
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	tokens, err := h.service.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "password changed", &TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+// ListSessions handles GET /user/sessions
+// @Summary      List sessions
+// @Description  List the devices the current user is signed in on, most recently used first. The session of the request is marked as current.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=[]SessionResponse} "Sessions retrieved"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/v1/user/sessions [get]
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(ctxkeys.UserClaimsContextKey).(*CustomClaims)
+	if !ok || claims == nil {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	sessions, err := h.service.ListSessions(r.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "sessions retrieved successfully", sessions)
+}
+
+// RevokeSession handles DELETE /user/sessions/{id}
+// @Summary      Sign out a session
+// @Description  Sign the current user out of one device. Its tokens stop working immediately.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Session ID"
+// @Success      200 {object} response.JSONResponse "Session revoked"
+// @Failure      400 {object} response.JSONResponse "Invalid session ID"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      404 {object} response.JSONResponse "Session not found"
+// @Security     BearerAuth
+// @Router       /api/v1/user/sessions/{id} [delete]
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := ctxkeys.GetUserID(r)
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		response.Error(w, http.StatusBadRequest, "session ID is required")
+		return
+	}
+
+	if err := h.service.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "session revoked", nil)
+}
+
+// VerifyEmail handles POST /auth/email/verify
+// @Summary      Verify email
+// @Description  Mark the user's email address as verified with the token from the verification email
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body VerifyEmailRequest true "Verification token"
+// @Success      200 {object} response.JSONResponse{data=UserResponse} "Email verified"
+// @Failure      400 {object} response.JSONResponse "Invalid request or invalid, expired or used token"
+// @Router       /api/v1/auth/email/verify [post]
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	user, err := h.service.VerifyEmail(r.Context(), strings.TrimSpace(req.Token))
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	userResp := ToUserResponse(
+		user.ID,
+		user.Email,
+		user.Username,
+		user.FirstName,
+		user.LastName,
+		user.EmailVerifiedAt,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+
+	response.JSON(w, http.StatusOK, "email verified", userResp)
+}
+
+// ResendVerificationEmail handles POST /auth/email/resend
+// @Summary      Resend verification email
+// @Description  Send a new verification link to an unverified account. The response is the same whether or not the email is registered.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body ResendVerificationRequest true "Account email"
+// @Success      200 {object} response.JSONResponse "Verification link sent if the account exists and is unverified"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      429 {object} response.JSONResponse "A link was sent too recently"
+// @Router       /api/v1/auth/email/resend [post]
+func (h *Handler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	if err := h.service.ResendVerificationEmail(r.Context(), strings.TrimSpace(req.Email)); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "if an unverified account exists for this email, a verification link has been sent", nil)
+}
+
+// RequestMagicLink handles POST /auth/magic-link
+// @Summary      Request login link
+// @Description  Email a single-use link to log in without a password. The response is the same whether or not the email is registered.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body MagicLinkRequest true "Account email"
+// @Success      200 {object} response.JSONResponse "Login link sent if the account exists"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      429 {object} response.JSONResponse "Too many links requested for this email or from this address"
+// @Router       /api/v1/auth/magic-link [post]
+func (h *Handler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	if err := h.service.RequestMagicLink(r.Context(), strings.TrimSpace(req.Email)); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "if an account exists for this email, a login link has been sent", nil)
+}
+
+// VerifyMagicLink handles POST /auth/magic-link/verify
+// @Summary      Log in with login link
+// @Description  Exchange the token from a login link email for an access/refresh token pair. Marks the email as verified.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        request body VerifyMagicLinkRequest true "Login link token"
+// @Success      200 {object} response.JSONResponse{data=LoginResponse} "Login successful"
+// @Failure      400 {object} response.JSONResponse "Invalid request or invalid, expired or used token"
+// @Router       /api/v1/auth/magic-link/verify [post]
+func (h *Handler) VerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req VerifyMagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	tokens, user, err := h.service.LoginWithMagicLink(r.Context(), strings.TrimSpace(req.Token))
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	userResp := ToUserResponse(
+		user.ID,
+		user.Email,
+		user.Username,
+		user.FirstName,
+		user.LastName,
+		user.EmailVerifiedAt,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+
+	loginResp := LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         userResp,
+	}
+
+	response.JSON(w, http.StatusOK, "login successful", loginResp)
+}
+
 // UserFromContext extracts user from request context (set by auth middleware)
 func UserFromContext(r *http.Request) *db.User {
 	user, ok := r.Context().Value("user").(*db.User)
@@ -0,0 +1,79 @@
+package frontend_auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// TokenActor is the "act" claim (RFC 8693) of an impersonation token: the admin
+// acting as the user the token was issued for
+type TokenActor struct {
+	Subject string `json:"sub"` // admin ID
+}
+
+// Impersonated reports whether the token was issued to an admin acting as the user
+func (c *CustomClaims) Impersonated() bool {
+	return c.Actor != nil
+}
+
+// Impersonate issues a short-lived access token that lets an admin act as the
+// user. It carries the admin in its act claim and comes without a refresh token,
+// so it cannot be renewed. The token is revoked like any other access token,
+// and stops working when the admin is deactivated.
+func (s *Service) Impersonate(ctx context.Context, user *db.User, adminID uuid.UUID) (string, time.Time, error) {
+	userID := uuid.UUID(user.ID.Bytes)
+	now := time.Now()
+	expiresAt := now.Add(s.impersonationTokenDuration)
+
+	claims := &CustomClaims{
+		UserID:       userID.String(),
+		Email:        user.Email,
+		Username:     user.Username,
+		TokenVersion: user.TokenVersion,
+		Actor:        &TokenActor{Subject: adminID.String()},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    TokenIssuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{TokenAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, errors.Internal("failed to generate token", err)
+	}
+
+	// The jti identifies the impersonation in the audit log and can be used to revoke it
+	s.auditService.LogCreate(ctx, "user_impersonations", uuid.MustParse(claims.ID), map[string]interface{}{
+		"user_id":    userID.String(),
+		"admin_id":   adminID.String(),
+		"expires_at": expiresAt,
+	})
+
+	return token, expiresAt, nil
+}
+
+// checkImpersonator ensures the admin behind an impersonation token is still active
+func (s *Service) checkImpersonator(ctx context.Context, actor *TokenActor) error {
+	adminID, err := uuid.Parse(actor.Subject)
+	if err != nil {
+		return errors.Unauthorized("invalid or expired token")
+	}
+
+	// GetAdminByID only returns active admins
+	if _, err := s.queries.GetAdminByID(ctx, pgtype.UUID{Bytes: adminID, Valid: true}); err != nil {
+		return errors.Unauthorized("impersonation has ended")
+	}
+
+	return nil
+}
@@ -2,17 +2,43 @@ package frontend_auth
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/user/coc/internal/accountdeletion"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/lockout"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/passhistory"
+	"github.com/user/coc/internal/refreshtoken"
+	"github.com/user/coc/internal/revocation"
+	"github.com/user/coc/internal/signing"
+	"github.com/user/coc/internal/usersession"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// testConfig returns the service settings used by the integration tests
+func testConfig(policy VerificationPolicy) Config {
+	return Config{
+		AppURL:                     "http://localhost:3000",
+		VerificationPolicy:         policy,
+		BearerTokenDuration:        time.Hour,
+		RefreshTokenDuration:       24 * time.Hour,
+		ResetTokenDuration:         time.Hour,
+		VerificationTokenDuration:  24 * time.Hour,
+		ImpersonationTokenDuration: 15 * time.Minute,
+		MagicLinkDuration:          15 * time.Minute,
+	}
+}
+
 func setupTestDB(t *testing.T) (*pgxpool.Pool, *db.Queries) {
 	t.Helper()
 
@@ -102,18 +128,22 @@ func TestIntegration_Login_Success(t *testing.T) {
 
 	// Create service
 	auditService := audit.NewService(qtx)
-	service := NewService(qtx, auditService, "test-secret", time.Hour)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
 
 	// Test login
-	token, user, err := service.Login(ctx, "test@example.com", "testpassword")
+	tokens, user, err := service.Login(ctx, "test@example.com", "testpassword")
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
 
-	if token == "" {
+	if tokens.AccessToken == "" {
 		t.Error("expected non-empty token")
 	}
 
+	if tokens.RefreshToken == "" {
+		t.Error("expected non-empty refresh token")
+	}
+
 	if user.Email != "test@example.com" {
 		t.Errorf("expected email test@example.com, got %s", user.Email)
 	}
@@ -123,7 +153,7 @@ func TestIntegration_Login_Success(t *testing.T) {
 	}
 
 	// Verify token is valid
-	claims, err := service.ValidateToken(token)
+	claims, err := service.ValidateToken(tokens.AccessToken)
 	if err != nil {
 		t.Fatalf("ValidateToken failed: %v", err)
 	}
@@ -150,7 +180,7 @@ func TestIntegration_Login_InvalidCredentials(t *testing.T) {
 
 	// Create service
 	auditService := audit.NewService(qtx)
-	service := NewService(qtx, auditService, "test-secret", time.Hour)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
 
 	// Test login with non-existent user
 	_, _, err = service.Login(ctx, "nonexistent@example.com", "password")
@@ -197,7 +227,7 @@ func TestIntegration_Register_Success(t *testing.T) {
 
 	// Create service
 	auditService := audit.NewService(qtx)
-	service := NewService(qtx, auditService, "test-secret", time.Hour)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
 
 	// Test registration
 	user, err := service.Register(ctx, "newuser@example.com", "newuser", "password123", "Jane", "Smith")
@@ -269,7 +299,7 @@ func TestIntegration_Register_DuplicateEmail(t *testing.T) {
 
 	// Create service
 	auditService := audit.NewService(qtx)
-	service := NewService(qtx, auditService, "test-secret", time.Hour)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
 
 	// Try to register with same email
 	_, err = service.Register(ctx, "existing@example.com", "newuser", "password123", "John", "Doe")
@@ -310,7 +340,7 @@ func TestIntegration_Register_DuplicateUsername(t *testing.T) {
 
 	// Create service
 	auditService := audit.NewService(qtx)
-	service := NewService(qtx, auditService, "test-secret", time.Hour)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
 
 	// Try to register with same username
 	_, err = service.Register(ctx, "new@example.com", "existinguser", "password123", "John", "Doe")
@@ -361,10 +391,10 @@ func TestIntegration_TokenGenerationAndValidation(t *testing.T) {
 
 	// Create service
 	auditService := audit.NewService(qtx)
-	service := NewService(qtx, auditService, "test-secret", time.Hour)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
 
 	// Generate token
-	token, err := service.GenerateToken(&testUser)
+	token, err := service.GenerateToken(&testUser, uuid.New())
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -388,3 +418,875 @@ func TestIntegration_TokenGenerationAndValidation(t *testing.T) {
 		t.Errorf("expected username testuser, got %s", claims.Username)
 	}
 }
+
+func TestIntegration_Refresh_RotationAndReuse(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	_, err = qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "refresh@example.com",
+		Username:     "refreshuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	login, _, err := service.Login(ctx, "refresh@example.com", "password")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// First refresh rotates the token
+	rotated, err := service.Refresh(ctx, login.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	if rotated.RefreshToken == login.RefreshToken {
+		t.Error("expected refresh token to be rotated")
+	}
+
+	// Reusing the original token is rejected and revokes the family
+	if _, err := service.Refresh(ctx, login.RefreshToken); err == nil {
+		t.Fatal("expected reuse of rotated refresh token to fail")
+	}
+
+	// The newest token in the family is now revoked as well
+	if _, err := service.Refresh(ctx, rotated.RefreshToken); err == nil {
+		t.Error("expected token family to be revoked after reuse")
+	}
+}
+
+// recordingMailer keeps sent messages so tests can read the links in them
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestIntegration_PasswordReset(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	_, err = qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "reset@example.com",
+		Username:     "resetuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	mail := &recordingMailer{}
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mail, signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	login, _, err := service.Login(ctx, "reset@example.com", "password")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// Unknown emails succeed without sending anything
+	if err := service.ForgotPassword(ctx, "nobody@example.com"); err != nil {
+		t.Fatalf("ForgotPassword failed for unknown email: %v", err)
+	}
+	if len(mail.sent) != 0 {
+		t.Fatalf("expected no email for unknown account, got %d", len(mail.sent))
+	}
+
+	if err := service.ForgotPassword(ctx, "reset@example.com"); err != nil {
+		t.Fatalf("ForgotPassword failed: %v", err)
+	}
+	if len(mail.sent) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(mail.sent))
+	}
+
+	_, token, found := strings.Cut(mail.sent[0].Body, "/reset-password?token=")
+	if !found {
+		t.Fatalf("expected reset link in email, got: %s", mail.sent[0].Body)
+	}
+	token = strings.Fields(token)[0]
+
+	if err := service.ResetPassword(ctx, token, "new-password"); err != nil {
+		t.Fatalf("ResetPassword failed: %v", err)
+	}
+
+	// The token is single-use
+	if err := service.ResetPassword(ctx, token, "another-password"); err == nil {
+		t.Error("expected used reset token to be rejected")
+	}
+
+	// Existing sessions are revoked and only the new password works
+	if _, _, err := service.Authenticate(ctx, login.AccessToken); err == nil {
+		t.Error("expected access token to be revoked after password reset")
+	}
+	if _, err := service.Refresh(ctx, login.RefreshToken); err == nil {
+		t.Error("expected refresh token to be revoked after password reset")
+	}
+	if _, _, err := service.Login(ctx, "reset@example.com", "password"); err == nil {
+		t.Error("expected old password to be rejected")
+	}
+	if _, _, err := service.Login(ctx, "reset@example.com", "new-password"); err != nil {
+		t.Errorf("expected login with new password, got %v", err)
+	}
+}
+
+func TestIntegration_EmailVerification(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	mail := &recordingMailer{}
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mail, signing.NewHMACKeySet("test-secret"), testConfig(VerificationRequired))
+
+	user, err := service.Register(ctx, "verify@example.com", "verifyuser", "password", "", "")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if user.EmailVerifiedAt.Valid {
+		t.Fatal("expected new user to be unverified")
+	}
+	if len(mail.sent) != 1 {
+		t.Fatalf("expected verification email on registration, got %d emails", len(mail.sent))
+	}
+
+	// The required policy rejects logins until the email is verified
+	if _, _, err := service.Login(ctx, "verify@example.com", "password"); err == nil {
+		t.Error("expected login of unverified user to be rejected")
+	}
+
+	// Re-sending right after registration is throttled
+	if err := service.ResendVerificationEmail(ctx, "verify@example.com"); err == nil {
+		t.Error("expected resend within the cooldown to be rejected")
+	}
+
+	_, token, found := strings.Cut(mail.sent[0].Body, "/verify-email?token=")
+	if !found {
+		t.Fatalf("expected verification link in email, got: %s", mail.sent[0].Body)
+	}
+	token = strings.Fields(token)[0]
+
+	verified, err := service.VerifyEmail(ctx, token)
+	if err != nil {
+		t.Fatalf("VerifyEmail failed: %v", err)
+	}
+	if !verified.EmailVerifiedAt.Valid {
+		t.Error("expected email_verified_at to be set")
+	}
+
+	// The token is single-use
+	if _, err := service.VerifyEmail(ctx, token); err == nil {
+		t.Error("expected used verification token to be rejected")
+	}
+
+	if _, _, err := service.Login(ctx, "verify@example.com", "password"); err != nil {
+		t.Errorf("expected login after verification, got %v", err)
+	}
+
+	// Verified accounts are not sent another link
+	if err := service.ResendVerificationEmail(ctx, "verify@example.com"); err != nil {
+		t.Errorf("ResendVerificationEmail failed for verified user: %v", err)
+	}
+	if len(mail.sent) != 1 {
+		t.Errorf("expected no email for verified account, got %d emails", len(mail.sent))
+	}
+}
+
+func TestIntegration_Login_Lockout(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := audit.WithIPAddress(context.Background(), "203.0.113.7")
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	_, err = qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "lockout@example.com",
+		Username:     "lockoutuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	auditService := audit.NewService(qtx)
+	logins := lockout.NewGuard(qtx, auditService, lockout.Policy{
+		MaxAccountAttempts: 3,
+		Window:             15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+	})
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), logins, passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	// A success in between resets the counter
+	for _, password := range []string{"wrong", "wrong", "password"} {
+		service.Login(ctx, "lockout@example.com", password)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _, err := service.Login(ctx, "LOCKOUT@example.com", "wrong")
+		if appErr, ok := err.(*errors.DomainError); !ok || appErr.Code != errors.CodeUnauthorized {
+			t.Fatalf("attempt %d: expected unauthorized, got %v", i+1, err)
+		}
+	}
+
+	// Locked: even the right password is refused
+	_, _, err = service.Login(ctx, "lockout@example.com", "password")
+	if appErr, ok := err.(*errors.DomainError); !ok || appErr.Code != errors.CodeRateLimited {
+		t.Fatalf("expected rate limited while locked, got %v", err)
+	}
+
+	if err := logins.Unlock(ctx, refreshtoken.SubjectUser, "lockout@example.com"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+
+	if _, _, err := service.Login(ctx, "lockout@example.com", "password"); err != nil {
+		t.Errorf("expected login after unlock, got %v", err)
+	}
+}
+
+func TestIntegration_Login_RehashesLegacyPassword(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	// A user created before argon2id became the default
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	created, err := qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "legacy@example.com",
+		Username:     "legacyuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	if _, _, err := service.Login(ctx, "legacy@example.com", "password"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	user, err := qtx.GetUserByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if !strings.HasPrefix(user.PasswordHash, "$argon2id$") {
+		t.Errorf("expected password to be rehashed with argon2id, got %q", user.PasswordHash[:7])
+	}
+	if user.TokenVersion != created.TokenVersion {
+		t.Errorf("rehash should not sign out other sessions")
+	}
+
+	// The new hash works for the next login
+	if _, _, err := service.Login(ctx, "legacy@example.com", "password"); err != nil {
+		t.Errorf("Login with rehashed password failed: %v", err)
+	}
+}
+
+func TestIntegration_ChangePassword(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("SecurePass123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	created, err := qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "change@example.com",
+		Username:     "changeuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	userID := uuid.UUID(created.ID.Bytes).String()
+
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 2), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	other, _, err := service.Login(ctx, "change@example.com", "SecurePass123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	rejected := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+	}{
+		{"wrong current password", "WrongPass123", "NewSecurePass123"},
+		{"same as current password", "SecurePass123", "SecurePass123"},
+	}
+	for _, tc := range rejected {
+		_, err := service.ChangePassword(ctx, userID, tc.currentPassword, tc.newPassword)
+		if appErr, ok := err.(*errors.DomainError); !ok || appErr.Code != errors.CodeValidation {
+			t.Errorf("%s: expected validation error, got %v", tc.name, err)
+		}
+	}
+
+	tokens, err := service.ChangePassword(ctx, userID, "SecurePass123", "NewSecurePass123")
+	if err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+
+	// Other sessions are signed out; the returned tokens keep the caller signed in
+	if _, _, err := service.Authenticate(ctx, other.AccessToken); err == nil {
+		t.Error("expected other access token to be revoked")
+	}
+	if _, err := service.Refresh(ctx, other.RefreshToken); err == nil {
+		t.Error("expected other refresh token to be revoked")
+	}
+	if _, _, err := service.Authenticate(ctx, tokens.AccessToken); err != nil {
+		t.Errorf("expected new access token to be valid, got %v", err)
+	}
+
+	// Going back to a recent password is refused
+	_, err = service.ChangePassword(ctx, userID, "NewSecurePass123", "SecurePass123")
+	if appErr, ok := err.(*errors.DomainError); !ok || appErr.Code != errors.CodeValidation {
+		t.Errorf("expected recently used password to be rejected, got %v", err)
+	}
+}
+
+func TestIntegration_Impersonate(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	user, err := qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "impersonated@example.com",
+		Username:     "impersonated",
+		PasswordHash: "unused",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	admin, err := qtx.CreateAdmin(ctx, db.CreateAdminParams{
+		Email:        "support@example.com",
+		Username:     "support",
+		PasswordHash: "unused",
+		Role:         "super_admin",
+		IsActive:     true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create test admin: %v", err)
+	}
+	adminID := uuid.UUID(admin.ID.Bytes)
+
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	token, expiresAt, err := service.Impersonate(audit.WithAdminID(ctx, adminID), &user, adminID)
+	if err != nil {
+		t.Fatalf("Impersonate failed: %v", err)
+	}
+	if time.Until(expiresAt) > 15*time.Minute {
+		t.Errorf("expected a short-lived token, expires at %v", expiresAt)
+	}
+
+	authenticated, claims, err := service.Authenticate(ctx, token)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if authenticated.ID != user.ID || !claims.Impersonated() || claims.Actor.Subject != adminID.String() {
+		t.Errorf("expected token for the user acting as the admin, got %+v", claims)
+	}
+	if claims.SessionID != "" {
+		t.Error("expected impersonation token to have no refresh session")
+	}
+
+	// Deactivating the admin ends the impersonation
+	_, err = qtx.UpdateAdmin(ctx, db.UpdateAdminParams{
+		ID:           admin.ID,
+		Email:        admin.Email,
+		Username:     admin.Username,
+		PasswordHash: admin.PasswordHash,
+		Role:         admin.Role,
+		IsActive:     false,
+	})
+	if err != nil {
+		t.Fatalf("failed to deactivate admin: %v", err)
+	}
+	if _, _, err := service.Authenticate(ctx, token); err == nil {
+		t.Error("expected token of a deactivated admin to be rejected")
+	}
+}
+
+func TestIntegration_Sessions(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("SecurePass123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	created, err := qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "sessions@example.com",
+		Username:     "sessionsuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	userID := uuid.UUID(created.ID.Bytes).String()
+
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	// Log in from two devices
+	laptop, _, err := service.Login(audit.WithUserAgent(audit.WithIPAddress(ctx, "203.0.113.7"), "laptop"), "sessions@example.com", "SecurePass123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	phone, _, err := service.Login(audit.WithUserAgent(ctx, "phone"), "sessions@example.com", "SecurePass123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	_, laptopClaims, err := service.Authenticate(ctx, laptop.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	_, phoneClaims, err := service.Authenticate(ctx, phone.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	sessions, err := service.ListSessions(ctx, userID, laptopClaims.SessionID)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.ID == laptopClaims.SessionID) {
+			t.Errorf("expected only the laptop session to be current, got %+v", session)
+		}
+		if session.ID == laptopClaims.SessionID && (session.IPAddress != "203.0.113.7" || session.UserAgent != "laptop") {
+			t.Errorf("expected device of the login to be recorded, got %+v", session)
+		}
+	}
+
+	// Signing out the phone from the laptop ends its tokens right away
+	if err := service.RevokeSession(ctx, userID, phoneClaims.SessionID); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+	if _, _, err := service.Authenticate(ctx, phone.AccessToken); err == nil {
+		t.Error("expected access token of the revoked session to be rejected")
+	}
+	if _, err := service.Refresh(ctx, phone.RefreshToken); err == nil {
+		t.Error("expected refresh token of the revoked session to be rejected")
+	}
+	if _, _, err := service.Authenticate(ctx, laptop.AccessToken); err != nil {
+		t.Errorf("expected other session to stay signed in, got %v", err)
+	}
+
+	sessions, err = service.ListSessions(ctx, userID, laptopClaims.SessionID)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != laptopClaims.SessionID {
+		t.Errorf("expected only the laptop session to remain, got %+v", sessions)
+	}
+
+	// Sessions of other users are not found
+	err = service.RevokeSession(ctx, uuid.NewString(), laptopClaims.SessionID)
+	if appErr, ok := err.(*errors.DomainError); !ok || appErr.Code != errors.CodeNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestIntegration_SecurityEvents(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("SecurePass123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	created, err := qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "events@example.com",
+		Username:     "eventsuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mailer.NewLogMailer("no-reply@example.com"), signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	deviceCtx := audit.WithUserAgent(audit.WithIPAddress(ctx, "203.0.113.9"), "laptop")
+	if _, _, err := service.Login(deviceCtx, "events@example.com", "WrongPass123"); err == nil {
+		t.Fatal("expected login with wrong password to fail")
+	}
+	tokens, _, err := service.Login(deviceCtx, "events@example.com", "SecurePass123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := service.Refresh(deviceCtx, tokens.RefreshToken); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	events, err := qtx.ListRecentSecurityEventsByActor(ctx, db.ListRecentSecurityEventsByActorParams{
+		ActorType:  db.AuditActorTypeUser,
+		ActorID:    created.ID,
+		EventTypes: []string{string(audit.EventLogin), string(audit.EventTokenRefresh)},
+		Since:      pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+		Limit:      10,
+	})
+	if err != nil {
+		t.Fatalf("ListRecentSecurityEventsByActor failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	// Events are listed newest first; created_at is the same within a transaction
+	outcomes := map[string]int{}
+	for _, event := range events {
+		outcomes[event.EventType+":"+event.Outcome]++
+		if event.IpAddress.String != "203.0.113.9" || event.UserAgent.String != "laptop" {
+			t.Errorf("expected client of the request to be recorded, got %+v", event)
+		}
+		if event.Outcome == string(audit.OutcomeFailure) && event.Reason.String != "invalid_password" {
+			t.Errorf("expected invalid_password reason, got %q", event.Reason.String)
+		}
+	}
+	if outcomes["login:failure"] != 1 || outcomes["login:success"] != 1 || outcomes["token_refresh:success"] != 1 {
+		t.Errorf("unexpected events: %v", outcomes)
+	}
+}
+
+func TestIntegration_MagicLink(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := audit.WithIPAddress(context.Background(), "203.0.113.8")
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	_, err = qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "magic@example.com",
+		Username:     "magicuser",
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	mail := &recordingMailer{}
+	auditService := audit.NewService(qtx)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), accountdeletion.NewService(qtx, auditService, 0), mail, signing.NewHMACKeySet("test-secret"), testConfig(VerificationRequired))
+
+	// Unknown emails succeed without sending anything
+	if err := service.RequestMagicLink(ctx, "nobody@example.com"); err != nil {
+		t.Fatalf("RequestMagicLink failed for unknown email: %v", err)
+	}
+	if len(mail.sent) != 0 {
+		t.Fatalf("expected no email for unknown account, got %d", len(mail.sent))
+	}
+
+	// The account is found whatever the case of the email
+	if err := service.RequestMagicLink(ctx, " Magic@Example.com"); err != nil {
+		t.Fatalf("RequestMagicLink failed: %v", err)
+	}
+	if len(mail.sent) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(mail.sent))
+	}
+
+	// Requesting again within the cooldown is throttled, whatever the case of the email
+	if err := service.RequestMagicLink(ctx, "magic@example.com"); err == nil {
+		t.Error("expected request within the cooldown to be rejected")
+	}
+
+	_, token, found := strings.Cut(mail.sent[0].Body, "/magic-link?token=")
+	if !found {
+		t.Fatalf("expected login link in email, got: %s", mail.sent[0].Body)
+	}
+	token = strings.Fields(token)[0]
+
+	// The link logs in even under the required policy, since it proves control of the email
+	tokens, user, err := service.LoginWithMagicLink(ctx, token)
+	if err != nil {
+		t.Fatalf("LoginWithMagicLink failed: %v", err)
+	}
+	if !user.EmailVerifiedAt.Valid {
+		t.Error("expected email to be verified by the login link")
+	}
+	if _, _, err := service.Authenticate(ctx, tokens.AccessToken); err != nil {
+		t.Errorf("expected access token to authenticate, got %v", err)
+	}
+
+	// The token is single-use
+	if _, _, err := service.LoginWithMagicLink(ctx, token); err == nil {
+		t.Error("expected used login link to be rejected")
+	}
+}
+
+func TestIntegration_AccountDeletion(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Use transaction for queries
+	qtx := queries.WithTx(tx)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	created, err := qtx.CreateUser(ctx, db.CreateUserParams{
+		Email:        "deleteme@example.com",
+		Username:     "deleteme",
+		PasswordHash: string(hashedPassword),
+		FirstName:    pgtype.Text{String: "Delete", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	userID := uuid.UUID(created.ID.Bytes)
+
+	mail := &recordingMailer{}
+	auditService := audit.NewService(qtx)
+	deletions := accountdeletion.NewService(qtx, auditService, 0)
+	service := NewService(tx, auditService, refreshtoken.NewStore(tx), usersession.NewStore(qtx, auditService), revocation.NewDenylist(qtx), onetimetoken.NewStore(qtx), lockout.NewGuard(qtx, auditService, lockout.Policy{}), passhistory.NewStore(qtx, 0), deletions, mail, signing.NewHMACKeySet("test-secret"), testConfig(VerificationLimited))
+
+	tokens, _, err := service.Login(ctx, "deleteme@example.com", "password")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	scheduled, err := service.DeleteAccount(ctx, userID.String())
+	if err != nil {
+		t.Fatalf("DeleteAccount failed: %v", err)
+	}
+	if !scheduled.DeletionScheduledAt.Valid {
+		t.Fatal("expected deletion to be scheduled")
+	}
+	if len(mail.sent) != 1 {
+		t.Errorf("expected 1 deletion notice, got %d", len(mail.sent))
+	}
+
+	// Requesting deletion signs the user out everywhere
+	if _, _, err := service.Authenticate(ctx, tokens.AccessToken); err == nil {
+		t.Error("expected access token to be revoked")
+	}
+	if _, err := service.Refresh(ctx, tokens.RefreshToken); err == nil {
+		t.Error("expected refresh token to be revoked")
+	}
+
+	// Logging in during the grace period cancels the deletion
+	_, user, err := service.Login(ctx, "deleteme@example.com", "password")
+	if err != nil {
+		t.Fatalf("Login during grace period failed: %v", err)
+	}
+	if user.DeletionScheduledAt.Valid {
+		t.Error("expected login to cancel the deletion")
+	}
+
+	if _, err := service.DeleteAccount(ctx, userID.String()); err != nil {
+		t.Fatalf("DeleteAccount failed: %v", err)
+	}
+
+	// A completed export holds the personal data until its retention ends
+	export, err := qtx.CreateDataExport(ctx, db.CreateDataExportParams{
+		UserID:          created.ID,
+		RequestedByType: db.AuditActorTypeUser,
+		RequestedByID:   created.ID,
+	})
+	if err != nil {
+		t.Fatalf("failed to create data export: %v", err)
+	}
+	if err := qtx.CreateDataExportArchive(ctx, db.CreateDataExportArchiveParams{ExportID: export.ID, Content: []byte("deleteme@example.com")}); err != nil {
+		t.Fatalf("failed to create data export archive: %v", err)
+	}
+
+	// With no grace period the account is due right away
+	deleted, err := deletions.AnonymizeDue(ctx)
+	if err != nil {
+		t.Fatalf("AnonymizeDue failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 anonymized account, got %d", deleted)
+	}
+
+	anonymized, err := qtx.GetUserByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("expected anonymized user to be kept: %v", err)
+	}
+	if !anonymized.AnonymizedAt.Valid || anonymized.FirstName.Valid || strings.Contains(anonymized.Email, "deleteme") {
+		t.Errorf("expected personal data to be removed, got %+v", anonymized)
+	}
+
+	// Earlier audit entries no longer hold the email either
+	logs, err := qtx.ListAuditLogsByEntity(ctx, db.ListAuditLogsByEntityParams{
+		EntityType: "users",
+		EntityID:   created.ID,
+		Limit:      50,
+	})
+	if err != nil {
+		t.Fatalf("failed to get audit logs: %v", err)
+	}
+	for _, log := range logs {
+		if strings.Contains(string(log.OldData), "deleteme@example.com") || strings.Contains(string(log.NewData), "deleteme@example.com") {
+			t.Errorf("expected audit log %s to be anonymized", log.Action)
+		}
+	}
+
+	// Data exports and their archives are deleted with it
+	if _, err := qtx.GetDataExportForUser(ctx, db.GetDataExportForUserParams{ID: export.ID, UserID: created.ID}); err != pgx.ErrNoRows {
+		t.Errorf("expected data export to be deleted, got %v", err)
+	}
+	if _, err := qtx.GetDataExportArchive(ctx, export.ID); err != pgx.ErrNoRows {
+		t.Errorf("expected data export archive to be deleted, got %v", err)
+	}
+
+	// The old credentials are gone
+	if _, _, err := service.Login(ctx, "deleteme@example.com", "password"); err == nil {
+		t.Error("expected login to an anonymized account to fail")
+	}
+}
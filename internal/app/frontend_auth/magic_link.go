@@ -0,0 +1,201 @@
+package frontend_auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// Login links can be requested once per cooldown for an email, and within
+// MagicLinkWindow at most MaxMagicLinksPerEmail times for an email and
+// MaxMagicLinksPerIP times from one client IP address
+const (
+	MagicLinkCooldown     = time.Minute
+	MagicLinkWindow       = time.Hour
+	MaxMagicLinksPerEmail = 5
+	MaxMagicLinksPerIP    = 20
+)
+
+// RequestMagicLink emails a single-use login link when an account exists for email.
+// It succeeds either way so the response does not reveal which emails are registered;
+// requests are throttled before the account is looked up for the same reason.
+// Emails are matched lowercased, so changing the case does not get around the limits.
+func (s *Service) RequestMagicLink(ctx context.Context, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if err := s.throttleMagicLink(ctx, email); err != nil {
+		s.logEvent(ctx, audit.EventMagicLinkRequest, uuid.Nil, email, "throttled")
+		return err
+	}
+
+	user, err := s.queries.GetUserByNormalizedEmail(ctx, email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.logEvent(ctx, audit.EventMagicLinkRequest, uuid.Nil, email, "unknown_account")
+			return nil
+		}
+		return errors.Internal("failed to request login link", err)
+	}
+	userID := uuid.UUID(user.ID.Bytes)
+
+	token, err := s.oneTimeTokens.Issue(ctx, onetimetoken.PurposeMagicLink, refreshtoken.SubjectUser, userID, s.magicLinkDuration)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/magic-link?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to log in without a password:\n\n%s\n\n"+
+			"The link expires in %s and can only be used once. If you did not request this, you can ignore this email.\n",
+			user.Username, link, s.magicLinkDuration),
+	}
+
+	// Delivery failures are logged rather than returned, for the same reason unknown emails succeed
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		slog.Error("failed to send login link email", "user_id", userID.String(), "error", err)
+	}
+
+	s.logEvent(ctx, audit.EventMagicLinkRequest, userID, email, "")
+
+	return nil
+}
+
+// LoginWithMagicLink redeems a token from RequestMagicLink and starts a session,
+// like a password login. Opening the link proves control of the email, so an
+// unverified email is marked verified.
+func (s *Service) LoginWithMagicLink(ctx context.Context, token string) (*TokenPair, *db.User, error) {
+	userID, err := s.oneTimeTokens.Consume(ctx, onetimetoken.PurposeMagicLink, refreshtoken.SubjectUser, token)
+	if err != nil {
+		s.logEvent(ctx, audit.EventMagicLinkLogin, uuid.Nil, "", "invalid_token")
+		return nil, nil, err
+	}
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+
+	user, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil, errors.Validation("invalid or expired token")
+		}
+		return nil, nil, errors.Internal("failed to log in", err)
+	}
+
+	if !user.EmailVerifiedAt.Valid {
+		verified, err := s.queries.MarkUserEmailVerified(ctx, pgUserID)
+		if err != nil {
+			return nil, nil, errors.Internal("failed to verify email", err)
+		}
+
+		// Audit log the verification (the user proved control of the email, so is the actor)
+		s.auditService.LogUpdate(audit.WithUserID(ctx, userID), "users", userID, user, verified)
+		user = verified
+	}
+
+	if err := s.restoreAccount(ctx, &user); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.IssueTokens(ctx, &user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logEvent(ctx, audit.EventMagicLinkLogin, userID, user.Email, "")
+
+	return tokens, &user, nil
+}
+
+// StartMagicLinkCleanup deletes login link requests that no longer count towards the
+// limits immediately and then on every interval until ctx is cancelled
+func (s *Service) StartMagicLinkCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			deleted, err := s.queries.DeleteMagicLinkRequestsBefore(ctx, pgtype.Timestamptz{Time: time.Now().Add(-MagicLinkWindow), Valid: true})
+			if err != nil {
+				slog.Error("failed to delete old login link requests", "error", err)
+			} else if deleted > 0 {
+				slog.Info("deleted old login link requests", "deleted", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// throttleMagicLink rejects a request with a rate limit error when the email or
+// the client IP address asked for too many links, and records it otherwise.
+// email must already be normalized.
+func (s *Service) throttleMagicLink(ctx context.Context, email string) error {
+	// Set by the AuditContext middleware, which only believes forwarding headers
+	// from TRUSTED_PROXIES, so rotating X-Forwarded-For does not reset the count
+	ip := audit.ExtractAuditContext(ctx).IPAddress
+	now := time.Now()
+
+	recent, err := s.queries.CountMagicLinkRequestsByEmailSince(ctx, db.CountMagicLinkRequestsByEmailSinceParams{
+		Email:     email,
+		CreatedAt: pgtype.Timestamptz{Time: now.Add(-MagicLinkCooldown), Valid: true},
+	})
+	if err != nil {
+		return errors.Internal("failed to check login link requests", err)
+	}
+	if recent > 0 {
+		return errors.RateLimited("a login link was requested recently, please wait before requesting another")
+	}
+
+	windowStart := pgtype.Timestamptz{Time: now.Add(-MagicLinkWindow), Valid: true}
+
+	perEmail, err := s.queries.CountMagicLinkRequestsByEmailSince(ctx, db.CountMagicLinkRequestsByEmailSinceParams{
+		Email:     email,
+		CreatedAt: windowStart,
+	})
+	if err != nil {
+		return errors.Internal("failed to check login link requests", err)
+	}
+	if perEmail >= MaxMagicLinksPerEmail {
+		return errors.RateLimited("too many login links requested, please try again later")
+	}
+
+	if ip != "" {
+		perIP, err := s.queries.CountMagicLinkRequestsByIPSince(ctx, db.CountMagicLinkRequestsByIPSinceParams{
+			IpAddress: pgtype.Text{String: ip, Valid: true},
+			CreatedAt: windowStart,
+		})
+		if err != nil {
+			return errors.Internal("failed to check login link requests", err)
+		}
+		if perIP >= MaxMagicLinksPerIP {
+			return errors.RateLimited("too many login links requested from this address, please try again later")
+		}
+	}
+
+	err = s.queries.CreateMagicLinkRequest(ctx, db.CreateMagicLinkRequestParams{
+		Email:     email,
+		IpAddress: pgtype.Text{String: ip, Valid: ip != ""},
+	})
+	if err != nil {
+		return errors.Internal("failed to record login link request", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package frontend_auth
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// ChangePassword replaces the password of a signed-in user after checking the
+// current one. Every session is signed out and a new token pair is returned,
+// so only the caller stays signed in.
+func (s *Service) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) (*TokenPair, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.Validation("invalid user ID format")
+	}
+	pgUserID := pgtype.UUID{Bytes: id, Valid: true}
+
+	oldUser, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("user not found")
+		}
+		return nil, errors.Internal("failed to change password", err)
+	}
+
+	// Wrong current passwords count as failed logins, so a stolen session cannot be
+	// used to guess the password
+	if err := s.logins.Check(ctx, refreshtoken.SubjectUser, oldUser.Email); err != nil {
+		s.logEvent(ctx, audit.EventPasswordChange, id, oldUser.Email, "throttled")
+		return nil, err
+	}
+
+	match, _, err := passhash.Verify(oldUser.PasswordHash, currentPassword)
+	if err != nil {
+		slog.Error("failed to verify password hash", "user_id", id.String(), "error", err)
+	}
+	if !match {
+		s.logEvent(ctx, audit.EventPasswordChange, id, oldUser.Email, "invalid_password")
+		if err := s.logins.Fail(ctx, refreshtoken.SubjectUser, oldUser.Email); err != nil {
+			return nil, err
+		}
+		return nil, errors.Validation("current password is incorrect")
+	}
+
+	if err := s.history.Check(ctx, refreshtoken.SubjectUser, id, oldUser.PasswordHash, newPassword); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := passhash.Hash(newPassword)
+	if err != nil {
+		return nil, errors.Internal("failed to hash password", err)
+	}
+
+	// The new password, the history entry and the sign-out commit together, so a
+	// failure cannot leave the password changed with the old sessions still valid
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Internal("failed to change password", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Bumps token_version, which invalidates outstanding access tokens
+	user, err := s.queries.WithTx(tx).UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		ID:           pgUserID,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		return nil, errors.Internal("failed to change password", err)
+	}
+
+	if err := s.history.WithTx(tx).Record(ctx, refreshtoken.SubjectUser, id, oldUser.PasswordHash); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokens.WithTx(tx).RevokeSubject(ctx, refreshtoken.SubjectUser, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.Internal("failed to change password", err)
+	}
+
+	// Audit log the change (the audit service strips password hashes)
+	s.auditService.LogUpdate(ctx, "users", id, oldUser, user)
+	s.logEvent(ctx, audit.EventPasswordChange, id, user.Email, "")
+
+	return s.IssueTokens(ctx, &user)
+}
@@ -0,0 +1,103 @@
+package frontend_auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// ForgotPassword emails a password reset link when an account exists for email.
+// It succeeds either way so the response does not reveal which emails are registered.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.queries.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			s.logEvent(ctx, audit.EventPasswordResetRequest, uuid.Nil, email, "unknown_account")
+			return nil
+		}
+		return errors.Internal("failed to request password reset", err)
+	}
+
+	token, err := s.oneTimeTokens.Issue(ctx, onetimetoken.PurposePasswordReset, refreshtoken.SubjectUser, uuid.UUID(user.ID.Bytes), s.resetTokenDuration)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link expires in %s and can only be used once. If you did not request this, you can ignore this email.\n",
+			user.Username, link, s.resetTokenDuration),
+	}
+
+	// Delivery failures are logged rather than returned, for the same reason unknown emails succeed
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		slog.Error("failed to send password reset email", "user_id", uuid.UUID(user.ID.Bytes).String(), "error", err)
+	}
+
+	s.logEvent(ctx, audit.EventPasswordResetRequest, uuid.UUID(user.ID.Bytes), email, "")
+
+	return nil
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword.
+// Every existing session of the user is signed out.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	userID, err := s.oneTimeTokens.Consume(ctx, onetimetoken.PurposePasswordReset, refreshtoken.SubjectUser, token)
+	if err != nil {
+		s.logEvent(ctx, audit.EventPasswordReset, uuid.Nil, "", "invalid_token")
+		return err
+	}
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+
+	oldUser, err := s.queries.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.Validation("invalid or expired token")
+		}
+		return errors.Internal("failed to reset password", err)
+	}
+
+	hashedPassword, err := passhash.Hash(newPassword)
+	if err != nil {
+		return errors.Internal("failed to hash password", err)
+	}
+
+	// Bumps token_version, which invalidates outstanding access tokens
+	user, err := s.queries.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		ID:           pgUserID,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		return errors.Internal("failed to reset password", err)
+	}
+
+	// Remember the replaced password, so a later password change cannot go back to it
+	if err := s.history.Record(ctx, refreshtoken.SubjectUser, userID, oldUser.PasswordHash); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokens.RevokeSubject(ctx, refreshtoken.SubjectUser, userID); err != nil {
+		return err
+	}
+
+	// Audit log the reset (the user proved control of the email, so is the actor)
+	s.auditService.LogUpdate(audit.WithUserID(ctx, userID), "users", userID, oldUser, user)
+	s.logEvent(ctx, audit.EventPasswordReset, userID, user.Email, "")
+
+	return nil
+}
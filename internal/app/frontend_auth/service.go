@@ -3,65 +3,387 @@ package frontend_auth
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/accountdeletion"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/errors"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/user/coc/internal/lockout"
+	"github.com/user/coc/internal/mailer"
+	"github.com/user/coc/internal/onetimetoken"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/passhistory"
+	"github.com/user/coc/internal/refreshtoken"
+	"github.com/user/coc/internal/revocation"
+	"github.com/user/coc/internal/signing"
+	"github.com/user/coc/internal/usersession"
+)
+
+// Issuer and audience of frontend access tokens. Admin tokens use different
+// values, so a token issued for one API is rejected by the other.
+const (
+	TokenIssuer   = "coc-api"
+	TokenAudience = "coc-frontend"
 )
 
 type Service struct {
-	queries             *db.Queries
-	auditService        *audit.Service
-	jwtSecret           string
-	bearerTokenDuration time.Duration
+	conn                 refreshtoken.DB // for statements that must commit together
+	queries              *db.Queries
+	auditService         *audit.Service
+	refreshTokens        *refreshtoken.Store
+	sessions             *usersession.Store
+	denylist             *revocation.Denylist
+	oneTimeTokens        *onetimetoken.Store
+	logins               *lockout.Guard
+	history              *passhistory.Store
+	deletions            *accountdeletion.Service
+	mailer               mailer.Mailer
+	keys                 *signing.KeySet
+	appURL               string // base URL of the frontend app, used for links in emails
+	verificationPolicy   VerificationPolicy
+	bearerTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
+	resetTokenDuration   time.Duration
+
+	verificationTokenDuration  time.Duration
+	impersonationTokenDuration time.Duration
+	magicLinkDuration          time.Duration
+}
+
+// Config holds the settings of the frontend auth service
+type Config struct {
+	AppURL                     string // base URL of the frontend app, used for links in emails
+	VerificationPolicy         VerificationPolicy
+	BearerTokenDuration        time.Duration
+	RefreshTokenDuration       time.Duration
+	ResetTokenDuration         time.Duration
+	VerificationTokenDuration  time.Duration
+	ImpersonationTokenDuration time.Duration
+	MagicLinkDuration          time.Duration
 }
 
-func NewService(queries *db.Queries, auditService *audit.Service, jwtSecret string, bearerTokenDuration time.Duration) *Service {
+func NewService(conn refreshtoken.DB, auditService *audit.Service, refreshTokens *refreshtoken.Store, sessions *usersession.Store, denylist *revocation.Denylist, oneTimeTokens *onetimetoken.Store, logins *lockout.Guard, history *passhistory.Store, deletions *accountdeletion.Service, mail mailer.Mailer, keys *signing.KeySet, cfg Config) *Service {
 	return &Service{
-		queries:             queries,
-		auditService:        auditService,
-		jwtSecret:           jwtSecret,
-		bearerTokenDuration: bearerTokenDuration,
+		conn:                 conn,
+		queries:              db.New(conn),
+		auditService:         auditService,
+		refreshTokens:        refreshTokens,
+		sessions:             sessions,
+		denylist:             denylist,
+		oneTimeTokens:        oneTimeTokens,
+		logins:               logins,
+		history:              history,
+		deletions:            deletions,
+		mailer:               mail,
+		keys:                 keys,
+		appURL:               strings.TrimRight(cfg.AppURL, "/"),
+		verificationPolicy:   cfg.VerificationPolicy,
+		bearerTokenDuration:  cfg.BearerTokenDuration,
+		refreshTokenDuration: cfg.RefreshTokenDuration,
+		resetTokenDuration:   cfg.ResetTokenDuration,
+
+		verificationTokenDuration:  cfg.VerificationTokenDuration,
+		impersonationTokenDuration: cfg.ImpersonationTokenDuration,
+		magicLinkDuration:          cfg.MagicLinkDuration,
 	}
 }
 
+// TokenPair is a short-lived access token and the refresh token used to renew it
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // access token lifetime in seconds
+}
+
 // CustomClaims defines the JWT claims structure
+// RegisteredClaims.ID carries the token's jti, used to revoke it on logout.
 type CustomClaims struct {
-	UserID   string `json:"user_id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
+	UserID       string      `json:"user_id"`
+	Email        string      `json:"email"`
+	Username     string      `json:"username"`
+	TokenVersion int32       `json:"tv"`
+	SessionID    string      `json:"sid,omitempty"` // refresh token family the token was issued with
+	Actor        *TokenActor `json:"act,omitempty"` // admin impersonating the user, see Impersonate
 	jwt.RegisteredClaims
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *Service) Login(ctx context.Context, email, password string) (string, *db.User, error) {
+// Login authenticates a user and returns an access/refresh token pair
+func (s *Service) Login(ctx context.Context, email, password string) (*TokenPair, *db.User, error) {
+	// Refuse locked or throttled attempts before spending a password hash on them
+	if err := s.logins.Check(ctx, refreshtoken.SubjectUser, email); err != nil {
+		s.logEvent(ctx, audit.EventLogin, uuid.Nil, email, "throttled")
+		return nil, nil, err
+	}
+
 	// Get user by email
 	user, err := s.queries.GetUserByEmail(ctx, email)
 	if err != nil {
-		return "", nil, errors.Unauthorized("invalid email or password")
+		// Spend as long as a wrong password would, so response times do not reveal which emails are registered
+		passhash.Verify(passhash.DummyHash, password)
+		return nil, nil, s.loginFailed(ctx, uuid.Nil, email, "unknown_account")
 	}
+	userID := uuid.UUID(user.ID.Bytes)
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", nil, errors.Unauthorized("invalid email or password")
+	match, rehash, err := passhash.Verify(user.PasswordHash, password)
+	if err != nil {
+		slog.Error("failed to verify password hash", "user_id", userID.String(), "error", err)
+	}
+	if !match {
+		return nil, nil, s.loginFailed(ctx, userID, email, "invalid_password")
+	}
+
+	if err := s.logins.Succeed(ctx, refreshtoken.SubjectUser, email); err != nil {
+		return nil, nil, err
+	}
+
+	if rehash {
+		s.rehashPassword(ctx, &user, password)
+	}
+
+	if s.verificationPolicy == VerificationRequired && !user.EmailVerifiedAt.Valid {
+		s.logEvent(ctx, audit.EventLogin, userID, email, "email_not_verified")
+		return nil, nil, errors.Forbidden("email address not verified")
+	}
+
+	// Logging in during the grace period keeps the account
+	if err := s.restoreAccount(ctx, &user); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.IssueTokens(ctx, &user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.logEvent(ctx, audit.EventLogin, userID, email, "")
+
+	return tokens, &user, nil
+}
+
+// loginFailed records and counts a failed login and returns the error for it.
+// Unknown emails count too, so responses do not reveal which accounts exist.
+func (s *Service) loginFailed(ctx context.Context, userID uuid.UUID, email, reason string) error {
+	s.logEvent(ctx, audit.EventLogin, userID, email, reason)
+
+	if err := s.logins.Fail(ctx, refreshtoken.SubjectUser, email); err != nil {
+		return err
+	}
+	return errors.Unauthorized("invalid email or password")
+}
+
+// logEvent records authentication activity of a user in the security event log.
+// userID is uuid.Nil when no account matched; an empty reason records a success.
+func (s *Service) logEvent(ctx context.Context, eventType audit.SecurityEventType, userID uuid.UUID, identifier, reason string) {
+	outcome := audit.OutcomeSuccess
+	if reason != "" {
+		outcome = audit.OutcomeFailure
+	}
+
+	s.auditService.LogSecurityEvent(ctx, audit.SecurityEvent{
+		Type:       eventType,
+		Outcome:    outcome,
+		ActorType:  audit.ActorUser,
+		ActorID:    userID,
+		Identifier: identifier,
+		Reason:     reason,
+	})
+}
+
+// rehashPassword replaces an outdated password hash after a successful login.
+// Failures are only logged, since the old hash keeps working.
+func (s *Service) rehashPassword(ctx context.Context, user *db.User, password string) {
+	hash, err := passhash.Hash(password)
+	if err != nil {
+		slog.Error("failed to rehash password", "user_id", uuid.UUID(user.ID.Bytes).String(), "error", err)
+		return
+	}
+
+	_, err = s.queries.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+		NewHash: hash,
+		ID:      user.ID,
+		OldHash: user.PasswordHash,
+	})
+	if err != nil {
+		slog.Error("failed to store rehashed password", "user_id", uuid.UUID(user.ID.Bytes).String(), "error", err)
+		return
+	}
+	user.PasswordHash = hash
+}
+
+// IssueTokens starts a new session for the user: an access token plus a refresh token in a new family
+func (s *Service) IssueTokens(ctx context.Context, user *db.User) (*TokenPair, error) {
+	userID := uuid.UUID(user.ID.Bytes)
+	refresh, err := s.refreshTokens.Issue(ctx, refreshtoken.SubjectUser, userID, user.TokenVersion, s.refreshTokenDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessions.Start(ctx, userID, refresh.FamilyID); err != nil {
+		return nil, err
+	}
+
+	token, err := s.GenerateToken(user, refresh.FamilyID)
+	if err != nil {
+		return nil, errors.Internal("failed to generate token", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  token,
+		RefreshToken: refresh.Token,
+		ExpiresIn:    int64(s.bearerTokenDuration.Seconds()),
+	}, nil
+}
+
+// Refresh rotates a refresh token and issues a new access token for its user
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	refresh, err := s.refreshTokens.Rotate(ctx, refreshtoken.SubjectUser, refreshToken, s.refreshTokenDuration)
+	if err != nil {
+		s.logEvent(ctx, audit.EventTokenRefresh, uuid.Nil, "", "invalid_token")
+		return nil, err
+	}
+
+	user, err := s.queries.GetUserByID(ctx, pgtype.UUID{Bytes: refresh.SubjectID, Valid: true})
+	if err != nil {
+		s.logEvent(ctx, audit.EventTokenRefresh, refresh.SubjectID, "", "unknown_account")
+		return nil, errors.Unauthorized("user not found")
+	}
+
+	// The user logged out everywhere (or changed password) after this session started
+	if refresh.TokenVersion != user.TokenVersion {
+		if err := s.refreshTokens.RevokeFamily(ctx, refresh.FamilyID); err != nil {
+			return nil, err
+		}
+		s.logEvent(ctx, audit.EventTokenRefresh, refresh.SubjectID, "", "session_revoked")
+		return nil, errors.Unauthorized("session has been revoked")
+	}
+
+	token, err := s.GenerateToken(&user, refresh.FamilyID)
+	if err != nil {
+		return nil, errors.Internal("failed to generate token", err)
+	}
+
+	s.logEvent(ctx, audit.EventTokenRefresh, refresh.SubjectID, "", "")
+
+	return &TokenPair{
+		AccessToken:  token,
+		RefreshToken: refresh.Token,
+		ExpiresIn:    int64(s.bearerTokenDuration.Seconds()),
+	}, nil
+}
+
+// Authenticate validates an access token and checks it has not been revoked.
+// Used by the auth middleware on every request.
+func (s *Service) Authenticate(ctx context.Context, tokenString string) (*db.User, *CustomClaims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, nil, errors.Unauthorized("invalid or expired token")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, errors.Unauthorized("invalid or expired token")
 	}
 
-	// Generate JWT token
-	token, err := s.GenerateToken(&user)
+	// Get user from database to ensure user still exists
+	user, err := s.queries.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
 	if err != nil {
-		return "", nil, errors.Internal("failed to generate token", err)
+		return nil, nil, errors.Unauthorized("user not found")
 	}
 
-	return token, &user, nil
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, nil, errors.Unauthorized("session has been revoked")
+	}
+
+	if claims.Impersonated() {
+		if err := s.checkImpersonator(ctx, claims.Actor); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Tokens of a session the user signed out of remotely stop working right away
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		if err := s.sessions.Check(ctx, sessionID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, nil, errors.Unauthorized("invalid or expired token")
+	}
+
+	revoked, err := s.denylist.IsRevoked(ctx, jti)
+	if err != nil {
+		return nil, nil, errors.Internal("failed to check token revocation", err)
+	}
+	if revoked {
+		return nil, nil, errors.Unauthorized("session has been revoked")
+	}
+
+	return &user, claims, nil
 }
 
-// GenerateToken creates a new JWT token for a user
-func (s *Service) GenerateToken(user *db.User) (string, error) {
+// Logout revokes the current access token and the refresh tokens of its session
+func (s *Service) Logout(ctx context.Context, claims *CustomClaims) error {
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return errors.Validation("invalid token ID")
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return errors.Validation("invalid user ID format")
+	}
+
+	if err := s.denylist.Revoke(ctx, jti, string(refreshtoken.SubjectUser), userID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		if err := s.refreshTokens.RevokeFamily(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	s.logEvent(ctx, audit.EventLogout, userID, "", "")
+
+	return nil
+}
+
+// LogoutAll invalidates every access and refresh token of the user by bumping its token version
+func (s *Service) LogoutAll(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.Validation("invalid user ID format")
+	}
+
+	if _, err := s.queries.IncrementUserTokenVersion(ctx, pgtype.UUID{Bytes: id, Valid: true}); err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("user not found")
+		}
+		return errors.Internal("failed to revoke sessions", err)
+	}
+
+	if err := s.refreshTokens.RevokeSubject(ctx, refreshtoken.SubjectUser, id); err != nil {
+		return err
+	}
+
+	s.logEvent(ctx, audit.EventLogoutAll, id, "", "")
+
+	return nil
+}
+
+// GenerateToken creates a new JWT token for a user.
+// sessionID is the refresh token family the access token belongs to.
+func (s *Service) GenerateToken(user *db.User, sessionID uuid.UUID) (string, error) {
 	// Token expires based on BEARER_TOKEN_DURATION from config
 	expirationTime := time.Now().Add(s.bearerTokenDuration)
 
@@ -73,21 +395,27 @@ func (s *Service) GenerateToken(user *db.User) (string, error) {
 
 	// Create claims
 	claims := &CustomClaims{
-		UserID:   userID.String(),
-		Email:    user.Email,
-		Username: user.Username,
+		UserID:       userID.String(),
+		Email:        user.Email,
+		Username:     user.Username,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    TokenIssuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{TokenAudience},
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if sessionID != uuid.Nil {
+		claims.SessionID = sessionID.String()
+	}
 
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	// Sign token with the active key; the kid header tells verifiers which key to use
+	tokenString, err := s.keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -98,13 +426,12 @@ func (s *Service) GenerateToken(user *db.User) (string, error) {
 // ValidateToken validates a JWT token and returns the claims
 func (s *Service) ValidateToken(tokenString string) (*CustomClaims, error) {
 	// Parse token
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.Algorithms()),
+		jwt.WithIssuer(TokenIssuer),
+		jwt.WithAudience(TokenAudience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -116,6 +443,11 @@ func (s *Service) ValidateToken(tokenString string) (*CustomClaims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	// The subject identifies the user the token was issued for
+	if claims.Subject == "" || claims.Subject != claims.UserID {
+		return nil, fmt.Errorf("invalid token subject")
+	}
+
 	return claims, nil
 }
 
@@ -124,17 +456,19 @@ func (s *Service) Register(ctx context.Context, email, username, password, first
 	// Check if user with email already exists
 	_, err := s.queries.GetUserByEmail(ctx, email)
 	if err == nil {
+		s.logEvent(ctx, audit.EventRegistration, uuid.Nil, email, "email_taken")
 		return nil, errors.AlreadyExists("user with this email already exists")
 	}
 
 	// Check if user with username already exists
 	_, err = s.queries.GetUserByUsername(ctx, username)
 	if err == nil {
+		s.logEvent(ctx, audit.EventRegistration, uuid.Nil, email, "username_taken")
 		return nil, errors.AlreadyExists("user with this username already exists")
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := passhash.Hash(password)
 	if err != nil {
 		return nil, errors.Internal("failed to hash password", err)
 	}
@@ -143,7 +477,7 @@ func (s *Service) Register(ctx context.Context, email, username, password, first
 	params := db.CreateUserParams{
 		Email:        email,
 		Username:     username,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 	}
 
 	if firstName != "" {
@@ -159,9 +493,15 @@ func (s *Service) Register(ctx context.Context, email, username, password, first
 		return nil, errors.Internal("failed to create user", err)
 	}
 
-	// Audit log the user registration
+	// Audit log the user registration (the new user is the actor)
 	userID := uuid.UUID(user.ID.Bytes)
-	s.auditService.LogCreate(ctx, "users", userID, user)
+	s.auditService.LogCreate(audit.WithUserID(ctx, userID), "users", userID, user)
+	s.logEvent(ctx, audit.EventRegistration, userID, email, "")
+
+	// The account exists either way; a failed email can be re-sent from the resend endpoint
+	if err := s.SendVerificationEmail(ctx, &user); err != nil {
+		slog.Error("failed to send verification email after registration", "user_id", userID.String(), "error", err)
+	}
 
 	return &user, nil
 }
@@ -0,0 +1,43 @@
+package frontend_auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/coc/internal/errors"
+)
+
+// ListSessions returns where the user is signed in. currentSessionID is the
+// session of the request, marked as current in the result.
+func (s *Service) ListSessions(ctx context.Context, userID, currentSessionID string) ([]SessionResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.Validation("invalid user ID format")
+	}
+
+	sessions, err := s.sessions.List(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = ToSessionResponse(session, currentSessionID)
+	}
+	return responses, nil
+}
+
+// RevokeSession signs the user out of one of their sessions, which may be the current one
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.Validation("invalid user ID format")
+	}
+
+	sid, err := uuid.Parse(sessionID)
+	if err != nil {
+		return errors.Validation("invalid session ID format")
+	}
+
+	return s.sessions.Revoke(ctx, id, sid)
+}
@@ -0,0 +1,54 @@
+package role
+
+// CreateRoleRequest represents the request to create a role
+type CreateRoleRequest struct {
+	Name         string `json:"name" validate:"required,min=2,max=50" example:"support"`
+	Description  string `json:"description,omitempty" validate:"omitempty,max=255" example:"Customer support staff"`
+	InheritsFrom string `json:"inherits_from,omitempty" validate:"omitempty,max=50" example:"moderator"`
+}
+
+// UpdateRoleRequest represents the request to update a role. An empty
+// inherits_from stops the role from inheriting.
+type UpdateRoleRequest struct {
+	Description  *string `json:"description,omitempty" validate:"omitempty,max=255" example:"Customer support staff"`
+	InheritsFrom *string `json:"inherits_from,omitempty" validate:"omitempty,max=50" example:"moderator"`
+}
+
+// RoleResponse represents a role with the permission codes it grants
+type RoleResponse struct {
+	Role         string `json:"role" example:"moderator"`
+	Description  string `json:"description,omitempty" example:"Read-only access"`
+	IsSystem     bool   `json:"is_system" example:"true"`
+	InheritsFrom string `json:"inherits_from,omitempty" example:""`
+	// Permissions are granted to the role itself
+	Permissions []string `json:"permissions" example:"users.read,analytics.dashboard"`
+	// EffectivePermissions adds the permissions of the roles it inherits from
+	EffectivePermissions []string `json:"effective_permissions" example:"users.read,analytics.dashboard"`
+}
+
+// PermissionResponse represents a permission and the roles that grant it,
+// directly or through inheritance
+type PermissionResponse struct {
+	ID          string   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Code        string   `json:"code" example:"users.read"`
+	Name        string   `json:"name" example:"Read Users"`
+	Description string   `json:"description,omitempty" example:"Ability to view user information"`
+	Category    string   `json:"category" example:"users"`
+	Roles       []string `json:"roles" example:"super_admin,admin,moderator"`
+}
+
+// PermissionCacheStats reports how well the permission cache of an instance is doing
+type PermissionCacheStats struct {
+	Hits          uint64 `json:"hits" example:"1520"`
+	Misses        uint64 `json:"misses" example:"12"`
+	Invalidations uint64 `json:"invalidations" example:"3"`
+	Roles         int    `json:"roles" example:"3"`
+	Admins        int    `json:"admins" example:"8"`
+	TTLSeconds    int64  `json:"ttl_seconds" example:"60"`
+}
+
+// RolePermissionRequest identifies a permission of a role in the URL
+type RolePermissionRequest struct {
+	Role string `json:"-" validate:"required,max=50"`
+	Code string `json:"-" validate:"required,max=100"`
+}
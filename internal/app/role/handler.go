@@ -0,0 +1,354 @@
+package role
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/response"
+	"github.com/user/coc/internal/validation"
+)
+
+// CacheStatsSource reports the counters of the in-memory permission cache
+type CacheStatsSource interface {
+	// CacheStats returns false when the cache is disabled
+	CacheStats() (PermissionCacheStats, bool)
+}
+
+// Handler handles role and permission management
+type Handler struct {
+	service    *Service
+	validate   *validation.Validator
+	cacheStats CacheStatsSource // optional; nil reports the cache as disabled
+}
+
+func NewHandler(service *Service, validator *validation.Validator, cacheStats CacheStatsSource) *Handler {
+	return &Handler{
+		service:    service,
+		validate:   validator,
+		cacheStats: cacheStats,
+	}
+}
+
+// ListRoles handles GET /api/admin/v1/roles
+// @Summary      List roles
+// @Description  List every admin role with the permission codes it grants directly and through inheritance
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=[]RoleResponse} "Roles retrieved successfully"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/roles [get]
+func (h *Handler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	roles, err := h.service.ListRoles(r.Context())
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "roles retrieved successfully", roles)
+}
+
+// GetRole handles GET /api/admin/v1/roles/{role}
+// @Summary      Get role
+// @Description  Retrieve an admin role with the permission codes it grants directly and through inheritance
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Param        role path string true "Role name"
+// @Success      200 {object} response.JSONResponse{data=RoleResponse} "Role retrieved successfully"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Role not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/roles/{role} [get]
+func (h *Handler) GetRole(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	resp, err := h.service.GetRole(r.Context(), chi.URLParam(r, "role"))
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "role retrieved successfully", resp)
+}
+
+// CreateRole handles POST /api/admin/v1/roles
+// @Summary      Create role
+// @Description  Create an admin role, optionally inheriting the permissions of another role. Grant permissions to it afterwards.
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Param        request body CreateRoleRequest true "Role data"
+// @Success      201 {object} response.JSONResponse{data=RoleResponse} "Role created successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      409 {object} response.JSONResponse "Role already exists"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/roles [post]
+func (h *Handler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	var req CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	resp, err := h.service.CreateRole(r.Context(), req)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, "role created successfully", resp)
+}
+
+// UpdateRole handles PUT /api/admin/v1/roles/{role}
+// @Summary      Update role
+// @Description  Change the description of a role or the role it inherits from. An empty inherits_from stops inheriting. Takes effect on the next request.
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Param        role path string true "Role name"
+// @Param        request body UpdateRoleRequest true "Role data"
+// @Success      200 {object} response.JSONResponse{data=RoleResponse} "Role updated successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Role not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/roles/{role} [put]
+func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	resp, err := h.service.UpdateRole(r.Context(), chi.URLParam(r, "role"), req)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "role updated successfully", resp)
+}
+
+// DeleteRole handles DELETE /api/admin/v1/roles/{role}
+// @Summary      Delete role
+// @Description  Delete a role together with its grants. System roles, roles assigned to admins and roles other roles inherit from cannot be deleted.
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Param        role path string true "Role name"
+// @Success      200 {object} response.JSONResponse "Role deleted successfully"
+// @Failure      400 {object} response.JSONResponse "Role is still in use"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "System role"
+// @Failure      404 {object} response.JSONResponse "Role not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/roles/{role} [delete]
+func (h *Handler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	if err := h.service.DeleteRole(r.Context(), chi.URLParam(r, "role")); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "role deleted successfully", nil)
+}
+
+// GrantPermission handles PUT /api/admin/v1/roles/{role}/permissions/{code}
+// @Summary      Grant permission to role
+// @Description  Let every admin with the role use the permission. Takes effect on the next request; granting a permission the role has changes nothing.
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Param        role path string true "Role name"
+// @Param        code path string true "Permission code"
+// @Success      200 {object} response.JSONResponse{data=RoleResponse} "Permission granted"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Permission not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/roles/{role}/permissions/{code} [put]
+func (h *Handler) GrantPermission(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	req := RolePermissionRequest{
+		Role: chi.URLParam(r, "role"),
+		Code: chi.URLParam(r, "code"),
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	resp, err := h.service.GrantPermission(r.Context(), req.Role, req.Code)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "permission granted", resp)
+}
+
+// RevokePermission handles DELETE /api/admin/v1/roles/{role}/permissions/{code}
+// @Summary      Revoke permission from role
+// @Description  Stop every admin with the role from using the permission. Takes effect on the next request; the role keeps permissions it inherits. An admin cannot revoke admins.manage from their own role or a role it inherits from.
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Param        role path string true "Role name"
+// @Param        code path string true "Permission code"
+// @Success      200 {object} response.JSONResponse{data=RoleResponse} "Permission revoked"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Permission not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/roles/{role}/permissions/{code} [delete]
+func (h *Handler) RevokePermission(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	req := RolePermissionRequest{
+		Role: chi.URLParam(r, "role"),
+		Code: chi.URLParam(r, "code"),
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	resp, err := h.service.RevokePermission(r.Context(), role, req.Role, req.Code)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "permission revoked", resp)
+}
+
+// ListPermissions handles GET /api/admin/v1/permissions
+// @Summary      List permissions
+// @Description  List every active permission with the roles that grant it, directly or through inheritance
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=[]PermissionResponse} "Permissions retrieved successfully"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/permissions [get]
+func (h *Handler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	permissions, err := h.service.ListPermissions(r.Context())
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "permissions retrieved successfully", permissions)
+}
+
+// PermissionCacheStats handles GET /api/admin/v1/permissions/cache
+// @Summary      Permission cache statistics
+// @Description  Hit, miss and invalidation counters of this instance's role permission and admin override cache since startup
+// @Tags         Role Management
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=PermissionCacheStats} "Cache statistics retrieved successfully"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "Permission cache disabled"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/permissions/cache [get]
+func (h *Handler) PermissionCacheStats(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	var stats PermissionCacheStats
+	enabled := false
+	if h.cacheStats != nil {
+		stats, enabled = h.cacheStats.CacheStats()
+	}
+	if !enabled {
+		response.Error(w, http.StatusNotFound, "permission cache is disabled")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "cache statistics retrieved successfully", stats)
+}
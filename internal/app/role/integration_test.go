@@ -0,0 +1,222 @@
+package role
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/user/coc/internal/app/admin_menu"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+)
+
+func setupTestDB(t *testing.T) (*pgxpool.Pool, *db.Queries) {
+	t.Helper()
+
+	// Use DATABASE_URL from environment (Docker Postgres)
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL not set, skipping integration tests")
+	}
+
+	ctx := context.Background()
+
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to create connection pool: %v", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		t.Fatalf("failed to ping database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+	})
+
+	return pool, db.New(pool)
+}
+
+func TestIntegration_GrantAndRevokePermission(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	// Start transaction for isolation
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	auditService := audit.NewService(qtx)
+	service := NewService(qtx, auditService)
+
+	// Moderators cannot read the audit log in the seed data
+	before, err := admin_menu.GetRolePermissions(ctx, qtx, "moderator")
+	if err != nil {
+		t.Fatalf("GetRolePermissions failed: %v", err)
+	}
+	if before["audit.read"] {
+		t.Skip("moderator already has audit.read in this database")
+	}
+
+	granted, err := service.GrantPermission(ctx, "moderator", "audit.read")
+	if err != nil {
+		t.Fatalf("GrantPermission failed: %v", err)
+	}
+	if !slices.Contains(granted.Permissions, "audit.read") {
+		t.Errorf("expected audit.read in %v", granted.Permissions)
+	}
+
+	// The permission middleware reads the same rows, so the grant is live right away
+	after, err := admin_menu.GetRolePermissions(ctx, qtx, "moderator")
+	if err != nil {
+		t.Fatalf("GetRolePermissions failed: %v", err)
+	}
+	if !after["audit.read"] {
+		t.Error("expected moderator to have audit.read after the grant")
+	}
+
+	// Granting again changes nothing
+	if _, err := service.GrantPermission(ctx, "moderator", "audit.read"); err != nil {
+		t.Fatalf("repeated GrantPermission failed: %v", err)
+	}
+
+	permission, err := qtx.GetPermissionByCode(ctx, "audit.read")
+	if err != nil {
+		t.Fatalf("GetPermissionByCode failed: %v", err)
+	}
+	rolePermission, err := qtx.GetRolePermission(ctx, db.GetRolePermissionParams{Role: "moderator", PermissionID: permission.ID})
+	if err != nil {
+		t.Fatalf("GetRolePermission failed: %v", err)
+	}
+
+	revoked, err := service.RevokePermission(ctx, "super_admin", "moderator", "audit.read")
+	if err != nil {
+		t.Fatalf("RevokePermission failed: %v", err)
+	}
+	if slices.Contains(revoked.Permissions, "audit.read") {
+		t.Errorf("expected audit.read to be revoked, got %v", revoked.Permissions)
+	}
+
+	// One create and one delete are audited, each naming the permission
+	history, err := auditService.GetEntityHistory(ctx, "role_permissions", uuid.UUID(rolePermission.ID.Bytes), 10, 0)
+	if err != nil {
+		t.Fatalf("GetEntityHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(history))
+	}
+	for _, entry := range history {
+		data := entry.NewData
+		if entry.Action == db.AuditActionDELETE {
+			data = entry.OldData
+		}
+		var snapshot map[string]any
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			t.Fatalf("invalid audit snapshot: %v", err)
+		}
+		if snapshot["permission_code"] != "audit.read" || snapshot["role"] != "moderator" {
+			t.Errorf("unexpected audit snapshot %v", snapshot)
+		}
+	}
+}
+
+func TestIntegration_ListPermissions(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	service := NewService(qtx, audit.NewService(qtx))
+
+	permissions, err := service.ListPermissions(ctx)
+	if err != nil {
+		t.Fatalf("ListPermissions failed: %v", err)
+	}
+
+	for _, permission := range permissions {
+		if permission.Code == ManagePermission {
+			if !slices.Contains(permission.Roles, "super_admin") {
+				t.Errorf("expected super_admin to grant %s, got %v", ManagePermission, permission.Roles)
+			}
+			return
+		}
+	}
+	t.Errorf("expected %s in the permission list", ManagePermission)
+}
+
+func TestIntegration_RoleInheritance(t *testing.T) {
+	pool, queries := setupTestDB(t)
+
+	ctx := context.Background()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := queries.WithTx(tx)
+	service := NewService(qtx, audit.NewService(qtx))
+
+	created, err := service.CreateRole(ctx, CreateRoleRequest{Name: "support", Description: "Customer support", InheritsFrom: "moderator"})
+	if err != nil {
+		t.Fatalf("CreateRole failed: %v", err)
+	}
+	if len(created.Permissions) != 0 {
+		t.Errorf("expected no direct permissions, got %v", created.Permissions)
+	}
+	if !slices.Contains(created.EffectivePermissions, "users.read") {
+		t.Errorf("expected support to inherit users.read from moderator, got %v", created.EffectivePermissions)
+	}
+
+	// Grants to the new role are on top of the inherited ones
+	granted, err := service.GrantPermission(ctx, "support", "audit.read")
+	if err != nil {
+		t.Fatalf("GrantPermission failed: %v", err)
+	}
+	if !slices.Equal(granted.Permissions, []string{"audit.read"}) {
+		t.Errorf("expected audit.read as the only direct permission, got %v", granted.Permissions)
+	}
+
+	// The permission middleware sees the inherited permissions too
+	permissions, err := admin_menu.GetRolePermissions(ctx, qtx, "support")
+	if err != nil {
+		t.Fatalf("GetRolePermissions failed: %v", err)
+	}
+	if !permissions["audit.read"] || !permissions["users.read"] {
+		t.Errorf("expected direct and inherited permissions, got %v", permissions)
+	}
+
+	// moderator cannot inherit from a role that inherits from it
+	support := "support"
+	if _, err := service.UpdateRole(ctx, "moderator", UpdateRoleRequest{InheritsFrom: &support}); err == nil {
+		t.Error("expected inheritance cycle to be rejected")
+	}
+
+	// Roles in use cannot be deleted
+	if err := service.DeleteRole(ctx, "moderator"); err == nil {
+		t.Error("expected system role deletion to be rejected")
+	}
+
+	if err := service.DeleteRole(ctx, "support"); err != nil {
+		t.Fatalf("DeleteRole failed: %v", err)
+	}
+	if _, err := service.GetRole(ctx, "support"); err == nil {
+		t.Error("expected deleted role to be gone")
+	}
+}
@@ -0,0 +1,133 @@
+package role
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/validation"
+)
+
+// newRolePermissionRequest builds a request with the admin role and the {role} and {code} URL params set
+func newRolePermissionRequest(method, adminRole, role, code string) *http.Request {
+	req := httptest.NewRequest(method, "/roles/"+role+"/permissions/"+code, nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("role", role)
+	rctx.URLParams.Add("code", code)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, ctxkeys.AdminRoleContextKey, adminRole)
+
+	return req.WithContext(ctx)
+}
+
+// TestHandler_ListRoles_MissingAdminRole tests ListRoles without admin role
+func TestHandler_ListRoles_MissingAdminRole(t *testing.T) {
+	handler := NewHandler(nil, validation.New(), nil)
+	req := httptest.NewRequest("GET", "/roles", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListRoles(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// TestHandler_CreateRole_InvalidBody tests CreateRole with a malformed body
+func TestHandler_CreateRole_InvalidBody(t *testing.T) {
+	handler := NewHandler(nil, validation.New(), nil)
+	req := httptest.NewRequest("POST", "/roles", strings.NewReader("{"))
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.AdminRoleContextKey, "super_admin"))
+	rec := httptest.NewRecorder()
+
+	handler.CreateRole(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestHandler_ListPermissions_MissingAdminRole tests ListPermissions without admin role
+func TestHandler_ListPermissions_MissingAdminRole(t *testing.T) {
+	handler := NewHandler(nil, validation.New(), nil)
+	req := httptest.NewRequest("GET", "/permissions", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListPermissions(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+// fixedCacheStats is a CacheStatsSource with fixed counters
+type fixedCacheStats struct {
+	stats   PermissionCacheStats
+	enabled bool
+}
+
+func (f fixedCacheStats) CacheStats() (PermissionCacheStats, bool) {
+	return f.stats, f.enabled
+}
+
+// TestHandler_PermissionCacheStats tests that the counters are served, and 404 when the cache is disabled
+func TestHandler_PermissionCacheStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		cacheStats CacheStatsSource
+		want       int
+	}{
+		{"no cache", nil, http.StatusNotFound},
+		{"cache disabled", fixedCacheStats{}, http.StatusNotFound},
+		{"cache enabled", fixedCacheStats{stats: PermissionCacheStats{Hits: 7}, enabled: true}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(nil, validation.New(), tt.cacheStats)
+			req := httptest.NewRequest("GET", "/permissions/cache", nil)
+			req = req.WithContext(context.WithValue(req.Context(), ctxkeys.AdminRoleContextKey, "super_admin"))
+			rec := httptest.NewRecorder()
+
+			handler.PermissionCacheStats(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, rec.Code)
+			}
+			if tt.want == http.StatusOK && !strings.Contains(rec.Body.String(), `"hits":7`) {
+				t.Errorf("expected the hit counter in the response, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestHandler_GrantPermission_InvalidRole tests that role names longer than the column are rejected
+func TestHandler_GrantPermission_InvalidRole(t *testing.T) {
+	handler := NewHandler(nil, validation.New(), nil)
+	req := newRolePermissionRequest("PUT", "super_admin", strings.Repeat("r", 51), "users.read")
+	rec := httptest.NewRecorder()
+
+	handler.GrantPermission(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestHandler_RevokePermission_OwnManagePermission tests that admins cannot lock their own role out of role management
+func TestHandler_RevokePermission_OwnManagePermission(t *testing.T) {
+	handler := NewHandler(NewService(nil, nil), validation.New(), nil)
+	req := newRolePermissionRequest("DELETE", "super_admin", "super_admin", ManagePermission)
+	rec := httptest.NewRecorder()
+
+	handler.RevokePermission(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+}
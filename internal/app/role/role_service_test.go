@@ -0,0 +1,55 @@
+package role
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// TestService_CreateRole_InvalidName tests that role names are checked before any DB call
+func TestService_CreateRole_InvalidName(t *testing.T) {
+	service := NewService(nil, nil) // Will panic if DB called
+
+	for _, name := range []string{"Support", "1st_line", "support-team", "support team"} {
+		_, err := service.CreateRole(context.Background(), CreateRoleRequest{Name: name})
+
+		domainErr, ok := err.(*errors.DomainError)
+		if !ok || domainErr.Code != errors.CodeValidation {
+			t.Errorf("expected VALIDATION_ERROR for %q, got %v", name, err)
+		}
+	}
+}
+
+// TestService_RevokePermission_OwnManagePermission tests the lockout guard runs before any DB call
+func TestService_RevokePermission_OwnManagePermission(t *testing.T) {
+	service := NewService(nil, nil) // Will panic if DB called
+
+	_, err := service.RevokePermission(context.Background(), "admin", "admin", ManagePermission)
+
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok || domainErr.Code != errors.CodeForbidden {
+		t.Errorf("expected FORBIDDEN, got %v", err)
+	}
+}
+
+// TestToPermissionResponse tests that permissions no role grants list no roles rather than null
+func TestToPermissionResponse(t *testing.T) {
+	permission := &db.Permission{
+		Code:        "users.read",
+		Name:        "Read Users",
+		Description: pgtype.Text{String: "Ability to view user information", Valid: true},
+		Category:    "users",
+	}
+
+	resp := toPermissionResponse(permission, nil)
+
+	if resp.Roles == nil || len(resp.Roles) != 0 {
+		t.Errorf("expected empty roles, got %v", resp.Roles)
+	}
+	if resp.Description != "Ability to view user information" {
+		t.Errorf("unexpected description %q", resp.Description)
+	}
+}
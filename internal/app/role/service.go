@@ -0,0 +1,385 @@
+package role
+
+import (
+	"context"
+	stderrors "errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// ManagePermission is the permission that guards role management. A role
+// cannot take it away from itself, so admins cannot lock themselves out.
+const ManagePermission = "admins.manage"
+
+// namePattern is what role names look like: they end up in tokens and URLs
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Service manages admin roles and the permissions they grant. Changes flush
+// the permission caches of every instance, so they apply right away.
+type Service struct {
+	queries      *db.Queries
+	auditService *audit.Service
+}
+
+func NewService(queries *db.Queries, auditService *audit.Service) *Service {
+	return &Service{
+		queries:      queries,
+		auditService: auditService,
+	}
+}
+
+// grant is the audit log snapshot of a role_permissions row. It carries the
+// permission code, which the row itself only references by ID.
+type grant struct {
+	ID             pgtype.UUID        `json:"id"`
+	Role           string             `json:"role"`
+	PermissionID   pgtype.UUID        `json:"permission_id"`
+	PermissionCode string             `json:"permission_code"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+// ListRoles returns every role with the permission codes it grants
+func (s *Service) ListRoles(ctx context.Context) ([]*RoleResponse, error) {
+	roles, err := s.queries.ListRoles(ctx)
+	if err != nil {
+		return nil, errors.Internal("failed to list roles", err)
+	}
+
+	responses := make([]*RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		resp, err := s.roleResponse(ctx, &role)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+
+	return responses, nil
+}
+
+// GetRole returns the role with the permission codes it grants
+func (s *Service) GetRole(ctx context.Context, name string) (*RoleResponse, error) {
+	role, err := s.role(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.roleResponse(ctx, role)
+}
+
+// CreateRole creates a role without permissions of its own; grant them afterwards
+func (s *Service) CreateRole(ctx context.Context, req CreateRoleRequest) (*RoleResponse, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if !namePattern.MatchString(req.Name) {
+		return nil, errors.Validation("name must start with a lowercase letter and contain only lowercase letters, digits and underscores")
+	}
+
+	if _, err := s.queries.GetRoleByName(ctx, req.Name); err == nil {
+		return nil, errors.AlreadyExists("role with this name already exists")
+	} else if err != pgx.ErrNoRows {
+		return nil, errors.Internal("failed to check role", err)
+	}
+
+	params := db.CreateRoleParams{Name: req.Name}
+	if req.Description != "" {
+		params.Description = pgtype.Text{String: strings.TrimSpace(req.Description), Valid: true}
+	}
+	if req.InheritsFrom != "" {
+		if _, err := s.role(ctx, req.InheritsFrom); err != nil {
+			return nil, errors.Validation("role to inherit from does not exist")
+		}
+		params.InheritsFrom = pgtype.Text{String: req.InheritsFrom, Valid: true}
+	}
+
+	role, err := s.queries.CreateRole(ctx, params)
+	if err != nil {
+		return nil, errors.Internal("failed to create role", err)
+	}
+
+	// Audit log
+	s.auditService.LogCreate(ctx, "roles", uuid.UUID(role.ID.Bytes), role)
+
+	return s.roleResponse(ctx, &role)
+}
+
+// UpdateRole changes the description of a role or the role it inherits from.
+// A role cannot end up inheriting from itself, directly or through others.
+func (s *Service) UpdateRole(ctx context.Context, name string, req UpdateRoleRequest) (*RoleResponse, error) {
+	oldRole, err := s.role(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := db.UpdateRoleParams{
+		Name:         oldRole.Name,
+		Description:  oldRole.Description,  // default to existing
+		InheritsFrom: oldRole.InheritsFrom, // default to existing
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		params.Description = pgtype.Text{String: description, Valid: description != ""}
+	}
+
+	if req.InheritsFrom != nil {
+		params.InheritsFrom = pgtype.Text{}
+		if *req.InheritsFrom != "" {
+			if _, err := s.role(ctx, *req.InheritsFrom); err != nil {
+				return nil, errors.Validation("role to inherit from does not exist")
+			}
+
+			lineage, err := s.queries.GetRoleLineage(ctx, *req.InheritsFrom)
+			if err != nil {
+				return nil, errors.Internal("failed to check role inheritance", err)
+			}
+			if slices.Contains(lineage, name) {
+				return nil, errors.Validation("a role cannot inherit from itself")
+			}
+
+			params.InheritsFrom = pgtype.Text{String: *req.InheritsFrom, Valid: true}
+		}
+	}
+
+	role, err := s.queries.UpdateRole(ctx, params)
+	if err != nil {
+		return nil, errors.Internal("failed to update role", err)
+	}
+
+	// Audit log
+	s.auditService.LogUpdate(ctx, "roles", uuid.UUID(role.ID.Bytes), oldRole, role)
+
+	return s.roleResponse(ctx, &role)
+}
+
+// DeleteRole deletes a role that no admin has and no role inherits from,
+// together with its grants. System roles cannot be deleted.
+func (s *Service) DeleteRole(ctx context.Context, name string) error {
+	role, err := s.role(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return errors.Forbidden("system roles cannot be deleted")
+	}
+
+	deleted, err := s.queries.DeleteRole(ctx, name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if stderrors.As(err, &pgErr) && pgErr.Code == "23503" {
+			if pgErr.ConstraintName == "fk_admins_role" {
+				return errors.Validation("role is still assigned to admins")
+			}
+			return errors.Validation("other roles inherit from this role")
+		}
+		return errors.Internal("failed to delete role", err)
+	}
+	if deleted == 0 {
+		return errors.NotFound("role not found")
+	}
+
+	// Audit log
+	s.auditService.LogDelete(ctx, "roles", uuid.UUID(role.ID.Bytes), role)
+
+	return nil
+}
+
+// ListPermissions returns every active permission with the roles that grant
+// it, which is the permission matrix read by permission rather than by role
+func (s *Service) ListPermissions(ctx context.Context) ([]*PermissionResponse, error) {
+	permissions, err := s.queries.GetAllPermissions(ctx)
+	if err != nil {
+		return nil, errors.Internal("failed to list permissions", err)
+	}
+
+	roles, err := s.queries.ListRoles(ctx)
+	if err != nil {
+		return nil, errors.Internal("failed to list roles", err)
+	}
+
+	grantedBy := make(map[string][]string)
+	for _, role := range roles {
+		codes, err := s.queries.GetRolePermissionCodes(ctx, role.Name)
+		if err != nil {
+			return nil, errors.Internal("failed to list role permissions", err)
+		}
+		for _, code := range codes {
+			grantedBy[code] = append(grantedBy[code], role.Name)
+		}
+	}
+
+	responses := make([]*PermissionResponse, len(permissions))
+	for i, permission := range permissions {
+		responses[i] = toPermissionResponse(&permission, grantedBy[permission.Code])
+	}
+
+	return responses, nil
+}
+
+// GrantPermission lets the role use the permission and returns the updated
+// role. Granting a permission the role has is a no-op.
+func (s *Service) GrantPermission(ctx context.Context, name, code string) (*RoleResponse, error) {
+	role, permission, err := s.rolePermission(ctx, name, code)
+	if err != nil {
+		return nil, err
+	}
+
+	params := db.GetRolePermissionParams{Role: role.Name, PermissionID: permission.ID}
+	if _, err := s.queries.GetRolePermission(ctx, params); err == nil {
+		return s.roleResponse(ctx, role)
+	} else if err != pgx.ErrNoRows {
+		return nil, errors.Internal("failed to check role permission", err)
+	}
+
+	err = s.queries.AssignPermissionToRole(ctx, db.AssignPermissionToRoleParams{
+		Role:         role.Name,
+		PermissionID: permission.ID,
+	})
+	if err != nil {
+		return nil, errors.Internal("failed to grant permission", err)
+	}
+
+	rolePermission, err := s.queries.GetRolePermission(ctx, params)
+	if err != nil {
+		return nil, errors.Internal("failed to grant permission", err)
+	}
+
+	// Audit log
+	s.auditService.LogCreate(ctx, "role_permissions", uuid.UUID(rolePermission.ID.Bytes), toGrant(&rolePermission, code))
+
+	return s.roleResponse(ctx, role)
+}
+
+// RevokePermission takes the permission away from the role and returns the
+// updated role. Revoking a permission the role lacks is a no-op; the role may
+// still have it through inheritance. actorRole is the role of the admin making
+// the change; it cannot revoke ManagePermission from itself or from a role it
+// inherits from.
+func (s *Service) RevokePermission(ctx context.Context, actorRole, name, code string) (*RoleResponse, error) {
+	if code == ManagePermission {
+		if name == actorRole {
+			return nil, errors.Forbidden("cannot revoke " + ManagePermission + " from your own role")
+		}
+
+		lineage, err := s.queries.GetRoleLineage(ctx, actorRole)
+		if err != nil {
+			return nil, errors.Internal("failed to check role inheritance", err)
+		}
+		if slices.Contains(lineage, name) {
+			return nil, errors.Forbidden("cannot revoke " + ManagePermission + " from a role your role inherits from")
+		}
+	}
+
+	role, permission, err := s.rolePermission(ctx, name, code)
+	if err != nil {
+		return nil, err
+	}
+
+	params := db.GetRolePermissionParams{Role: role.Name, PermissionID: permission.ID}
+	rolePermission, err := s.queries.GetRolePermission(ctx, params)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return s.roleResponse(ctx, role)
+		}
+		return nil, errors.Internal("failed to check role permission", err)
+	}
+
+	err = s.queries.RevokePermissionFromRole(ctx, db.RevokePermissionFromRoleParams{
+		Role:         role.Name,
+		PermissionID: permission.ID,
+	})
+	if err != nil {
+		return nil, errors.Internal("failed to revoke permission", err)
+	}
+
+	// Audit log
+	s.auditService.LogDelete(ctx, "role_permissions", uuid.UUID(rolePermission.ID.Bytes), toGrant(&rolePermission, code))
+
+	return s.roleResponse(ctx, role)
+}
+
+// role looks up a role by name
+func (s *Service) role(ctx context.Context, name string) (*db.Role, error) {
+	role, err := s.queries.GetRoleByName(ctx, name)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("role not found")
+		}
+		return nil, errors.Internal("failed to get role", err)
+	}
+
+	return &role, nil
+}
+
+// rolePermission looks up the role and the active permission by code
+func (s *Service) rolePermission(ctx context.Context, name, code string) (*db.Role, *db.Permission, error) {
+	role, err := s.role(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permission, err := s.queries.GetPermissionByCode(ctx, code)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil, errors.NotFound("permission not found")
+		}
+		return nil, nil, errors.Internal("failed to get permission", err)
+	}
+
+	return role, &permission, nil
+}
+
+func (s *Service) roleResponse(ctx context.Context, role *db.Role) (*RoleResponse, error) {
+	direct, err := s.queries.GetDirectRolePermissionCodes(ctx, role.Name)
+	if err != nil {
+		return nil, errors.Internal("failed to list role permissions", err)
+	}
+
+	effective, err := s.queries.GetRolePermissionCodes(ctx, role.Name)
+	if err != nil {
+		return nil, errors.Internal("failed to list role permissions", err)
+	}
+	slices.Sort(effective)
+
+	return &RoleResponse{
+		Role:                 role.Name,
+		Description:          role.Description.String,
+		IsSystem:             role.IsSystem,
+		InheritsFrom:         role.InheritsFrom.String,
+		Permissions:          direct,
+		EffectivePermissions: effective,
+	}, nil
+}
+
+func toGrant(rolePermission *db.RolePermission, code string) *grant {
+	return &grant{
+		ID:             rolePermission.ID,
+		Role:           rolePermission.Role,
+		PermissionID:   rolePermission.PermissionID,
+		PermissionCode: code,
+		CreatedAt:      rolePermission.CreatedAt,
+	}
+}
+
+func toPermissionResponse(permission *db.Permission, roles []string) *PermissionResponse {
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return &PermissionResponse{
+		ID:          uuid.UUID(permission.ID.Bytes).String(),
+		Code:        permission.Code,
+		Name:        permission.Name,
+		Description: permission.Description.String,
+		Category:    permission.Category,
+		Roles:       roles,
+	}
+}
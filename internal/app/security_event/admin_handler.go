@@ -0,0 +1,90 @@
+package security_event
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/response"
+	"github.com/user/coc/internal/validation"
+)
+
+// AdminHandler handles admin security event browsing (read-only)
+type AdminHandler struct {
+	service  *Service
+	validate *validation.Validator
+}
+
+func NewAdminHandler(service *Service, validator *validation.Validator) *AdminHandler {
+	return &AdminHandler{
+		service:  service,
+		validate: validator,
+	}
+}
+
+// ListSecurityEvents handles GET /api/admin/v1/security-events
+// Filters are combined; the date range defaults to the last 30 days
+// @Summary      List security events
+// @Description  Browse authentication activity of users and admins (logins, registrations, token refreshes, lockouts) with combined filters and total count
+// @Tags         Security Events
+// @Accept       json
+// @Produce      json
+// @Param        event_type query string false "Event type" Enums(login, mfa_verify, sso_login, magic_link_request, magic_link_login, registration, token_refresh, logout, logout_all, password_change, password_reset_request, password_reset, account_deletion, account_restore, lockout, unlock)
+// @Param        outcome query string false "Outcome" Enums(success, failure)
+// @Param        actor_type query string false "Account type" Enums(admin, user)
+// @Param        actor_id query string false "Account ID"
+// @Param        identifier query string false "Email or username that was tried"
+// @Param        ip_address query string false "Client IP address"
+// @Param        from query string false "Start of created_at range (RFC3339, default 30 days before 'to')"
+// @Param        to query string false "End of created_at range, exclusive (RFC3339, default now)"
+// @Param        limit query int false "Number of events to return (default 10, max 100)"
+// @Param        offset query int false "Number of events to skip (default 0)"
+// @Success      200 {object} response.JSONResponse{data=SecurityEventListResponse} "Security events retrieved successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/security-events [get]
+func (h *AdminHandler) ListSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.ParseInt(query.Get("limit"), 10, 32)
+	offset, _ := strconv.ParseInt(query.Get("offset"), 10, 32)
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	req := ListSecurityEventsRequest{
+		EventType:  query.Get("event_type"),
+		Outcome:    query.Get("outcome"),
+		ActorType:  query.Get("actor_type"),
+		ActorID:    query.Get("actor_id"),
+		Identifier: query.Get("identifier"),
+		IPAddress:  query.Get("ip_address"),
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		errorMsg := h.validate.TranslateErrors(err)
+		response.Error(w, http.StatusBadRequest, errorMsg)
+		return
+	}
+
+	events, err := h.service.ListSecurityEvents(r.Context(), req)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "security events retrieved successfully", events)
+}
@@ -0,0 +1,69 @@
+package security_event
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/validation"
+)
+
+func newSecurityEventRequest(method, url string, role string) (*http.Request, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, url, nil)
+	if role != "" {
+		req = req.WithContext(context.WithValue(req.Context(), ctxkeys.AdminRoleContextKey, role))
+	}
+	return req, httptest.NewRecorder()
+}
+
+// TestAdminHandler_ListSecurityEvents_MissingAdminRole tests that listing requires an admin role in context
+func TestAdminHandler_ListSecurityEvents_MissingAdminRole(t *testing.T) {
+	handler := NewAdminHandler(nil, validation.New())
+	req, rec := newSecurityEventRequest("GET", "/api/admin/v1/security-events", "")
+
+	handler.ListSecurityEvents(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 Unauthorized, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	if response["message"] != "admin role not found" {
+		t.Errorf("expected 'admin role not found' message, got %s", response["message"])
+	}
+}
+
+// TestAdminHandler_ListSecurityEvents_InvalidFilters tests that invalid query filters are rejected before hitting the database
+func TestAdminHandler_ListSecurityEvents_InvalidFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid event_type", query: "event_type=teleport"},
+		{name: "invalid outcome", query: "outcome=maybe"},
+		{name: "invalid actor_type", query: "actor_type=system"},
+		{name: "invalid actor_id", query: "actor_id=not-a-uuid"},
+		{name: "invalid ip_address", query: "ip_address=localhost"},
+		{name: "limit too large", query: "limit=500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(nil, validation.New())
+			req, rec := newSecurityEventRequest("GET", "/api/admin/v1/security-events?"+tt.query, "super_admin")
+
+			handler.ListSecurityEvents(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package security_event
+
+import "encoding/json"
+
+// ListSecurityEventsRequest represents the filters for browsing security events
+// All filters are optional and combined with AND.
+type ListSecurityEventsRequest struct {
+	EventType  string `json:"event_type" validate:"omitempty,oneof=login mfa_verify sso_login magic_link_request magic_link_login registration token_refresh logout logout_all password_change password_reset_request password_reset account_deletion account_restore lockout unlock" example:"login"`
+	Outcome    string `json:"outcome" validate:"omitempty,oneof=success failure" example:"failure"`
+	ActorType  string `json:"actor_type" validate:"omitempty,oneof=admin user" example:"user"`
+	ActorID    string `json:"actor_id" validate:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Identifier string `json:"identifier" validate:"omitempty,max=255" example:"jane@example.com"`
+	IPAddress  string `json:"ip_address" validate:"omitempty,ip" example:"203.0.113.10"`
+	// From and To bound created_at (RFC3339). Defaults to the last 30 days.
+	From   string `json:"from" example:"2026-01-01T00:00:00Z"`
+	To     string `json:"to" example:"2026-02-01T00:00:00Z"`
+	Limit  int32  `json:"limit" validate:"omitempty,min=1,max=100" example:"10"`
+	Offset int32  `json:"offset" validate:"omitempty,min=0" example:"0"`
+}
+
+// SecurityEventResponse represents a security event
+type SecurityEventResponse struct {
+	ID         string          `json:"id" example:"750e8400-e29b-41d4-a716-446655440002"`
+	EventType  string          `json:"event_type" example:"login"`
+	Outcome    string          `json:"outcome" example:"failure"`
+	ActorType  string          `json:"actor_type" example:"user"`
+	ActorID    string          `json:"actor_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Identifier string          `json:"identifier,omitempty" example:"jane@example.com"`
+	Reason     string          `json:"reason,omitempty" example:"invalid_password"`
+	RequestID  string          `json:"request_id,omitempty" example:"b3c1f7a2-1d2e-4f5a-9b8c-7d6e5f4a3b2c"`
+	IPAddress  string          `json:"ip_address,omitempty" example:"203.0.113.10"`
+	UserAgent  string          `json:"user_agent,omitempty" example:"Mozilla/5.0"`
+	Metadata   json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+	CreatedAt  string          `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// SecurityEventListResponse represents a page of security events
+type SecurityEventListResponse struct {
+	Items  []*SecurityEventResponse `json:"items"`
+	Total  int64                    `json:"total" example:"42"`
+	Limit  int32                    `json:"limit" example:"10"`
+	Offset int32                    `json:"offset" example:"0"`
+	From   string                   `json:"from" example:"2026-01-01T00:00:00Z"`
+	To     string                   `json:"to" example:"2026-02-01T00:00:00Z"`
+}
+
+// ActivityResponse represents one entry of a user's own sign-in activity
+type ActivityResponse struct {
+	EventType string `json:"event_type" example:"login"`
+	Outcome   string `json:"outcome" example:"success"`
+	Reason    string `json:"reason,omitempty" example:"invalid_password"`
+	IPAddress string `json:"ip_address,omitempty" example:"203.0.113.10"`
+	UserAgent string `json:"user_agent,omitempty" example:"Mozilla/5.0"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
@@ -0,0 +1,53 @@
+package security_event
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/response"
+)
+
+// FrontendHandler shows users their own sign-in activity
+type FrontendHandler struct {
+	service *Service
+}
+
+func NewFrontendHandler(service *Service) *FrontendHandler {
+	return &FrontendHandler{
+		service: service,
+	}
+}
+
+// ListActivity handles GET /api/v1/user/activity
+// @Summary      List own sign-in activity
+// @Description  Recent logins, failed login attempts, logouts and password changes of the authenticated user, newest first (last 90 days, at most 50 entries)
+// @Tags         User Profile
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} response.JSONResponse{data=[]ActivityResponse} "Activity retrieved successfully"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Security     BearerAuth
+// @Router       /api/v1/user/activity [get]
+func (h *FrontendHandler) ListActivity(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context (set by auth middleware)
+	userIDStr, ok := ctxkeys.GetUserID(r)
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "invalid user ID format")
+		return
+	}
+
+	activity, err := h.service.ListUserActivity(r.Context(), userID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "activity retrieved successfully", activity)
+}
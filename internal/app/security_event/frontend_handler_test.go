@@ -0,0 +1,37 @@
+package security_event
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/user/coc/internal/ctxkeys"
+)
+
+// TestFrontendHandler_ListActivity_NotAuthenticated tests that activity requires a signed-in user
+func TestFrontendHandler_ListActivity_NotAuthenticated(t *testing.T) {
+	handler := NewFrontendHandler(nil)
+	req := httptest.NewRequest("GET", "/api/v1/user/activity", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListActivity(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 Unauthorized, got %d", rec.Code)
+	}
+}
+
+// TestFrontendHandler_ListActivity_InvalidUserID tests that a malformed user ID in context is rejected
+func TestFrontendHandler_ListActivity_InvalidUserID(t *testing.T) {
+	handler := NewFrontendHandler(nil)
+	req := httptest.NewRequest("GET", "/api/v1/user/activity", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.UserIDContextKey, "not-a-uuid"))
+	rec := httptest.NewRecorder()
+
+	handler.ListActivity(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request, got %d", rec.Code)
+	}
+}
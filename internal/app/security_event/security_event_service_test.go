@@ -0,0 +1,88 @@
+package security_event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+)
+
+// TestBuildFilter_DefaultRange tests that the date range defaults to the last 30 days
+func TestBuildFilter_DefaultRange(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	filter, err := buildFilter(ListSecurityEventsRequest{}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !filter.CreatedTo.Time.Equal(now) {
+		t.Errorf("expected to=%v, got %v", now, filter.CreatedTo.Time)
+	}
+	if !filter.CreatedFrom.Time.Equal(now.Add(-defaultLookback)) {
+		t.Errorf("expected from=%v, got %v", now.Add(-defaultLookback), filter.CreatedFrom.Time)
+	}
+	if filter.EventType.Valid || filter.Outcome.Valid || filter.ActorType.Valid || filter.ActorID.Valid || filter.Identifier.Valid || filter.IpAddress.Valid {
+		t.Errorf("expected optional filters to be unset, got %+v", filter)
+	}
+}
+
+// TestBuildFilter_AllFilters tests that every filter is mapped onto the query params
+func TestBuildFilter_AllFilters(t *testing.T) {
+	req := ListSecurityEventsRequest{
+		EventType:  "login",
+		Outcome:    "failure",
+		ActorType:  "user",
+		ActorID:    "550e8400-e29b-41d4-a716-446655440000",
+		Identifier: "jane@example.com",
+		IPAddress:  "203.0.113.10",
+		From:       "2026-01-01T00:00:00Z",
+		To:         "2026-02-01T00:00:00Z",
+	}
+
+	filter, err := buildFilter(req, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if filter.EventType.String != "login" || filter.Outcome.String != "failure" || !filter.ActorID.Valid {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+	if filter.Identifier.String != "jane@example.com" || filter.IpAddress.String != "203.0.113.10" {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+	if filter.ActorType.AuditActorType != db.AuditActorTypeUser {
+		t.Errorf("expected actor type user, got %v", filter.ActorType.AuditActorType)
+	}
+}
+
+// TestBuildFilter_InvalidRange tests that invalid or inverted date ranges are rejected
+func TestBuildFilter_InvalidRange(t *testing.T) {
+	tests := []struct {
+		name string
+		req  ListSecurityEventsRequest
+	}{
+		{name: "bad from", req: ListSecurityEventsRequest{From: "yesterday"}},
+		{name: "bad to", req: ListSecurityEventsRequest{To: "2026-13-01"}},
+		{name: "inverted", req: ListSecurityEventsRequest{From: "2026-02-01T00:00:00Z", To: "2026-01-01T00:00:00Z"}},
+		{name: "bad actor id", req: ListSecurityEventsRequest{ActorID: "nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildFilter(tt.req, time.Now()); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+// TestActivityEvents tests that token refreshes are kept out of the user's activity
+func TestActivityEvents(t *testing.T) {
+	for _, eventType := range activityEvents {
+		if eventType == string(audit.EventTokenRefresh) {
+			t.Error("expected token refreshes not to be shown as activity")
+		}
+	}
+}
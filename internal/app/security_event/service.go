@@ -0,0 +1,233 @@
+package security_event
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// defaultLookback is the date range searched when no from/to is given
+const defaultLookback = 30 * 24 * time.Hour
+
+// activityLookback and activityLimit bound the activity shown to users
+const (
+	activityLookback = 90 * 24 * time.Hour
+	activityLimit    = 50
+)
+
+// activityEvents are the events users see in their own activity.
+// Token refreshes happen every few minutes and would drown out the rest.
+var activityEvents = []string{
+	string(audit.EventLogin),
+	string(audit.EventMagicLinkLogin),
+	string(audit.EventRegistration),
+	string(audit.EventLogout),
+	string(audit.EventLogoutAll),
+	string(audit.EventPasswordChange),
+	string(audit.EventPasswordResetRequest),
+	string(audit.EventPasswordReset),
+	string(audit.EventAccountDeletion),
+	string(audit.EventAccountRestore),
+}
+
+// Service contains business logic for browsing security events and purging
+// them past their retention. The events are written by audit.Service.LogSecurityEvent.
+type Service struct {
+	queries *db.Queries
+}
+
+func NewService(queries *db.Queries) *Service {
+	return &Service{
+		queries: queries,
+	}
+}
+
+// ListSecurityEvents returns security events matching the combined filters, newest first
+func (s *Service) ListSecurityEvents(ctx context.Context, req ListSecurityEventsRequest) (*SecurityEventListResponse, error) {
+	filter, err := buildFilter(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	events, err := s.queries.SearchSecurityEvents(ctx, db.SearchSecurityEventsParams{
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+		EventType:   filter.EventType,
+		Outcome:     filter.Outcome,
+		ActorType:   filter.ActorType,
+		ActorID:     filter.ActorID,
+		Identifier:  filter.Identifier,
+		IpAddress:   filter.IpAddress,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+	})
+	if err != nil {
+		slog.Error("failed to search security events", "error", err)
+		return nil, errors.Internal("failed to list security events", err)
+	}
+
+	total, err := s.queries.CountSearchSecurityEvents(ctx, filter)
+	if err != nil {
+		slog.Error("failed to count security events", "error", err)
+		return nil, errors.Internal("failed to count security events", err)
+	}
+
+	items := make([]*SecurityEventResponse, 0, len(events))
+	for i := range events {
+		items = append(items, toSecurityEventResponse(&events[i]))
+	}
+
+	return &SecurityEventListResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+		From:   filter.CreatedFrom.Time.Format(time.RFC3339),
+		To:     filter.CreatedTo.Time.Format(time.RFC3339),
+	}, nil
+}
+
+// ListUserActivity returns the recent sign-in activity of a user, newest first
+func (s *Service) ListUserActivity(ctx context.Context, userID uuid.UUID) ([]ActivityResponse, error) {
+	events, err := s.queries.ListRecentSecurityEventsByActor(ctx, db.ListRecentSecurityEventsByActorParams{
+		ActorType:  db.AuditActorTypeUser,
+		ActorID:    pgtype.UUID{Bytes: userID, Valid: true},
+		EventTypes: activityEvents,
+		Since:      pgtype.Timestamptz{Time: time.Now().Add(-activityLookback), Valid: true},
+		Limit:      activityLimit,
+	})
+	if err != nil {
+		slog.Error("failed to list user activity", "user_id", userID.String(), "error", err)
+		return nil, errors.Internal("failed to list activity", err)
+	}
+
+	activity := make([]ActivityResponse, 0, len(events))
+	for _, event := range events {
+		activity = append(activity, ActivityResponse{
+			EventType: event.EventType,
+			Outcome:   event.Outcome,
+			Reason:    event.Reason.String,
+			IPAddress: event.IpAddress.String,
+			UserAgent: event.UserAgent.String,
+			CreatedAt: event.CreatedAt.Time.Format(time.RFC3339),
+		})
+	}
+
+	return activity, nil
+}
+
+// StartRetention deletes events older than retention immediately and then on every
+// interval until ctx is cancelled. A zero retention keeps events forever.
+func (s *Service) StartRetention(ctx context.Context, interval, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			deleted, err := s.queries.DeleteSecurityEventsBefore(ctx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+			if err != nil {
+				slog.Error("failed to delete expired security events", "error", err)
+			} else if deleted > 0 {
+				slog.Info("deleted expired security events", "deleted", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// buildFilter converts request filters into query parameters.
+// The date range defaults to the 30 days before now.
+func buildFilter(req ListSecurityEventsRequest, now time.Time) (db.CountSearchSecurityEventsParams, error) {
+	filter := db.CountSearchSecurityEventsParams{
+		EventType:  pgtype.Text{String: req.EventType, Valid: req.EventType != ""},
+		Outcome:    pgtype.Text{String: req.Outcome, Valid: req.Outcome != ""},
+		Identifier: pgtype.Text{String: req.Identifier, Valid: req.Identifier != ""},
+		IpAddress:  pgtype.Text{String: req.IPAddress, Valid: req.IPAddress != ""},
+	}
+
+	if req.ActorID != "" {
+		actorID, err := uuid.Parse(req.ActorID)
+		if err != nil {
+			return filter, errors.Validation("invalid actor ID format")
+		}
+		filter.ActorID = pgtype.UUID{Bytes: actorID, Valid: true}
+	}
+
+	if req.ActorType != "" {
+		filter.ActorType = db.NullAuditActorType{AuditActorType: db.AuditActorType(req.ActorType), Valid: true}
+	}
+
+	to := now
+	if req.To != "" {
+		parsed, err := time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			return filter, errors.Validation("invalid 'to' date, expected RFC3339")
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultLookback)
+	if req.From != "" {
+		parsed, err := time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			return filter, errors.Validation("invalid 'from' date, expected RFC3339")
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return filter, errors.Validation("'from' must be before 'to'")
+	}
+
+	filter.CreatedFrom = pgtype.Timestamptz{Time: from, Valid: true}
+	filter.CreatedTo = pgtype.Timestamptz{Time: to, Valid: true}
+
+	return filter, nil
+}
+
+func toSecurityEventResponse(event *db.SecurityEvent) *SecurityEventResponse {
+	resp := &SecurityEventResponse{
+		ID:         uuid.UUID(event.ID.Bytes).String(),
+		EventType:  event.EventType,
+		Outcome:    event.Outcome,
+		ActorType:  string(event.ActorType),
+		Identifier: event.Identifier.String,
+		Reason:     event.Reason.String,
+		RequestID:  event.RequestID.String,
+		IPAddress:  event.IpAddress.String,
+		UserAgent:  event.UserAgent.String,
+		Metadata:   event.Metadata,
+		CreatedAt:  event.CreatedAt.Time.Format(time.RFC3339),
+	}
+
+	if event.ActorID.Valid {
+		resp.ActorID = uuid.UUID(event.ActorID.Bytes).String()
+	}
+
+	return resp
+}
@@ -227,3 +227,147 @@ func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, http.StatusOK, "user deleted successfully", nil)
 }
+
+// UnlockUser handles POST /api/admin/v1/users/{id}/unlock
+// @Summary      Unlock user login (admin)
+// @Description  Lift a lockout after too many failed logins and clear the user's failed attempts
+// @Tags         Admin User Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} response.JSONResponse "User unlocked successfully"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      404 {object} response.JSONResponse "User not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "user ID is required")
+		return
+	}
+
+	if err := h.service.UnlockUser(r.Context(), id); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "user unlocked successfully", nil)
+}
+
+// ListUserSessions handles GET /api/admin/v1/users/{id}/sessions
+// @Summary      List user sessions (admin)
+// @Description  List the devices a user is signed in on, most recently used first
+// @Tags         Admin User Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} response.JSONResponse{data=[]SessionResponse} "Sessions retrieved"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      404 {object} response.JSONResponse "User not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/users/{id}/sessions [get]
+func (h *AdminHandler) ListUserSessions(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "user ID is required")
+		return
+	}
+
+	sessions, err := h.service.ListUserSessions(r.Context(), id)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "sessions retrieved successfully", sessions)
+}
+
+// RevokeUserSession handles DELETE /api/admin/v1/users/{id}/sessions/{session_id}
+// @Summary      Sign out a user session (admin)
+// @Description  Sign a user out of one device. Its tokens stop working immediately.
+// @Tags         Admin User Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Param        session_id path string true "Session ID"
+// @Success      200 {object} response.JSONResponse "Session revoked"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      404 {object} response.JSONResponse "Session not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/users/{id}/sessions/{session_id} [delete]
+func (h *AdminHandler) RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	// REQUIRED: Check admin role first
+	role, ok := ctxkeys.GetAdminRole(r)
+	if !ok || role == "" {
+		response.Error(w, http.StatusUnauthorized, "admin role not found")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	sessionID := chi.URLParam(r, "session_id")
+	if id == "" || sessionID == "" {
+		response.Error(w, http.StatusBadRequest, "user ID and session ID are required")
+		return
+	}
+
+	if err := h.service.RevokeUserSession(r.Context(), id, sessionID); err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "session revoked", nil)
+}
+
+// ImpersonateUser handles POST /api/admin/v1/users/{id}/impersonate
+// @Summary      Impersonate user (admin)
+// @Description  Get a short-lived frontend access token to act as the user. The token cannot be refreshed, every action is audited with both the user and the admin, and account security actions such as changing the password are refused.
+// @Tags         Admin User Management
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "User ID"
+// @Success      200 {object} response.JSONResponse{data=ImpersonationResponse} "Impersonation started"
+// @Failure      400 {object} response.JSONResponse "Invalid request"
+// @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Forbidden"
+// @Failure      404 {object} response.JSONResponse "User not found"
+// @Security     BearerAuth
+// @Router       /api/admin/v1/users/{id}/impersonate [post]
+func (h *AdminHandler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := ctxkeys.GetAdminID(r)
+	if !ok || adminID == "" {
+		response.Error(w, http.StatusUnauthorized, "admin not authenticated")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		response.Error(w, http.StatusBadRequest, "user ID is required")
+		return
+	}
+
+	resp, err := h.service.ImpersonateUser(r.Context(), id, adminID)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, "impersonation started", resp)
+}
@@ -128,3 +128,65 @@ func TestAdminHandler_DeleteUser_MissingUserID(t *testing.T) {
 		t.Errorf("expected 400, got %d", rec.Code)
 	}
 }
+
+func TestAdminHandler_UnlockUser_MissingUserID(t *testing.T) {
+	handler := NewAdminHandler(nil, validation.New())
+	req, rec := newAdminRequest("POST", "/users//unlock", nil)
+	// Missing user ID in URL params
+
+	handler.UnlockUser(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_ImpersonateUser_NotAuthenticated(t *testing.T) {
+	handler := NewAdminHandler(nil, validation.New())
+	req, rec := newAdminRequest("POST", "/users/550e8400-e29b-41d4-a716-446655440000/impersonate", nil)
+	// No admin ID in context
+
+	handler.ImpersonateUser(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_ImpersonateUser_MissingUserID(t *testing.T) {
+	handler := NewAdminHandler(nil, validation.New())
+	req, rec := newAdminRequest("POST", "/users//impersonate", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxkeys.AdminIDContextKey, "550e8400-e29b-41d4-a716-446655440000"))
+	// Missing user ID in URL params
+
+	handler.ImpersonateUser(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_ListUserSessions_MissingAdminRole(t *testing.T) {
+	handler := NewAdminHandler(nil, validation.New())
+	req := httptest.NewRequest("GET", "/users/550e8400-e29b-41d4-a716-446655440000/sessions", nil)
+	// No admin role in context
+	rec := httptest.NewRecorder()
+
+	handler.ListUserSessions(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_RevokeUserSession_MissingSessionID(t *testing.T) {
+	handler := NewAdminHandler(nil, validation.New())
+	req, rec := newAdminRequest("DELETE", "/users/550e8400-e29b-41d4-a716-446655440000/sessions/", nil)
+	// Missing URL params
+
+	handler.RevokeUserSession(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
@@ -4,34 +4,68 @@ import (
 	"context"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/accountdeletion"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/errors"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/user/coc/internal/lockout"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
+	"github.com/user/coc/internal/usersession"
 )
 
+// EmailVerifier sends a link that proves the user controls their email address
+type EmailVerifier interface {
+	SendVerificationEmail(ctx context.Context, user *db.User) error
+}
+
+// Impersonator issues access tokens that let an admin act as a user
+type Impersonator interface {
+	Impersonate(ctx context.Context, user *db.User, adminID uuid.UUID) (string, time.Time, error)
+}
+
 // AdminService contains business logic for admin operations on users
 // Admin can operate on any user (create/read/update/delete)
 type AdminService struct {
 	queries      *db.Queries
 	auditService *audit.Service
+	verifier     EmailVerifier // optional; nil sends no verification emails
+	logins       *lockout.Guard
+	impersonator Impersonator // optional; nil disables impersonation
+	sessions     *usersession.Store
+	deletions    *accountdeletion.Service
 }
 
-func NewAdminService(queries *db.Queries, auditService *audit.Service) *AdminService {
+// AdminServiceDeps holds the collaborators of AdminService beyond the database and audit log
+type AdminServiceDeps struct {
+	Verifier     EmailVerifier // optional; nil sends no verification emails
+	Logins       *lockout.Guard
+	Impersonator Impersonator // optional; nil disables impersonation
+	Sessions     *usersession.Store
+	Deletions    *accountdeletion.Service
+}
+
+func NewAdminService(queries *db.Queries, auditService *audit.Service, deps AdminServiceDeps) *AdminService {
 	return &AdminService{
 		queries:      queries,
 		auditService: auditService,
+		verifier:     deps.Verifier,
+		logins:       deps.Logins,
+		impersonator: deps.Impersonator,
+		sessions:     deps.Sessions,
+		deletions:    deps.Deletions,
 	}
 }
 
 // CreateUser creates a new user
 func (s *AdminService) CreateUser(ctx context.Context, req CreateUserRequest) (*UserResponse, error) {
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := passhash.Hash(req.Password)
 	if err != nil {
 		slog.Error("failed to hash password", "error", err)
 		return nil, errors.Internal("failed to hash password", err)
@@ -65,7 +99,7 @@ func (s *AdminService) CreateUser(ctx context.Context, req CreateUserRequest) (*
 	user, err := s.queries.CreateUser(ctx, db.CreateUserParams{
 		Email:        req.Email,
 		Username:     req.Username,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		FirstName:    pgtype.Text{String: req.FirstName, Valid: req.FirstName != ""},
 		LastName:     pgtype.Text{String: req.LastName, Valid: req.LastName != ""},
 	})
@@ -78,6 +112,8 @@ func (s *AdminService) CreateUser(ctx context.Context, req CreateUserRequest) (*
 	userID := uuid.UUID(user.ID.Bytes)
 	s.auditService.LogCreate(ctx, "users", userID, user)
 
+	s.sendVerificationEmail(ctx, &user)
+
 	return toUserResponse(&user), nil
 }
 
@@ -158,17 +194,32 @@ func (s *AdminService) UpdateUser(ctx context.Context, id string, req UpdateUser
 	// Audit log the user update
 	s.auditService.LogUpdate(ctx, "users", userID, oldUser, user)
 
+	// UpdateUser cleared email_verified_at, so the new address has to be verified
+	if user.Email != oldUser.Email {
+		s.sendVerificationEmail(ctx, &user)
+	}
+
 	return toUserResponse(&user), nil
 }
 
-// DeleteUser deletes a user
+// DeleteUser deletes a user right away. The account is anonymized rather than
+// removed, so its addresses and audit history keep referencing it.
 func (s *AdminService) DeleteUser(ctx context.Context, id string) error {
 	userID, err := uuid.Parse(id)
 	if err != nil {
 		return errors.Validation("invalid user ID format")
 	}
 
-	// Check if user exists
+	return s.deletions.Anonymize(ctx, userID)
+}
+
+// UnlockUser lifts a login lockout of a user and clears their failed login attempts
+func (s *AdminService) UnlockUser(ctx context.Context, id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.Validation("invalid user ID format")
+	}
+
 	user, err := s.queries.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
 	if err == pgx.ErrNoRows {
 		return errors.NotFound("user not found")
@@ -177,16 +228,99 @@ func (s *AdminService) DeleteUser(ctx context.Context, id string) error {
 		return errors.Internal("failed to get user", err)
 	}
 
-	err = s.queries.DeleteUser(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	// Users log in with their email, which is what failed attempts are counted for
+	return s.logins.Unlock(ctx, refreshtoken.SubjectUser, user.Email)
+}
+
+// ImpersonateUser issues a short-lived access token for the user to the admin,
+// who can then use the frontend API as that user
+func (s *AdminService) ImpersonateUser(ctx context.Context, id, adminID string) (*ImpersonationResponse, error) {
+	if s.impersonator == nil {
+		return nil, errors.NotFound("impersonation is not enabled")
+	}
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.Validation("invalid user ID format")
+	}
+	actorID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, errors.Validation("invalid admin ID format")
+	}
+
+	user, err := s.queries.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("user not found")
+	} else if err != nil {
+		slog.Error("failed to get user", "id", id, "error", err)
+		return nil, errors.Internal("failed to get user", err)
+	}
+
+	token, expiresAt, err := s.impersonator.Impersonate(ctx, &user, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("admin started impersonating user", "admin_id", adminID, "user_id", id)
+
+	return &ImpersonationResponse{
+		Token:     token,
+		ExpiresIn: int64(time.Until(expiresAt).Seconds()),
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+		User:      *toUserResponse(&user),
+	}, nil
+}
+
+// ListUserSessions returns the devices a user is signed in on
+func (s *AdminService) ListUserSessions(ctx context.Context, id string) ([]SessionResponse, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.Validation("invalid user ID format")
+	}
+
+	if _, err := s.queries.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true}); err == pgx.ErrNoRows {
+		return nil, errors.NotFound("user not found")
+	} else if err != nil {
+		slog.Error("failed to get user", "id", id, "error", err)
+		return nil, errors.Internal("failed to get user", err)
+	}
+
+	sessions, err := s.sessions.List(ctx, userID)
 	if err != nil {
-		slog.Error("failed to delete user", "id", id, "error", err)
-		return errors.Internal("failed to delete user", err)
+		return nil, err
 	}
 
-	// Audit log the user deletion
-	s.auditService.LogDelete(ctx, "users", userID, user)
+	responses := make([]SessionResponse, len(sessions))
+	for i := range sessions {
+		responses[i] = *toSessionResponse(&sessions[i])
+	}
+	return responses, nil
+}
 
-	return nil
+// RevokeUserSession signs a user out of one of their sessions
+func (s *AdminService) RevokeUserSession(ctx context.Context, id, sessionID string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.Validation("invalid user ID format")
+	}
+
+	sid, err := uuid.Parse(sessionID)
+	if err != nil {
+		return errors.Validation("invalid session ID format")
+	}
+
+	return s.sessions.Revoke(ctx, userID, sid)
+}
+
+// sendVerificationEmail logs failures instead of returning them: the change is already saved
+// and the user can request a new link
+func (s *AdminService) sendVerificationEmail(ctx context.Context, user *db.User) {
+	if s.verifier == nil {
+		return
+	}
+	if err := s.verifier.SendVerificationEmail(ctx, user); err != nil {
+		slog.Error("failed to send verification email", "user_id", uuid.UUID(user.ID.Bytes).String(), "error", err)
+	}
 }
 
 // small helpers moved here to avoid duplication
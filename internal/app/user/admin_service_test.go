@@ -3,7 +3,10 @@ package user
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/errors"
 )
 
@@ -168,6 +171,43 @@ func TestAdminService_DeleteUser_InvalidUUID(t *testing.T) {
 	}
 }
 
+func TestAdminService_ImpersonateUser_Disabled(t *testing.T) {
+	service := &AdminService{}
+
+	_, err := service.ImpersonateUser(context.Background(), "550e8400-e29b-41d4-a716-446655440000", "650e8400-e29b-41d4-a716-446655440001")
+
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		t.Fatalf("expected DomainError, got %T", err)
+	}
+
+	if domainErr.Code != errors.CodeNotFound {
+		t.Errorf("expected not found error, got: %s", domainErr.Code)
+	}
+}
+
+func TestAdminService_ImpersonateUser_InvalidUUID(t *testing.T) {
+	service := &AdminService{impersonator: stubImpersonator{}}
+
+	_, err := service.ImpersonateUser(context.Background(), "invalid-uuid", "650e8400-e29b-41d4-a716-446655440001")
+
+	domainErr, ok := err.(*errors.DomainError)
+	if !ok {
+		t.Fatalf("expected DomainError, got %T", err)
+	}
+
+	if domainErr.Code != errors.CodeValidation {
+		t.Errorf("expected validation error, got: %s", domainErr.Code)
+	}
+}
+
+// stubImpersonator is an Impersonator that is never reached by the tests above
+type stubImpersonator struct{}
+
+func (stubImpersonator) Impersonate(context.Context, *db.User, uuid.UUID) (string, time.Time, error) {
+	return "", time.Time{}, nil
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
@@ -19,14 +19,16 @@ type UpdateUserRequest struct {
 
 // UserResponse represents the user response
 type UserResponse struct {
-	ID               string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Email            string `json:"email" example:"john.doe@example.com"`
-	Username         string `json:"username" example:"johndoe"`
-	FirstName        string `json:"first_name,omitempty" example:"John"`
-	LastName         string `json:"last_name,omitempty" example:"Doe"`
-	DefaultAddressID string `json:"default_address_id,omitempty" example:"650e8400-e29b-41d4-a716-446655440001"`
-	CreatedAt        string `json:"created_at" example:"2024-01-01T12:00:00Z"`
-	UpdatedAt        string `json:"updated_at" example:"2024-01-02T15:30:00Z"`
+	ID                  string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email               string `json:"email" example:"john.doe@example.com"`
+	Username            string `json:"username" example:"johndoe"`
+	FirstName           string `json:"first_name,omitempty" example:"John"`
+	LastName            string `json:"last_name,omitempty" example:"Doe"`
+	DefaultAddressID    string `json:"default_address_id,omitempty" example:"650e8400-e29b-41d4-a716-446655440001"`
+	EmailVerifiedAt     string `json:"email_verified_at,omitempty" example:"2024-01-01T12:05:00Z"`
+	DeletionScheduledAt string `json:"deletion_scheduled_at,omitempty" example:"2024-01-31T12:00:00Z"`
+	CreatedAt           string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt           string `json:"updated_at" example:"2024-01-02T15:30:00Z"`
 }
 
 // ListUsersRequest represents the request to list users
@@ -34,3 +36,21 @@ type ListUsersRequest struct {
 	Limit  int32 `json:"limit" validate:"omitempty,min=1,max=100" example:"10"`
 	Offset int32 `json:"offset" validate:"omitempty,min=0" example:"0"`
 }
+
+// ImpersonationResponse is the access token an admin uses to act as the user.
+// It cannot be refreshed and some account actions are refused with it.
+type ImpersonationResponse struct {
+	Token     string       `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn int64        `json:"expires_in" example:"900"`
+	ExpiresAt string       `json:"expires_at" example:"2024-01-01T12:15:00Z"`
+	User      UserResponse `json:"user"`
+}
+
+// SessionResponse represents a device a user is signed in on
+type SessionResponse struct {
+	ID         string `json:"id" example:"0b9d3c6e-5f1a-4c2e-9a7b-8d4e2f6a1c3b"`
+	UserAgent  string `json:"user_agent,omitempty" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) Safari/605.1.15"`
+	IPAddress  string `json:"ip_address,omitempty" example:"203.0.113.7"`
+	CreatedAt  string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	LastSeenAt string `json:"last_seen_at" example:"2024-01-02T15:30:00Z"`
+}
@@ -60,6 +60,7 @@ func (h *FrontendHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 // @Success      200 {object} response.JSONResponse{data=UserResponse} "Profile updated successfully"
 // @Failure      400 {object} response.JSONResponse "Invalid request"
 // @Failure      401 {object} response.JSONResponse "Unauthorized"
+// @Failure      403 {object} response.JSONResponse "Email or username change while impersonating"
 // @Security     BearerAuth
 // @Router       /api/v1/users/me [put]
 func (h *FrontendHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
@@ -82,6 +83,12 @@ func (h *FrontendHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// With the email an impersonating admin could take the account over through a password reset
+	if _, impersonated := ctxkeys.GetImpersonatorID(r); impersonated && (req.Email != nil || req.Username != nil) {
+		response.Error(w, http.StatusForbidden, "email and username cannot be changed while impersonating a user")
+		return
+	}
+
 	user, err := h.service.UpdateUser(r.Context(), userID, req)
 	if err != nil {
 		response.HandleServiceError(w, err)
@@ -80,4 +80,22 @@ func TestFrontendHandler_UpdateMe_ValidationError(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Errorf("expected 400, got %d", rec.Code)
 	}
-}
\ No newline at end of file
+}
+func TestFrontendHandler_UpdateMe_ImpersonatorCannotChangeEmail(t *testing.T) {
+	handler := NewFrontendHandler(nil, validation.New())
+
+	for _, body := range []map[string]interface{}{
+		{"email": "attacker@example.com"},
+		{"username": "attacker"},
+	} {
+		req, rec := newUserRequest("PUT", "/users/me", body)
+		ctx := context.WithValue(req.Context(), ctxkeys.ImpersonatorIDContextKey, "650e8400-e29b-41d4-a716-446655440000")
+		req = req.WithContext(ctx)
+
+		handler.UpdateMe(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected 403 for %v, got %d", body, rec.Code)
+		}
+	}
+}
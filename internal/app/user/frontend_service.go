@@ -15,9 +15,9 @@ type FrontendService struct {
 	auditService *audit.Service
 }
 
-func NewFrontendService(queries *db.Queries, auditService *audit.Service) *FrontendService {
+func NewFrontendService(queries *db.Queries, auditService *audit.Service, verifier EmailVerifier) *FrontendService {
 	return &FrontendService{
-		adminService: NewAdminService(queries, auditService),
+		adminService: NewAdminService(queries, auditService, AdminServiceDeps{Verifier: verifier}), // users cannot unlock logins, impersonate, manage sessions or delete accounts here
 		queries:      queries,
 		auditService: auditService,
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/user/coc/internal/accountdeletion"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
 	"golang.org/x/crypto/bcrypt"
@@ -74,7 +75,7 @@ func TestIntegration_AdminService_CreateUser(t *testing.T) {
 
 	// Use transaction for queries
 	qtx := queries.WithTx(tx)
-	service := NewAdminService(qtx, auditService)
+	service := NewAdminService(qtx, auditService, AdminServiceDeps{})
 
 	// Test: Create user
 	req := CreateUserRequest{
@@ -170,7 +171,7 @@ func TestIntegration_AdminService_UpdateUser(t *testing.T) {
 	defer tx.Rollback(ctx)
 
 	qtx := queries.WithTx(tx)
-	service := NewAdminService(qtx, auditService)
+	service := NewAdminService(qtx, auditService, AdminServiceDeps{})
 
 	// Create test user
 	user, err := qtx.CreateUser(ctx, db.CreateUserParams{
@@ -267,7 +268,7 @@ func TestIntegration_AdminService_DeleteUser(t *testing.T) {
 	defer tx.Rollback(ctx)
 
 	qtx := queries.WithTx(tx)
-	service := NewAdminService(qtx, auditService)
+	service := NewAdminService(qtx, auditService, AdminServiceDeps{Deletions: accountdeletion.NewService(qtx, auditService, 0)})
 
 	// Create test user
 	user, err := qtx.CreateUser(ctx, db.CreateUserParams{
@@ -289,10 +290,21 @@ func TestIntegration_AdminService_DeleteUser(t *testing.T) {
 		t.Fatalf("DeleteUser failed: %v", err)
 	}
 
-	// Verify: User deleted from database
-	_, err = qtx.GetUserByID(ctx, user.ID)
-	if err == nil {
-		t.Error("expected user to be deleted, but it still exists")
+	// Verify: User kept for referential integrity, with personal data removed
+	deleted, err := qtx.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("expected anonymized user to be kept: %v", err)
+	}
+	if !deleted.AnonymizedAt.Valid {
+		t.Error("expected user to be anonymized")
+	}
+	if deleted.Email == user.Email || deleted.FirstName.Valid || deleted.LastName.Valid {
+		t.Errorf("expected personal data to be removed, got %+v", deleted)
+	}
+
+	// Verify: Deleting again reports the user as gone
+	if err := service.DeleteUser(ctx, userUUID.String()); err == nil {
+		t.Error("expected deleting an anonymized user to fail")
 	}
 
 	// Verify: Audit log for DELETE created
@@ -332,7 +344,7 @@ func TestIntegration_FrontendService_GetUser(t *testing.T) {
 	defer tx.Rollback(ctx)
 
 	qtx := queries.WithTx(tx)
-	service := NewFrontendService(qtx, auditService)
+	service := NewFrontendService(qtx, auditService, nil)
 
 	// Create test user
 	user, err := qtx.CreateUser(ctx, db.CreateUserParams{
@@ -377,7 +389,7 @@ func TestIntegration_FrontendService_UpdateUser(t *testing.T) {
 	defer tx.Rollback(ctx)
 
 	qtx := queries.WithTx(tx)
-	service := NewFrontendService(qtx, auditService)
+	service := NewFrontendService(qtx, auditService, nil)
 
 	// Create test user
 	user, err := qtx.CreateUser(ctx, db.CreateUserParams{
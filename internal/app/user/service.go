@@ -11,7 +11,7 @@ import (
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/errors"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/user/coc/internal/passhash"
 )
 
 type Service struct {
@@ -29,7 +29,7 @@ func NewService(queries *db.Queries, auditService *audit.Service) *Service {
 // CreateUser creates a new user
 func (s *Service) CreateUser(ctx context.Context, req CreateUserRequest) (*UserResponse, error) {
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := passhash.Hash(req.Password)
 	if err != nil {
 		slog.Error("failed to hash password", "error", err)
 		return nil, errors.Internal("failed to hash password", err)
@@ -63,7 +63,7 @@ func (s *Service) CreateUser(ctx context.Context, req CreateUserRequest) (*UserR
 	user, err := s.queries.CreateUser(ctx, db.CreateUserParams{
 		Email:        req.Email,
 		Username:     req.Username,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		FirstName:    pgtype.Text{String: req.FirstName, Valid: req.FirstName != ""},
 		LastName:     pgtype.Text{String: req.LastName, Valid: req.LastName != ""},
 	})
@@ -202,11 +202,33 @@ func toUserResponse(user *db.User) *UserResponse {
 		// 	}
 		// 	return uuid.UUID(user.DefaultAddressID.Bytes).String()
 		// }(),
+		EmailVerifiedAt: func() string {
+			if !user.EmailVerifiedAt.Valid {
+				return ""
+			}
+			return user.EmailVerifiedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		}(),
+		DeletionScheduledAt: func() string {
+			if !user.DeletionScheduledAt.Valid {
+				return ""
+			}
+			return user.DeletionScheduledAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		}(),
 		CreatedAt: user.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt: user.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
 
+func toSessionResponse(session *db.UserSession) *SessionResponse {
+	return &SessionResponse{
+		ID:         uuid.UUID(session.ID.Bytes).String(),
+		UserAgent:  session.UserAgent.String,
+		IPAddress:  session.IpAddress.String,
+		CreatedAt:  session.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+		LastSeenAt: session.LastSeenAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
 func ptrToString(s *string) string {
 	if s == nil {
 		return ""
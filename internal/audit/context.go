@@ -10,27 +10,48 @@ import (
 type contextKey string
 
 const (
-	userIDKey    contextKey = "user_id"
+	actorTypeKey contextKey = "actor_type"
+	actorIDKey   contextKey = "actor_id"
 	requestIDKey contextKey = "request_id"
 	ipAddressKey contextKey = "ip_address"
 	userAgentKey contextKey = "user_agent"
+	apiKeyIDKey  contextKey = "api_key_id"
+
+	impersonatorIDKey contextKey = "impersonator_id"
+)
+
+// ActorType identifies who performed an audited action
+type ActorType string
+
+const (
+	ActorAdmin  ActorType = "admin"
+	ActorUser   ActorType = "user"
+	ActorSystem ActorType = "system"
 )
 
 // AuditContext holds audit-related information extracted from request context
 type AuditContext struct {
-	UserID    uuid.UUID
+	ActorType ActorType
+	ActorID   uuid.UUID
 	RequestID string
 	IPAddress string
 	UserAgent string
+	APIKeyID  uuid.UUID // set when an admin acted through an API key
+
+	ImpersonatorID uuid.UUID // set when an admin acted as the user
 }
 
 // ExtractAuditContext extracts audit information from context
 func ExtractAuditContext(ctx context.Context) AuditContext {
-	auditCtx := AuditContext{}
+	// Anything not running on behalf of an authenticated user or admin is the system
+	auditCtx := AuditContext{ActorType: ActorSystem}
 
-	// Extract user ID
-	if userID, ok := ctx.Value(userIDKey).(uuid.UUID); ok {
-		auditCtx.UserID = userID
+	// Extract actor
+	if actorType, ok := ctx.Value(actorTypeKey).(ActorType); ok {
+		auditCtx.ActorType = actorType
+	}
+	if actorID, ok := ctx.Value(actorIDKey).(uuid.UUID); ok {
+		auditCtx.ActorID = actorID
 	}
 
 	// Extract request ID
@@ -48,12 +69,43 @@ func ExtractAuditContext(ctx context.Context) AuditContext {
 		auditCtx.UserAgent = userAgent
 	}
 
+	// Extract API key
+	if apiKeyID, ok := ctx.Value(apiKeyIDKey).(uuid.UUID); ok {
+		auditCtx.APIKeyID = apiKeyID
+	}
+
+	// Extract impersonating admin
+	if impersonatorID, ok := ctx.Value(impersonatorIDKey).(uuid.UUID); ok {
+		auditCtx.ImpersonatorID = impersonatorID
+	}
+
 	return auditCtx
 }
 
-// WithUserID adds user ID to context
+// WithActor adds the acting principal to context
+func WithActor(ctx context.Context, actorType ActorType, actorID uuid.UUID) context.Context {
+	ctx = context.WithValue(ctx, actorTypeKey, actorType)
+	return context.WithValue(ctx, actorIDKey, actorID)
+}
+
+// WithUserID marks a frontend user as the actor
 func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
-	return context.WithValue(ctx, userIDKey, userID)
+	return WithActor(ctx, ActorUser, userID)
+}
+
+// WithAdminID marks an admin as the actor
+func WithAdminID(ctx context.Context, adminID uuid.UUID) context.Context {
+	return WithActor(ctx, ActorAdmin, adminID)
+}
+
+// WithAPIKeyID records the API key the actor authenticated with
+func WithAPIKeyID(ctx context.Context, apiKeyID uuid.UUID) context.Context {
+	return context.WithValue(ctx, apiKeyIDKey, apiKeyID)
+}
+
+// WithImpersonatorID records the admin acting as the user. The user stays the actor.
+func WithImpersonatorID(ctx context.Context, adminID uuid.UUID) context.Context {
+	return context.WithValue(ctx, impersonatorIDKey, adminID)
 }
 
 // WithRequestID adds request ID to context
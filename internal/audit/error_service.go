@@ -25,7 +25,8 @@ func (s *Service) LogError(ctx context.Context, errorType, errorMessage string,
 	}
 
 	params := db.CreateErrorLogParams{
-		UserID:        pgtype.UUID{Bytes: auditCtx.UserID, Valid: auditCtx.UserID != uuid.Nil},
+		ActorType:     db.AuditActorType(auditCtx.ActorType),
+		ActorID:       pgtype.UUID{Bytes: auditCtx.ActorID, Valid: auditCtx.ActorID != uuid.Nil},
 		RequestID:     pgtype.Text{String: auditCtx.RequestID, Valid: auditCtx.RequestID != ""},
 		ErrorType:     errorType,
 		ErrorMessage:  errorMessage,
@@ -63,7 +64,8 @@ func (s *Service) LogErrorWithMetadata(ctx context.Context, errorType, errorMess
 	}
 
 	params := db.CreateErrorLogParams{
-		UserID:        pgtype.UUID{Bytes: auditCtx.UserID, Valid: auditCtx.UserID != uuid.Nil},
+		ActorType:     db.AuditActorType(auditCtx.ActorType),
+		ActorID:       pgtype.UUID{Bytes: auditCtx.ActorID, Valid: auditCtx.ActorID != uuid.Nil},
 		RequestID:     pgtype.Text{String: auditCtx.RequestID, Valid: auditCtx.RequestID != ""},
 		ErrorType:     errorType,
 		ErrorMessage:  errorMessage,
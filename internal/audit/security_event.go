@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+)
+
+// SecurityEventType names an authentication activity recorded in security_events
+type SecurityEventType string
+
+const (
+	EventLogin                SecurityEventType = "login"
+	EventMFAVerify            SecurityEventType = "mfa_verify"
+	EventSSOLogin             SecurityEventType = "sso_login"
+	EventMagicLinkRequest     SecurityEventType = "magic_link_request"
+	EventMagicLinkLogin       SecurityEventType = "magic_link_login"
+	EventRegistration         SecurityEventType = "registration"
+	EventTokenRefresh         SecurityEventType = "token_refresh"
+	EventLogout               SecurityEventType = "logout"
+	EventLogoutAll            SecurityEventType = "logout_all"
+	EventPasswordChange       SecurityEventType = "password_change"
+	EventPasswordResetRequest SecurityEventType = "password_reset_request"
+	EventPasswordReset        SecurityEventType = "password_reset"
+	EventAccountDeletion      SecurityEventType = "account_deletion"
+	EventAccountRestore       SecurityEventType = "account_restore"
+	EventLockout              SecurityEventType = "lockout"
+	EventUnlock               SecurityEventType = "unlock"
+)
+
+// Outcome says whether a security event succeeded
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// SecurityEvent is one authentication activity of a user or admin account
+type SecurityEvent struct {
+	Type    SecurityEventType
+	Outcome Outcome
+
+	// ActorType and ActorID are the account the event concerns. Left empty they
+	// default to the authenticated actor of the context; ActorID stays empty for
+	// accounts that could not be identified.
+	ActorType ActorType
+	ActorID   uuid.UUID
+
+	Identifier string // the email or username that was tried
+	Reason     string // why the event failed, e.g. invalid_password
+}
+
+// LogSecurityEvent records an authentication activity to the security_events
+// table. The IP address, user agent and request ID come from the context.
+func (s *Service) LogSecurityEvent(ctx context.Context, event SecurityEvent) error {
+	auditCtx := ExtractAuditContext(ctx)
+
+	actorType, actorID := event.ActorType, event.ActorID
+	if actorType == "" {
+		actorType, actorID = auditCtx.ActorType, auditCtx.ActorID
+	}
+
+	params := db.CreateSecurityEventParams{
+		EventType:  string(event.Type),
+		Outcome:    string(event.Outcome),
+		ActorType:  db.AuditActorType(actorType),
+		ActorID:    pgtype.UUID{Bytes: actorID, Valid: actorID != uuid.Nil},
+		Identifier: pgtype.Text{String: event.Identifier, Valid: event.Identifier != ""},
+		Reason:     pgtype.Text{String: event.Reason, Valid: event.Reason != ""},
+		RequestID:  pgtype.Text{String: auditCtx.RequestID, Valid: auditCtx.RequestID != ""},
+		IpAddress:  pgtype.Text{String: auditCtx.IPAddress, Valid: auditCtx.IPAddress != ""},
+		UserAgent:  pgtype.Text{String: auditCtx.UserAgent, Valid: auditCtx.UserAgent != ""},
+		Metadata:   auditMetadata(auditCtx),
+	}
+
+	if err := s.queries.CreateSecurityEvent(ctx, params); err != nil {
+		slog.Error("failed to create security event", "error", err, "event_type", event.Type)
+		return nil // Don't fail the main operation
+	}
+
+	return nil
+}
@@ -34,7 +34,8 @@ func (s *Service) LogCreate(ctx context.Context, entityType string, entityID uui
 	}
 
 	params := db.CreateAuditLogParams{
-		UserID:     pgtype.UUID{Bytes: auditCtx.UserID, Valid: auditCtx.UserID != uuid.Nil},
+		ActorType:  db.AuditActorType(auditCtx.ActorType),
+		ActorID:    pgtype.UUID{Bytes: auditCtx.ActorID, Valid: auditCtx.ActorID != uuid.Nil},
 		Action:     db.AuditActionCREATE,
 		EntityType: entityType,
 		EntityID:   pgtype.UUID{Bytes: entityID, Valid: true},
@@ -43,7 +44,7 @@ func (s *Service) LogCreate(ctx context.Context, entityType string, entityID uui
 		RequestID:  pgtype.Text{String: auditCtx.RequestID, Valid: auditCtx.RequestID != ""},
 		IpAddress:  pgtype.Text{String: auditCtx.IPAddress, Valid: auditCtx.IPAddress != ""},
 		UserAgent:  pgtype.Text{String: auditCtx.UserAgent, Valid: auditCtx.UserAgent != ""},
-		Metadata:   nil,
+		Metadata:   auditMetadata(auditCtx),
 		CreatedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
 	}
 
@@ -73,7 +74,8 @@ func (s *Service) LogUpdate(ctx context.Context, entityType string, entityID uui
 	}
 
 	params := db.CreateAuditLogParams{
-		UserID:     pgtype.UUID{Bytes: auditCtx.UserID, Valid: auditCtx.UserID != uuid.Nil},
+		ActorType:  db.AuditActorType(auditCtx.ActorType),
+		ActorID:    pgtype.UUID{Bytes: auditCtx.ActorID, Valid: auditCtx.ActorID != uuid.Nil},
 		Action:     db.AuditActionUPDATE,
 		EntityType: entityType,
 		EntityID:   pgtype.UUID{Bytes: entityID, Valid: true},
@@ -82,7 +84,7 @@ func (s *Service) LogUpdate(ctx context.Context, entityType string, entityID uui
 		RequestID:  pgtype.Text{String: auditCtx.RequestID, Valid: auditCtx.RequestID != ""},
 		IpAddress:  pgtype.Text{String: auditCtx.IPAddress, Valid: auditCtx.IPAddress != ""},
 		UserAgent:  pgtype.Text{String: auditCtx.UserAgent, Valid: auditCtx.UserAgent != ""},
-		Metadata:   nil,
+		Metadata:   auditMetadata(auditCtx),
 		CreatedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
 	}
 
@@ -106,7 +108,8 @@ func (s *Service) LogDelete(ctx context.Context, entityType string, entityID uui
 	}
 
 	params := db.CreateAuditLogParams{
-		UserID:     pgtype.UUID{Bytes: auditCtx.UserID, Valid: auditCtx.UserID != uuid.Nil},
+		ActorType:  db.AuditActorType(auditCtx.ActorType),
+		ActorID:    pgtype.UUID{Bytes: auditCtx.ActorID, Valid: auditCtx.ActorID != uuid.Nil},
 		Action:     db.AuditActionDELETE,
 		EntityType: entityType,
 		EntityID:   pgtype.UUID{Bytes: entityID, Valid: true},
@@ -115,7 +118,7 @@ func (s *Service) LogDelete(ctx context.Context, entityType string, entityID uui
 		RequestID:  pgtype.Text{String: auditCtx.RequestID, Valid: auditCtx.RequestID != ""},
 		IpAddress:  pgtype.Text{String: auditCtx.IPAddress, Valid: auditCtx.IPAddress != ""},
 		UserAgent:  pgtype.Text{String: auditCtx.UserAgent, Valid: auditCtx.UserAgent != ""},
-		Metadata:   nil,
+		Metadata:   auditMetadata(auditCtx),
 		CreatedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
 	}
 
@@ -151,8 +154,8 @@ func (s *Service) GetEntityHistory(ctx context.Context, entityType string, entit
 	return logs, nil
 }
 
-// GetUserAuditHistory retrieves audit history for a specific user
-func (s *Service) GetUserAuditHistory(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]db.AuditLog, error) {
+// GetActorAuditHistory retrieves the changes made by a specific admin or user
+func (s *Service) GetActorAuditHistory(ctx context.Context, actorType ActorType, actorID uuid.UUID, limit, offset int32) ([]db.AuditLog, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -160,13 +163,14 @@ func (s *Service) GetUserAuditHistory(ctx context.Context, userID uuid.UUID, lim
 		limit = 100
 	}
 
-	logs, err := s.queries.ListAuditLogsByUser(ctx, db.ListAuditLogsByUserParams{
-		UserID: pgtype.UUID{Bytes: userID, Valid: true},
-		Limit:  limit,
-		Offset: offset,
+	logs, err := s.queries.ListAuditLogsByActor(ctx, db.ListAuditLogsByActorParams{
+		ActorType: db.AuditActorType(actorType),
+		ActorID:   pgtype.UUID{Bytes: actorID, Valid: true},
+		Limit:     limit,
+		Offset:    offset,
 	})
 	if err != nil {
-		slog.Error("failed to get user audit history", "error", err, "user_id", userID)
+		slog.Error("failed to get actor audit history", "error", err, "actor_type", actorType, "actor_id", actorID)
 		return nil, err
 	}
 
@@ -198,6 +202,27 @@ func (s *Service) prepareAuditData(data interface{}) ([]byte, error) {
 	return json.Marshal(dataMap)
 }
 
+// auditMetadata records how the actor authenticated when it was not their own
+// session: through an API key, or by an admin impersonating the user
+func auditMetadata(auditCtx AuditContext) []byte {
+	fields := map[string]string{}
+	if auditCtx.APIKeyID != uuid.Nil {
+		fields["api_key_id"] = auditCtx.APIKeyID.String()
+	}
+	if auditCtx.ImpersonatorID != uuid.Nil {
+		fields["impersonated_by_admin_id"] = auditCtx.ImpersonatorID.String()
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	metadata, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return metadata
+}
+
 // filterSensitiveData removes sensitive fields from audit data
 func (s *Service) filterSensitiveData(data map[string]interface{}) map[string]interface{} {
 	sensitiveFields := []string{
@@ -205,6 +230,8 @@ func (s *Service) filterSensitiveData(data map[string]interface{}) map[string]in
 		"password_hash",
 		"passwordHash",
 		"token",
+		"token_hash",
+		"key_hash",
 		"secret",
 		"api_key",
 		"apiKey",
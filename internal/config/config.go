@@ -1,9 +1,13 @@
 package config
 
 import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,18 +18,217 @@ type Config struct {
 	JWTSecret           string
 	BearerTokenDuration string
 	DBMaxConnection     int
+
+	// Signing secrets per API surface; both fall back to JWTSecret
+	FrontendJWTSecret string
+	AdminJWTSecret    string
+
+	// Asymmetric signing (RS256/EdDSA). When a private key file is set it replaces
+	// the HMAC secret of that surface; verify key files are extra public keys
+	// still accepted during key rotation.
+	FrontendJWTPrivateKeyFile string
+	FrontendJWTVerifyKeyFiles []string
+	AdminJWTPrivateKeyFile    string
+	AdminJWTVerifyKeyFiles    []string
+
+	// Access tokens are short-lived and renewed with opaque refresh tokens
+	RefreshTokenDuration      string
+	AdminTokenDuration        string
+	AdminRefreshTokenDuration string
+
+	// Admin MFA: key used to encrypt TOTP secrets at rest (falls back to a key
+	// derived from AdminJWTSecret) and lifetime of the challenge between password and code
+	MFAEncryptionKey          string
+	AdminMFAChallengeDuration string
+
+	// Base URLs of the frontend app and admin panel, used for links in emails
+	FrontendURL string
+	AdminURL    string
+
+	// Lifetime of password reset links
+	PasswordResetTokenDuration string
+
+	// Email verification: EMAIL_VERIFICATION_POLICY is optional, limited (unverified
+	// users can log in but only reach their profile) or required (login is rejected)
+	EmailVerificationPolicy        string
+	EmailVerificationTokenDuration string
+
+	// Lifetime of the access token an admin gets to act as a frontend user
+	ImpersonationTokenDuration string
+
+	// Lifetime of passwordless login links
+	MagicLinkTokenDuration string
+
+	// Proxies (IP addresses or CIDR ranges) whose X-Forwarded-For and X-Real-IP
+	// headers are trusted. Without any, the client IP is the connection's address.
+	TrustedProxies []string
+
+	// Brute-force protection on both login endpoints. Failures are counted per
+	// account and per IP within LOGIN_ATTEMPT_WINDOW; after LOGIN_DELAY_AFTER_ATTEMPTS
+	// failures an account (LOGIN_IP_DELAY_AFTER_ATTEMPTS for an IP) waits
+	// LOGIN_DELAY_BASE (doubling up to LOGIN_DELAY_MAX) between attempts, and
+	// reaching a max locks it for LOGIN_LOCKOUT_DURATION.
+	// A max of 0 disables that lockout.
+	LoginMaxFailedAttempts      int
+	LoginMaxFailedAttemptsPerIP int
+	LoginDelayAfterAttempts     int
+	LoginIPDelayAfterAttempts   int
+	LoginDelayBase              string
+	LoginDelayMax               string
+	LoginAttemptWindow          string
+	LoginLockoutDuration        string
+
+	// Password hashing: PASSWORD_HASH_ALGORITHM is argon2id or bcrypt. Hashes made
+	// with another algorithm or older parameters are upgraded on the next login.
+	PasswordHashAlgorithm     string
+	PasswordArgon2Memory      int // KiB
+	PasswordArgon2Iterations  int
+	PasswordArgon2Parallelism int
+	PasswordBcryptCost        int
+
+	// Number of previous passwords a password change may not reuse (0 only refuses the current one)
+	PasswordHistorySize int
+
+	// Admin single sign-on with an OpenID Connect provider, enabled when
+	// OIDC_ISSUER_URL is set. OIDC_ROLE_MAPPING maps values of OIDC_ROLE_CLAIM to
+	// admin roles ("group=role,group=role", first match wins); users matching no
+	// rule get OIDC_DEFAULT_ROLE, or are refused when it is empty.
+	OIDCIssuerURL          string
+	OIDCClientID           string
+	OIDCClientSecret       string
+	OIDCRedirectURL        string
+	OIDCScopes             []string
+	OIDCRoleClaim          string
+	OIDCRoleMapping        string
+	OIDCDefaultRole        string
+	OIDCLoginStateDuration string
+
+	// Outgoing email: MAIL_DRIVER is smtp, file (writes .eml files to MAIL_DIR) or log
+	MailDriver   string
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	// Partition maintenance for audit_logs and error_logs
+	PartitionMonthsAhead         int
+	PartitionRetentionMonths     int
+	PartitionDetachOnly          bool
+	PartitionMaintenanceInterval string
+
+	// How long security events are kept; 0 keeps them forever
+	SecurityEventRetention string
+
+	// How long a user can cancel the deletion of their account by logging in
+	AccountDeletionGracePeriod string
+
+	// Personal data exports: key that signs download links (falls back to a key
+	// derived from FrontendJWTSecret), how long a link works and how long archives are kept
+	DataExportSigningKey   string
+	DataExportLinkDuration string
+	DataExportRetention    string
+
+	// How long each instance caches a role's permissions; 0 disables the cache.
+	// Changes also flush the cache on every instance through Postgres NOTIFY.
+	PermissionCacheTTL string
 }
 
 func Load() (*Config, error) {
 	// Load .env file if it exists (ignore error in production)
 	_ = godotenv.Load()
 
+	jwtSecret := getEnv("JWT_SECRET", "")
+
 	cfg := &Config{
 		DatabaseURL:         getEnv("DATABASE_URL", ""),
 		Port:                getEnv("PORT", ""),
-		JWTSecret:           getEnv("JWT_SECRET", ""),
-		BearerTokenDuration: getEnv("BEARER_TOKEN_DURATION", "168h"),
+		JWTSecret:           jwtSecret,
+		BearerTokenDuration: getEnv("BEARER_TOKEN_DURATION", "15m"),
 		DBMaxConnection:     getEnvAsInt("MAX_CONNECTION", 25),
+
+		FrontendJWTSecret: getEnv("FRONTEND_JWT_SECRET", jwtSecret),
+		AdminJWTSecret:    getEnv("ADMIN_JWT_SECRET", jwtSecret),
+
+		FrontendJWTPrivateKeyFile: getEnv("FRONTEND_JWT_PRIVATE_KEY_FILE", ""),
+		FrontendJWTVerifyKeyFiles: getEnvAsList("FRONTEND_JWT_VERIFY_KEY_FILES"),
+		AdminJWTPrivateKeyFile:    getEnv("ADMIN_JWT_PRIVATE_KEY_FILE", ""),
+		AdminJWTVerifyKeyFiles:    getEnvAsList("ADMIN_JWT_VERIFY_KEY_FILES"),
+
+		RefreshTokenDuration:      getEnv("REFRESH_TOKEN_DURATION", "168h"),
+		AdminTokenDuration:        getEnv("ADMIN_TOKEN_DURATION", "15m"),
+		AdminRefreshTokenDuration: getEnv("ADMIN_REFRESH_TOKEN_DURATION", "24h"),
+
+		AdminMFAChallengeDuration: getEnv("ADMIN_MFA_CHALLENGE_DURATION", "5m"),
+
+		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+		AdminURL:    getEnv("ADMIN_URL", "http://localhost:3001"),
+
+		PasswordResetTokenDuration: getEnv("PASSWORD_RESET_TOKEN_DURATION", "1h"),
+
+		EmailVerificationPolicy:        getEnv("EMAIL_VERIFICATION_POLICY", "limited"),
+		EmailVerificationTokenDuration: getEnv("EMAIL_VERIFICATION_TOKEN_DURATION", "24h"),
+
+		ImpersonationTokenDuration: getEnv("IMPERSONATION_TOKEN_DURATION", "15m"),
+
+		MagicLinkTokenDuration: getEnv("MAGIC_LINK_TOKEN_DURATION", "15m"),
+
+		TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
+
+		LoginMaxFailedAttempts:      getEnvAsInt("LOGIN_MAX_FAILED_ATTEMPTS", 10),
+		LoginMaxFailedAttemptsPerIP: getEnvAsInt("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", 50),
+		LoginDelayAfterAttempts:     getEnvAsInt("LOGIN_DELAY_AFTER_ATTEMPTS", 3),
+		LoginIPDelayAfterAttempts:   getEnvAsInt("LOGIN_IP_DELAY_AFTER_ATTEMPTS", 10),
+		LoginDelayBase:              getEnv("LOGIN_DELAY_BASE", "1s"),
+		LoginDelayMax:               getEnv("LOGIN_DELAY_MAX", "30s"),
+		LoginAttemptWindow:          getEnv("LOGIN_ATTEMPT_WINDOW", "15m"),
+		LoginLockoutDuration:        getEnv("LOGIN_LOCKOUT_DURATION", "15m"),
+
+		PasswordHashAlgorithm:     getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		PasswordArgon2Memory:      getEnvAsInt("PASSWORD_ARGON2_MEMORY", 65536),
+		PasswordArgon2Iterations:  getEnvAsInt("PASSWORD_ARGON2_ITERATIONS", 3),
+		PasswordArgon2Parallelism: getEnvAsInt("PASSWORD_ARGON2_PARALLELISM", 2),
+		PasswordBcryptCost:        getEnvAsInt("PASSWORD_BCRYPT_COST", 10),
+
+		PasswordHistorySize: getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+
+		OIDCIssuerURL:          getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:           getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:       getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCScopes:             getEnvAsList("OIDC_SCOPES"),
+		OIDCRoleClaim:          getEnv("OIDC_ROLE_CLAIM", "groups"),
+		OIDCRoleMapping:        getEnv("OIDC_ROLE_MAPPING", ""),
+		OIDCDefaultRole:        getEnv("OIDC_DEFAULT_ROLE", ""),
+		OIDCLoginStateDuration: getEnv("OIDC_LOGIN_STATE_DURATION", "10m"),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailDir:      getEnv("MAIL_DIR", "tmp/mail"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		PartitionMonthsAhead:         getEnvAsInt("PARTITION_MONTHS_AHEAD", 3),
+		PartitionRetentionMonths:     getEnvAsInt("PARTITION_RETENTION_MONTHS", 0),
+		PartitionDetachOnly:          getEnvAsBool("PARTITION_DETACH_ONLY", true),
+		PartitionMaintenanceInterval: getEnv("PARTITION_MAINTENANCE_INTERVAL", "24h"),
+
+		SecurityEventRetention: getEnv("SECURITY_EVENT_RETENTION", "2160h"),
+
+		AccountDeletionGracePeriod: getEnv("ACCOUNT_DELETION_GRACE_PERIOD", "720h"),
+
+		DataExportLinkDuration: getEnv("DATA_EXPORT_LINK_DURATION", "15m"),
+		DataExportRetention:    getEnv("DATA_EXPORT_RETENTION", "168h"),
+
+		PermissionCacheTTL: getEnv("PERMISSION_CACHE_TTL", "60s"),
+	}
+	cfg.MFAEncryptionKey = getEnv("MFA_ENCRYPTION_KEY", deriveKey(cfg.AdminJWTSecret, "mfa-encryption"))
+	cfg.DataExportSigningKey = getEnv("DATA_EXPORT_SIGNING_KEY", deriveKey(cfg.FrontendJWTSecret, "data-export-link"))
+	cfg.OIDCRedirectURL = getEnv("OIDC_REDIRECT_URL", strings.TrimRight(cfg.AdminURL, "/")+"/auth/oidc/callback")
+	if len(cfg.OIDCScopes) == 0 {
+		cfg.OIDCScopes = []string{"openid", "email", "profile"}
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -35,6 +238,19 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// deriveKey derives a key for one purpose from secret with HKDF-SHA256, so a
+// secret that signs tokens is never used for anything else as is. It returns
+// an empty string when secret is empty.
+func deriveKey(secret, purpose string) string {
+	if secret == "" {
+		return ""
+	}
+
+	// HKDF-SHA256 only fails for keys longer than 8160 bytes
+	key, _ := hkdf.Key(sha256.New, []byte(secret), nil, purpose, 32)
+	return hex.EncodeToString(key)
+}
+
 func (c *Config) validate() error {
 	if c.DatabaseURL == "" {
 		return fmt.Errorf("DATABASE_URL is required")
@@ -42,8 +258,17 @@ func (c *Config) validate() error {
 	if c.Port == "" {
 		return fmt.Errorf("PORT is required")
 	}
-	if c.JWTSecret == "" {
-		return fmt.Errorf("JWT_SECRET is required")
+	if c.FrontendJWTSecret == "" && c.FrontendJWTPrivateKeyFile == "" {
+		return fmt.Errorf("FRONTEND_JWT_PRIVATE_KEY_FILE, FRONTEND_JWT_SECRET or JWT_SECRET is required")
+	}
+	if c.AdminJWTSecret == "" && c.AdminJWTPrivateKeyFile == "" {
+		return fmt.Errorf("ADMIN_JWT_PRIVATE_KEY_FILE, ADMIN_JWT_SECRET or JWT_SECRET is required")
+	}
+	if c.MFAEncryptionKey == "" {
+		return fmt.Errorf("MFA_ENCRYPTION_KEY, ADMIN_JWT_SECRET or JWT_SECRET is required")
+	}
+	if c.DataExportSigningKey == "" {
+		return fmt.Errorf("DATA_EXPORT_SIGNING_KEY, FRONTEND_JWT_SECRET or JWT_SECRET is required")
 	}
 	if c.BearerTokenDuration == "" {
 		return fmt.Errorf("BEARER_TOKEN_DURATION is required")
@@ -52,6 +277,32 @@ func (c *Config) validate() error {
 	if c.DBMaxConnection <= 0 {
 		return fmt.Errorf("MAX_CONNECTION must be greater than 0")
 	}
+	if c.LoginMaxFailedAttempts < 0 || c.LoginMaxFailedAttemptsPerIP < 0 || c.LoginDelayAfterAttempts < 0 || c.LoginIPDelayAfterAttempts < 0 {
+		return fmt.Errorf("LOGIN_MAX_FAILED_ATTEMPTS, LOGIN_MAX_FAILED_ATTEMPTS_PER_IP, LOGIN_DELAY_AFTER_ATTEMPTS and LOGIN_IP_DELAY_AFTER_ATTEMPTS must not be negative")
+	}
+	if c.PasswordArgon2Memory <= 0 || c.PasswordArgon2Iterations <= 0 {
+		return fmt.Errorf("PASSWORD_ARGON2_MEMORY and PASSWORD_ARGON2_ITERATIONS must be greater than 0")
+	}
+	if c.PasswordArgon2Parallelism < 1 || c.PasswordArgon2Parallelism > 255 {
+		return fmt.Errorf("PASSWORD_ARGON2_PARALLELISM must be between 1 and 255")
+	}
+	if c.PasswordHistorySize < 0 {
+		return fmt.Errorf("PASSWORD_HISTORY_SIZE must not be negative")
+	}
+	if c.OIDCIssuerURL != "" {
+		if c.OIDCClientID == "" {
+			return fmt.Errorf("OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set")
+		}
+		if c.OIDCRoleMapping == "" && c.OIDCDefaultRole == "" {
+			return fmt.Errorf("OIDC_ROLE_MAPPING or OIDC_DEFAULT_ROLE is required when OIDC_ISSUER_URL is set")
+		}
+	}
+	if c.PartitionMonthsAhead < 0 {
+		return fmt.Errorf("PARTITION_MONTHS_AHEAD must not be negative")
+	}
+	if c.PartitionRetentionMonths < 0 {
+		return fmt.Errorf("PARTITION_RETENTION_MONTHS must not be negative")
+	}
 	return nil
 }
 
@@ -70,3 +321,23 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma-separated value, ignoring empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
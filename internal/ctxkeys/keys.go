@@ -7,14 +7,18 @@ type contextKey string
 
 const (
 	// User context keys
-	UserContextKey   contextKey = "user"
-	UserIDContextKey contextKey = "user_id"
+	UserContextKey           contextKey = "user"
+	UserIDContextKey         contextKey = "user_id"
+	UserClaimsContextKey     contextKey = "user_claims"
+	ImpersonatorIDContextKey contextKey = "impersonator_id" // admin acting as the user, if any
 
 	// Admin context keys
-	AdminContextKey     contextKey = "admin"
-	AdminIDContextKey   contextKey = "admin_id"
-	AdminRoleContextKey contextKey = "admin_role"
-	IsAdminContextKey   contextKey = "is_admin"
+	AdminContextKey       contextKey = "admin"
+	AdminIDContextKey     contextKey = "admin_id"
+	AdminRoleContextKey   contextKey = "admin_role"
+	AdminClaimsContextKey contextKey = "admin_claims"
+	AdminAPIKeyContextKey contextKey = "admin_api_key" // set instead of claims for API key requests
+	IsAdminContextKey     contextKey = "is_admin"
 )
 
 // GetAdminRole retrieves the admin role from the request context
@@ -29,6 +33,12 @@ func GetAdminID(r *http.Request) (string, bool) {
 	return id, ok
 }
 
+// GetImpersonatorID retrieves the ID of the admin impersonating the user from the request context
+func GetImpersonatorID(r *http.Request) (string, bool) {
+	id, ok := r.Context().Value(ImpersonatorIDContextKey).(string)
+	return id, ok
+}
+
 // GetUserID retrieves the user ID from the request context
 func GetUserID(r *http.Request) (string, bool) {
 	id, ok := r.Context().Value(UserIDContextKey).(string)
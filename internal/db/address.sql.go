@@ -15,7 +15,7 @@ const clearDefaultAddress = `-- name: ClearDefaultAddress :one
 UPDATE users
 SET default_address_id = NULL
 WHERE id = $1
-RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id
+RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
 `
 
 func (q *Queries) ClearDefaultAddress(ctx context.Context, id pgtype.UUID) (User, error) {
@@ -31,6 +31,10 @@ func (q *Queries) ClearDefaultAddress(ctx context.Context, id pgtype.UUID) (User
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
@@ -226,7 +230,7 @@ func (q *Queries) GetAddressesByUserID(ctx context.Context, userID pgtype.UUID)
 
 const getUserWithDefaultAddress = `-- name: GetUserWithDefaultAddress :one
 SELECT 
-    u.id, u.email, u.username, u.password_hash, u.first_name, u.last_name, u.created_at, u.updated_at, u.default_address_id,
+    u.id, u.email, u.username, u.password_hash, u.first_name, u.last_name, u.created_at, u.updated_at, u.default_address_id, u.token_version, u.email_verified_at, u.deletion_scheduled_at, u.anonymized_at,
     a.id as default_address_id,
     a.address as default_address,
     a.floor as default_floor,
@@ -240,21 +244,25 @@ LIMIT 1
 `
 
 type GetUserWithDefaultAddressRow struct {
-	ID                 pgtype.UUID        `json:"id"`
-	Email              string             `json:"email"`
-	Username           string             `json:"username"`
-	PasswordHash       string             `json:"password_hash"`
-	FirstName          pgtype.Text        `json:"first_name"`
-	LastName           pgtype.Text        `json:"last_name"`
-	CreatedAt          pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
-	DefaultAddressID   pgtype.UUID        `json:"default_address_id"`
-	DefaultAddressID_2 pgtype.UUID        `json:"default_address_id_2"`
-	DefaultAddress     pgtype.Text        `json:"default_address"`
-	DefaultFloor       pgtype.Text        `json:"default_floor"`
-	DefaultUnitNo      pgtype.Text        `json:"default_unit_no"`
-	DefaultBlockTower  pgtype.Text        `json:"default_block_tower"`
-	DefaultCompanyName pgtype.Text        `json:"default_company_name"`
+	ID                  pgtype.UUID        `json:"id"`
+	Email               string             `json:"email"`
+	Username            string             `json:"username"`
+	PasswordHash        string             `json:"password_hash"`
+	FirstName           pgtype.Text        `json:"first_name"`
+	LastName            pgtype.Text        `json:"last_name"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	DefaultAddressID    pgtype.UUID        `json:"default_address_id"`
+	TokenVersion        int32              `json:"token_version"`
+	EmailVerifiedAt     pgtype.Timestamptz `json:"email_verified_at"`
+	DeletionScheduledAt pgtype.Timestamptz `json:"deletion_scheduled_at"`
+	AnonymizedAt        pgtype.Timestamptz `json:"anonymized_at"`
+	DefaultAddressID_2  pgtype.UUID        `json:"default_address_id_2"`
+	DefaultAddress      pgtype.Text        `json:"default_address"`
+	DefaultFloor        pgtype.Text        `json:"default_floor"`
+	DefaultUnitNo       pgtype.Text        `json:"default_unit_no"`
+	DefaultBlockTower   pgtype.Text        `json:"default_block_tower"`
+	DefaultCompanyName  pgtype.Text        `json:"default_company_name"`
 }
 
 func (q *Queries) GetUserWithDefaultAddress(ctx context.Context, id pgtype.UUID) (GetUserWithDefaultAddressRow, error) {
@@ -270,6 +278,10 @@ func (q *Queries) GetUserWithDefaultAddress(ctx context.Context, id pgtype.UUID)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 		&i.DefaultAddressID_2,
 		&i.DefaultAddress,
 		&i.DefaultFloor,
@@ -325,7 +337,7 @@ const setDefaultAddress = `-- name: SetDefaultAddress :one
 UPDATE users
 SET default_address_id = $2
 WHERE id = $1
-RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id
+RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
 `
 
 type SetDefaultAddressParams struct {
@@ -346,6 +358,10 @@ func (q *Queries) SetDefaultAddress(ctx context.Context, arg SetDefaultAddressPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
@@ -358,7 +374,7 @@ AND EXISTS (
     SELECT 1 FROM addresses a 
     WHERE a.id = $2 AND a.user_id = $1
 )
-RETURNING u.id, u.email, u.username, u.password_hash, u.first_name, u.last_name, u.created_at, u.updated_at, u.default_address_id
+RETURNING u.id, u.email, u.username, u.password_hash, u.first_name, u.last_name, u.created_at, u.updated_at, u.default_address_id, u.token_version, u.email_verified_at, u.deletion_scheduled_at, u.anonymized_at
 `
 
 type SetDefaultAddressForUserParams struct {
@@ -379,6 +395,10 @@ func (q *Queries) SetDefaultAddressForUser(ctx context.Context, arg SetDefaultAd
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
@@ -14,7 +14,7 @@ import (
 const createAdmin = `-- name: CreateAdmin :one
 INSERT INTO admins (email, username, password_hash, first_name, last_name, role, is_active)
 VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at
+RETURNING id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at, token_version
 `
 
 type CreateAdminParams struct {
@@ -49,13 +49,14 @@ func (q *Queries) CreateAdmin(ctx context.Context, arg CreateAdminParams) (Admin
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenVersion,
 	)
 	return i, err
 }
 
 const deleteAdmin = `-- name: DeleteAdmin :exec
 UPDATE admins
-SET is_active = false, updated_at = CURRENT_TIMESTAMP
+SET is_active = false, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
 `
 
@@ -65,7 +66,7 @@ func (q *Queries) DeleteAdmin(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getAdminByEmail = `-- name: GetAdminByEmail :one
-SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at FROM admins
+SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at, token_version FROM admins
 WHERE email = $1 AND is_active = true
 LIMIT 1
 `
@@ -84,12 +85,13 @@ func (q *Queries) GetAdminByEmail(ctx context.Context, email string) (Admin, err
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenVersion,
 	)
 	return i, err
 }
 
 const getAdminByID = `-- name: GetAdminByID :one
-SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at FROM admins
+SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at, token_version FROM admins
 WHERE id = $1 AND is_active = true
 LIMIT 1
 `
@@ -108,12 +110,13 @@ func (q *Queries) GetAdminByID(ctx context.Context, id pgtype.UUID) (Admin, erro
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenVersion,
 	)
 	return i, err
 }
 
 const getAdminByUsername = `-- name: GetAdminByUsername :one
-SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at FROM admins
+SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at, token_version FROM admins
 WHERE username = $1 AND is_active = true
 LIMIT 1
 `
@@ -132,6 +135,7 @@ func (q *Queries) GetAdminByUsername(ctx context.Context, username string) (Admi
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenVersion,
 	)
 	return i, err
 }
@@ -146,8 +150,22 @@ func (q *Queries) HardDeleteAdmin(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const incrementAdminTokenVersion = `-- name: IncrementAdminTokenVersion :one
+UPDATE admins
+SET token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING token_version
+`
+
+func (q *Queries) IncrementAdminTokenVersion(ctx context.Context, id pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, incrementAdminTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
 const listAdmins = `-- name: ListAdmins :many
-SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at FROM admins
+SELECT id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at, token_version FROM admins
 WHERE is_active = true
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
@@ -178,6 +196,7 @@ func (q *Queries) ListAdmins(ctx context.Context, arg ListAdminsParams) ([]Admin
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TokenVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -189,6 +208,28 @@ func (q *Queries) ListAdmins(ctx context.Context, arg ListAdminsParams) ([]Admin
 	return items, nil
 }
 
+const rehashAdminPassword = `-- name: RehashAdminPassword :execrows
+UPDATE admins
+SET password_hash = $1
+WHERE id = $2 AND password_hash = $3
+`
+
+type RehashAdminPasswordParams struct {
+	NewHash string      `json:"new_hash"`
+	ID      pgtype.UUID `json:"id"`
+	OldHash string      `json:"old_hash"`
+}
+
+// Upgrades the hash of an unchanged password, so sessions stay valid. Matches no
+// row when the password was changed since old_hash was read.
+func (q *Queries) RehashAdminPassword(ctx context.Context, arg RehashAdminPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rehashAdminPassword, arg.NewHash, arg.ID, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateAdmin = `-- name: UpdateAdmin :one
 UPDATE admins
 SET 
@@ -199,9 +240,16 @@ SET
     last_name = $6,
     role = COALESCE($7, role),
     is_active = COALESCE($8, is_active),
+    token_version = CASE
+        WHEN password_hash IS DISTINCT FROM COALESCE($4, password_hash)
+            OR role IS DISTINCT FROM COALESCE($7, role)
+            OR is_active IS DISTINCT FROM COALESCE($8, is_active)
+        THEN token_version + 1
+        ELSE token_version
+    END,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at
+RETURNING id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at, token_version
 `
 
 type UpdateAdminParams struct {
@@ -215,6 +263,7 @@ type UpdateAdminParams struct {
 	IsActive     bool        `json:"is_active"`
 }
 
+// Changing the password, role or active status bumps token_version, which signs the admin out everywhere
 func (q *Queries) UpdateAdmin(ctx context.Context, arg UpdateAdminParams) (Admin, error) {
 	row := q.db.QueryRow(ctx, updateAdmin,
 		arg.ID,
@@ -238,6 +287,39 @@ func (q *Queries) UpdateAdmin(ctx context.Context, arg UpdateAdminParams) (Admin
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenVersion,
+	)
+	return i, err
+}
+
+const updateAdminPassword = `-- name: UpdateAdminPassword :one
+UPDATE admins
+SET password_hash = $2, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND is_active = true
+RETURNING id, email, username, password_hash, first_name, last_name, role, is_active, created_at, updated_at, token_version
+`
+
+type UpdateAdminPasswordParams struct {
+	ID           pgtype.UUID `json:"id"`
+	PasswordHash string      `json:"password_hash"`
+}
+
+// Bumps token_version so existing sessions are signed out
+func (q *Queries) UpdateAdminPassword(ctx context.Context, arg UpdateAdminPasswordParams) (Admin, error) {
+	row := q.db.QueryRow(ctx, updateAdminPassword, arg.ID, arg.PasswordHash)
+	var i Admin
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.Role,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokenVersion,
 	)
 	return i, err
 }
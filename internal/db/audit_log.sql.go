@@ -11,6 +11,23 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countAuditLogsByActor = `-- name: CountAuditLogsByActor :one
+SELECT COUNT(*) FROM audit_logs
+WHERE actor_type = $1 AND actor_id = $2
+`
+
+type CountAuditLogsByActorParams struct {
+	ActorType AuditActorType `json:"actor_type"`
+	ActorID   pgtype.UUID    `json:"actor_id"`
+}
+
+func (q *Queries) CountAuditLogsByActor(ctx context.Context, arg CountAuditLogsByActorParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAuditLogsByActor, arg.ActorType, arg.ActorID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countAuditLogsByEntity = `-- name: CountAuditLogsByEntity :one
 SELECT COUNT(*) FROM audit_logs
 WHERE entity_type = $1 AND entity_id = $2
@@ -28,13 +45,40 @@ func (q *Queries) CountAuditLogsByEntity(ctx context.Context, arg CountAuditLogs
 	return count, err
 }
 
-const countAuditLogsByUser = `-- name: CountAuditLogsByUser :one
+const countSearchAuditLogs = `-- name: CountSearchAuditLogs :one
 SELECT COUNT(*) FROM audit_logs
-WHERE user_id = $1
+WHERE created_at >= $1
+  AND created_at < $2
+  AND ($3::varchar IS NULL OR entity_type = $3)
+  AND ($4::uuid IS NULL OR entity_id = $4)
+  AND ($5::audit_actor_type IS NULL OR actor_type = $5)
+  AND ($6::uuid IS NULL OR actor_id = $6)
+  AND ($7::audit_action IS NULL OR action = $7)
+  AND ($8::varchar IS NULL OR request_id = $8)
 `
 
-func (q *Queries) CountAuditLogsByUser(ctx context.Context, userID pgtype.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, countAuditLogsByUser, userID)
+type CountSearchAuditLogsParams struct {
+	CreatedFrom pgtype.Timestamptz `json:"created_from"`
+	CreatedTo   pgtype.Timestamptz `json:"created_to"`
+	EntityType  pgtype.Text        `json:"entity_type"`
+	EntityID    pgtype.UUID        `json:"entity_id"`
+	ActorType   NullAuditActorType `json:"actor_type"`
+	ActorID     pgtype.UUID        `json:"actor_id"`
+	Action      NullAuditAction    `json:"action"`
+	RequestID   pgtype.Text        `json:"request_id"`
+}
+
+func (q *Queries) CountSearchAuditLogs(ctx context.Context, arg CountSearchAuditLogsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchAuditLogs,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.EntityType,
+		arg.EntityID,
+		arg.ActorType,
+		arg.ActorID,
+		arg.Action,
+		arg.RequestID,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -42,7 +86,8 @@ func (q *Queries) CountAuditLogsByUser(ctx context.Context, userID pgtype.UUID)
 
 const createAuditLog = `-- name: CreateAuditLog :one
 INSERT INTO audit_logs (
-    user_id,
+    actor_type,
+    actor_id,
     action,
     entity_type,
     entity_id,
@@ -54,12 +99,13 @@ INSERT INTO audit_logs (
     metadata,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-) RETURNING id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id
 `
 
 type CreateAuditLogParams struct {
-	UserID     pgtype.UUID        `json:"user_id"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
 	Action     AuditAction        `json:"action"`
 	EntityType string             `json:"entity_type"`
 	EntityID   pgtype.UUID        `json:"entity_id"`
@@ -74,7 +120,8 @@ type CreateAuditLogParams struct {
 
 func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
 	row := q.db.QueryRow(ctx, createAuditLog,
-		arg.UserID,
+		arg.ActorType,
+		arg.ActorID,
 		arg.Action,
 		arg.EntityType,
 		arg.EntityID,
@@ -89,7 +136,6 @@ func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams)
 	var i AuditLog
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.Action,
 		&i.EntityType,
 		&i.EntityID,
@@ -100,12 +146,14 @@ func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams)
 		&i.UserAgent,
 		&i.Metadata,
 		&i.CreatedAt,
+		&i.ActorType,
+		&i.ActorID,
 	)
 	return i, err
 }
 
 const getAuditLogByID = `-- name: GetAuditLogByID :one
-SELECT id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at FROM audit_logs
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
 WHERE id = $1 LIMIT 1
 `
 
@@ -114,7 +162,6 @@ func (q *Queries) GetAuditLogByID(ctx context.Context, id pgtype.UUID) (AuditLog
 	var i AuditLog
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.Action,
 		&i.EntityType,
 		&i.EntityID,
@@ -125,12 +172,67 @@ func (q *Queries) GetAuditLogByID(ctx context.Context, id pgtype.UUID) (AuditLog
 		&i.UserAgent,
 		&i.Metadata,
 		&i.CreatedAt,
+		&i.ActorType,
+		&i.ActorID,
 	)
 	return i, err
 }
 
+const listAuditLogsByActor = `-- name: ListAuditLogsByActor :many
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
+WHERE actor_type = $1 AND actor_id = $2
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListAuditLogsByActorParams struct {
+	ActorType AuditActorType `json:"actor_type"`
+	ActorID   pgtype.UUID    `json:"actor_id"`
+	Limit     int32          `json:"limit"`
+	Offset    int32          `json:"offset"`
+}
+
+func (q *Queries) ListAuditLogsByActor(ctx context.Context, arg ListAuditLogsByActorParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLogsByActor,
+		arg.ActorType,
+		arg.ActorID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.OldData,
+			&i.NewData,
+			&i.RequestID,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAuditLogsByDateRange = `-- name: ListAuditLogsByDateRange :many
-SELECT id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at FROM audit_logs
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
 WHERE created_at >= $1 AND created_at < $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -159,7 +261,6 @@ func (q *Queries) ListAuditLogsByDateRange(ctx context.Context, arg ListAuditLog
 		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.Action,
 			&i.EntityType,
 			&i.EntityID,
@@ -170,6 +271,8 @@ func (q *Queries) ListAuditLogsByDateRange(ctx context.Context, arg ListAuditLog
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -182,7 +285,7 @@ func (q *Queries) ListAuditLogsByDateRange(ctx context.Context, arg ListAuditLog
 }
 
 const listAuditLogsByEntity = `-- name: ListAuditLogsByEntity :many
-SELECT id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at FROM audit_logs
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
 WHERE entity_type = $1 AND entity_id = $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -211,7 +314,6 @@ func (q *Queries) ListAuditLogsByEntity(ctx context.Context, arg ListAuditLogsBy
 		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.Action,
 			&i.EntityType,
 			&i.EntityID,
@@ -222,6 +324,8 @@ func (q *Queries) ListAuditLogsByEntity(ctx context.Context, arg ListAuditLogsBy
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -234,7 +338,7 @@ func (q *Queries) ListAuditLogsByEntity(ctx context.Context, arg ListAuditLogsBy
 }
 
 const listAuditLogsByEntityAndDateRange = `-- name: ListAuditLogsByEntityAndDateRange :many
-SELECT id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at FROM audit_logs
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
 WHERE entity_type = $1 
   AND entity_id = $2
   AND created_at >= $3 
@@ -270,7 +374,6 @@ func (q *Queries) ListAuditLogsByEntityAndDateRange(ctx context.Context, arg Lis
 		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.Action,
 			&i.EntityType,
 			&i.EntityID,
@@ -281,6 +384,8 @@ func (q *Queries) ListAuditLogsByEntityAndDateRange(ctx context.Context, arg Lis
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -293,7 +398,7 @@ func (q *Queries) ListAuditLogsByEntityAndDateRange(ctx context.Context, arg Lis
 }
 
 const listAuditLogsByEntityType = `-- name: ListAuditLogsByEntityType :many
-SELECT id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at FROM audit_logs
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
 WHERE entity_type = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -316,7 +421,6 @@ func (q *Queries) ListAuditLogsByEntityType(ctx context.Context, arg ListAuditLo
 		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.Action,
 			&i.EntityType,
 			&i.EntityID,
@@ -327,6 +431,8 @@ func (q *Queries) ListAuditLogsByEntityType(ctx context.Context, arg ListAuditLo
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -339,7 +445,7 @@ func (q *Queries) ListAuditLogsByEntityType(ctx context.Context, arg ListAuditLo
 }
 
 const listAuditLogsByRequestID = `-- name: ListAuditLogsByRequestID :many
-SELECT id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at FROM audit_logs
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
 WHERE request_id = $1
 ORDER BY created_at ASC
 `
@@ -355,7 +461,6 @@ func (q *Queries) ListAuditLogsByRequestID(ctx context.Context, requestID pgtype
 		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.Action,
 			&i.EntityType,
 			&i.EntityID,
@@ -366,6 +471,8 @@ func (q *Queries) ListAuditLogsByRequestID(ctx context.Context, requestID pgtype
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -377,21 +484,96 @@ func (q *Queries) ListAuditLogsByRequestID(ctx context.Context, requestID pgtype
 	return items, nil
 }
 
-const listAuditLogsByUser = `-- name: ListAuditLogsByUser :many
-SELECT id, user_id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at FROM audit_logs
-WHERE user_id = $1
+const listAuditLogsForUser = `-- name: ListAuditLogsForUser :many
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
+WHERE (actor_type = 'user' AND actor_id = $1)
+   OR (entity_type = 'users' AND entity_id = $1)
+   OR (entity_type = 'addresses' AND entity_id IN (SELECT id FROM addresses WHERE user_id = $1))
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
 
-type ListAuditLogsByUserParams struct {
-	UserID pgtype.UUID `json:"user_id"`
-	Limit  int32       `json:"limit"`
-	Offset int32       `json:"offset"`
+type ListAuditLogsForUserParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	RowLimit  int32       `json:"row_limit"`
+	RowOffset int32       `json:"row_offset"`
 }
 
-func (q *Queries) ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUserParams) ([]AuditLog, error) {
-	rows, err := q.db.Query(ctx, listAuditLogsByUser, arg.UserID, arg.Limit, arg.Offset)
+// Entries about the user or their addresses, and changes the user made
+func (q *Queries) ListAuditLogsForUser(ctx context.Context, arg ListAuditLogsForUserParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLogsForUser, arg.UserID, arg.RowLimit, arg.RowOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.OldData,
+			&i.NewData,
+			&i.RequestID,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAuditLogs = `-- name: SearchAuditLogs :many
+SELECT id, action, entity_type, entity_id, old_data, new_data, request_id, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM audit_logs
+WHERE created_at >= $1
+  AND created_at < $2
+  AND ($3::varchar IS NULL OR entity_type = $3)
+  AND ($4::uuid IS NULL OR entity_id = $4)
+  AND ($5::audit_actor_type IS NULL OR actor_type = $5)
+  AND ($6::uuid IS NULL OR actor_id = $6)
+  AND ($7::audit_action IS NULL OR action = $7)
+  AND ($8::varchar IS NULL OR request_id = $8)
+ORDER BY created_at DESC
+LIMIT $9 OFFSET $10
+`
+
+type SearchAuditLogsParams struct {
+	CreatedFrom pgtype.Timestamptz `json:"created_from"`
+	CreatedTo   pgtype.Timestamptz `json:"created_to"`
+	EntityType  pgtype.Text        `json:"entity_type"`
+	EntityID    pgtype.UUID        `json:"entity_id"`
+	ActorType   NullAuditActorType `json:"actor_type"`
+	ActorID     pgtype.UUID        `json:"actor_id"`
+	Action      NullAuditAction    `json:"action"`
+	RequestID   pgtype.Text        `json:"request_id"`
+	Limit       int32              `json:"limit"`
+	Offset      int32              `json:"offset"`
+}
+
+func (q *Queries) SearchAuditLogs(ctx context.Context, arg SearchAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, searchAuditLogs,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.EntityType,
+		arg.EntityID,
+		arg.ActorType,
+		arg.ActorID,
+		arg.Action,
+		arg.RequestID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -401,7 +583,6 @@ func (q *Queries) ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUs
 		var i AuditLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.Action,
 			&i.EntityType,
 			&i.EntityID,
@@ -412,6 +593,8 @@ func (q *Queries) ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUs
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
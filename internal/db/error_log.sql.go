@@ -42,7 +42,8 @@ func (q *Queries) CountErrorLogsByType(ctx context.Context, errorType string) (i
 
 const createErrorLog = `-- name: CreateErrorLog :one
 INSERT INTO error_logs (
-    user_id,
+    actor_type,
+    actor_id,
     request_id,
     error_type,
     error_message,
@@ -54,12 +55,13 @@ INSERT INTO error_logs (
     metadata,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-) RETURNING id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id
 `
 
 type CreateErrorLogParams struct {
-	UserID        pgtype.UUID        `json:"user_id"`
+	ActorType     AuditActorType     `json:"actor_type"`
+	ActorID       pgtype.UUID        `json:"actor_id"`
 	RequestID     pgtype.Text        `json:"request_id"`
 	ErrorType     string             `json:"error_type"`
 	ErrorMessage  string             `json:"error_message"`
@@ -74,7 +76,8 @@ type CreateErrorLogParams struct {
 
 func (q *Queries) CreateErrorLog(ctx context.Context, arg CreateErrorLogParams) (ErrorLog, error) {
 	row := q.db.QueryRow(ctx, createErrorLog,
-		arg.UserID,
+		arg.ActorType,
+		arg.ActorID,
 		arg.RequestID,
 		arg.ErrorType,
 		arg.ErrorMessage,
@@ -89,7 +92,6 @@ func (q *Queries) CreateErrorLog(ctx context.Context, arg CreateErrorLogParams)
 	var i ErrorLog
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.RequestID,
 		&i.ErrorType,
 		&i.ErrorMessage,
@@ -100,12 +102,14 @@ func (q *Queries) CreateErrorLog(ctx context.Context, arg CreateErrorLogParams)
 		&i.UserAgent,
 		&i.Metadata,
 		&i.CreatedAt,
+		&i.ActorType,
+		&i.ActorID,
 	)
 	return i, err
 }
 
 const getErrorLogByID = `-- name: GetErrorLogByID :one
-SELECT id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at FROM error_logs
+SELECT id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM error_logs
 WHERE id = $1 LIMIT 1
 `
 
@@ -114,7 +118,6 @@ func (q *Queries) GetErrorLogByID(ctx context.Context, id pgtype.UUID) (ErrorLog
 	var i ErrorLog
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.RequestID,
 		&i.ErrorType,
 		&i.ErrorMessage,
@@ -125,12 +128,67 @@ func (q *Queries) GetErrorLogByID(ctx context.Context, id pgtype.UUID) (ErrorLog
 		&i.UserAgent,
 		&i.Metadata,
 		&i.CreatedAt,
+		&i.ActorType,
+		&i.ActorID,
 	)
 	return i, err
 }
 
+const listErrorLogsByActor = `-- name: ListErrorLogsByActor :many
+SELECT id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM error_logs
+WHERE actor_type = $1 AND actor_id = $2
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListErrorLogsByActorParams struct {
+	ActorType AuditActorType `json:"actor_type"`
+	ActorID   pgtype.UUID    `json:"actor_id"`
+	Limit     int32          `json:"limit"`
+	Offset    int32          `json:"offset"`
+}
+
+func (q *Queries) ListErrorLogsByActor(ctx context.Context, arg ListErrorLogsByActorParams) ([]ErrorLog, error) {
+	rows, err := q.db.Query(ctx, listErrorLogsByActor,
+		arg.ActorType,
+		arg.ActorID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ErrorLog{}
+	for rows.Next() {
+		var i ErrorLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequestID,
+			&i.ErrorType,
+			&i.ErrorMessage,
+			&i.StackTrace,
+			&i.RequestPath,
+			&i.RequestMethod,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listErrorLogsByDateRange = `-- name: ListErrorLogsByDateRange :many
-SELECT id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at FROM error_logs
+SELECT id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM error_logs
 WHERE created_at >= $1 AND created_at < $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -159,7 +217,6 @@ func (q *Queries) ListErrorLogsByDateRange(ctx context.Context, arg ListErrorLog
 		var i ErrorLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.RequestID,
 			&i.ErrorType,
 			&i.ErrorMessage,
@@ -170,6 +227,8 @@ func (q *Queries) ListErrorLogsByDateRange(ctx context.Context, arg ListErrorLog
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -182,7 +241,7 @@ func (q *Queries) ListErrorLogsByDateRange(ctx context.Context, arg ListErrorLog
 }
 
 const listErrorLogsByPath = `-- name: ListErrorLogsByPath :many
-SELECT id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at FROM error_logs
+SELECT id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM error_logs
 WHERE request_path = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -205,7 +264,6 @@ func (q *Queries) ListErrorLogsByPath(ctx context.Context, arg ListErrorLogsByPa
 		var i ErrorLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.RequestID,
 			&i.ErrorType,
 			&i.ErrorMessage,
@@ -216,6 +274,8 @@ func (q *Queries) ListErrorLogsByPath(ctx context.Context, arg ListErrorLogsByPa
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -228,7 +288,7 @@ func (q *Queries) ListErrorLogsByPath(ctx context.Context, arg ListErrorLogsByPa
 }
 
 const listErrorLogsByRequestID = `-- name: ListErrorLogsByRequestID :many
-SELECT id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at FROM error_logs
+SELECT id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM error_logs
 WHERE request_id = $1
 ORDER BY created_at ASC
 `
@@ -244,7 +304,6 @@ func (q *Queries) ListErrorLogsByRequestID(ctx context.Context, requestID pgtype
 		var i ErrorLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.RequestID,
 			&i.ErrorType,
 			&i.ErrorMessage,
@@ -255,6 +314,8 @@ func (q *Queries) ListErrorLogsByRequestID(ctx context.Context, requestID pgtype
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -267,7 +328,7 @@ func (q *Queries) ListErrorLogsByRequestID(ctx context.Context, requestID pgtype
 }
 
 const listErrorLogsByType = `-- name: ListErrorLogsByType :many
-SELECT id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at FROM error_logs
+SELECT id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM error_logs
 WHERE error_type = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -290,53 +351,6 @@ func (q *Queries) ListErrorLogsByType(ctx context.Context, arg ListErrorLogsByTy
 		var i ErrorLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
-			&i.RequestID,
-			&i.ErrorType,
-			&i.ErrorMessage,
-			&i.StackTrace,
-			&i.RequestPath,
-			&i.RequestMethod,
-			&i.IpAddress,
-			&i.UserAgent,
-			&i.Metadata,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const listErrorLogsByUser = `-- name: ListErrorLogsByUser :many
-SELECT id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at FROM error_logs
-WHERE user_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
-`
-
-type ListErrorLogsByUserParams struct {
-	UserID pgtype.UUID `json:"user_id"`
-	Limit  int32       `json:"limit"`
-	Offset int32       `json:"offset"`
-}
-
-func (q *Queries) ListErrorLogsByUser(ctx context.Context, arg ListErrorLogsByUserParams) ([]ErrorLog, error) {
-	rows, err := q.db.Query(ctx, listErrorLogsByUser, arg.UserID, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []ErrorLog{}
-	for rows.Next() {
-		var i ErrorLog
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
 			&i.RequestID,
 			&i.ErrorType,
 			&i.ErrorMessage,
@@ -347,6 +361,8 @@ func (q *Queries) ListErrorLogsByUser(ctx context.Context, arg ListErrorLogsByUs
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
@@ -359,7 +375,7 @@ func (q *Queries) ListErrorLogsByUser(ctx context.Context, arg ListErrorLogsByUs
 }
 
 const listRecentErrors = `-- name: ListRecentErrors :many
-SELECT id, user_id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at FROM error_logs
+SELECT id, request_id, error_type, error_message, stack_trace, request_path, request_method, ip_address, user_agent, metadata, created_at, actor_type, actor_id FROM error_logs
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -380,7 +396,6 @@ func (q *Queries) ListRecentErrors(ctx context.Context, arg ListRecentErrorsPara
 		var i ErrorLog
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
 			&i.RequestID,
 			&i.ErrorType,
 			&i.ErrorMessage,
@@ -391,6 +406,8 @@ func (q *Queries) ListRecentErrors(ctx context.Context, arg ListRecentErrorsPara
 			&i.UserAgent,
 			&i.Metadata,
 			&i.CreatedAt,
+			&i.ActorType,
+			&i.ActorID,
 		); err != nil {
 			return nil, err
 		}
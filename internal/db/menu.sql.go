@@ -205,11 +205,7 @@ WITH RECURSIVE menu_tree AS (
       AND mi.is_active = true
       AND (
           mi.permission_id IS NULL 
-          OR mi.permission_id IN (
-              SELECT rp.permission_id 
-              FROM role_permissions rp 
-              WHERE rp.role = $1
-          )
+          OR mi.permission_id IN (SELECT admin_permission_ids($1, $2))
       )
     
     UNION ALL
@@ -230,17 +226,18 @@ WITH RECURSIVE menu_tree AS (
     WHERE mi.is_active = true
       AND (
           mi.permission_id IS NULL 
-          OR mi.permission_id IN (
-              SELECT rp.permission_id 
-              FROM role_permissions rp 
-              WHERE rp.role = $1
-          )
+          OR mi.permission_id IN (SELECT admin_permission_ids($1, $2))
       )
 )
 SELECT id, parent_id, code, label, icon, path, permission_id, order_index, depth FROM menu_tree
 ORDER BY depth, order_index
 `
 
+type GetMenuItemsByRoleParams struct {
+	AdminID pgtype.UUID `json:"admin_id"`
+	Role    string      `json:"role"`
+}
+
 type GetMenuItemsByRoleRow struct {
 	ID           pgtype.UUID `json:"id"`
 	ParentID     pgtype.UUID `json:"parent_id"`
@@ -253,8 +250,9 @@ type GetMenuItemsByRoleRow struct {
 	Depth        int32       `json:"depth"`
 }
 
-func (q *Queries) GetMenuItemsByRole(ctx context.Context, role string) ([]GetMenuItemsByRoleRow, error) {
-	rows, err := q.db.Query(ctx, getMenuItemsByRole, role)
+// Items the admin may see through their role and their own permission overrides
+func (q *Queries) GetMenuItemsByRole(ctx context.Context, arg GetMenuItemsByRoleParams) ([]GetMenuItemsByRoleRow, error) {
+	rows, err := q.db.Query(ctx, getMenuItemsByRole, arg.AdminID, arg.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +54,49 @@ func (ns NullAuditAction) Value() (driver.Value, error) {
 	return string(ns.AuditAction), nil
 }
 
+type AuditActorType string
+
+const (
+	AuditActorTypeAdmin  AuditActorType = "admin"
+	AuditActorTypeUser   AuditActorType = "user"
+	AuditActorTypeSystem AuditActorType = "system"
+)
+
+func (e *AuditActorType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AuditActorType(s)
+	case string:
+		*e = AuditActorType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AuditActorType: %T", src)
+	}
+	return nil
+}
+
+type NullAuditActorType struct {
+	AuditActorType AuditActorType `json:"audit_actor_type"`
+	Valid          bool           `json:"valid"` // Valid is true if AuditActorType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAuditActorType) Scan(value interface{}) error {
+	if value == nil {
+		ns.AuditActorType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AuditActorType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAuditActorType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AuditActorType), nil
+}
+
 type Address struct {
 	ID          pgtype.UUID        `json:"id"`
 	UserID      pgtype.UUID        `json:"user_id"`
@@ -77,11 +120,67 @@ type Admin struct {
 	IsActive     bool               `json:"is_active"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	TokenVersion int32              `json:"token_version"`
+}
+
+type AdminApiKey struct {
+	ID          pgtype.UUID        `json:"id"`
+	AdminID     pgtype.UUID        `json:"admin_id"`
+	Name        string             `json:"name"`
+	KeyPrefix   string             `json:"key_prefix"`
+	KeyHash     string             `json:"key_hash"`
+	Permissions []string           `json:"permissions"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt   pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AdminIdentity struct {
+	ID          pgtype.UUID        `json:"id"`
+	AdminID     pgtype.UUID        `json:"admin_id"`
+	Issuer      string             `json:"issuer"`
+	Subject     string             `json:"subject"`
+	Email       pgtype.Text        `json:"email"`
+	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AdminMfa struct {
+	AdminID        pgtype.UUID        `json:"admin_id"`
+	Secret         string             `json:"secret"`
+	EnabledAt      pgtype.Timestamptz `json:"enabled_at"`
+	LastUsedStep   pgtype.Int8        `json:"last_used_step"`
+	FailedAttempts int32              `json:"failed_attempts"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AdminMfaRecoveryCode struct {
+	ID        pgtype.UUID        `json:"id"`
+	AdminID   pgtype.UUID        `json:"admin_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AdminPermissionOverride struct {
+	ID           pgtype.UUID        `json:"id"`
+	AdminID      pgtype.UUID        `json:"admin_id"`
+	PermissionID pgtype.UUID        `json:"permission_id"`
+	Effect       string             `json:"effect"`
+	Reason       pgtype.Text        `json:"reason"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedBy    pgtype.UUID        `json:"created_by"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
 type AuditLog struct {
 	ID         pgtype.UUID        `json:"id"`
-	UserID     pgtype.UUID        `json:"user_id"`
 	Action     AuditAction        `json:"action"`
 	EntityType string             `json:"entity_type"`
 	EntityID   pgtype.UUID        `json:"entity_id"`
@@ -92,11 +191,12 @@ type AuditLog struct {
 	UserAgent  pgtype.Text        `json:"user_agent"`
 	Metadata   []byte             `json:"metadata"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
 }
 
 type AuditLogs202511 struct {
 	ID         pgtype.UUID        `json:"id"`
-	UserID     pgtype.UUID        `json:"user_id"`
 	Action     AuditAction        `json:"action"`
 	EntityType string             `json:"entity_type"`
 	EntityID   pgtype.UUID        `json:"entity_id"`
@@ -107,11 +207,12 @@ type AuditLogs202511 struct {
 	UserAgent  pgtype.Text        `json:"user_agent"`
 	Metadata   []byte             `json:"metadata"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
 }
 
 type AuditLogs202512 struct {
 	ID         pgtype.UUID        `json:"id"`
-	UserID     pgtype.UUID        `json:"user_id"`
 	Action     AuditAction        `json:"action"`
 	EntityType string             `json:"entity_type"`
 	EntityID   pgtype.UUID        `json:"entity_id"`
@@ -122,11 +223,12 @@ type AuditLogs202512 struct {
 	UserAgent  pgtype.Text        `json:"user_agent"`
 	Metadata   []byte             `json:"metadata"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
 }
 
 type AuditLogs202601 struct {
 	ID         pgtype.UUID        `json:"id"`
-	UserID     pgtype.UUID        `json:"user_id"`
 	Action     AuditAction        `json:"action"`
 	EntityType string             `json:"entity_type"`
 	EntityID   pgtype.UUID        `json:"entity_id"`
@@ -137,11 +239,12 @@ type AuditLogs202601 struct {
 	UserAgent  pgtype.Text        `json:"user_agent"`
 	Metadata   []byte             `json:"metadata"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
 }
 
 type AuditLogs202602 struct {
 	ID         pgtype.UUID        `json:"id"`
-	UserID     pgtype.UUID        `json:"user_id"`
 	Action     AuditAction        `json:"action"`
 	EntityType string             `json:"entity_type"`
 	EntityID   pgtype.UUID        `json:"entity_id"`
@@ -152,11 +255,12 @@ type AuditLogs202602 struct {
 	UserAgent  pgtype.Text        `json:"user_agent"`
 	Metadata   []byte             `json:"metadata"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
 }
 
 type AuditLogsDefault struct {
 	ID         pgtype.UUID        `json:"id"`
-	UserID     pgtype.UUID        `json:"user_id"`
 	Action     AuditAction        `json:"action"`
 	EntityType string             `json:"entity_type"`
 	EntityID   pgtype.UUID        `json:"entity_id"`
@@ -167,11 +271,43 @@ type AuditLogsDefault struct {
 	UserAgent  pgtype.Text        `json:"user_agent"`
 	Metadata   []byte             `json:"metadata"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
+}
+
+type AuthThrottle struct {
+	ID             pgtype.UUID        `json:"id"`
+	SubjectType    string             `json:"subject_type"`
+	KeyType        string             `json:"key_type"`
+	ThrottleKey    string             `json:"throttle_key"`
+	FailedAttempts int32              `json:"failed_attempts"`
+	LastFailedAt   pgtype.Timestamptz `json:"last_failed_at"`
+	LockedUntil    pgtype.Timestamptz `json:"locked_until"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type DataExport struct {
+	ID              pgtype.UUID        `json:"id"`
+	UserID          pgtype.UUID        `json:"user_id"`
+	RequestedByType AuditActorType     `json:"requested_by_type"`
+	RequestedByID   pgtype.UUID        `json:"requested_by_id"`
+	Status          string             `json:"status"`
+	Error           pgtype.Text        `json:"error"`
+	StartedAt       pgtype.Timestamptz `json:"started_at"`
+	CompletedAt     pgtype.Timestamptz `json:"completed_at"`
+	ExpiresAt       pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type DataExportArchive struct {
+	ExportID  pgtype.UUID        `json:"export_id"`
+	Content   []byte             `json:"content"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type ErrorLog struct {
 	ID            pgtype.UUID        `json:"id"`
-	UserID        pgtype.UUID        `json:"user_id"`
 	RequestID     pgtype.Text        `json:"request_id"`
 	ErrorType     string             `json:"error_type"`
 	ErrorMessage  string             `json:"error_message"`
@@ -182,11 +318,12 @@ type ErrorLog struct {
 	UserAgent     pgtype.Text        `json:"user_agent"`
 	Metadata      []byte             `json:"metadata"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ActorType     AuditActorType     `json:"actor_type"`
+	ActorID       pgtype.UUID        `json:"actor_id"`
 }
 
 type ErrorLogs202511 struct {
 	ID            pgtype.UUID        `json:"id"`
-	UserID        pgtype.UUID        `json:"user_id"`
 	RequestID     pgtype.Text        `json:"request_id"`
 	ErrorType     string             `json:"error_type"`
 	ErrorMessage  string             `json:"error_message"`
@@ -197,11 +334,12 @@ type ErrorLogs202511 struct {
 	UserAgent     pgtype.Text        `json:"user_agent"`
 	Metadata      []byte             `json:"metadata"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ActorType     AuditActorType     `json:"actor_type"`
+	ActorID       pgtype.UUID        `json:"actor_id"`
 }
 
 type ErrorLogs202512 struct {
 	ID            pgtype.UUID        `json:"id"`
-	UserID        pgtype.UUID        `json:"user_id"`
 	RequestID     pgtype.Text        `json:"request_id"`
 	ErrorType     string             `json:"error_type"`
 	ErrorMessage  string             `json:"error_message"`
@@ -212,11 +350,12 @@ type ErrorLogs202512 struct {
 	UserAgent     pgtype.Text        `json:"user_agent"`
 	Metadata      []byte             `json:"metadata"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ActorType     AuditActorType     `json:"actor_type"`
+	ActorID       pgtype.UUID        `json:"actor_id"`
 }
 
 type ErrorLogs202601 struct {
 	ID            pgtype.UUID        `json:"id"`
-	UserID        pgtype.UUID        `json:"user_id"`
 	RequestID     pgtype.Text        `json:"request_id"`
 	ErrorType     string             `json:"error_type"`
 	ErrorMessage  string             `json:"error_message"`
@@ -227,11 +366,12 @@ type ErrorLogs202601 struct {
 	UserAgent     pgtype.Text        `json:"user_agent"`
 	Metadata      []byte             `json:"metadata"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ActorType     AuditActorType     `json:"actor_type"`
+	ActorID       pgtype.UUID        `json:"actor_id"`
 }
 
 type ErrorLogs202602 struct {
 	ID            pgtype.UUID        `json:"id"`
-	UserID        pgtype.UUID        `json:"user_id"`
 	RequestID     pgtype.Text        `json:"request_id"`
 	ErrorType     string             `json:"error_type"`
 	ErrorMessage  string             `json:"error_message"`
@@ -242,11 +382,12 @@ type ErrorLogs202602 struct {
 	UserAgent     pgtype.Text        `json:"user_agent"`
 	Metadata      []byte             `json:"metadata"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ActorType     AuditActorType     `json:"actor_type"`
+	ActorID       pgtype.UUID        `json:"actor_id"`
 }
 
 type ErrorLogsDefault struct {
 	ID            pgtype.UUID        `json:"id"`
-	UserID        pgtype.UUID        `json:"user_id"`
 	RequestID     pgtype.Text        `json:"request_id"`
 	ErrorType     string             `json:"error_type"`
 	ErrorMessage  string             `json:"error_message"`
@@ -257,6 +398,15 @@ type ErrorLogsDefault struct {
 	UserAgent     pgtype.Text        `json:"user_agent"`
 	Metadata      []byte             `json:"metadata"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ActorType     AuditActorType     `json:"actor_type"`
+	ActorID       pgtype.UUID        `json:"actor_id"`
+}
+
+type MagicLinkRequest struct {
+	ID        pgtype.UUID        `json:"id"`
+	Email     string             `json:"email"`
+	IpAddress pgtype.Text        `json:"ip_address"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type MenuItem struct {
@@ -273,6 +423,27 @@ type MenuItem struct {
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
+type OidcLoginState struct {
+	ID           pgtype.UUID        `json:"id"`
+	StateHash    string             `json:"state_hash"`
+	Nonce        string             `json:"nonce"`
+	CodeVerifier string             `json:"code_verifier"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type OneTimeToken struct {
+	ID          pgtype.UUID        `json:"id"`
+	Purpose     string             `json:"purpose"`
+	SubjectType string             `json:"subject_type"`
+	SubjectID   pgtype.UUID        `json:"subject_id"`
+	TokenHash   string             `json:"token_hash"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	UsedAt      pgtype.Timestamptz `json:"used_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Order struct {
 	ID          pgtype.UUID        `json:"id"`
 	UserID      pgtype.UUID        `json:"user_id"`
@@ -317,6 +488,14 @@ type OrdersDefault struct {
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
+type PasswordHistory struct {
+	ID           pgtype.UUID        `json:"id"`
+	SubjectType  string             `json:"subject_type"`
+	SubjectID    pgtype.UUID        `json:"subject_id"`
+	PasswordHash string             `json:"password_hash"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
 type Permission struct {
 	ID          pgtype.UUID        `json:"id"`
 	Code        string             `json:"code"`
@@ -328,6 +507,46 @@ type Permission struct {
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
+type RefreshToken struct {
+	ID           pgtype.UUID        `json:"id"`
+	SubjectType  string             `json:"subject_type"`
+	SubjectID    pgtype.UUID        `json:"subject_id"`
+	FamilyID     pgtype.UUID        `json:"family_id"`
+	TokenHash    string             `json:"token_hash"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt    pgtype.Timestamptz `json:"revoked_at"`
+	ReplacedBy   pgtype.UUID        `json:"replaced_by"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	TokenVersion int32              `json:"token_version"`
+}
+
+type RevokedToken struct {
+	Jti         pgtype.UUID        `json:"jti"`
+	SubjectType string             `json:"subject_type"`
+	SubjectID   pgtype.UUID        `json:"subject_id"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Role struct {
+	ID           pgtype.UUID        `json:"id"`
+	Name         string             `json:"name"`
+	Description  pgtype.Text        `json:"description"`
+	IsSystem     bool               `json:"is_system"`
+	InheritsFrom pgtype.Text        `json:"inherits_from"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type RoleMfaPolicy struct {
+	Role        string             `json:"role"`
+	MfaRequired bool               `json:"mfa_required"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type RolePermission struct {
 	ID           pgtype.UUID        `json:"id"`
 	Role         string             `json:"role"`
@@ -335,14 +554,42 @@ type RolePermission struct {
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
 
+type SecurityEvent struct {
+	ID         pgtype.UUID        `json:"id"`
+	EventType  string             `json:"event_type"`
+	Outcome    string             `json:"outcome"`
+	ActorType  AuditActorType     `json:"actor_type"`
+	ActorID    pgtype.UUID        `json:"actor_id"`
+	Identifier pgtype.Text        `json:"identifier"`
+	Reason     pgtype.Text        `json:"reason"`
+	RequestID  pgtype.Text        `json:"request_id"`
+	IpAddress  pgtype.Text        `json:"ip_address"`
+	UserAgent  pgtype.Text        `json:"user_agent"`
+	Metadata   []byte             `json:"metadata"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type User struct {
-	ID               pgtype.UUID        `json:"id"`
-	Email            string             `json:"email"`
-	Username         string             `json:"username"`
-	PasswordHash     string             `json:"password_hash"`
-	FirstName        pgtype.Text        `json:"first_name"`
-	LastName         pgtype.Text        `json:"last_name"`
-	CreatedAt        pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
-	DefaultAddressID pgtype.UUID        `json:"default_address_id"`
+	ID                  pgtype.UUID        `json:"id"`
+	Email               string             `json:"email"`
+	Username            string             `json:"username"`
+	PasswordHash        string             `json:"password_hash"`
+	FirstName           pgtype.Text        `json:"first_name"`
+	LastName            pgtype.Text        `json:"last_name"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	DefaultAddressID    pgtype.UUID        `json:"default_address_id"`
+	TokenVersion        int32              `json:"token_version"`
+	EmailVerifiedAt     pgtype.Timestamptz `json:"email_verified_at"`
+	DeletionScheduledAt pgtype.Timestamptz `json:"deletion_scheduled_at"`
+	AnonymizedAt        pgtype.Timestamptz `json:"anonymized_at"`
+}
+
+type UserSession struct {
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	UserAgent  pgtype.Text        `json:"user_agent"`
+	IpAddress  pgtype.Text        `json:"ip_address"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
@@ -105,6 +105,35 @@ func (q *Queries) GetAllPermissions(ctx context.Context) ([]Permission, error) {
 	return items, nil
 }
 
+const getDirectRolePermissionCodes = `-- name: GetDirectRolePermissionCodes :many
+SELECT p.code
+FROM permissions p
+INNER JOIN role_permissions rp ON rp.permission_id = p.id
+WHERE rp.role = $1 AND p.is_active = true
+ORDER BY p.code
+`
+
+// Only the permissions granted to the role itself, not the inherited ones
+func (q *Queries) GetDirectRolePermissionCodes(ctx context.Context, role string) ([]string, error) {
+	rows, err := q.db.Query(ctx, getDirectRolePermissionCodes, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		items = append(items, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPermissionByCode = `-- name: GetPermissionByCode :one
 SELECT id, code, name, description, category, is_active, created_at, updated_at FROM permissions
 WHERE code = $1 AND is_active = true
@@ -129,10 +158,15 @@ func (q *Queries) GetPermissionByCode(ctx context.Context, code string) (Permiss
 const getPermissionsByRole = `-- name: GetPermissionsByRole :many
 SELECT p.id, p.code, p.name, p.description, p.category, p.is_active, p.created_at, p.updated_at
 FROM permissions p
-INNER JOIN role_permissions rp ON rp.permission_id = p.id
-WHERE rp.role = $1 AND p.is_active = true
+WHERE p.is_active = true
+  AND p.id IN (
+      SELECT rp.permission_id
+      FROM role_permissions rp
+      WHERE rp.role IN (SELECT role_lineage($1))
+  )
 `
 
+// Includes the permissions the role inherits
 func (q *Queries) GetPermissionsByRole(ctx context.Context, role string) ([]Permission, error) {
 	rows, err := q.db.Query(ctx, getPermissionsByRole, role)
 	if err != nil {
@@ -162,13 +196,40 @@ func (q *Queries) GetPermissionsByRole(ctx context.Context, role string) ([]Perm
 	return items, nil
 }
 
+const getRolePermission = `-- name: GetRolePermission :one
+SELECT id, role, permission_id, created_at FROM role_permissions
+WHERE role = $1 AND permission_id = $2
+`
+
+type GetRolePermissionParams struct {
+	Role         string      `json:"role"`
+	PermissionID pgtype.UUID `json:"permission_id"`
+}
+
+func (q *Queries) GetRolePermission(ctx context.Context, arg GetRolePermissionParams) (RolePermission, error) {
+	row := q.db.QueryRow(ctx, getRolePermission, arg.Role, arg.PermissionID)
+	var i RolePermission
+	err := row.Scan(
+		&i.ID,
+		&i.Role,
+		&i.PermissionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getRolePermissionCodes = `-- name: GetRolePermissionCodes :many
 SELECT p.code
 FROM permissions p
-INNER JOIN role_permissions rp ON rp.permission_id = p.id
-WHERE rp.role = $1 AND p.is_active = true
+WHERE p.is_active = true
+  AND p.id IN (
+      SELECT rp.permission_id
+      FROM role_permissions rp
+      WHERE rp.role IN (SELECT role_lineage($1))
+  )
 `
 
+// Includes the permissions the role inherits
 func (q *Queries) GetRolePermissionCodes(ctx context.Context, role string) ([]string, error) {
 	rows, err := q.db.Query(ctx, getRolePermissionCodes, role)
 	if err != nil {
@@ -11,82 +11,228 @@ import (
 )
 
 type Querier interface {
+	// Returns false if the user does not exist or was already anonymized
+	AnonymizeUser(ctx context.Context, id pgtype.UUID) (bool, error)
 	AssignPermissionToRole(ctx context.Context, arg AssignPermissionToRoleParams) error
+	CancelUserDeletion(ctx context.Context, id pgtype.UUID) (User, error)
+	// Takes the oldest pending export, or one whose worker stopped before finishing,
+	// and marks it processing. Concurrent workers skip exports another one claimed.
+	ClaimDataExport(ctx context.Context, staleBefore pgtype.Timestamptz) (DataExport, error)
 	ClearDefaultAddress(ctx context.Context, id pgtype.UUID) (User, error)
+	CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) (DataExport, error)
+	// Deletes the state so it cannot be used twice; returns no rows if it is unknown or expired
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (OidcLoginState, error)
+	// Marks the token used; returns no rows if it is unknown, expired or already used
+	ConsumeOneTimeToken(ctx context.Context, arg ConsumeOneTimeTokenParams) (OneTimeToken, error)
 	CountAddresses(ctx context.Context) (int64, error)
 	CountAddressesByUserID(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountAuditLogsByActor(ctx context.Context, arg CountAuditLogsByActorParams) (int64, error)
 	CountAuditLogsByEntity(ctx context.Context, arg CountAuditLogsByEntityParams) (int64, error)
-	CountAuditLogsByUser(ctx context.Context, userID pgtype.UUID) (int64, error)
 	CountErrorLogsByDateRange(ctx context.Context, arg CountErrorLogsByDateRangeParams) (int64, error)
 	CountErrorLogsByType(ctx context.Context, errorType string) (int64, error)
+	CountMagicLinkRequestsByEmailSince(ctx context.Context, arg CountMagicLinkRequestsByEmailSinceParams) (int64, error)
+	CountMagicLinkRequestsByIPSince(ctx context.Context, arg CountMagicLinkRequestsByIPSinceParams) (int64, error)
+	// Used to throttle how often a token can be re-sent
+	CountOneTimeTokensSince(ctx context.Context, arg CountOneTimeTokensSinceParams) (int64, error)
+	CountSearchAuditLogs(ctx context.Context, arg CountSearchAuditLogsParams) (int64, error)
+	CountSearchSecurityEvents(ctx context.Context, arg CountSearchSecurityEventsParams) (int64, error)
+	CountUnusedAdminMFARecoveryCodes(ctx context.Context, adminID pgtype.UUID) (int64, error)
 	CreateAddress(ctx context.Context, arg CreateAddressParams) (Address, error)
 	CreateAdmin(ctx context.Context, arg CreateAdminParams) (Admin, error)
+	CreateAdminAPIKey(ctx context.Context, arg CreateAdminAPIKeyParams) (AdminApiKey, error)
+	CreateAdminIdentity(ctx context.Context, arg CreateAdminIdentityParams) (AdminIdentity, error)
+	CreateAdminMFARecoveryCode(ctx context.Context, arg CreateAdminMFARecoveryCodeParams) error
 	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateDataExport(ctx context.Context, arg CreateDataExportParams) (DataExport, error)
+	CreateDataExportArchive(ctx context.Context, arg CreateDataExportArchiveParams) error
 	CreateErrorLog(ctx context.Context, arg CreateErrorLogParams) (ErrorLog, error)
+	CreateMagicLinkRequest(ctx context.Context, arg CreateMagicLinkRequestParams) error
 	CreateMenuItem(ctx context.Context, arg CreateMenuItemParams) (MenuItem, error)
+	CreateOIDCLoginState(ctx context.Context, arg CreateOIDCLoginStateParams) error
+	CreateOneTimeToken(ctx context.Context, arg CreateOneTimeTokenParams) (OneTimeToken, error)
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreatePasswordHistory(ctx context.Context, arg CreatePasswordHistoryParams) error
 	CreatePermission(ctx context.Context, arg CreatePermissionParams) (Permission, error)
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
+	CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserSession(ctx context.Context, arg CreateUserSessionParams) error
 	DeleteAddress(ctx context.Context, id pgtype.UUID) error
 	DeleteAddressForUser(ctx context.Context, arg DeleteAddressForUserParams) error
 	DeleteAddressesByUserID(ctx context.Context, userID pgtype.UUID) error
 	DeleteAdmin(ctx context.Context, id pgtype.UUID) error
+	DeleteAdminMFA(ctx context.Context, adminID pgtype.UUID) error
+	DeleteAdminMFARecoveryCodes(ctx context.Context, adminID pgtype.UUID) error
+	// Returns no rows if the admin has no override for the permission
+	DeleteAdminPermissionOverride(ctx context.Context, arg DeleteAdminPermissionOverrideParams) (AdminPermissionOverride, error)
+	DeleteAuthThrottle(ctx context.Context, arg DeleteAuthThrottleParams) (AuthThrottle, error)
+	// Removes sessions whose refresh tokens have all been purged
+	DeleteEndedUserSessions(ctx context.Context) (int64, error)
+	DeleteExpiredDataExports(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	DeleteExpiredOIDCLoginStates(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	DeleteExpiredOneTimeTokens(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	DeleteExpiredRefreshTokens(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	DeleteExpiredRevokedTokens(ctx context.Context, expiresAt pgtype.Timestamptz) (int64, error)
+	DeleteMagicLinkRequestsBefore(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error)
 	DeleteMenuItem(ctx context.Context, id pgtype.UUID) error
 	DeleteOrder(ctx context.Context, id pgtype.UUID) error
 	DeletePermission(ctx context.Context, id pgtype.UUID) error
+	// System roles are never deleted
+	DeleteRole(ctx context.Context, name string) (int64, error)
+	DeleteSecurityEventsBefore(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error)
+	// Removes counters whose last failure is older than $1 and that are not locked at $2
+	DeleteStaleAuthThrottles(ctx context.Context, arg DeleteStaleAuthThrottlesParams) (int64, error)
 	DeleteUser(ctx context.Context, id pgtype.UUID) error
+	DrainDefaultPartition(ctx context.Context, parentTable string) (int64, error)
+	DropExpiredPartitions(ctx context.Context, arg DropExpiredPartitionsParams) (int32, error)
+	EnableAdminMFA(ctx context.Context, arg EnableAdminMFAParams) (int64, error)
+	EnsureMonthlyPartition(ctx context.Context, arg EnsureMonthlyPartitionParams) (bool, error)
+	FailDataExport(ctx context.Context, arg FailDataExportParams) error
+	// Unexpired overrides of active permissions, as permission checks apply them
+	GetActiveAdminPermissionOverrides(ctx context.Context, adminID pgtype.UUID) ([]GetActiveAdminPermissionOverridesRow, error)
+	GetActiveUserSession(ctx context.Context, arg GetActiveUserSessionParams) (UserSession, error)
 	GetAddressByID(ctx context.Context, id pgtype.UUID) (Address, error)
 	GetAddressByIDAndUserID(ctx context.Context, arg GetAddressByIDAndUserIDParams) (Address, error)
 	GetAddressesByUserID(ctx context.Context, userID pgtype.UUID) ([]Address, error)
+	GetAdminAPIKeyByHash(ctx context.Context, keyHash string) (AdminApiKey, error)
 	GetAdminByEmail(ctx context.Context, email string) (Admin, error)
 	GetAdminByID(ctx context.Context, id pgtype.UUID) (Admin, error)
 	GetAdminByUsername(ctx context.Context, username string) (Admin, error)
+	GetAdminIdentity(ctx context.Context, arg GetAdminIdentityParams) (AdminIdentity, error)
+	GetAdminMFA(ctx context.Context, adminID pgtype.UUID) (AdminMfa, error)
+	GetAdminPermissionOverride(ctx context.Context, arg GetAdminPermissionOverrideParams) (AdminPermissionOverride, error)
 	GetAllMenuItems(ctx context.Context) ([]MenuItem, error)
 	GetAllPermissions(ctx context.Context) ([]Permission, error)
 	GetAuditLogByID(ctx context.Context, id pgtype.UUID) (AuditLog, error)
+	GetAuthThrottle(ctx context.Context, arg GetAuthThrottleParams) (AuthThrottle, error)
 	GetChildMenuItems(ctx context.Context, parentID pgtype.UUID) ([]MenuItem, error)
+	// Archives of anonymized users are not returned
+	GetDataExportArchive(ctx context.Context, exportID pgtype.UUID) ([]byte, error)
+	// Exports of anonymized users are not returned
+	GetDataExportForUser(ctx context.Context, arg GetDataExportForUserParams) (DataExport, error)
+	// Only the permissions granted to the role itself, not the inherited ones
+	GetDirectRolePermissionCodes(ctx context.Context, role string) ([]string, error)
 	GetErrorLogByID(ctx context.Context, id pgtype.UUID) (ErrorLog, error)
 	GetMenuItemByCode(ctx context.Context, code string) (MenuItem, error)
 	GetMenuItemByID(ctx context.Context, id pgtype.UUID) (MenuItem, error)
-	GetMenuItemsByRole(ctx context.Context, role string) ([]GetMenuItemsByRoleRow, error)
+	// Items the admin may see through their role and their own permission overrides
+	GetMenuItemsByRole(ctx context.Context, arg GetMenuItemsByRoleParams) ([]GetMenuItemsByRoleRow, error)
 	GetOrderByID(ctx context.Context, id pgtype.UUID) (Order, error)
 	GetOrderByOrderNumber(ctx context.Context, orderNumber string) (Order, error)
 	GetPermissionByCode(ctx context.Context, code string) (Permission, error)
+	// Includes the permissions the role inherits
 	GetPermissionsByRole(ctx context.Context, role string) ([]Permission, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetRoleByName(ctx context.Context, name string) (Role, error)
+	// The role and every role it inherits from, transitively
+	GetRoleLineage(ctx context.Context, role string) ([]string, error)
+	GetRolePermission(ctx context.Context, arg GetRolePermissionParams) (RolePermission, error)
+	// Includes the permissions the role inherits
 	GetRolePermissionCodes(ctx context.Context, role string) ([]string, error)
+	GetUnfinishedDataExportForUser(ctx context.Context, userID pgtype.UUID) (DataExport, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
+	// Matches email case-insensitively; $1 must already be lowercased. Should two
+	// accounts differ only in case, the oldest wins.
+	GetUserByNormalizedEmail(ctx context.Context, email string) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
 	GetUserWithDefaultAddress(ctx context.Context, id pgtype.UUID) (GetUserWithDefaultAddressRow, error)
 	HardDeleteAdmin(ctx context.Context, id pgtype.UUID) error
+	// Reports whether a token family (a session) can still be refreshed
+	HasActiveRefreshToken(ctx context.Context, familyID pgtype.UUID) (bool, error)
+	IncrementAdminTokenVersion(ctx context.Context, id pgtype.UUID) (int32, error)
+	IncrementUserTokenVersion(ctx context.Context, id pgtype.UUID) (int32, error)
+	InvalidateOneTimeTokens(ctx context.Context, arg InvalidateOneTimeTokensParams) (int64, error)
+	IsMFARequiredForRole(ctx context.Context, role string) (bool, error)
+	IsTokenRevoked(ctx context.Context, jti pgtype.UUID) (bool, error)
+	ListActiveUserSessions(ctx context.Context, userID pgtype.UUID) ([]UserSession, error)
+	ListAdminAPIKeys(ctx context.Context, adminID pgtype.UUID) ([]AdminApiKey, error)
+	// Includes expired overrides
+	ListAdminPermissionOverrides(ctx context.Context, adminID pgtype.UUID) ([]ListAdminPermissionOverridesRow, error)
 	ListAdmins(ctx context.Context, arg ListAdminsParams) ([]Admin, error)
 	ListAllAddresses(ctx context.Context, arg ListAllAddressesParams) ([]Address, error)
+	ListAuditLogsByActor(ctx context.Context, arg ListAuditLogsByActorParams) ([]AuditLog, error)
 	ListAuditLogsByDateRange(ctx context.Context, arg ListAuditLogsByDateRangeParams) ([]AuditLog, error)
 	ListAuditLogsByEntity(ctx context.Context, arg ListAuditLogsByEntityParams) ([]AuditLog, error)
 	ListAuditLogsByEntityAndDateRange(ctx context.Context, arg ListAuditLogsByEntityAndDateRangeParams) ([]AuditLog, error)
 	ListAuditLogsByEntityType(ctx context.Context, arg ListAuditLogsByEntityTypeParams) ([]AuditLog, error)
 	ListAuditLogsByRequestID(ctx context.Context, requestID pgtype.Text) ([]AuditLog, error)
-	ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUserParams) ([]AuditLog, error)
+	// Entries about the user or their addresses, and changes the user made
+	ListAuditLogsForUser(ctx context.Context, arg ListAuditLogsForUserParams) ([]AuditLog, error)
+	ListErrorLogsByActor(ctx context.Context, arg ListErrorLogsByActorParams) ([]ErrorLog, error)
 	ListErrorLogsByDateRange(ctx context.Context, arg ListErrorLogsByDateRangeParams) ([]ErrorLog, error)
 	ListErrorLogsByPath(ctx context.Context, arg ListErrorLogsByPathParams) ([]ErrorLog, error)
 	ListErrorLogsByRequestID(ctx context.Context, requestID pgtype.Text) ([]ErrorLog, error)
 	ListErrorLogsByType(ctx context.Context, arg ListErrorLogsByTypeParams) ([]ErrorLog, error)
-	ListErrorLogsByUser(ctx context.Context, arg ListErrorLogsByUserParams) ([]ErrorLog, error)
 	ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order, error)
 	ListOrdersByUserID(ctx context.Context, arg ListOrdersByUserIDParams) ([]Order, error)
 	ListRecentErrors(ctx context.Context, arg ListRecentErrorsParams) ([]ErrorLog, error)
+	ListRecentPasswordHashes(ctx context.Context, arg ListRecentPasswordHashesParams) ([]string, error)
+	// The recent activity of one account, newest first
+	ListRecentSecurityEventsByActor(ctx context.Context, arg ListRecentSecurityEventsByActorParams) ([]SecurityEvent, error)
+	ListRoleMFAPolicies(ctx context.Context) ([]RoleMfaPolicy, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	// Every session of the user still on record, including ended ones
+	ListUserSessionsByUserID(ctx context.Context, userID pgtype.UUID) ([]UserSession, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	// Accounts whose deletion grace period has ended and that are not anonymized yet
+	ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]pgtype.UUID, error)
+	LockAuthThrottle(ctx context.Context, arg LockAuthThrottleParams) (AuthThrottle, error)
+	MarkUserEmailVerified(ctx context.Context, id pgtype.UUID) (User, error)
+	// Keeps the most recent entries of the account and deletes the rest
+	PrunePasswordHistory(ctx context.Context, arg PrunePasswordHistoryParams) (int64, error)
+	RecordAdminMFAFailure(ctx context.Context, adminID pgtype.UUID) (int32, error)
+	// Counting starts over when the previous failure is older than window_start
+	RecordAuthFailure(ctx context.Context, arg RecordAuthFailureParams) (AuthThrottle, error)
+	// Upgrades the hash of an unchanged password, so sessions stay valid. Matches no
+	// row when the password was changed since old_hash was read.
+	RehashAdminPassword(ctx context.Context, arg RehashAdminPasswordParams) (int64, error)
+	// Upgrades the hash of an unchanged password, so sessions stay valid. Matches no
+	// row when the password was changed since old_hash was read.
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	ResetAdminMFAFailures(ctx context.Context, adminID pgtype.UUID) error
+	// Returns no rows if the key does not belong to the admin or is already revoked
+	RevokeAdminAPIKey(ctx context.Context, arg RevokeAdminAPIKeyParams) (AdminApiKey, error)
 	RevokePermissionFromRole(ctx context.Context, arg RevokePermissionFromRoleParams) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID pgtype.UUID) (int64, error)
+	RevokeRefreshTokensBySubject(ctx context.Context, arg RevokeRefreshTokensBySubjectParams) (int64, error)
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	// Only succeeds for a token that has not been rotated or revoked yet
+	RotateRefreshToken(ctx context.Context, arg RotateRefreshTokenParams) (int64, error)
+	ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (User, error)
+	SearchAuditLogs(ctx context.Context, arg SearchAuditLogsParams) ([]AuditLog, error)
+	SearchSecurityEvents(ctx context.Context, arg SearchSecurityEventsParams) ([]SecurityEvent, error)
 	SetDefaultAddress(ctx context.Context, arg SetDefaultAddressParams) (User, error)
 	SetDefaultAddressForUser(ctx context.Context, arg SetDefaultAddressForUserParams) (User, error)
+	// Only writes when last_used_at is older than the given time, to spare a write per request
+	TouchAdminAPIKey(ctx context.Context, arg TouchAdminAPIKeyParams) error
+	TouchAdminIdentity(ctx context.Context, arg TouchAdminIdentityParams) error
+	// Only writes when last_seen_at is older than seen_before, to limit writes per request
+	TouchUserSession(ctx context.Context, arg TouchUserSessionParams) error
 	UpdateAddress(ctx context.Context, arg UpdateAddressParams) (Address, error)
 	UpdateAddressForUser(ctx context.Context, arg UpdateAddressForUserParams) (Address, error)
+	// Changing the password, role or active status bumps token_version, which signs the admin out everywhere
 	UpdateAdmin(ctx context.Context, arg UpdateAdminParams) (Admin, error)
+	// Bumps token_version so existing sessions are signed out
+	UpdateAdminPassword(ctx context.Context, arg UpdateAdminPasswordParams) (Admin, error)
 	UpdateMenuItem(ctx context.Context, arg UpdateMenuItemParams) (MenuItem, error)
 	UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Order, error)
 	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
 	UpdatePermission(ctx context.Context, arg UpdatePermissionParams) (Permission, error)
+	UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, error)
+	// Changing the email clears email_verified_at until the new address is verified
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	// Bumps token_version so existing sessions are signed out
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	// Starts (or restarts) enrollment; MFA stays disabled until EnableAdminMFA
+	UpsertAdminMFASecret(ctx context.Context, arg UpsertAdminMFASecretParams) (AdminMfa, error)
+	// Replaces the admin's existing override for the permission, if any
+	UpsertAdminPermissionOverride(ctx context.Context, arg UpsertAdminPermissionOverrideParams) (AdminPermissionOverride, error)
+	UpsertRoleMFAPolicy(ctx context.Context, arg UpsertRoleMFAPolicyParams) (RoleMfaPolicy, error)
+	UseAdminMFARecoveryCode(ctx context.Context, arg UseAdminMFARecoveryCodeParams) (int64, error)
+	// Records an accepted TOTP step; affects no rows if the step was already used
+	UseAdminMFAStep(ctx context.Context, arg UseAdminMFAStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,6 +11,46 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeUser = `-- name: AnonymizeUser :one
+SELECT anonymize_user($1::uuid)
+`
+
+// Returns false if the user does not exist or was already anonymized
+func (q *Queries) AnonymizeUser(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, anonymizeUser, id)
+	var anonymize_user bool
+	err := row.Scan(&anonymize_user)
+	return anonymize_user, err
+}
+
+const cancelUserDeletion = `-- name: CancelUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND anonymized_at IS NULL
+RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, cancelUserDeletion, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     email,
@@ -20,7 +60,7 @@ INSERT INTO users (
     last_name
 ) VALUES (
     $1, $2, $3, $4, $5
-) RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id
+) RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
 `
 
 type CreateUserParams struct {
@@ -50,6 +90,10 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
@@ -65,7 +109,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id FROM users
+SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -82,12 +126,16 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id FROM users
+SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -104,12 +152,46 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
+const getUserByNormalizedEmail = `-- name: GetUserByNormalizedEmail :one
+SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at FROM users
+WHERE lower(email) = $1
+ORDER BY created_at
+LIMIT 1
+`
+
+// Matches email case-insensitively; $1 must already be lowercased. Should two
+// accounts differ only in case, the oldest wins.
+func (q *Queries) GetUserByNormalizedEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByNormalizedEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id FROM users
+SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -126,12 +208,30 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
 
+const incrementUserTokenVersion = `-- name: IncrementUserTokenVersion :one
+UPDATE users
+SET token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING token_version
+`
+
+func (q *Queries) IncrementUserTokenVersion(ctx context.Context, id pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, incrementUserTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id FROM users
+SELECT id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at FROM users
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -160,6 +260,10 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DefaultAddressID,
+			&i.TokenVersion,
+			&i.EmailVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.AnonymizedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -171,9 +275,129 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const listUsersDueForDeletion = `-- name: ListUsersDueForDeletion :many
+SELECT id FROM users
+WHERE deletion_scheduled_at <= $1 AND anonymized_at IS NULL
+ORDER BY deletion_scheduled_at
+LIMIT $2
+`
+
+type ListUsersDueForDeletionParams struct {
+	DeletionScheduledAt pgtype.Timestamptz `json:"deletion_scheduled_at"`
+	Limit               int32              `json:"limit"`
+}
+
+// Accounts whose deletion grace period has ended and that are not anonymized yet
+func (q *Queries) ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listUsersDueForDeletion, arg.DeletionScheduledAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
+`
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, markUserEmailVerified, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users
+SET password_hash = $1
+WHERE id = $2 AND password_hash = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHash string      `json:"new_hash"`
+	ID      pgtype.UUID `json:"id"`
+	OldHash string      `json:"old_hash"`
+}
+
+// Upgrades the hash of an unchanged password, so sessions stay valid. Matches no
+// row when the password was changed since old_hash was read.
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rehashUserPassword, arg.NewHash, arg.ID, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND anonymized_at IS NULL
+RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
+`
+
+type ScheduleUserDeletionParams struct {
+	ID                  pgtype.UUID        `json:"id"`
+	DeletionScheduledAt pgtype.Timestamptz `json:"deletion_scheduled_at"`
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (User, error) {
+	row := q.db.QueryRow(ctx, scheduleUserDeletion, arg.ID, arg.DeletionScheduledAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
+    email_verified_at = CASE
+        WHEN COALESCE($1, email) IS DISTINCT FROM email THEN NULL
+        ELSE email_verified_at
+    END,
     email = COALESCE($1, email),
     username = COALESCE($2, username),
     first_name = COALESCE($3, first_name),
@@ -181,7 +405,7 @@ SET
     default_address_id = COALESCE($5, default_address_id),
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $6
-RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id
+RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
 `
 
 type UpdateUserParams struct {
@@ -193,6 +417,7 @@ type UpdateUserParams struct {
 	ID               pgtype.UUID `json:"id"`
 }
 
+// Changing the email clears email_verified_at until the new address is verified
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
 	row := q.db.QueryRow(ctx, updateUser,
 		arg.Email,
@@ -213,6 +438,44 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET password_hash = $2, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, email, username, password_hash, first_name, last_name, created_at, updated_at, default_address_id, token_version, email_verified_at, deletion_scheduled_at, anonymized_at
+`
+
+type UpdateUserPasswordParams struct {
+	ID           pgtype.UUID `json:"id"`
+	PasswordHash string      `json:"password_hash"`
+}
+
+// Bumps token_version so existing sessions are signed out
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.ID, arg.PasswordHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultAddressID,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
@@ -27,6 +27,8 @@ const (
 	CodeValidation    = "VALIDATION_ERROR"
 	CodeInternal      = "INTERNAL_ERROR"
 	CodeUnauthorized  = "UNAUTHORIZED"
+	CodeForbidden     = "FORBIDDEN"
+	CodeRateLimited   = "RATE_LIMITED"
 )
 
 // Common error constructors
@@ -65,3 +67,17 @@ func Unauthorized(message string) *DomainError {
 		Message: message,
 	}
 }
+
+func Forbidden(message string) *DomainError {
+	return &DomainError{
+		Code:    CodeForbidden,
+		Message: message,
+	}
+}
+
+func RateLimited(message string) *DomainError {
+	return &DomainError{
+		Code:    CodeRateLimited,
+		Message: message,
+	}
+}
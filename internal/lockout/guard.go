@@ -0,0 +1,272 @@
+// Package lockout protects the login endpoints against password guessing.
+// Failed attempts are counted per account and per client IP address in
+// Postgres, so every replica enforces the same limits. Accounts are slowed
+// down with a growing delay between attempts, and both accounts and IP
+// addresses are locked for a while once they reach their limit.
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// KeyType is what a throttle counts failures for
+type KeyType string
+
+const (
+	KeyAccount KeyType = "account" // lowercased email (users) or username (admins)
+	KeyIP      KeyType = "ip"
+)
+
+// Policy holds the thresholds. A zero limit disables that part of the protection.
+type Policy struct {
+	MaxAccountAttempts int           // failures before an account is locked
+	MaxIPAttempts      int           // failures before an IP address is locked
+	DelayAfter         int           // failures on an account before attempts are delayed
+	IPDelayAfter       int           // failures from an IP address before attempts are delayed
+	BaseDelay          time.Duration // first delay, doubled with every further failure
+	MaxDelay           time.Duration
+	Window             time.Duration // failures older than this are forgotten
+	LockoutDuration    time.Duration
+}
+
+// Delay returns how long an account with failed attempts has to wait before the next attempt
+func (p Policy) Delay(failed int32) time.Duration {
+	return p.delay(failed, p.DelayAfter)
+}
+
+// IPDelay returns how long an IP address with failed attempts has to wait before the next attempt
+func (p Policy) IPDelay(failed int32) time.Duration {
+	return p.delay(failed, p.IPDelayAfter)
+}
+
+func (p Policy) delay(failed int32, after int) time.Duration {
+	if after <= 0 || p.BaseDelay <= 0 || int(failed) < after {
+		return 0
+	}
+
+	// Cap the exponent so the shift cannot overflow; MaxDelay caps the result anyway
+	exp := min(int(failed)-after, 30)
+	delay := p.BaseDelay * time.Duration(1<<exp)
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Guard tracks failed logins for both API surfaces
+type Guard struct {
+	queries      *db.Queries
+	auditService *audit.Service
+	policy       Policy
+	now          func() time.Time
+}
+
+// NewGuard creates a login guard
+func NewGuard(queries *db.Queries, auditService *audit.Service, policy Policy) *Guard {
+	return &Guard{
+		queries:      queries,
+		auditService: auditService,
+		policy:       policy,
+		now:          time.Now,
+	}
+}
+
+// Check rejects a login attempt with a rate limit error while the account or the
+// client IP address is locked, or while the delay of either has not passed yet.
+// Call it before looking at the password, so throttled attempts cost no hashing.
+func (g *Guard) Check(ctx context.Context, subjectType refreshtoken.SubjectType, account string) error {
+	now := g.now()
+
+	if ip := clientIP(ctx); ip != "" {
+		throttle, err := g.get(ctx, subjectType, KeyIP, ip)
+		if err != nil {
+			return err
+		}
+		if throttle != nil && throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(now) {
+			return errors.RateLimited(fmt.Sprintf("too many failed login attempts from this address, try again in %s", waitFor(throttle.LockedUntil.Time, now)))
+		}
+		if next, delayed := g.nextAttempt(throttle, g.policy.IPDelay, now); delayed {
+			return errors.RateLimited(fmt.Sprintf("too many failed login attempts from this address, try again in %s", waitFor(next, now)))
+		}
+	}
+
+	throttle, err := g.get(ctx, subjectType, KeyAccount, normalize(account))
+	if err != nil || throttle == nil {
+		return err
+	}
+
+	if throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(now) {
+		return errors.RateLimited(fmt.Sprintf("account temporarily locked after too many failed login attempts, try again in %s", waitFor(throttle.LockedUntil.Time, now)))
+	}
+
+	if next, delayed := g.nextAttempt(throttle, g.policy.Delay, now); delayed {
+		return errors.RateLimited(fmt.Sprintf("too many failed login attempts, try again in %s", waitFor(next, now)))
+	}
+
+	return nil
+}
+
+// nextAttempt returns when the next attempt is allowed, and whether that is
+// still in the future. Delays only apply to failures that still count.
+func (g *Guard) nextAttempt(throttle *db.AuthThrottle, delay func(int32) time.Duration, now time.Time) (time.Time, bool) {
+	if throttle == nil || !throttle.LastFailedAt.Time.After(now.Add(-g.policy.Window)) {
+		return time.Time{}, false
+	}
+
+	next := throttle.LastFailedAt.Time.Add(delay(throttle.FailedAttempts))
+	return next, next.After(now)
+}
+
+// Fail records a failed login for the account and the client IP address and locks
+// whichever reached its limit
+func (g *Guard) Fail(ctx context.Context, subjectType refreshtoken.SubjectType, account string) error {
+	if err := g.fail(ctx, subjectType, KeyAccount, normalize(account), g.policy.MaxAccountAttempts); err != nil {
+		return err
+	}
+
+	if ip := clientIP(ctx); ip != "" {
+		return g.fail(ctx, subjectType, KeyIP, ip, g.policy.MaxIPAttempts)
+	}
+	return nil
+}
+
+// Succeed forgets the failed attempts of the account after a successful login.
+// The IP address counter is kept, so one valid account cannot reset it.
+func (g *Guard) Succeed(ctx context.Context, subjectType refreshtoken.SubjectType, account string) error {
+	_, err := g.queries.DeleteAuthThrottle(ctx, db.DeleteAuthThrottleParams{
+		SubjectType: string(subjectType),
+		KeyType:     string(KeyAccount),
+		ThrottleKey: normalize(account),
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		return errors.Internal("failed to reset failed login attempts", err)
+	}
+	return nil
+}
+
+// Unlock lifts a lockout and clears the failed attempts of an account.
+// Unlocking an account that is not locked is not an error.
+func (g *Guard) Unlock(ctx context.Context, subjectType refreshtoken.SubjectType, account string) error {
+	throttle, err := g.queries.DeleteAuthThrottle(ctx, db.DeleteAuthThrottleParams{
+		SubjectType: string(subjectType),
+		KeyType:     string(KeyAccount),
+		ThrottleKey: normalize(account),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return errors.Internal("failed to unlock account", err)
+	}
+
+	// Audit log the unlock (the actor is the admin in ctx)
+	g.auditService.LogDelete(ctx, "auth_throttles", uuid.UUID(throttle.ID.Bytes), throttle)
+	g.auditService.LogSecurityEvent(ctx, audit.SecurityEvent{
+		Type:       audit.EventUnlock,
+		Outcome:    audit.OutcomeSuccess,
+		ActorType:  audit.ActorType(subjectType),
+		Identifier: throttle.ThrottleKey,
+	})
+
+	return nil
+}
+
+// DeleteExpired removes counters that no longer delay or lock anything
+func (g *Guard) DeleteExpired(ctx context.Context) (int64, error) {
+	now := g.now()
+	return g.queries.DeleteStaleAuthThrottles(ctx, db.DeleteStaleAuthThrottlesParams{
+		LastFailedAt: pgtype.Timestamptz{Time: now.Add(-g.policy.Window), Valid: true},
+		LockedUntil:  pgtype.Timestamptz{Time: now, Valid: true},
+	})
+}
+
+func (g *Guard) get(ctx context.Context, subjectType refreshtoken.SubjectType, keyType KeyType, key string) (*db.AuthThrottle, error) {
+	throttle, err := g.queries.GetAuthThrottle(ctx, db.GetAuthThrottleParams{
+		SubjectType: string(subjectType),
+		KeyType:     string(keyType),
+		ThrottleKey: key,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Internal("failed to check failed login attempts", err)
+	}
+	return &throttle, nil
+}
+
+func (g *Guard) fail(ctx context.Context, subjectType refreshtoken.SubjectType, keyType KeyType, key string, limit int) error {
+	now := g.now()
+
+	throttle, err := g.queries.RecordAuthFailure(ctx, db.RecordAuthFailureParams{
+		SubjectType: string(subjectType),
+		KeyType:     string(keyType),
+		ThrottleKey: key,
+		FailedAt:    pgtype.Timestamptz{Time: now, Valid: true},
+		WindowStart: pgtype.Timestamptz{Time: now.Add(-g.policy.Window), Valid: true},
+	})
+	if err != nil {
+		return errors.Internal("failed to record failed login attempt", err)
+	}
+
+	alreadyLocked := throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(now)
+	if limit <= 0 || int(throttle.FailedAttempts) < limit || alreadyLocked {
+		return nil
+	}
+
+	locked, err := g.queries.LockAuthThrottle(ctx, db.LockAuthThrottleParams{
+		ID:          throttle.ID,
+		LockedUntil: pgtype.Timestamptz{Time: now.Add(g.policy.LockoutDuration), Valid: true},
+	})
+	if err != nil {
+		return errors.Internal("failed to lock account", err)
+	}
+
+	slog.Warn("login locked after too many failed attempts",
+		"subject_type", subjectType, "key_type", keyType, "key", key,
+		"failed_attempts", locked.FailedAttempts, "locked_until", locked.LockedUntil.Time)
+
+	// Audit log the lockout (nobody is authenticated, so the actor is the system)
+	g.auditService.LogUpdate(ctx, "auth_throttles", uuid.UUID(locked.ID.Bytes), throttle, locked)
+
+	event := audit.SecurityEvent{
+		Type:      audit.EventLockout,
+		Outcome:   audit.OutcomeFailure,
+		ActorType: audit.ActorType(subjectType),
+		Reason:    string(keyType) + "_locked",
+	}
+	if keyType == KeyAccount {
+		event.Identifier = key
+	}
+	g.auditService.LogSecurityEvent(ctx, event)
+
+	return nil
+}
+
+// clientIP returns the address set by the AuditContext middleware, which only
+// believes forwarding headers from trusted proxies
+func clientIP(ctx context.Context) string {
+	return audit.ExtractAuditContext(ctx).IPAddress
+}
+
+// normalize makes Alice@Example.com and alice@example.com share one counter
+func normalize(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+// waitFor rounds up to whole seconds, so clients never retry too early
+func waitFor(until, now time.Time) time.Duration {
+	return until.Sub(now).Truncate(time.Second) + time.Second
+}
@@ -0,0 +1,77 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPolicy_Delay tests that delays start after DelayAfter failures, double and are capped
+func TestPolicy_Delay(t *testing.T) {
+	policy := Policy{
+		DelayAfter: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   10 * time.Second,
+	}
+
+	tests := []struct {
+		failed int32
+		want   time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{6, 8 * time.Second},
+		{7, 10 * time.Second},
+		{1000, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.failed); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.failed, got, tt.want)
+		}
+	}
+}
+
+// TestPolicy_IPDelay tests that IP addresses are delayed after their own threshold
+func TestPolicy_IPDelay(t *testing.T) {
+	policy := Policy{
+		DelayAfter:   3,
+		IPDelayAfter: 10,
+		BaseDelay:    time.Second,
+		MaxDelay:     10 * time.Second,
+	}
+
+	if got := policy.IPDelay(9); got != 0 {
+		t.Errorf("IPDelay(9) = %s, want 0", got)
+	}
+	if got := policy.IPDelay(10); got != time.Second {
+		t.Errorf("IPDelay(10) = %s, want 1s", got)
+	}
+	if got := policy.IPDelay(12); got != 4*time.Second {
+		t.Errorf("IPDelay(12) = %s, want 4s", got)
+	}
+}
+
+// TestPolicy_Delay_Disabled tests that a zero policy never delays
+func TestPolicy_Delay_Disabled(t *testing.T) {
+	if got := (Policy{}).Delay(100); got != 0 {
+		t.Errorf("expected no delay, got %s", got)
+	}
+	if got := (Policy{}).IPDelay(100); got != 0 {
+		t.Errorf("expected no IP delay, got %s", got)
+	}
+}
+
+// TestWaitFor tests that waits are rounded up to whole seconds
+func TestWaitFor(t *testing.T) {
+	now := time.Now()
+
+	if got := waitFor(now.Add(1500*time.Millisecond), now); got != 2*time.Second {
+		t.Errorf("expected 2s, got %s", got)
+	}
+	if got := waitFor(now.Add(100*time.Millisecond), now); got != time.Second {
+		t.Errorf("expected 1s, got %s", got)
+	}
+}
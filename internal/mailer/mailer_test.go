@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFileMailer tests that messages are written as .eml files
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	m, err := NewFileMailer(dir, "COC <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("NewFileMailer failed: %v", err)
+	}
+
+	err = m.Send(context.Background(), Message{
+		To:      "user@example.com",
+		Subject: "Reset your password",
+		Body:    "Open this link:\nhttps://example.com/reset?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected 1 .eml file, got %v (%v)", files, err)
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read email: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"From: COC <no-reply@example.com>\r\n",
+		"To: user@example.com\r\n",
+		"Subject: Reset your password\r\n",
+		"\r\n\r\nOpen this link:\r\nhttps://example.com/reset?token=abc",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected email to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+// TestCompose_HeaderInjection tests that header values with line breaks are rejected
+func TestCompose_HeaderInjection(t *testing.T) {
+	msg := Message{
+		To:      "user@example.com\r\nBcc: attacker@example.com",
+		Subject: "Hello",
+	}
+
+	if err := NewLogMailer("no-reply@example.com").Send(context.Background(), msg); err == nil {
+		t.Error("expected recipient with line break to be rejected")
+	}
+}
+
+// TestNew tests driver selection
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"log", Config{Driver: DriverLog, From: "no-reply@example.com"}, false},
+		{"file", Config{Driver: DriverFile, From: "no-reply@example.com", Dir: t.TempDir()}, false},
+		{"smtp", Config{Driver: DriverSMTP, From: "no-reply@example.com", SMTPHost: "localhost", SMTPPort: 25}, false},
+		{"smtp without host", Config{Driver: DriverSMTP, From: "no-reply@example.com"}, true},
+		{"missing sender", Config{Driver: DriverLog}, true},
+		{"unknown driver", Config{Driver: "carrier-pigeon", From: "no-reply@example.com"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
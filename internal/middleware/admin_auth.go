@@ -9,10 +9,12 @@ import (
 	"github.com/user/coc/internal/app/admin_auth"
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/ctxkeys"
+	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/response"
 )
 
-// AdminAuthMiddleware checks for valid admin JWT tokens
+// AdminAuthMiddleware checks for valid admin JWT tokens or API keys.
+// Both are sent as bearer credentials; API keys start with admin_auth.APIKeyPrefix.
 func AdminAuthMiddleware(authService *admin_auth.AuthService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -32,35 +34,29 @@ func AdminAuthMiddleware(authService *admin_auth.AuthService) func(http.Handler)
 
 			tokenString := parts[1]
 
-			// Validate admin token
-			claims, err := authService.ValidateToken(tokenString)
-			if err != nil {
-				response.Error(w, http.StatusUnauthorized, "invalid or expired admin token")
+			if admin_auth.IsAPIKey(tokenString) {
+				authenticateAPIKey(authService, next, w, r, tokenString)
 				return
 			}
 
-			// Get admin from database to ensure admin still exists and is active
-			adminUser, err := authService.GetAdminByID(r.Context(), claims.AdminID)
+			// Validate admin token, ensure the admin is still active and the session was not revoked
+			adminUser, claims, err := authService.Authenticate(r.Context(), tokenString)
 			if err != nil {
-				response.Error(w, http.StatusUnauthorized, "admin not found")
+				response.HandleServiceError(w, err)
 				return
 			}
 
-			// Check if admin is still active
-			if !adminUser.IsActive {
-				response.Error(w, http.StatusUnauthorized, "admin account is disabled")
-				return
-			}
-
-			// Add admin to context
-			ctx := context.WithValue(r.Context(), ctxkeys.AdminContextKey, &adminUser)
+			// Add admin to context. The role comes from the database rather than
+			// the token so role changes take effect immediately.
+			ctx := context.WithValue(r.Context(), ctxkeys.AdminContextKey, adminUser)
 			ctx = context.WithValue(ctx, ctxkeys.AdminIDContextKey, claims.AdminID)
-			ctx = context.WithValue(ctx, ctxkeys.AdminRoleContextKey, claims.Role)
+			ctx = context.WithValue(ctx, ctxkeys.AdminRoleContextKey, adminUser.Role)
+			ctx = context.WithValue(ctx, ctxkeys.AdminClaimsContextKey, claims)
 
 			// Add admin ID to audit context
 			adminID, err := uuid.Parse(claims.AdminID)
 			if err == nil {
-				ctx = audit.WithUserID(ctx, adminID)
+				ctx = audit.WithAdminID(ctx, adminID)
 			}
 
 			// Call next handler with updated context
@@ -69,6 +65,42 @@ func AdminAuthMiddleware(authService *admin_auth.AuthService) func(http.Handler)
 	}
 }
 
+// authenticateAPIKey serves an API key request on behalf of the key's owner.
+// The key's permission codes are enforced by PermissionMiddleware.
+func authenticateAPIKey(authService *admin_auth.AuthService, next http.Handler, w http.ResponseWriter, r *http.Request, key string) {
+	adminUser, apiKey, err := authService.AuthenticateAPIKey(r.Context(), key)
+	if err != nil {
+		response.HandleServiceError(w, err)
+		return
+	}
+
+	adminID := uuid.UUID(adminUser.ID.Bytes)
+
+	ctx := context.WithValue(r.Context(), ctxkeys.AdminContextKey, adminUser)
+	ctx = context.WithValue(ctx, ctxkeys.AdminIDContextKey, adminID.String())
+	ctx = context.WithValue(ctx, ctxkeys.AdminRoleContextKey, adminUser.Role)
+	ctx = context.WithValue(ctx, ctxkeys.AdminAPIKeyContextKey, apiKey)
+
+	// The owner is the actor; the audit entry also records which key acted
+	ctx = audit.WithAdminID(ctx, adminID)
+	ctx = audit.WithAPIKeyID(ctx, uuid.UUID(apiKey.ID.Bytes))
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireAdminSession rejects requests authenticated with an API key. Used on
+// routes that manage the admin's own credentials and sessions.
+func RequireAdminSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isAPIKeyRequest(r) {
+			response.Error(w, http.StatusForbidden, "this endpoint cannot be used with an API key")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // RequireAdminRole middleware ensures the admin has specific role(s)
 func RequireAdminRole(allowedRoles ...string) func(http.Handler) http.Handler {
 	roleMap := make(map[string]bool)
@@ -78,6 +110,12 @@ func RequireAdminRole(allowedRoles ...string) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// API keys are limited to their permission codes, which role-gated routes do not check
+			if isAPIKeyRequest(r) {
+				response.Error(w, http.StatusForbidden, "this endpoint cannot be used with an API key")
+				return
+			}
+
 			// Get admin role from context
 			role, ok := r.Context().Value(ctxkeys.AdminRoleContextKey).(string)
 			if !ok || role == "" {
@@ -96,6 +134,12 @@ func RequireAdminRole(allowedRoles ...string) func(http.Handler) http.Handler {
 	}
 }
 
+// isAPIKeyRequest reports whether the request was authenticated with an API key
+func isAPIKeyRequest(r *http.Request) bool {
+	apiKey, ok := r.Context().Value(ctxkeys.AdminAPIKeyContextKey).(*db.AdminApiKey)
+	return ok && apiKey != nil
+}
+
 // GetAdminRole retrieves the admin role from the request context
 func GetAdminRole(r *http.Request) (string, bool) {
 	role, ok := r.Context().Value(ctxkeys.AdminRoleContextKey).(string)
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"github.com/google/uuid"
@@ -28,48 +30,97 @@ func RequestID(next http.Handler) http.Handler {
 	})
 }
 
-// AuditContext middleware extracts IP address and user agent from request
-func AuditContext(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+// AuditContext middleware extracts the client IP address and user agent from
+// the request. The address also drives per-IP login throttling, so forwarding
+// headers are only believed when the request comes from one of trustedProxies.
+func AuditContext(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
 
-		// Extract IP address (handle X-Forwarded-For for proxies/load balancers)
-		ipAddress := getIPAddress(r)
-		ctx = audit.WithIPAddress(ctx, ipAddress)
+			// Extract IP address (X-Forwarded-For only from trusted proxies)
+			ipAddress := clientIP(r, trustedProxies)
+			ctx = audit.WithIPAddress(ctx, ipAddress)
 
-		// Extract user agent
-		userAgent := r.Header.Get("User-Agent")
-		ctx = audit.WithUserAgent(ctx, userAgent)
+			// Extract user agent
+			userAgent := r.Header.Get("User-Agent")
+			ctx = audit.WithUserAgent(ctx, userAgent)
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
-// getIPAddress extracts the real IP address from the request
-// Handles X-Forwarded-For, X-Real-IP headers for reverse proxies
-func getIPAddress(r *http.Request) string {
-	// Check X-Forwarded-For header (comma-separated list, first is original client)
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		// Get first IP from comma-separated list
-		ips := strings.Split(forwarded, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
+// ParseTrustedProxies parses a list of IP addresses and CIDR ranges
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
 		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
 	}
+	return prefixes, nil
+}
 
-	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+// clientIP returns the address of the client that sent the request. When the
+// connection comes from a trusted proxy, X-Forwarded-For is read from the right
+// and the first address that is not a trusted proxy is the client; entries
+// further left were written by the client and could be anything. X-Real-IP is
+// used when a trusted proxy sends no X-Forwarded-For.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
 	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	remote = remote.Unmap()
 
-	// Fallback to RemoteAddr (format: "IP:port")
-	ip := r.RemoteAddr
-	// Remove port if present
-	if idx := strings.LastIndex(ip, ":"); idx != -1 {
-		ip = ip[:idx]
+	if !trusted(remote, trustedProxies) {
+		return remote.String()
 	}
 
-	return ip
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			client = hop.Unmap()
+			if !trusted(client, trustedProxies) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.Unmap().String()
+	}
+
+	return remote.String()
+}
+
+func trusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestClientIP tests that forwarding headers are only believed from trusted proxies
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:52000", "", "", "203.0.113.7"},
+		{"spoofed header from untrusted peer", "203.0.113.7:52000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"client behind trusted proxy", "10.1.2.3:443", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed entries left of the client", "10.1.2.3:443", "1.1.1.1, 198.51.100.1", "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:443", "198.51.100.1, 192.0.2.1, 10.9.9.9", "", "198.51.100.1"},
+		{"malformed hop", "10.1.2.3:443", "garbage, 10.9.9.9", "", "10.9.9.9"},
+		{"real ip from trusted proxy", "192.0.2.1:443", "", "198.51.100.3", "198.51.100.3"},
+		{"ipv4-mapped ipv6 peer", "[::ffff:203.0.113.7]:52000", "198.51.100.1", "", "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := clientIP(req, proxies); got != tt.want {
+				t.Errorf("clientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseTrustedProxies_Invalid tests that malformed entries are rejected
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	if _, err := ParseTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected an error for an invalid range")
+	}
+}
@@ -10,10 +10,11 @@ import (
 	"github.com/user/coc/internal/audit"
 	"github.com/user/coc/internal/ctxkeys"
 	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/response"
 )
 
 // Middleware creates a middleware that validates JWT tokens and adds user to context
-func Middleware(authService *frontend_auth.Service, queries *db.Queries) func(http.Handler) http.Handler {
+func Middleware(authService *frontend_auth.Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get token from Authorization header
@@ -32,23 +33,17 @@ func Middleware(authService *frontend_auth.Service, queries *db.Queries) func(ht
 
 			tokenString := parts[1]
 
-			// Validate token
-			claims, err := authService.ValidateToken(tokenString)
+			// Validate token, ensure the user still exists and the session was not revoked
+			user, claims, err := authService.Authenticate(r.Context(), tokenString)
 			if err != nil {
-				respondUnauthorized(w, "invalid or expired token")
-				return
-			}
-
-			// Get user from database to ensure user still exists
-			user, err := queries.GetUserByEmail(r.Context(), claims.Email)
-			if err != nil {
-				respondUnauthorized(w, "user not found")
+				response.HandleServiceError(w, err)
 				return
 			}
 
 			// Add user to context using ctxkeys constants
-			ctx := context.WithValue(r.Context(), ctxkeys.UserContextKey, &user)
+			ctx := context.WithValue(r.Context(), ctxkeys.UserContextKey, user)
 			ctx = context.WithValue(ctx, ctxkeys.UserIDContextKey, claims.UserID)
+			ctx = context.WithValue(ctx, ctxkeys.UserClaimsContextKey, claims)
 
 			// Add user ID to audit context
 			userID, err := uuid.Parse(claims.UserID)
@@ -56,12 +51,59 @@ func Middleware(authService *frontend_auth.Service, queries *db.Queries) func(ht
 				ctx = audit.WithUserID(ctx, userID)
 			}
 
+			// An admin acting as the user is recorded next to the user
+			if claims.Impersonated() {
+				ctx = context.WithValue(ctx, ctxkeys.ImpersonatorIDContextKey, claims.Actor.Subject)
+				if adminID, err := uuid.Parse(claims.Actor.Subject); err == nil {
+					ctx = audit.WithImpersonatorID(ctx, adminID)
+				}
+			}
+
 			// Call next handler with updated context
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// RequireVerifiedEmail rejects users whose email address is not verified with 403.
+// It must run after Middleware. With the optional policy every user passes.
+func RequireVerifiedEmail(policy frontend_auth.VerificationPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy == frontend_auth.VerificationOptional {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(ctxkeys.UserContextKey).(*db.User)
+			if !ok || user == nil {
+				respondUnauthorized(w, "user not found in context")
+				return
+			}
+
+			if !user.EmailVerifiedAt.Valid {
+				response.Error(w, http.StatusForbidden, "email address not verified")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireNoImpersonation rejects admins impersonating the user with 403. It guards
+// account security actions, such as changing the password, that only the user
+// may take. It must run after Middleware.
+func RequireNoImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := ctxkeys.GetImpersonatorID(r); ok {
+			response.Error(w, http.StatusForbidden, "this action is not allowed while impersonating a user")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func respondUnauthorized(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/user/coc/internal/app/admin_menu"
+	"github.com/user/coc/internal/app/role"
+)
+
+// PermissionsChangedChannel is the Postgres NOTIFY channel the roles,
+// role_permissions, permissions and admin_permission_overrides tables signal
+// on when they change
+const PermissionsChangedChannel = "permissions_changed"
+
+// listenRetryInterval is how long the listener waits before reconnecting
+const listenRetryInterval = 5 * time.Second
+
+type permissionCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// PermissionCache keeps the permission set of each role, and the permission
+// overrides of each admin, in memory for a TTL. The whole cache is flushed on
+// any change, since with role inheritance a single grant can change the
+// permissions of many roles.
+type PermissionCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.RWMutex
+	roles     map[string]permissionCacheEntry[map[string]bool]
+	overrides map[string]permissionCacheEntry[[]admin_menu.PermissionOverride]
+	// generation changes on every flush, so a load that raced a flush is not stored
+	generation uint64
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// NewPermissionCache creates a permission cache whose entries live for ttl
+func NewPermissionCache(ttl time.Duration) *PermissionCache {
+	return &PermissionCache{
+		ttl:       ttl,
+		now:       time.Now,
+		roles:     make(map[string]permissionCacheEntry[map[string]bool]),
+		overrides: make(map[string]permissionCacheEntry[[]admin_menu.PermissionOverride]),
+	}
+}
+
+// RolePermissions returns the cached permissions of the role, calling load on
+// a miss. Callers must not modify the returned map.
+func (c *PermissionCache) RolePermissions(ctx context.Context, role string, load func(context.Context, string) (map[string]bool, error)) (map[string]bool, error) {
+	return cached(ctx, c, c.roles, role, load)
+}
+
+// AdminOverrides returns the cached permission overrides of the admin, calling
+// load on a miss. Overrides are cached with their expiry and must be checked
+// against the current time by the caller.
+func (c *PermissionCache) AdminOverrides(ctx context.Context, adminID string, load func(context.Context, string) ([]admin_menu.PermissionOverride, error)) ([]admin_menu.PermissionOverride, error) {
+	return cached(ctx, c, c.overrides, adminID, load)
+}
+
+// cached looks key up in entries, one of the cache's maps, and loads it on a miss
+func cached[V any](ctx context.Context, c *PermissionCache, entries map[string]permissionCacheEntry[V], key string, load func(context.Context, string) (V, error)) (V, error) {
+	c.mu.RLock()
+	entry, ok := entries[key]
+	generation := c.generation
+	c.mu.RUnlock()
+
+	if ok && c.now().Before(entry.expiresAt) {
+		c.hits.Add(1)
+		return entry.value, nil
+	}
+	c.misses.Add(1)
+
+	value, err := load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		entries[key] = permissionCacheEntry[V]{
+			value:     value,
+			expiresAt: c.now().Add(c.ttl),
+		}
+	}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// Invalidate drops every cached permission set and override
+func (c *PermissionCache) Invalidate() {
+	c.mu.Lock()
+	clear(c.roles)
+	clear(c.overrides)
+	c.generation++
+	c.mu.Unlock()
+
+	c.invalidations.Add(1)
+}
+
+// Stats returns the hit, miss and invalidation counters since startup
+func (c *PermissionCache) Stats() role.PermissionCacheStats {
+	c.mu.RLock()
+	roles := len(c.roles)
+	admins := len(c.overrides)
+	c.mu.RUnlock()
+
+	return role.PermissionCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Roles:         roles,
+		Admins:        admins,
+		TTLSeconds:    int64(c.ttl / time.Second),
+	}
+}
+
+// Listen flushes the cache whenever another connection notifies
+// PermissionsChangedChannel, until ctx is cancelled. It holds one connection
+// out of the pool and reconnects after failures, flushing the cache each time
+// it (re)subscribes since notifications sent while disconnected are lost.
+func (c *PermissionCache) Listen(ctx context.Context, pool *pgxpool.Pool) {
+	go func() {
+		for {
+			if err := c.listen(ctx, pool); err != nil && ctx.Err() == nil {
+				slog.Error("permission change listener failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(listenRetryInterval):
+			}
+		}
+	}()
+}
+
+func (c *PermissionCache) listen(ctx context.Context, pool *pgxpool.Pool) error {
+	poolConn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The connection stays subscribed, so take it out of the pool for good
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+PermissionsChangedChannel); err != nil {
+		return err
+	}
+	c.Invalidate()
+	slog.Info("listening for permission changes", "channel", PermissionsChangedChannel)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		slog.Debug("permissions changed, flushing cache", "table", notification.Payload)
+		c.Invalidate()
+	}
+}
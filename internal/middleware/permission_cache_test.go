@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/user/coc/internal/app/admin_menu"
+)
+
+// countingLoader returns a loader that counts its calls
+func countingLoader(calls *int, permissions map[string]bool) func(context.Context, string) (map[string]bool, error) {
+	return func(context.Context, string) (map[string]bool, error) {
+		*calls++
+		return permissions, nil
+	}
+}
+
+// TestPermissionCache_HitAndMiss tests that a role is loaded once within the TTL
+func TestPermissionCache_HitAndMiss(t *testing.T) {
+	cache := NewPermissionCache(time.Minute)
+	calls := 0
+	load := countingLoader(&calls, map[string]bool{"users.read": true})
+
+	for i := 0; i < 3; i++ {
+		permissions, err := cache.RolePermissions(context.Background(), "moderator", load)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !permissions["users.read"] {
+			t.Errorf("expected users.read to be granted")
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 load, got %d", calls)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Roles != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// TestPermissionCache_Expires tests that entries are reloaded after the TTL
+func TestPermissionCache_Expires(t *testing.T) {
+	now := time.Now()
+	cache := NewPermissionCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	calls := 0
+	load := countingLoader(&calls, map[string]bool{})
+
+	cache.RolePermissions(context.Background(), "admin", load)
+	now = now.Add(time.Minute)
+	cache.RolePermissions(context.Background(), "admin", load)
+
+	if calls != 2 {
+		t.Errorf("expected the expired entry to be reloaded, got %d loads", calls)
+	}
+}
+
+// TestPermissionCache_Invalidate tests that a flush forces every role to be reloaded
+func TestPermissionCache_Invalidate(t *testing.T) {
+	cache := NewPermissionCache(time.Minute)
+	calls := 0
+	load := countingLoader(&calls, map[string]bool{})
+
+	cache.RolePermissions(context.Background(), "admin", load)
+	cache.RolePermissions(context.Background(), "moderator", load)
+	cache.Invalidate()
+	cache.RolePermissions(context.Background(), "admin", load)
+
+	if calls != 3 {
+		t.Errorf("expected 3 loads, got %d", calls)
+	}
+	stats := cache.Stats()
+	if stats.Invalidations != 1 || stats.Roles != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// TestPermissionCache_InvalidateDuringLoad tests that a load racing a flush is not cached
+func TestPermissionCache_InvalidateDuringLoad(t *testing.T) {
+	cache := NewPermissionCache(time.Minute)
+
+	stale := func(context.Context, string) (map[string]bool, error) {
+		cache.Invalidate()
+		return map[string]bool{"users.delete": true}, nil
+	}
+	if _, err := cache.RolePermissions(context.Background(), "admin", stale); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := 0
+	permissions, _ := cache.RolePermissions(context.Background(), "admin", countingLoader(&calls, map[string]bool{}))
+	if calls != 1 || permissions["users.delete"] {
+		t.Errorf("expected the stale load to be discarded")
+	}
+}
+
+// TestPermissionCache_LoadError tests that failed loads are not cached
+func TestPermissionCache_LoadError(t *testing.T) {
+	cache := NewPermissionCache(time.Minute)
+
+	_, err := cache.RolePermissions(context.Background(), "admin", func(context.Context, string) (map[string]bool, error) {
+		return nil, errors.New("connection refused")
+	})
+	if err == nil {
+		t.Fatal("expected the load error to be returned")
+	}
+	if stats := cache.Stats(); stats.Roles != 0 {
+		t.Errorf("expected nothing to be cached, got %d roles", stats.Roles)
+	}
+}
+
+// TestPermissionCache_AdminOverrides tests that overrides are cached per admin and flushed with roles
+func TestPermissionCache_AdminOverrides(t *testing.T) {
+	cache := NewPermissionCache(time.Minute)
+	calls := 0
+	load := func(context.Context, string) ([]admin_menu.PermissionOverride, error) {
+		calls++
+		return []admin_menu.PermissionOverride{{Code: "users.update", Allow: true}}, nil
+	}
+
+	cache.AdminOverrides(context.Background(), "admin-1", load)
+	cache.AdminOverrides(context.Background(), "admin-1", load)
+	cache.AdminOverrides(context.Background(), "admin-2", load)
+
+	if calls != 2 {
+		t.Errorf("expected 2 loads, got %d", calls)
+	}
+	if stats := cache.Stats(); stats.Admins != 2 {
+		t.Errorf("expected 2 cached admins, got %d", stats.Admins)
+	}
+
+	cache.Invalidate()
+	if stats := cache.Stats(); stats.Admins != 0 {
+		t.Errorf("expected the flush to drop cached overrides, got %d admins", stats.Admins)
+	}
+}
@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/user/coc/internal/app/admin_menu"
+	"github.com/user/coc/internal/app/role"
 	"github.com/user/coc/internal/ctxkeys"
 	"github.com/user/coc/internal/db"
 	"github.com/user/coc/internal/response"
@@ -13,12 +17,15 @@ import (
 // PermissionMiddleware wraps permission checking with database access
 type PermissionMiddleware struct {
 	queries *db.Queries
+	cache   *PermissionCache
 }
 
-// NewPermissionMiddleware creates a new permission middleware
-func NewPermissionMiddleware(queries *db.Queries) *PermissionMiddleware {
+// NewPermissionMiddleware creates a new permission middleware. A nil cache
+// loads the role's permissions from the database on every request.
+func NewPermissionMiddleware(queries *db.Queries, cache *PermissionCache) *PermissionMiddleware {
 	return &PermissionMiddleware{
 		queries: queries,
+		cache:   cache,
 	}
 }
 
@@ -33,8 +40,8 @@ func (pm *PermissionMiddleware) RequirePermission(requiredPermission string) fun
 				return
 			}
 
-			// Get permissions for this role from database
-			permissions, err := admin_menu.GetRolePermissions(r.Context(), pm.queries, role)
+			// Get permissions for this role from database, narrowed to the API key's if one is used
+			permissions, err := pm.permissionsFor(r, role)
 			if err != nil {
 				slog.Error("failed to get role permissions", "error", err, "role", role)
 				response.Error(w, http.StatusInternalServerError, "failed to check permissions")
@@ -64,7 +71,7 @@ func (pm *PermissionMiddleware) RequireAnyPermission(requiredPermissions ...stri
 				return
 			}
 
-			permissions, err := admin_menu.GetRolePermissions(r.Context(), pm.queries, role)
+			permissions, err := pm.permissionsFor(r, role)
 			if err != nil {
 				slog.Error("failed to get role permissions", "error", err, "role", role)
 				response.Error(w, http.StatusInternalServerError, "failed to check permissions")
@@ -100,7 +107,7 @@ func (pm *PermissionMiddleware) RequireAllPermissions(requiredPermissions ...str
 				return
 			}
 
-			permissions, err := admin_menu.GetRolePermissions(r.Context(), pm.queries, role)
+			permissions, err := pm.permissionsFor(r, role)
 			if err != nil {
 				slog.Error("failed to get role permissions", "error", err, "role", role)
 				response.Error(w, http.StatusInternalServerError, "failed to check permissions")
@@ -119,3 +126,70 @@ func (pm *PermissionMiddleware) RequireAllPermissions(requiredPermissions ...str
 		})
 	}
 }
+
+// permissionsFor returns the permissions of the admin: those of the role with
+// the admin's permission overrides applied. For API key requests only the codes
+// the key carries count, and only while the admin still holds them.
+func (pm *PermissionMiddleware) permissionsFor(r *http.Request, role string) (map[string]bool, error) {
+	permissions, err := pm.rolePermissions(r.Context(), role)
+	if err != nil {
+		return nil, err
+	}
+
+	if adminID, ok := ctxkeys.GetAdminID(r); ok && adminID != "" {
+		overrides, err := pm.adminOverrides(r.Context(), adminID)
+		if err != nil {
+			return nil, err
+		}
+		permissions = admin_menu.ApplyPermissionOverrides(permissions, overrides, time.Now())
+	}
+
+	apiKey, ok := r.Context().Value(ctxkeys.AdminAPIKeyContextKey).(*db.AdminApiKey)
+	if !ok || apiKey == nil {
+		return permissions, nil
+	}
+
+	scoped := make(map[string]bool, len(apiKey.Permissions))
+	for _, code := range apiKey.Permissions {
+		if permissions[code] {
+			scoped[code] = true
+		}
+	}
+	return scoped, nil
+}
+
+// rolePermissions returns the permissions of the role, from the cache when one is configured
+func (pm *PermissionMiddleware) rolePermissions(ctx context.Context, role string) (map[string]bool, error) {
+	load := func(ctx context.Context, role string) (map[string]bool, error) {
+		return admin_menu.GetRolePermissions(ctx, pm.queries, role)
+	}
+
+	if pm.cache == nil {
+		return load(ctx, role)
+	}
+	return pm.cache.RolePermissions(ctx, role, load)
+}
+
+// adminOverrides returns the permission overrides of the admin, from the cache when one is configured
+func (pm *PermissionMiddleware) adminOverrides(ctx context.Context, adminID string) ([]admin_menu.PermissionOverride, error) {
+	load := func(ctx context.Context, adminID string) ([]admin_menu.PermissionOverride, error) {
+		id, err := uuid.Parse(adminID)
+		if err != nil {
+			return nil, err
+		}
+		return admin_menu.GetAdminPermissionOverrides(ctx, pm.queries, id)
+	}
+
+	if pm.cache == nil {
+		return load(ctx, adminID)
+	}
+	return pm.cache.AdminOverrides(ctx, adminID, load)
+}
+
+// CacheStats returns the counters of the permission cache, or false when it is disabled
+func (pm *PermissionMiddleware) CacheStats() (role.PermissionCacheStats, bool) {
+	if pm.cache == nil {
+		return role.PermissionCacheStats{}, false
+	}
+	return pm.cache.Stats(), true
+}
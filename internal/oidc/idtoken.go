@@ -0,0 +1,59 @@
+package oidc
+
+import "github.com/golang-jwt/jwt/v5"
+
+// IDToken holds the verified claims of an ID token. The standard profile claims
+// are parsed; anything else, such as a groups claim, is read with String or Strings.
+type IDToken struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	GivenName         string
+	FamilyName        string
+
+	claims jwt.MapClaims
+}
+
+func newIDToken(claims jwt.MapClaims) *IDToken {
+	t := &IDToken{claims: claims}
+	t.Subject = t.String("sub")
+	t.Email = t.String("email")
+	t.PreferredUsername = t.String("preferred_username")
+	t.GivenName = t.String("given_name")
+	t.FamilyName = t.String("family_name")
+
+	// Some providers send email_verified as a string
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		t.EmailVerified = v
+	case string:
+		t.EmailVerified = v == "true"
+	}
+
+	return t
+}
+
+// String returns a string claim, or "" when it is missing or not a string
+func (t *IDToken) String(name string) string {
+	s, _ := t.claims[name].(string)
+	return s
+}
+
+// Strings returns a claim that is a list of strings, such as groups or roles.
+// A single string is returned as a list of one.
+func (t *IDToken) Strings(name string) []string {
+	switch v := t.claims[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/user/coc/internal/oidc"
+	"github.com/user/coc/internal/oidc/oidctest"
+)
+
+const (
+	testClientID     = "coc-admin"
+	testClientSecret = "test-secret"
+	testRedirectURL  = "http://localhost:3001/auth/oidc/callback"
+)
+
+func startProvider(t *testing.T) (*oidctest.Provider, *oidc.Provider) {
+	t.Helper()
+
+	mock, server, err := oidctest.Start(testClientID, testClientSecret)
+	if err != nil {
+		t.Fatalf("failed to start mock provider: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    mock.Issuer(),
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}, server.Client())
+	return mock, provider
+}
+
+// authorize follows the authorization URL and returns the code and state of the callback
+func authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorization request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected redirect, got %d", resp.StatusCode)
+	}
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid callback URL: %v", err)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+// TestProvider_AuthorizationCodeFlow tests a full login against the mock provider
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	mock, provider := startProvider(t)
+	mock.SetUser(oidctest.Claims{
+		"sub":            "user-1",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"groups":         []string{"staff", "coc-admins"},
+	})
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-0123456789012345678901234567890123")
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	code, state := authorize(t, authURL)
+	if state != "state-1" {
+		t.Errorf("expected state to be echoed, got %q", state)
+	}
+
+	token, err := provider.Exchange(ctx, code, "verifier-0123456789012345678901234567890123", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if token.Subject != "user-1" || token.Email != "jane@example.com" || !token.EmailVerified || token.GivenName != "Jane" {
+		t.Errorf("unexpected claims: %+v", token)
+	}
+	if groups := token.Strings("groups"); len(groups) != 2 || groups[1] != "coc-admins" {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+
+	// Codes are single-use
+	if _, err := provider.Exchange(ctx, code, "verifier-0123456789012345678901234567890123", "nonce-1"); err == nil {
+		t.Error("expected redeemed code to be rejected")
+	}
+}
+
+// TestProvider_Exchange_WrongVerifier tests that a code cannot be redeemed without its PKCE verifier
+func TestProvider_Exchange_WrongVerifier(t *testing.T) {
+	mock, provider := startProvider(t)
+	mock.SetUser(oidctest.Claims{"sub": "user-1"})
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-0123456789012345678901234567890123")
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	code, _ := authorize(t, authURL)
+
+	if _, err := provider.Exchange(ctx, code, "another-verifier-012345678901234567890123456", "nonce-1"); err == nil {
+		t.Error("expected wrong code verifier to be rejected")
+	}
+}
+
+// TestProvider_Verify tests ID token validation
+func TestProvider_Verify(t *testing.T) {
+	mock, provider := startProvider(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   mock.Issuer(),
+			"aud":   testClientID,
+			"sub":   "user-1",
+			"nonce": "nonce-1",
+			"iat":   now.Unix(),
+			"exp":   now.Add(5 * time.Minute).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(jwt.MapClaims)
+		ok     bool
+	}{
+		{"valid", func(jwt.MapClaims) {}, true},
+		{"wrong nonce", func(c jwt.MapClaims) { c["nonce"] = "other" }, false},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other-client" }, false},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, false},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() }, false},
+		{"no subject", func(c jwt.MapClaims) { delete(c, "sub") }, false},
+		{"other authorized party", func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+			c["azp"] = "other-client"
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(claims)
+			raw, err := mock.SignIDToken(claims)
+			if err != nil {
+				t.Fatalf("failed to sign ID token: %v", err)
+			}
+
+			_, err = provider.Verify(ctx, raw, "nonce-1")
+			if tt.ok && err != nil {
+				t.Errorf("expected token to verify, got %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Error("expected token to be rejected")
+			}
+		})
+	}
+}
+
+// TestRoleMapping tests that the first matching rule wins
+func TestRoleMapping(t *testing.T) {
+	roles := []string{"super_admin", "admin", "moderator"}
+
+	mapping, err := oidc.ParseRoleMapping("groups", "coc-owners=super_admin, coc-admins=admin,coc-support=moderator", "", roles)
+	if err != nil {
+		t.Fatalf("ParseRoleMapping failed: %v", err)
+	}
+
+	mock, provider := startProvider(t)
+	verify := func(groups []string) *oidc.IDToken {
+		raw, err := mock.SignIDToken(jwt.MapClaims{
+			"iss": mock.Issuer(), "aud": testClientID, "sub": "user-1", "groups": groups,
+			"exp": time.Now().Add(time.Minute).Unix(),
+		})
+		if err != nil {
+			t.Fatalf("failed to sign ID token: %v", err)
+		}
+		token, err := provider.Verify(context.Background(), raw, "")
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		return token
+	}
+
+	if role, ok := mapping.Role(verify([]string{"coc-support", "coc-admins"})); !ok || role != "admin" {
+		t.Errorf("expected admin, got %q", role)
+	}
+	if _, ok := mapping.Role(verify([]string{"staff"})); ok {
+		t.Error("expected no role without a matching group")
+	}
+
+	mapping.DefaultRole = "moderator"
+	if role, ok := mapping.Role(verify([]string{"staff"})); !ok || role != "moderator" {
+		t.Errorf("expected default role, got %q", role)
+	}
+
+	if _, err := oidc.ParseRoleMapping("groups", "coc-admins=root", "", roles); err == nil {
+		t.Error("expected unknown role to be rejected")
+	}
+	if _, err := oidc.ParseRoleMapping("groups", "coc-admins", "", roles); err == nil {
+		t.Error("expected rule without role to be rejected")
+	}
+}
@@ -0,0 +1,71 @@
+package oidc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RoleRule grants Role to users whose role claim contains Value
+type RoleRule struct {
+	Value string
+	Role  string
+}
+
+// RoleMapping maps the values of one claim, usually the user's groups, to local roles
+type RoleMapping struct {
+	Claim       string
+	Rules       []RoleRule // in order of precedence
+	DefaultRole string     // for users no rule matches; empty refuses them
+}
+
+// ParseRoleMapping parses rules written as "value=role,value=role". The first
+// matching rule wins, so list the most privileged role first. Every role must
+// be one of allowedRoles.
+func ParseRoleMapping(claim, rules, defaultRole string, allowedRoles []string) (RoleMapping, error) {
+	mapping := RoleMapping{
+		Claim:       strings.TrimSpace(claim),
+		DefaultRole: strings.TrimSpace(defaultRole),
+	}
+	if mapping.Claim == "" {
+		return RoleMapping{}, fmt.Errorf("role claim must not be empty")
+	}
+	if mapping.DefaultRole != "" && !slices.Contains(allowedRoles, mapping.DefaultRole) {
+		return RoleMapping{}, fmt.Errorf("unknown default role %q", mapping.DefaultRole)
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		value, role, ok := strings.Cut(rule, "=")
+		value, role = strings.TrimSpace(value), strings.TrimSpace(role)
+		if !ok || value == "" || role == "" {
+			return RoleMapping{}, fmt.Errorf("invalid role mapping %q, expected value=role", rule)
+		}
+		if !slices.Contains(allowedRoles, role) {
+			return RoleMapping{}, fmt.Errorf("unknown role %q in role mapping", role)
+		}
+
+		mapping.Rules = append(mapping.Rules, RoleRule{Value: value, Role: role})
+	}
+
+	return mapping, nil
+}
+
+// Role returns the role for the user of the ID token, or false when none applies
+func (m RoleMapping) Role(token *IDToken) (string, bool) {
+	values := token.Strings(m.Claim)
+	for _, rule := range m.Rules {
+		if slices.Contains(values, rule.Value) {
+			return rule.Role, true
+		}
+	}
+
+	if m.DefaultRole != "" {
+		return m.DefaultRole, true
+	}
+	return "", false
+}
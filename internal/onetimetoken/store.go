@@ -0,0 +1,115 @@
+// Package onetimetoken issues short-lived, single-use tokens that are sent to
+// users and admins out of band, such as password reset, email verification and
+// passwordless login links.
+package onetimetoken
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// Purpose scopes a token to the flow it was issued for, so a token from one
+// email cannot be redeemed in another flow
+type Purpose string
+
+const (
+	PurposePasswordReset     Purpose = "password_reset"
+	PurposeEmailVerification Purpose = "email_verification"
+	PurposeMagicLink         Purpose = "magic_link"
+)
+
+// Store issues and consumes one-time tokens
+type Store struct {
+	queries *db.Queries
+	now     func() time.Time
+}
+
+// NewStore creates a one-time token store
+func NewStore(queries *db.Queries) *Store {
+	return &Store{
+		queries: queries,
+		now:     time.Now,
+	}
+}
+
+// Issue creates a token for the subject and invalidates any unused token issued
+// to it before for the same purpose. The token is only returned here; the
+// database keeps its hash.
+func (s *Store) Issue(ctx context.Context, purpose Purpose, subjectType refreshtoken.SubjectType, subjectID uuid.UUID, ttl time.Duration) (string, error) {
+	pgSubjectID := pgtype.UUID{Bytes: subjectID, Valid: true}
+
+	_, err := s.queries.InvalidateOneTimeTokens(ctx, db.InvalidateOneTimeTokensParams{
+		Purpose:     string(purpose),
+		SubjectType: string(subjectType),
+		SubjectID:   pgSubjectID,
+	})
+	if err != nil {
+		slog.Error("failed to invalidate one-time tokens", "purpose", purpose, "subject_type", subjectType, "error", err)
+		return "", errors.Internal("failed to issue token", err)
+	}
+
+	token, err := refreshtoken.GenerateToken()
+	if err != nil {
+		return "", errors.Internal("failed to issue token", err)
+	}
+
+	_, err = s.queries.CreateOneTimeToken(ctx, db.CreateOneTimeTokenParams{
+		Purpose:     string(purpose),
+		SubjectType: string(subjectType),
+		SubjectID:   pgSubjectID,
+		TokenHash:   refreshtoken.HashToken(token),
+		ExpiresAt:   pgtype.Timestamptz{Time: s.now().Add(ttl), Valid: true},
+	})
+	if err != nil {
+		slog.Error("failed to create one-time token", "purpose", purpose, "subject_type", subjectType, "error", err)
+		return "", errors.Internal("failed to issue token", err)
+	}
+
+	return token, nil
+}
+
+// Consume redeems a token and returns the subject it was issued to. Unknown,
+// expired and already used tokens are rejected with a validation error.
+func (s *Store) Consume(ctx context.Context, purpose Purpose, subjectType refreshtoken.SubjectType, token string) (uuid.UUID, error) {
+	row, err := s.queries.ConsumeOneTimeToken(ctx, db.ConsumeOneTimeTokenParams{
+		TokenHash:   refreshtoken.HashToken(token),
+		Purpose:     string(purpose),
+		SubjectType: string(subjectType),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return uuid.Nil, errors.Validation("invalid or expired token")
+		}
+		return uuid.Nil, errors.Internal("failed to verify token", err)
+	}
+
+	return uuid.UUID(row.SubjectID.Bytes), nil
+}
+
+// CountIssuedSince returns how many tokens were issued to the subject for purpose
+// after since, so callers can throttle how often a token is re-sent
+func (s *Store) CountIssuedSince(ctx context.Context, purpose Purpose, subjectType refreshtoken.SubjectType, subjectID uuid.UUID, since time.Time) (int64, error) {
+	count, err := s.queries.CountOneTimeTokensSince(ctx, db.CountOneTimeTokensSinceParams{
+		Purpose:     string(purpose),
+		SubjectType: string(subjectType),
+		SubjectID:   pgtype.UUID{Bytes: subjectID, Valid: true},
+		CreatedAt:   pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		return 0, errors.Internal("failed to check issued tokens", err)
+	}
+	return count, nil
+}
+
+// DeleteExpired removes tokens that have expired
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.queries.DeleteExpiredOneTimeTokens(ctx, pgtype.Timestamptz{Time: s.now(), Valid: true})
+}
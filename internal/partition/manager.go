@@ -0,0 +1,152 @@
+package partition
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+)
+
+// Tables lists the monthly range-partitioned tables kept up to date by the Manager
+var Tables = []string{"audit_logs", "error_logs"}
+
+// Options controls how far ahead partitions are created and how long they are kept
+type Options struct {
+	// MonthsAhead is the number of future months to create in addition to the current one
+	MonthsAhead int
+	// RetentionMonths removes partitions whose range ended more than this many months ago.
+	// Zero keeps every partition.
+	RetentionMonths int
+	// DetachOnly detaches expired partitions instead of dropping them, so they can be archived
+	DetachOnly bool
+}
+
+// Result summarises the maintenance performed on a single table
+type Result struct {
+	Table   string `json:"table"`
+	Created int    `json:"created"`
+	Moved   int64  `json:"moved"`
+	Expired int32  `json:"expired"`
+}
+
+// Manager creates, fills and expires monthly partitions
+type Manager struct {
+	queries *db.Queries
+	tables  []string
+	opts    Options
+	now     func() time.Time
+}
+
+// NewManager creates a partition manager for the audit and error log tables
+func NewManager(queries *db.Queries, opts Options) *Manager {
+	return &Manager{
+		queries: queries,
+		tables:  Tables,
+		opts:    opts,
+		now:     time.Now,
+	}
+}
+
+// Run performs one maintenance pass over every managed table:
+// rows stuck in the default partition are moved out, upcoming months are
+// created and partitions outside the retention window are removed.
+func (m *Manager) Run(ctx context.Context) ([]Result, error) {
+	now := m.now().UTC()
+	results := make([]Result, 0, len(m.tables))
+
+	for _, table := range m.tables {
+		result := Result{Table: table}
+
+		moved, err := m.queries.DrainDefaultPartition(ctx, table)
+		if err != nil {
+			slog.Error("failed to drain default partition", "table", table, "error", err)
+			return results, err
+		}
+		result.Moved = moved
+
+		for _, month := range upcomingMonths(now, m.opts.MonthsAhead) {
+			created, err := m.queries.EnsureMonthlyPartition(ctx, db.EnsureMonthlyPartitionParams{
+				ParentTable: table,
+				MonthStart:  pgtype.Date{Time: month, Valid: true},
+			})
+			if err != nil {
+				slog.Error("failed to create partition", "table", table, "month", month.Format("2006-01"), "error", err)
+				return results, err
+			}
+			if created {
+				result.Created++
+			}
+		}
+
+		if m.opts.RetentionMonths > 0 {
+			expired, err := m.queries.DropExpiredPartitions(ctx, db.DropExpiredPartitionsParams{
+				ParentTable: table,
+				Cutoff:      pgtype.Date{Time: retentionCutoff(now, m.opts.RetentionMonths), Valid: true},
+				DetachOnly:  m.opts.DetachOnly,
+			})
+			if err != nil {
+				slog.Error("failed to expire partitions", "table", table, "error", err)
+				return results, err
+			}
+			result.Expired = expired
+		}
+
+		slog.Info("partition maintenance completed",
+			"table", table,
+			"created", result.Created,
+			"moved", result.Moved,
+			"expired", result.Expired,
+		)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Start runs maintenance immediately and then on every interval until ctx is cancelled.
+// Failures are logged and retried on the next tick.
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := m.Run(ctx); err != nil {
+				slog.Error("partition maintenance failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// upcomingMonths returns the first day (UTC) of the current month and the next `ahead` months
+func upcomingMonths(now time.Time, ahead int) []time.Time {
+	if ahead < 0 {
+		ahead = 0
+	}
+
+	start := monthStart(now)
+	months := make([]time.Time, 0, ahead+1)
+	for i := 0; i <= ahead; i++ {
+		months = append(months, start.AddDate(0, i, 0))
+	}
+	return months
+}
+
+// retentionCutoff returns the first day of the oldest month that is still retained.
+// Partitions whose range ends on or before the cutoff are expired.
+func retentionCutoff(now time.Time, retentionMonths int) time.Time {
+	return monthStart(now).AddDate(0, -retentionMonths, 0)
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
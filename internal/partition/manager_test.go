@@ -0,0 +1,68 @@
+package partition
+
+import (
+	"testing"
+	"time"
+)
+
+// TestUpcomingMonths tests that the current month and the requested future months are returned
+func TestUpcomingMonths(t *testing.T) {
+	now := time.Date(2026, time.November, 17, 15, 4, 5, 0, time.UTC)
+
+	months := upcomingMonths(now, 3)
+
+	expected := []time.Time{
+		time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2027, time.February, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if len(months) != len(expected) {
+		t.Fatalf("expected %d months, got %d", len(expected), len(months))
+	}
+	for i := range expected {
+		if !months[i].Equal(expected[i]) {
+			t.Errorf("month %d: expected %v, got %v", i, expected[i], months[i])
+		}
+	}
+}
+
+// TestUpcomingMonths_NegativeAhead tests that a negative value still yields the current month
+func TestUpcomingMonths_NegativeAhead(t *testing.T) {
+	now := time.Date(2026, time.March, 31, 23, 0, 0, 0, time.UTC)
+
+	months := upcomingMonths(now, -2)
+
+	if len(months) != 1 {
+		t.Fatalf("expected 1 month, got %d", len(months))
+	}
+	if !months[0].Equal(time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected month: %v", months[0])
+	}
+}
+
+// TestUpcomingMonths_UsesUTC tests that months are computed in UTC to match partition bounds
+func TestUpcomingMonths_UsesUTC(t *testing.T) {
+	// 1 March 05:00 in Singapore is still 28 February in UTC
+	loc := time.FixedZone("SGT", 8*60*60)
+	now := time.Date(2026, time.March, 1, 5, 0, 0, 0, loc)
+
+	months := upcomingMonths(now, 0)
+
+	if !months[0].Equal(time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected February 2026, got %v", months[0])
+	}
+}
+
+// TestRetentionCutoff tests the cutoff date for expiring partitions
+func TestRetentionCutoff(t *testing.T) {
+	now := time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	cutoff := retentionCutoff(now, 12)
+
+	expected := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if !cutoff.Equal(expected) {
+		t.Errorf("expected cutoff %v, got %v", expected, cutoff)
+	}
+}
@@ -0,0 +1,207 @@
+// Package passhash hashes and verifies account passwords. New hashes use the
+// configured algorithm (argon2id by default); hashes made with an older
+// algorithm or weaker parameters still verify, and Verify reports that they
+// should be replaced, so callers can upgrade them after a successful login.
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm is a password hashing algorithm
+type Algorithm string
+
+const (
+	Argon2id Algorithm = "argon2id"
+	Bcrypt   Algorithm = "bcrypt"
+)
+
+// Params configures how new hashes are made
+type Params struct {
+	Algorithm Algorithm
+
+	// argon2id
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+
+	// bcrypt
+	BcryptCost int
+}
+
+// DefaultParams returns argon2id with the parameters recommended by RFC 9106
+// for memory-constrained environments (64 MiB, 3 passes)
+func DefaultParams() Params {
+	return Params{
+		Algorithm:   Argon2id,
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+		BcryptCost:  bcrypt.DefaultCost,
+	}
+}
+
+// DummyHash is an argon2id hash of a random password, made with the default
+// parameters. Logins verify the password against it when the account does not
+// exist, so unknown accounts take as long to reject as wrong passwords.
+const DummyHash = "$argon2id$v=19$m=65536,t=3,p=2$LDqpwUkWPBC34swpjsD8TA$rHHM/X3B3woTeZLoiagCqz2IPWgT7IvqioNQpBfMLxE"
+
+// Hasher hashes passwords with fixed parameters
+type Hasher struct {
+	params Params
+}
+
+// NewHasher validates params and creates a hasher
+func NewHasher(params Params) (*Hasher, error) {
+	switch params.Algorithm {
+	case Argon2id:
+		if params.Memory < 8*uint32(params.Parallelism) || params.Iterations < 1 || params.Parallelism < 1 {
+			return nil, fmt.Errorf("invalid argon2id parameters: memory %d KiB, iterations %d, parallelism %d", params.Memory, params.Iterations, params.Parallelism)
+		}
+		if params.SaltLength < 8 || params.KeyLength < 16 {
+			return nil, fmt.Errorf("argon2id salt must be at least 8 bytes and key at least 16 bytes")
+		}
+	case Bcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q (expected argon2id or bcrypt)", params.Algorithm)
+	}
+
+	return &Hasher{params: params}, nil
+}
+
+// Hash hashes password with the configured algorithm
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.params.Algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.params.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	// PHC string format, as used by the reference implementation
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches hash, and whether hash was made with
+// another algorithm or other parameters than the configured ones and should be
+// replaced by a new Hash of the password. An error means the hash is malformed.
+func (h *Hasher) Verify(hash, password string) (match, rehash bool, err error) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return h.verifyArgon2id(hash, password)
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, fmt.Errorf("unrecognized password hash: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	return true, h.params.Algorithm != Bcrypt || cost != h.params.BcryptCost, nil
+}
+
+func (h *Hasher) verifyArgon2id(hash, password string) (bool, bool, error) {
+	// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, fmt.Errorf("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, fmt.Errorf("unsupported argon2id version")
+	}
+
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, false, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	// argon2.IDKey panics on zero parallelism
+	if memory < 8*uint32(parallelism) || iterations < 1 || parallelism < 1 {
+		return false, false, fmt.Errorf("invalid argon2id parameters: memory %d KiB, iterations %d, parallelism %d", memory, iterations, parallelism)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, fmt.Errorf("malformed argon2id key: %w", err)
+	}
+	if len(key) == 0 {
+		return false, false, fmt.Errorf("malformed argon2id key")
+	}
+
+	other := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+
+	p := h.params
+	rehash := p.Algorithm != Argon2id ||
+		memory != p.Memory || iterations != p.Iterations || parallelism != p.Parallelism ||
+		uint32(len(salt)) != p.SaltLength || uint32(len(key)) != p.KeyLength
+
+	return true, rehash, nil
+}
+
+var (
+	mu            sync.RWMutex
+	defaultHasher = &Hasher{params: DefaultParams()}
+)
+
+// SetDefault replaces the hasher used by Hash and Verify, normally once at startup
+func SetDefault(h *Hasher) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultHasher = h
+}
+
+// Default returns the hasher used by Hash and Verify
+func Default() *Hasher {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultHasher
+}
+
+// Hash hashes password with the default hasher
+func Hash(password string) (string, error) {
+	return Default().Hash(password)
+}
+
+// Verify checks password against hash with the default hasher
+func Verify(hash, password string) (match, rehash bool, err error) {
+	return Default().Verify(hash, password)
+}
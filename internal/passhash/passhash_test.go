@@ -0,0 +1,143 @@
+package passhash
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastParams keeps argon2id cheap in tests
+func fastParams() Params {
+	p := DefaultParams()
+	p.Memory = 1024
+	p.Iterations = 1
+	p.Parallelism = 1
+	return p
+}
+
+func mustHasher(t *testing.T, p Params) *Hasher {
+	t.Helper()
+	h, err := NewHasher(p)
+	if err != nil {
+		t.Fatalf("NewHasher failed: %v", err)
+	}
+	return h
+}
+
+// TestArgon2id_HashAndVerify tests the argon2id round trip
+func TestArgon2id_HashAndVerify(t *testing.T) {
+	h := mustHasher(t, fastParams())
+
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("unexpected hash format: %s", hash)
+	}
+
+	match, rehash, err := h.Verify(hash, "correct horse")
+	if err != nil || !match || rehash {
+		t.Errorf("expected match without rehash, got match=%v rehash=%v err=%v", match, rehash, err)
+	}
+
+	match, _, err = h.Verify(hash, "wrong horse")
+	if err != nil || match {
+		t.Errorf("expected mismatch, got match=%v err=%v", match, err)
+	}
+
+	// Salted: the same password hashes differently every time
+	other, _ := h.Hash("correct horse")
+	if other == hash {
+		t.Error("expected different hashes for the same password")
+	}
+}
+
+// TestVerify_LegacyBcrypt tests that bcrypt hashes verify and are flagged for rehash
+func TestVerify_LegacyBcrypt(t *testing.T) {
+	h := mustHasher(t, fastParams())
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt failed: %v", err)
+	}
+
+	match, rehash, err := h.Verify(string(legacy), "password123")
+	if err != nil || !match || !rehash {
+		t.Errorf("expected match with rehash, got match=%v rehash=%v err=%v", match, rehash, err)
+	}
+
+	match, rehash, err = h.Verify(string(legacy), "wrong")
+	if err != nil || match || rehash {
+		t.Errorf("expected mismatch without rehash, got match=%v rehash=%v err=%v", match, rehash, err)
+	}
+}
+
+// TestVerify_OutdatedParams tests that hashes with other parameters or cost are flagged for rehash
+func TestVerify_OutdatedParams(t *testing.T) {
+	old := mustHasher(t, fastParams())
+	hash, _ := old.Hash("password123")
+
+	stronger := fastParams()
+	stronger.Iterations = 2
+	match, rehash, err := mustHasher(t, stronger).Verify(hash, "password123")
+	if err != nil || !match || !rehash {
+		t.Errorf("expected argon2id rehash after parameter change, got match=%v rehash=%v err=%v", match, rehash, err)
+	}
+
+	bcryptParams := Params{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost}
+	lowCost, _ := mustHasher(t, bcryptParams).Hash("password123")
+	bcryptParams.BcryptCost = bcrypt.MinCost + 1
+	match, rehash, err = mustHasher(t, bcryptParams).Verify(lowCost, "password123")
+	if err != nil || !match || !rehash {
+		t.Errorf("expected bcrypt rehash after cost change, got match=%v rehash=%v err=%v", match, rehash, err)
+	}
+}
+
+// TestVerify_Malformed tests that unknown hash formats are errors
+func TestVerify_Malformed(t *testing.T) {
+	h := mustHasher(t, fastParams())
+
+	for _, hash := range []string{
+		"",
+		"plaintext",
+		"$argon2id$v=19$m=1024$abc",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=4,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+	} {
+		if _, _, err := h.Verify(hash, "password"); err == nil {
+			t.Errorf("expected error for %q", hash)
+		}
+	}
+}
+
+// TestDummyHash tests that the dummy hash is well formed and matches nothing
+func TestDummyHash(t *testing.T) {
+	match, _, err := Verify(DummyHash, "password")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if match {
+		t.Error("expected the dummy hash not to match")
+	}
+}
+
+// TestNewHasher_Invalid tests parameter validation
+func TestNewHasher_Invalid(t *testing.T) {
+	tests := []Params{
+		{Algorithm: "md5"},
+		{Algorithm: Bcrypt, BcryptCost: 2},
+		{Algorithm: Argon2id, Memory: 1024, Iterations: 0, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+		{Algorithm: Argon2id, Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 4, KeyLength: 32},
+	}
+
+	for _, p := range tests {
+		if _, err := NewHasher(p); err == nil {
+			t.Errorf("expected error for %+v", p)
+		}
+	}
+}
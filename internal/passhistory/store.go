@@ -0,0 +1,106 @@
+// Package passhistory remembers the previous password hashes of users and
+// admins, so a password change can refuse a recently used password.
+package passhistory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+	"github.com/user/coc/internal/passhash"
+	"github.com/user/coc/internal/refreshtoken"
+)
+
+// Store checks and records password history
+type Store struct {
+	queries *db.Queries
+	size    int
+}
+
+// NewStore creates a password history store that keeps the last size
+// passwords of every account. With a size of 0 only the current password
+// is refused.
+func NewStore(queries *db.Queries, size int) *Store {
+	return &Store{
+		queries: queries,
+		size:    max(size, 0),
+	}
+}
+
+// WithTx returns a store that runs its statements in tx
+func (s *Store) WithTx(tx pgx.Tx) *Store {
+	return &Store{
+		queries: s.queries.WithTx(tx),
+		size:    s.size,
+	}
+}
+
+// Check rejects password with a validation error when it matches the current
+// password hash or one of the remembered previous ones
+func (s *Store) Check(ctx context.Context, subjectType refreshtoken.SubjectType, subjectID uuid.UUID, currentHash, password string) error {
+	hashes := []string{currentHash}
+
+	if s.size > 0 {
+		previous, err := s.queries.ListRecentPasswordHashes(ctx, db.ListRecentPasswordHashesParams{
+			SubjectType: string(subjectType),
+			SubjectID:   pgtype.UUID{Bytes: subjectID, Valid: true},
+			Limit:       int32(s.size),
+		})
+		if err != nil {
+			return errors.Internal("failed to check password history", err)
+		}
+		hashes = append(hashes, previous...)
+	}
+
+	for _, hash := range hashes {
+		match, _, err := passhash.Verify(hash, password)
+		if err != nil {
+			// A malformed old hash cannot match anything; skip it rather than block the change
+			slog.Warn("skipping unreadable password hash in history", "subject_type", subjectType, "error", err)
+			continue
+		}
+		if match {
+			if s.size == 0 {
+				return errors.Validation("new password must be different from the current password")
+			}
+			return errors.Validation(fmt.Sprintf("new password must not match your current or last %d passwords", s.size))
+		}
+	}
+
+	return nil
+}
+
+// Record remembers the hash of a password that is being replaced and forgets
+// entries beyond the configured size
+func (s *Store) Record(ctx context.Context, subjectType refreshtoken.SubjectType, subjectID uuid.UUID, oldHash string) error {
+	if s.size == 0 {
+		return nil
+	}
+
+	pgSubjectID := pgtype.UUID{Bytes: subjectID, Valid: true}
+
+	err := s.queries.CreatePasswordHistory(ctx, db.CreatePasswordHistoryParams{
+		SubjectType:  string(subjectType),
+		SubjectID:    pgSubjectID,
+		PasswordHash: oldHash,
+	})
+	if err != nil {
+		return errors.Internal("failed to record password history", err)
+	}
+
+	_, err = s.queries.PrunePasswordHistory(ctx, db.PrunePasswordHistoryParams{
+		SubjectType: string(subjectType),
+		SubjectID:   pgSubjectID,
+		Limit:       int32(s.size),
+	})
+	if err != nil {
+		return errors.Internal("failed to prune password history", err)
+	}
+
+	return nil
+}
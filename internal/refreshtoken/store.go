@@ -0,0 +1,227 @@
+package refreshtoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// SubjectType identifies which account table a refresh token belongs to
+type SubjectType string
+
+const (
+	SubjectUser  SubjectType = "user"
+	SubjectAdmin SubjectType = "admin"
+)
+
+// tokenBytes is the amount of randomness in an opaque refresh token
+const tokenBytes = 32
+
+// DB is the connection used by the Store. Both *pgxpool.Pool and pgx.Tx
+// satisfy it, so integration tests can run the store inside a transaction.
+type DB interface {
+	db.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Issued is a newly created refresh token. Token is only available here;
+// the database keeps its hash.
+type Issued struct {
+	Token        string
+	SubjectID    uuid.UUID
+	FamilyID     uuid.UUID
+	TokenVersion int32
+	ExpiresAt    time.Time
+}
+
+// Store issues, rotates and revokes refresh tokens
+type Store struct {
+	conn    DB
+	queries *db.Queries
+	now     func() time.Time
+}
+
+// NewStore creates a refresh token store
+func NewStore(conn DB) *Store {
+	return &Store{
+		conn:    conn,
+		queries: db.New(conn),
+		now:     time.Now,
+	}
+}
+
+// Issue creates the first refresh token of a new token family (one per login).
+// tokenVersion is the subject's current token version; callers must reject a
+// rotated token whose version no longer matches the subject.
+func (s *Store) Issue(ctx context.Context, subjectType SubjectType, subjectID uuid.UUID, tokenVersion int32, ttl time.Duration) (*Issued, error) {
+	issued, _, err := s.create(ctx, s.queries, subjectType, subjectID, uuid.New(), tokenVersion, ttl)
+	if err != nil {
+		slog.Error("failed to issue refresh token", "subject_type", subjectType, "error", err)
+		return nil, errors.Internal("failed to issue refresh token", err)
+	}
+	return issued, nil
+}
+
+// Rotate exchanges a refresh token for a new one in the same family.
+// Presenting a token that was already rotated or revoked revokes the whole
+// family, since either the client or an attacker holds a stolen copy.
+func (s *Store) Rotate(ctx context.Context, subjectType SubjectType, token string, ttl time.Duration) (*Issued, error) {
+	current, err := s.queries.GetRefreshTokenByHash(ctx, HashToken(token))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.Unauthorized("invalid refresh token")
+		}
+		slog.Error("failed to get refresh token", "error", err)
+		return nil, errors.Internal("failed to refresh token", err)
+	}
+
+	if current.SubjectType != string(subjectType) {
+		return nil, errors.Unauthorized("invalid refresh token")
+	}
+
+	if current.RevokedAt.Valid {
+		return nil, s.reuseDetected(ctx, current)
+	}
+
+	if !s.now().Before(current.ExpiresAt.Time) {
+		return nil, errors.Unauthorized("refresh token expired")
+	}
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Internal("failed to refresh token", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	issued, next, err := s.create(ctx, qtx, subjectType, uuid.UUID(current.SubjectID.Bytes), uuid.UUID(current.FamilyID.Bytes), current.TokenVersion, ttl)
+	if err != nil {
+		slog.Error("failed to create rotated refresh token", "error", err)
+		return nil, errors.Internal("failed to refresh token", err)
+	}
+
+	// Conditional update: a concurrent refresh with the same token loses here
+	rows, err := qtx.RotateRefreshToken(ctx, db.RotateRefreshTokenParams{
+		ID:         current.ID,
+		ReplacedBy: next.ID,
+	})
+	if err != nil {
+		slog.Error("failed to rotate refresh token", "error", err)
+		return nil, errors.Internal("failed to refresh token", err)
+	}
+	if rows == 0 {
+		tx.Rollback(ctx)
+		return nil, s.reuseDetected(ctx, current)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.Internal("failed to refresh token", err)
+	}
+
+	return issued, nil
+}
+
+// WithTx returns a store that runs its statements in tx
+func (s *Store) WithTx(tx pgx.Tx) *Store {
+	return &Store{
+		conn:    tx,
+		queries: s.queries.WithTx(tx),
+		now:     s.now,
+	}
+}
+
+// RevokeFamily revokes every active token descending from the same login
+func (s *Store) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	if _, err := s.queries.RevokeRefreshTokenFamily(ctx, pgtype.UUID{Bytes: familyID, Valid: true}); err != nil {
+		slog.Error("failed to revoke refresh token family", "family_id", familyID, "error", err)
+		return errors.Internal("failed to revoke refresh tokens", err)
+	}
+	return nil
+}
+
+// RevokeSubject revokes every active refresh token of a user or admin (logout everywhere)
+func (s *Store) RevokeSubject(ctx context.Context, subjectType SubjectType, subjectID uuid.UUID) error {
+	_, err := s.queries.RevokeRefreshTokensBySubject(ctx, db.RevokeRefreshTokensBySubjectParams{
+		SubjectType: string(subjectType),
+		SubjectID:   pgtype.UUID{Bytes: subjectID, Valid: true},
+	})
+	if err != nil {
+		slog.Error("failed to revoke refresh tokens", "subject_type", subjectType, "subject_id", subjectID, "error", err)
+		return errors.Internal("failed to revoke refresh tokens", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes refresh tokens that have expired
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.queries.DeleteExpiredRefreshTokens(ctx, pgtype.Timestamptz{Time: s.now(), Valid: true})
+}
+
+func (s *Store) reuseDetected(ctx context.Context, token db.RefreshToken) error {
+	familyID := uuid.UUID(token.FamilyID.Bytes)
+	slog.Warn("refresh token reuse detected, revoking token family",
+		"subject_type", token.SubjectType,
+		"subject_id", uuid.UUID(token.SubjectID.Bytes).String(),
+		"family_id", familyID.String(),
+	)
+
+	if err := s.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return errors.Unauthorized("refresh token has been revoked")
+}
+
+func (s *Store) create(ctx context.Context, queries *db.Queries, subjectType SubjectType, subjectID, familyID uuid.UUID, tokenVersion int32, ttl time.Duration) (*Issued, *db.RefreshToken, error) {
+	token, err := GenerateToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expiresAt := s.now().Add(ttl)
+
+	row, err := queries.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		SubjectType:  string(subjectType),
+		SubjectID:    pgtype.UUID{Bytes: subjectID, Valid: true},
+		FamilyID:     pgtype.UUID{Bytes: familyID, Valid: true},
+		TokenHash:    HashToken(token),
+		ExpiresAt:    pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		TokenVersion: tokenVersion,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Issued{
+		Token:        token,
+		SubjectID:    subjectID,
+		FamilyID:     familyID,
+		TokenVersion: tokenVersion,
+		ExpiresAt:    expiresAt,
+	}, &row, nil
+}
+
+// GenerateToken returns a new random, URL-safe opaque token
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex SHA-256 digest stored in place of the token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package refreshtoken
+
+import (
+	"testing"
+)
+
+// TestGenerateToken tests that generated tokens are URL-safe and unique
+func TestGenerateToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := GenerateToken()
+		if err != nil {
+			t.Fatalf("GenerateToken failed: %v", err)
+		}
+		if len(token) != 43 {
+			t.Errorf("expected 43 characters, got %d", len(token))
+		}
+		if seen[token] {
+			t.Fatalf("duplicate token generated: %s", token)
+		}
+		seen[token] = true
+	}
+}
+
+// TestHashToken tests that hashing is deterministic and hides the token
+func TestHashToken(t *testing.T) {
+	token := "example-refresh-token"
+
+	hash := HashToken(token)
+	if hash != HashToken(token) {
+		t.Error("expected hashing to be deterministic")
+	}
+	if len(hash) != 64 {
+		t.Errorf("expected 64 hex characters, got %d", len(hash))
+	}
+	if hash == token {
+		t.Error("expected hash to differ from token")
+	}
+	if HashToken("other-token") == hash {
+		t.Error("expected different tokens to have different hashes")
+	}
+}
@@ -56,6 +56,10 @@ func HandleServiceError(w http.ResponseWriter, err error) {
 		Error(w, http.StatusBadRequest, domainErr.Message)
 	case errors.CodeUnauthorized:
 		Error(w, http.StatusUnauthorized, domainErr.Message)
+	case errors.CodeForbidden:
+		Error(w, http.StatusForbidden, domainErr.Message)
+	case errors.CodeRateLimited:
+		Error(w, http.StatusTooManyRequests, domainErr.Message)
 	default:
 		slog.Error("internal error", "error", domainErr)
 		Error(w, http.StatusInternalServerError, "internal server error")
@@ -0,0 +1,39 @@
+package revocation
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Purger deletes rows that are no longer needed once their token has expired
+type Purger interface {
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// StartCleanup purges expired tokens immediately and then on every interval until ctx is cancelled
+func StartCleanup(ctx context.Context, interval time.Duration, purgers ...Purger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for _, p := range purgers {
+				deleted, err := p.DeleteExpired(ctx)
+				if err != nil {
+					slog.Error("failed to purge expired tokens", "error", err)
+					continue
+				}
+				if deleted > 0 {
+					slog.Info("purged expired tokens", "deleted", deleted)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,52 @@
+package revocation
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// Denylist records individual access tokens (by jti) revoked before they expire.
+// Revoking every token of an account is done by bumping its token_version instead.
+type Denylist struct {
+	queries *db.Queries
+	now     func() time.Time
+}
+
+// NewDenylist creates an access token denylist
+func NewDenylist(queries *db.Queries) *Denylist {
+	return &Denylist{
+		queries: queries,
+		now:     time.Now,
+	}
+}
+
+// Revoke denies the token until expiresAt, after which it is rejected as expired anyway
+func (d *Denylist) Revoke(ctx context.Context, jti uuid.UUID, subjectType string, subjectID uuid.UUID, expiresAt time.Time) error {
+	err := d.queries.RevokeToken(ctx, db.RevokeTokenParams{
+		Jti:         pgtype.UUID{Bytes: jti, Valid: true},
+		SubjectType: subjectType,
+		SubjectID:   pgtype.UUID{Bytes: subjectID, Valid: true},
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		slog.Error("failed to revoke token", "jti", jti, "error", err)
+		return errors.Internal("failed to revoke token", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token with the given jti was revoked
+func (d *Denylist) IsRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	return d.queries.IsTokenRevoked(ctx, pgtype.UUID{Bytes: jti, Valid: true})
+}
+
+// DeleteExpired removes entries for tokens that have expired
+func (d *Denylist) DeleteExpired(ctx context.Context) (int64, error) {
+	return d.queries.DeleteExpiredRevokedTokens(ctx, pgtype.Timestamptz{Time: d.now(), Valid: true})
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/user/coc/internal/app/admin"
 	"github.com/user/coc/internal/app/admin_auth"
 	"github.com/user/coc/internal/app/admin_menu"
+	"github.com/user/coc/internal/app/audit_log"
+	"github.com/user/coc/internal/app/data_export"
+	"github.com/user/coc/internal/app/role"
+	"github.com/user/coc/internal/app/security_event"
 	"github.com/user/coc/internal/app/user"
 	"github.com/user/coc/internal/middleware"
 )
@@ -19,7 +23,11 @@ func NewAdminRouter(
 	addressAdminHandler *address.AdminHandler,
 	adminAuthHandler *admin_auth.AuthHandler,
 	adminHandler *admin.Handler,
+	roleHandler *role.Handler,
 	menuHandler *admin_menu.Handler,
+	auditLogHandler *audit_log.Handler,
+	securityEventHandler *security_event.AdminHandler,
+	dataExportHandler *data_export.AdminHandler,
 	adminAuthMiddleware func(http.Handler) http.Handler,
 	permissionMiddleware *middleware.PermissionMiddleware,
 ) chi.Router {
@@ -31,8 +39,23 @@ func NewAdminRouter(
 	// Admin auth routes
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/login", adminAuthHandler.Login)
+		r.Post("/refresh", adminAuthHandler.Refresh)
+		r.Post("/password/forgot", adminAuthHandler.ForgotPassword)
+		r.Post("/password/reset", adminAuthHandler.ResetPassword)
+
+		// Second login step for admins with MFA (authenticated by the MFA challenge token)
+		r.Post("/mfa/verify", adminAuthHandler.VerifyMFA)
+		r.Post("/mfa/enroll", adminAuthHandler.EnrollMFAWithChallenge)
+
+		// Single sign-on with the company identity provider (OpenID Connect)
+		r.Get("/oidc/login", adminAuthHandler.StartOIDCLogin)
+		r.Post("/oidc/callback", adminAuthHandler.CompleteOIDCLogin)
 		// Admin registration might be restricted or different
 		// r.Post("/register", adminAuthHandler.Register)
+
+		// Session revocation requires a valid admin token (API keys have no session)
+		r.With(adminAuthMiddleware, middleware.RequireAdminSession).Post("/logout", adminAuthHandler.Logout)
+		r.With(adminAuthMiddleware, middleware.RequireAdminSession).Post("/logout-all", adminAuthHandler.LogoutAll)
 	})
 
 	// Protected routes that require authentication
@@ -41,6 +64,34 @@ func NewAdminRouter(
 
 		// Get admin menu based on role
 		r.Get("/menu", menuHandler.GetMenu)
+
+		// The admin's own credentials can only be managed from a real session, not with an API key
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireAdminSession)
+
+			// Change the current admin's own password
+			r.Put("/me/password", adminAuthHandler.ChangePassword)
+
+			// Manage the current admin's own MFA
+			r.Post("/me/mfa/enroll", adminAuthHandler.StartMFAEnrollment)
+			r.Post("/me/mfa/activate", adminAuthHandler.ActivateMFA)
+			r.Post("/me/mfa/disable", adminAuthHandler.DisableMFA)
+			r.Post("/me/mfa/recovery-codes", adminAuthHandler.RegenerateRecoveryCodes)
+
+			// Manage the current admin's own API keys
+			r.Get("/me/api-keys", adminAuthHandler.ListAPIKeys)
+			r.Post("/me/api-keys", adminAuthHandler.CreateAPIKey)
+			r.Delete("/me/api-keys/{id}", adminAuthHandler.RevokeAPIKey)
+		})
+	})
+
+	// Per-role MFA policy (protected) - only super_admin may change who must use MFA
+	r.Route("/mfa-policies", func(r chi.Router) {
+		r.Use(adminAuthMiddleware)
+		r.Use(middleware.RequireAdminRole("super_admin"))
+
+		r.Get("/", adminAuthHandler.ListMFAPolicies)
+		r.Put("/{role}", adminAuthHandler.UpdateMFAPolicy)
 	})
 
 	// Admin user management (protected)
@@ -53,12 +104,22 @@ func NewAdminRouter(
 		// Read requires users.read permission
 		r.With(permissionMiddleware.RequirePermission("users.read")).Get("/", userAdminHandler.ListUsers)
 		r.With(permissionMiddleware.RequirePermission("users.read")).Get("/{id}", userAdminHandler.GetUser)
+		r.With(permissionMiddleware.RequirePermission("users.read")).Get("/{id}/sessions", userAdminHandler.ListUserSessions)
 
 		// Update requires users.update permission
 		r.With(permissionMiddleware.RequirePermission("users.update")).Put("/{id}", userAdminHandler.UpdateUser)
+		r.With(permissionMiddleware.RequirePermission("users.update")).Post("/{id}/unlock", userAdminHandler.UnlockUser)
+		r.With(permissionMiddleware.RequirePermission("users.update")).Delete("/{id}/sessions/{session_id}", userAdminHandler.RevokeUserSession)
 
 		// Delete requires users.delete permission
 		r.With(permissionMiddleware.RequirePermission("users.delete")).Delete("/{id}", userAdminHandler.DeleteUser)
+
+		// Personal data export requires users.export permission
+		r.With(permissionMiddleware.RequirePermission("users.export")).Post("/{id}/export", dataExportHandler.RequestExport)
+		r.With(permissionMiddleware.RequirePermission("users.export")).Get("/{id}/export/{export_id}", dataExportHandler.GetExport)
+
+		// Impersonation requires users.impersonate permission and a signed-in admin, not an API key
+		r.With(middleware.RequireAdminSession, permissionMiddleware.RequirePermission("users.impersonate")).Post("/{id}/impersonate", userAdminHandler.ImpersonateUser)
 	})
 
 	// Admin management (protected) - only super_admin should access these
@@ -71,6 +132,36 @@ func NewAdminRouter(
 		r.Get("/{id}", adminHandler.GetAdmin)
 		r.Put("/{id}", adminHandler.UpdateAdmin)
 		r.Delete("/{id}", adminHandler.DeleteAdmin)
+		r.Post("/{id}/unlock", adminHandler.UnlockAdmin)
+
+		// Permissions granted or denied to one admin on top of their role
+		r.Get("/{id}/permission-overrides", adminHandler.ListPermissionOverrides)
+		r.Put("/{id}/permission-overrides/{code}", adminHandler.SetPermissionOverride)
+		r.Delete("/{id}/permission-overrides/{code}", adminHandler.RemovePermissionOverride)
+	})
+
+	// Role management (protected) - admin roles and the permissions they grant
+	r.Route("/roles", func(r chi.Router) {
+		r.Use(adminAuthMiddleware)                                     // Protect all role management routes
+		r.Use(permissionMiddleware.RequirePermission("admins.manage")) // Require admins.manage permission
+
+		r.Get("/", roleHandler.ListRoles)
+		r.Post("/", roleHandler.CreateRole)
+		r.Get("/{role}", roleHandler.GetRole)
+		r.Put("/{role}", roleHandler.UpdateRole)
+		r.Delete("/{role}", roleHandler.DeleteRole)
+		r.Put("/{role}/permissions/{code}", roleHandler.GrantPermission)
+		r.Delete("/{role}/permissions/{code}", roleHandler.RevokePermission)
+	})
+
+	// Permission matrix (protected, read-only) - every permission with the roles that grant it,
+	// plus this instance's permission cache statistics
+	r.Route("/permissions", func(r chi.Router) {
+		r.Use(adminAuthMiddleware)
+		r.Use(permissionMiddleware.RequirePermission("admins.manage"))
+
+		r.Get("/", roleHandler.ListPermissions)
+		r.Get("/cache", roleHandler.PermissionCacheStats)
 	})
 
 	// (orders feature removed)
@@ -102,5 +193,22 @@ func NewAdminRouter(
 		r.With(permissionMiddleware.RequirePermission("addresses.update")).Post("/default", addressAdminHandler.SetDefaultAddress)
 	})
 
+	// Audit log browsing (protected, read-only)
+	r.Route("/audit-logs", func(r chi.Router) {
+		r.Use(adminAuthMiddleware)                                  // Protect all audit log routes
+		r.Use(permissionMiddleware.RequirePermission("audit.read")) // Require audit.read permission
+
+		r.Get("/", auditLogHandler.ListAuditLogs)
+		r.Get("/{id}", auditLogHandler.GetAuditLog)
+	})
+
+	// Security event browsing (protected, read-only)
+	r.Route("/security-events", func(r chi.Router) {
+		r.Use(adminAuthMiddleware)                                     // Protect all security event routes
+		r.Use(permissionMiddleware.RequirePermission("security.read")) // Require security.read permission
+
+		r.Get("/", securityEventHandler.ListSecurityEvents)
+	})
+
 	return r
 }
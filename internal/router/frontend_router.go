@@ -5,7 +5,9 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/user/coc/internal/app/address"
+	"github.com/user/coc/internal/app/data_export"
 	"github.com/user/coc/internal/app/frontend_auth"
+	"github.com/user/coc/internal/app/security_event"
 	"github.com/user/coc/internal/app/user"
 	"github.com/user/coc/internal/middleware"
 )
@@ -16,7 +18,10 @@ func NewFrontendRouter(
 	userFrontendHandler *user.FrontendHandler,
 	addressFrontendHandler *address.FrontendHandler,
 	authHandler *frontend_auth.Handler,
+	securityEventHandler *security_event.FrontendHandler,
+	dataExportHandler *data_export.FrontendHandler,
 	authMiddleware func(http.Handler) http.Handler,
+	verifiedEmailMiddleware func(http.Handler) http.Handler,
 ) chi.Router {
 	r := chi.NewRouter()
 
@@ -27,21 +32,54 @@ func NewFrontendRouter(
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/login", authHandler.Login)
 		r.Post("/register", authHandler.Register)
+		r.Post("/refresh", authHandler.Refresh)
+		r.Post("/password/forgot", authHandler.ForgotPassword)
+		r.Post("/password/reset", authHandler.ResetPassword)
+		r.Post("/email/verify", authHandler.VerifyEmail)
+		r.Post("/email/resend", authHandler.ResendVerificationEmail)
+		r.Post("/magic-link", authHandler.RequestMagicLink)
+		r.Post("/magic-link/verify", authHandler.VerifyMagicLink)
+
+		// Session revocation requires a valid token
+		r.With(authMiddleware).Post("/logout", authHandler.Logout)
+		r.With(authMiddleware, middleware.RequireNoImpersonation).Post("/logout-all", authHandler.LogoutAll)
 	})
 
 	// Frontend user routes (protected - users can access their own data)
+	// Unverified users can still reach their profile, e.g. to correct a mistyped email
 	r.Route("/user", func(r chi.Router) {
 		r.Use(authMiddleware)
 		// Frontend users can only access/update their own profile
 		r.Get("/", userFrontendHandler.GetMe)    // Get current user profile
 		r.Put("/", userFrontendHandler.UpdateMe) // Update current user profile
+
+		// Schedule deletion of the own account; logging in again cancels it
+		r.With(middleware.RequireNoImpersonation).Delete("/", authHandler.DeleteAccount)
+
+		// Change own password; an impersonating admin cannot
+		r.With(middleware.RequireNoImpersonation).Put("/password", authHandler.ChangePassword)
+
+		// Devices the user is signed in on
+		r.Get("/sessions", authHandler.ListSessions)
+		r.With(middleware.RequireNoImpersonation).Delete("/sessions/{id}", authHandler.RevokeSession)
+
+		// Recent sign-in activity of the user
+		r.Get("/activity", securityEventHandler.ListActivity)
+
+		// Personal data export; an impersonating admin cannot export the user's data
+		r.With(middleware.RequireNoImpersonation).Post("/export", dataExportHandler.RequestExport)
+		r.With(middleware.RequireNoImpersonation).Get("/export/{id}", dataExportHandler.GetExport)
 	})
 
+	// Data export downloads (public - the signed link is the credential)
+	r.Get("/exports/{id}/download", dataExportHandler.Download)
+
 	// (orders feature removed)
 
 	// Frontend address routes (protected - users can manage their own addresses)
 	r.Route("/addresses", func(r chi.Router) {
 		r.Use(authMiddleware)
+		r.Use(verifiedEmailMiddleware)
 		r.Post("/", addressFrontendHandler.CreateAddress)            // Create own address
 		r.Get("/", addressFrontendHandler.ListAddresses)             // List own addresses
 		r.Get("/{id}", addressFrontendHandler.GetAddress)            // Get own address by ID
@@ -10,7 +10,11 @@ import (
 	"github.com/user/coc/internal/app/admin"
 	"github.com/user/coc/internal/app/admin_auth"
 	"github.com/user/coc/internal/app/admin_menu"
+	"github.com/user/coc/internal/app/audit_log"
+	"github.com/user/coc/internal/app/data_export"
 	"github.com/user/coc/internal/app/frontend_auth"
+	"github.com/user/coc/internal/app/role"
+	"github.com/user/coc/internal/app/security_event"
 	"github.com/user/coc/internal/app/user"
 	"github.com/user/coc/internal/middleware"
 
@@ -27,8 +31,17 @@ func New(
 	userAuthHandler *frontend_auth.Handler,
 	adminAuthHandler *admin_auth.AuthHandler,
 	adminHandler *admin.Handler,
+	roleHandler *role.Handler,
 	menuHandler *admin_menu.Handler,
+	auditLogHandler *audit_log.Handler,
+	securityEventAdminHandler *security_event.AdminHandler,
+	securityEventFrontendHandler *security_event.FrontendHandler,
+	dataExportAdminHandler *data_export.AdminHandler,
+	dataExportFrontendHandler *data_export.FrontendHandler,
+	jwksHandler http.HandlerFunc,
+	auditContextMiddleware func(http.Handler) http.Handler,
 	userAuthMiddleware func(http.Handler) http.Handler,
+	verifiedEmailMiddleware func(http.Handler) http.Handler,
 	adminAuthMiddleware func(http.Handler) http.Handler,
 	permissionMiddleware *middleware.PermissionMiddleware,
 ) http.Handler {
@@ -38,8 +51,8 @@ func New(
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recovery)
 	r.Use(middleware.Timeout(60 * time.Second))
-	r.Use(middleware.RequestID)    // Add request ID to all requests
-	r.Use(middleware.AuditContext) // Add IP and user agent to all requests
+	r.Use(middleware.RequestID)   // Add request ID to all requests
+	r.Use(auditContextMiddleware) // Add IP and user agent to all requests
 
 	// Health check
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -48,6 +61,9 @@ func New(
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Public keys for verifying tokens issued by this API
+	r.Get("/.well-known/jwks.json", jwksHandler)
+
 	// Swagger UI endpoint
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
@@ -58,7 +74,10 @@ func New(
 		userFrontendHandler,
 		addressFrontendHandler,
 		userAuthHandler,
+		securityEventFrontendHandler,
+		dataExportFrontendHandler,
 		userAuthMiddleware,
+		verifiedEmailMiddleware,
 	))
 
 	// Mount admin API router (admin panel)
@@ -67,7 +86,11 @@ func New(
 		addressAdminHandler,
 		adminAuthHandler,
 		adminHandler,
+		roleHandler,
 		menuHandler,
+		auditLogHandler,
+		securityEventAdminHandler,
+		dataExportAdminHandler,
 		adminAuthMiddleware,
 		permissionMiddleware,
 	))
@@ -0,0 +1,86 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+)
+
+// JWKSet is a JSON Web Key Set document (RFC 7517)
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKs returns the public keys of the set, sorted by key ID.
+// HMAC secrets are never published.
+func (s *KeySet) PublicJWKs() []JWK {
+	jwks := make([]JWK, 0, len(s.keys))
+	for _, key := range s.keys {
+		if key.ID == "" {
+			continue
+		}
+		jwks = append(jwks, newJWK(key.verifyKey, key.ID))
+	}
+
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
+
+// JWKSHandler serves the public keys of the given key sets as a JWK Set.
+// It is mounted at /.well-known/jwks.json so other services can verify our tokens.
+func JWKSHandler(sets ...*KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc := JWKSet{Keys: []JWK{}}
+		seen := make(map[string]bool)
+		for _, set := range sets {
+			for _, jwk := range set.PublicJWKs() {
+				if !seen[jwk.Kid] {
+					seen[jwk.Kid] = true
+					doc.Keys = append(doc.Keys, jwk)
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(doc)
+	}
+}
+
+// PublicKey decodes the key, for verifying tokens signed by other issuers.
+// RSA and Ed25519 keys are supported, like for our own keys.
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+}
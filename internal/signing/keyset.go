@@ -0,0 +1,298 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a single JWT signing or verification key
+type Key struct {
+	// ID is published as the "kid" header. HMAC keys have no ID.
+	ID     string
+	Method jwt.SigningMethod
+	// signKey is the private key or HMAC secret; nil for verification-only keys
+	signKey interface{}
+	// verifyKey is the public key or HMAC secret
+	verifyKey interface{}
+}
+
+// KeySet holds the key used to sign new tokens and every key accepted when
+// verifying them. Keeping the previous public keys in the set lets tokens
+// issued before a rotation stay valid until they expire.
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// NewHMACKeySet creates a key set that signs and verifies with a shared HS256 secret
+func NewHMACKeySet(secret string) *KeySet {
+	key := &Key{
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+	return &KeySet{active: key, keys: map[string]*Key{"": key}}
+}
+
+// LoadKeySet creates a key set that signs with the private key in privateKeyFile
+// and additionally accepts tokens signed by the public keys in verifyKeyFiles.
+// Keys must be PEM encoded RSA (RS256) or Ed25519 (EdDSA) keys.
+func LoadKeySet(privateKeyFile string, verifyKeyFiles ...string) (*KeySet, error) {
+	active, err := loadPrivateKey(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{active: active, keys: map[string]*Key{active.ID: active}}
+	for _, file := range verifyKeyFiles {
+		key, err := loadPublicKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := set.keys[key.ID]; !exists {
+			set.keys[key.ID] = key
+		}
+	}
+
+	return set, nil
+}
+
+// NewKeySet creates a key set from an in-memory private key (*rsa.PrivateKey or
+// ed25519.PrivateKey) and extra public keys accepted for verification
+func NewKeySet(privateKey crypto.Signer, verifyKeys ...crypto.PublicKey) (*KeySet, error) {
+	active, err := newPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{active: active, keys: map[string]*Key{active.ID: active}}
+	for _, pub := range verifyKeys {
+		key, err := newPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := set.keys[key.ID]; !exists {
+			set.keys[key.ID] = key
+		}
+	}
+
+	return set, nil
+}
+
+// Sign signs claims with the active key and sets the "kid" header
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	if s.active.ID != "" {
+		token.Header["kid"] = s.active.ID
+	}
+	return token.SignedString(s.active.signKey)
+}
+
+// Keyfunc returns the verification key for a parsed token. The key is selected
+// by "kid" and must have been created for the algorithm in the token header.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.verifyKey, nil
+}
+
+// Algorithms lists the algorithms of every key in the set, for jwt.WithValidMethods
+func (s *KeySet) Algorithms() []string {
+	seen := make(map[string]bool)
+	algs := make([]string, 0, 1)
+	for _, key := range s.keys {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+// ActiveKeyID returns the "kid" of the key new tokens are signed with
+func (s *KeySet) ActiveKeyID() string {
+	return s.active.ID
+}
+
+func loadPrivateKey(file string) (*Key, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported private key type %T", file, parsed)
+	}
+
+	key, err := newPrivateKey(signer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return key, nil
+}
+
+func loadPublicKey(file string) (*Key, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	key, err := newPublicKey(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return key, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+	return block, nil
+}
+
+func newPrivateKey(priv crypto.Signer) (*Key, error) {
+	key, err := newPublicKey(priv.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.signKey = priv
+	return key, nil
+}
+
+func newPublicKey(pub crypto.PublicKey) (*Key, error) {
+	var method jwt.SigningMethod
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	jwk := newJWK(pub, "")
+	return &Key{
+		ID:        jwk.thumbprint(),
+		Method:    method,
+		verifyKey: pub,
+	}, nil
+}
+
+// JWK is the JSON Web Key representation of a public key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+func newJWK(pub crypto.PublicKey, kid string) JWK {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Kid: kid,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k),
+		}
+	}
+	return JWK{}
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint, used as the key ID
+func (j JWK) thumbprint() string {
+	var members interface{}
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
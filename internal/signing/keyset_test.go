@@ -0,0 +1,245 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "test",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func parse(t *testing.T, set *KeySet, token string) error {
+	t.Helper()
+	_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, set.Keyfunc, jwt.WithValidMethods(set.Algorithms()))
+	return err
+}
+
+// TestHMACKeySet tests signing and verifying with a shared secret
+func TestHMACKeySet(t *testing.T) {
+	set := NewHMACKeySet("test-secret")
+
+	token, err := set.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := parse(t, set, token); err != nil {
+		t.Errorf("expected token to verify, got %v", err)
+	}
+	if err := parse(t, NewHMACKeySet("other-secret"), token); err == nil {
+		t.Error("expected token signed with another secret to be rejected")
+	}
+	if len(set.PublicJWKs()) != 0 {
+		t.Error("expected HMAC secrets to never be published")
+	}
+}
+
+// TestAsymmetricKeySet tests RS256 and EdDSA signing with a kid header
+func TestAsymmetricKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  crypto.Signer
+		alg  string
+	}{
+		{"RS256", rsaKey, "RS256"},
+		{"EdDSA", edKey, "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewKeySet(tt.key)
+			if err != nil {
+				t.Fatalf("NewKeySet failed: %v", err)
+			}
+
+			token, err := set.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatalf("failed to parse token: %v", err)
+			}
+			if parsed.Header["kid"] != set.ActiveKeyID() {
+				t.Errorf("expected kid %s, got %v", set.ActiveKeyID(), parsed.Header["kid"])
+			}
+			if parsed.Header["alg"] != tt.alg {
+				t.Errorf("expected alg %s, got %v", tt.alg, parsed.Header["alg"])
+			}
+
+			if err := parse(t, set, token); err != nil {
+				t.Errorf("expected token to verify, got %v", err)
+			}
+		})
+	}
+}
+
+// TestKeySet_Rotation tests that tokens signed by a previous key verify while it is still listed
+func TestKeySet_Rotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	oldSet, err := NewKeySet(oldKey)
+	if err != nil {
+		t.Fatalf("NewKeySet failed: %v", err)
+	}
+	oldToken, err := oldSet.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	rotated, err := NewKeySet(newKey, oldKey.Public())
+	if err != nil {
+		t.Fatalf("NewKeySet failed: %v", err)
+	}
+	if rotated.ActiveKeyID() == oldSet.ActiveKeyID() {
+		t.Fatal("expected a different kid after rotation")
+	}
+	if err := parse(t, rotated, oldToken); err != nil {
+		t.Errorf("expected token signed by previous key to verify, got %v", err)
+	}
+
+	retired, err := NewKeySet(newKey)
+	if err != nil {
+		t.Fatalf("NewKeySet failed: %v", err)
+	}
+	if err := parse(t, retired, oldToken); err == nil {
+		t.Error("expected token signed by a retired key to be rejected")
+	}
+}
+
+// TestKeySet_AlgorithmConfusion tests that an HMAC token cannot claim an asymmetric kid
+func TestKeySet_AlgorithmConfusion(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	set, err := NewKeySet(edKey)
+	if err != nil {
+		t.Fatalf("NewKeySet failed: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	token.Header["kid"] = set.ActiveKeyID()
+	forged, err := token.SignedString([]byte(edKey.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	if err := parse(t, set, forged); err == nil {
+		t.Error("expected HS256 token to be rejected by an EdDSA key set")
+	}
+}
+
+// TestLoadKeySet tests loading PEM key files and publishing them as a JWK Set
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	privFile := writePEM(t, dir, "current.pem", "PRIVATE KEY", privDER)
+
+	edPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	pubDER, err := x509.MarshalPKIXPublicKey(edPub)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	pubFile := writePEM(t, dir, "previous.pub", "PUBLIC KEY", pubDER)
+
+	set, err := LoadKeySet(privFile, pubFile)
+	if err != nil {
+		t.Fatalf("LoadKeySet failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(set).ServeHTTP(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var doc JWKSet
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode JWK Set: %v", err)
+	}
+	if len(doc.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(doc.Keys))
+	}
+
+	kty := make(map[string]string)
+	for _, jwk := range doc.Keys {
+		kty[jwk.Kid] = jwk.Kty
+	}
+	if kty[set.ActiveKeyID()] != "RSA" {
+		t.Errorf("expected active key %s to be published as RSA", set.ActiveKeyID())
+	}
+
+	if _, err := LoadKeySet(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("expected error for missing key file")
+	}
+}
+
+// TestJWK_PublicKey tests that published keys decode back to the same public key
+func TestJWK_PublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+
+	for _, pub := range []crypto.PublicKey{rsaKey.Public(), edPub} {
+		decoded, err := newJWK(pub, "kid").PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey failed: %v", err)
+		}
+		if !decoded.(interface{ Equal(crypto.PublicKey) bool }).Equal(pub) {
+			t.Errorf("expected %T to round-trip", pub)
+		}
+	}
+
+	if _, err := (JWK{Kty: "EC", Crv: "P-256"}).PublicKey(); err == nil {
+		t.Error("expected unsupported key type to be rejected")
+	}
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return file
+}
@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// SecretBox encrypts TOTP secrets at rest with AES-256-GCM, and keys the
+// hashes of recovery codes so a database dump alone cannot be brute-forced
+type SecretBox struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// NewSecretBox derives an AES-256 key and a separate HMAC key from key
+func NewSecretBox(key string) (*SecretBox, error) {
+	if key == "" {
+		return nil, fmt.Errorf("encryption key is required")
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	macKey, err := hkdf.Key(sha256.New, []byte(key), nil, "mfa-recovery-code", sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretBox{aead: aead, macKey: macKey}, nil
+}
+
+// MAC returns the hex encoded HMAC-SHA256 of value. Unlike Seal it is
+// deterministic, so it can be looked up.
+func (b *SecretBox) MAC(value string) string {
+	mac := hmac.New(sha256.New, b.macKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Seal encrypts plaintext and returns it base64 encoded with its nonce
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal
+func (b *SecretBox) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed secret: %w", err)
+	}
+
+	size := b.aead.NonceSize()
+	if len(data) < size {
+		return "", fmt.Errorf("invalid sealed secret")
+	}
+
+	plaintext, err := b.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
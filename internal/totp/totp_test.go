@@ -0,0 +1,122 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 Appendix B ("12345678901234567890")
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// TestCode tests code generation against the RFC 6238 test vectors (last 6 digits)
+func TestCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, tt.unix/Period)
+		if err != nil {
+			t.Fatalf("Code failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d: expected %s, got %s", tt.unix, tt.want, got)
+		}
+	}
+}
+
+// TestValidate tests accepted clock skew and rejection of wrong codes
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	previous, _ := Code(rfcSecret, step-1)
+	if got, ok := Validate(rfcSecret, previous, now); !ok || got != step-1 {
+		t.Errorf("expected previous step code to be accepted with step %d, got %d, %v", step-1, got, ok)
+	}
+
+	stale, _ := Code(rfcSecret, step-2)
+	if _, ok := Validate(rfcSecret, stale, now); ok {
+		t.Error("expected code two steps old to be rejected")
+	}
+
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("expected %q to be rejected", code)
+		}
+	}
+}
+
+// TestGenerateSecret tests that secrets are valid base32 and unique
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret failed: %v", err)
+	}
+	b, _ := GenerateSecret()
+	if a == b {
+		t.Error("expected unique secrets")
+	}
+	if _, err := Code(a, 1); err != nil {
+		t.Errorf("expected generated secret to be usable, got %v", err)
+	}
+}
+
+// TestProvisioningURI tests the otpauth URI format
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("COC Admin", "admin@example.com", "JBSWY3DPEHPK3PXP")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/COC%20Admin:admin@example.com?") {
+		t.Errorf("unexpected URI label: %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=COC+Admin", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("expected URI to contain %s, got %s", part, uri)
+		}
+	}
+}
+
+// TestSecretBox tests sealing and opening secrets
+func TestSecretBox(t *testing.T) {
+	box, err := NewSecretBox("test-key")
+	if err != nil {
+		t.Fatalf("NewSecretBox failed: %v", err)
+	}
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") {
+		t.Error("expected sealed value to hide the secret")
+	}
+
+	opened, err := box.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if opened != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("expected round trip, got %s", opened)
+	}
+
+	other, _ := NewSecretBox("other-key")
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("expected error when opening with a different key")
+	}
+
+	if box.MAC("abcdefghjk") != box.MAC("abcdefghjk") {
+		t.Error("expected MAC to be deterministic")
+	}
+	if box.MAC("abcdefghjk") == other.MAC("abcdefghjk") {
+		t.Error("expected MAC to depend on the key")
+	}
+}
@@ -0,0 +1,123 @@
+// Package usersession records where frontend users are signed in. A session is
+// one login: its ID is the refresh token family of the login, carried as sid in
+// access tokens, and it stays active while the family can still be refreshed.
+// Revoking the refresh tokens (logout, password change, reuse detection) ends
+// the session as well.
+package usersession
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/user/coc/internal/audit"
+	"github.com/user/coc/internal/db"
+	"github.com/user/coc/internal/errors"
+)
+
+// touchInterval is how stale last_seen_at may get before a request updates it
+const touchInterval = time.Minute
+
+// Store records and revokes user sessions
+type Store struct {
+	queries      *db.Queries
+	auditService *audit.Service
+	now          func() time.Time
+}
+
+// NewStore creates a user session store
+func NewStore(queries *db.Queries, auditService *audit.Service) *Store {
+	return &Store{
+		queries:      queries,
+		auditService: auditService,
+		now:          time.Now,
+	}
+}
+
+// Start records a new session for the refresh token family of a login. The
+// device and IP address come from the audit context of the request.
+func (s *Store) Start(ctx context.Context, userID, sessionID uuid.UUID) error {
+	auditCtx := audit.ExtractAuditContext(ctx)
+
+	err := s.queries.CreateUserSession(ctx, db.CreateUserSessionParams{
+		ID:        pgtype.UUID{Bytes: sessionID, Valid: true},
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+		UserAgent: pgtype.Text{String: auditCtx.UserAgent, Valid: auditCtx.UserAgent != ""},
+		IpAddress: pgtype.Text{String: auditCtx.IPAddress, Valid: auditCtx.IPAddress != ""},
+	})
+	if err != nil {
+		slog.Error("failed to record session", "user_id", userID.String(), "error", err)
+		return errors.Internal("failed to start session", err)
+	}
+	return nil
+}
+
+// Check rejects a session that was revoked or has expired, and records the
+// request as the session's last activity
+func (s *Store) Check(ctx context.Context, sessionID uuid.UUID) error {
+	pgSessionID := pgtype.UUID{Bytes: sessionID, Valid: true}
+
+	active, err := s.queries.HasActiveRefreshToken(ctx, pgSessionID)
+	if err != nil {
+		return errors.Internal("failed to check session", err)
+	}
+	if !active {
+		return errors.Unauthorized("session has been revoked")
+	}
+
+	auditCtx := audit.ExtractAuditContext(ctx)
+	err = s.queries.TouchUserSession(ctx, db.TouchUserSessionParams{
+		ID:         pgSessionID,
+		IpAddress:  pgtype.Text{String: auditCtx.IPAddress, Valid: auditCtx.IPAddress != ""},
+		UserAgent:  pgtype.Text{String: auditCtx.UserAgent, Valid: auditCtx.UserAgent != ""},
+		SeenBefore: pgtype.Timestamptz{Time: s.now().Add(-touchInterval), Valid: true},
+	})
+	if err != nil {
+		// Activity is informational; the request can go on
+		slog.Error("failed to record session activity", "session_id", sessionID.String(), "error", err)
+	}
+
+	return nil
+}
+
+// List returns the active sessions of a user, most recently used first
+func (s *Store) List(ctx context.Context, userID uuid.UUID) ([]db.UserSession, error) {
+	sessions, err := s.queries.ListActiveUserSessions(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return nil, errors.Internal("failed to list sessions", err)
+	}
+	return sessions, nil
+}
+
+// Revoke signs a user out of one session. Its refresh tokens are revoked, and
+// its access tokens are rejected from the next request on.
+func (s *Store) Revoke(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.queries.GetActiveUserSession(ctx, db.GetActiveUserSessionParams{
+		ID:     pgtype.UUID{Bytes: sessionID, Valid: true},
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return errors.NotFound("session not found")
+		}
+		return errors.Internal("failed to get session", err)
+	}
+
+	if _, err := s.queries.RevokeRefreshTokenFamily(ctx, session.ID); err != nil {
+		slog.Error("failed to revoke session", "session_id", sessionID.String(), "error", err)
+		return errors.Internal("failed to revoke session", err)
+	}
+
+	s.auditService.LogDelete(ctx, "user_sessions", sessionID, session)
+
+	return nil
+}
+
+// DeleteExpired removes sessions whose refresh tokens have all been purged.
+// Run it after the refresh token cleanup.
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.queries.DeleteEndedUserSessions(ctx)
+}